@@ -30,6 +30,7 @@ import (
 	"github.com/ligato/cn-infra/logging/logrus"
 	"github.com/ligato/sfc-controller/controller/cnpdriver/l2driver"
 	"github.com/ligato/sfc-controller/controller/model/controller"
+	"github.com/ligato/sfc-controller/controller/utils/ipam"
 )
 
 var (
@@ -50,19 +51,28 @@ type SfcControllerCNPDriverAPI interface {
 	GetName() string
 	ReconcileStart(vppEtcdLabels map[string]struct{}) error
 	ReconcileEnd() error
+	ReconcileAbort() error
 	DatastoreReInitialize() error
 	WireHostEntityToDestinationHostEntity(sh *controller.HostEntity, dh *controller.HostEntity) error
 	WireHostEntityToExternalEntity(he *controller.HostEntity, ee *controller.ExternalEntity) error
 	WireInternalsForHostEntity(he *controller.HostEntity) error
 	WireInternalsForExternalEntity(ee *controller.ExternalEntity) error
 	WireSfcEntity(sfc *controller.SfcEntity) error
+	ReleaseSfcEntity(sfc *controller.SfcEntity) error
 	SetSystemParameters(sp *controller.SystemParameters) error
+	SetTenant(tenant *controller.Tenant) error
 	GetSfcInterfaceIPAndMac(container string, port string) (string, string, error)
+	IpamReserve(tenant string, subnet string, ipAddress string, owner string) error
+	IpamRelease(tenant string, subnet string, ipAddress string) error
+	IpamPools() []ipam.PoolUsage
+	IpamAllocations() []ipam.Allocation
+	InterfaceNames() []l2driver.InterfaceName
 	Dump()
 }
 
 // RegisterCNPDriverPlugin registers the container networking policy driver mode: example: sfcctlr layer 2, ...
-func RegisterCNPDriverPlugin(name string, dbFactory func(string) keyval.ProtoBroker) (SfcControllerCNPDriverAPI, error) {
+func RegisterCNPDriverPlugin(name string, instance string, dbFactory func(string) keyval.ProtoBroker,
+	putIfNotExists func(key string, value []byte) (bool, error)) (SfcControllerCNPDriverAPI, error) {
 
 	var cnpDriverAPI SfcControllerCNPDriverAPI
 
@@ -76,7 +86,7 @@ func RegisterCNPDriverPlugin(name string, dbFactory func(string) keyval.ProtoBro
 
 	switch name {
 	case "sfcctlrl2":
-		cnpDriverAPI = l2driver.NewSfcCtlrL2CNPDriver(name, dbFactory)
+		cnpDriverAPI = l2driver.NewSfcCtlrL2CNPDriver(name, instance, dbFactory, putIfNotExists)
 	default:
 		errMsg := fmt.Sprintf("RegisterCNPDriverPlugin: CNPDriver '%s' not recognized", name)
 		log.Error(errMsg)
@@ -25,6 +25,8 @@ package l2driver
 // to CRUD id's for type host, host to external, host to host, and sfc.  The ETCD keys are defined in keys_l2.go
 
 import (
+	"encoding/json"
+	"github.com/ligato/cn-infra/datasync"
 	"github.com/ligato/sfc-controller/controller/cnpdriver/l2driver/model"
 	"fmt"
 )
@@ -49,6 +51,26 @@ func (cnpd *sfcCtlrL2CNPDriver) DatastoreReInitialize() error {
 		log.Error("DatastoreReInitialize: DatastoreSFCIDsDeleteAll: ", err)
 		return err
 	}
+	if err := cnpd.DatastoreSFCHostIDsDeleteAll(); err != nil {
+		log.Error("DatastoreReInitialize: DatastoreSFCHostIDsDeleteAll: ", err)
+		return err
+	}
+	if err := cnpd.DatastoreIPAMAllocationsDeleteAll(); err != nil {
+		log.Error("DatastoreReInitialize: DatastoreIPAMAllocationsDeleteAll: ", err)
+		return err
+	}
+	if err := cnpd.DatastoreIDClaimsDeleteAll(); err != nil {
+		log.Error("DatastoreReInitialize: DatastoreIDClaimsDeleteAll: ", err)
+		return err
+	}
+	if err := cnpd.DatastoreMacAddressClaimsDeleteAll(); err != nil {
+		log.Error("DatastoreReInitialize: DatastoreMacAddressClaimsDeleteAll: ", err)
+		return err
+	}
+	if err := cnpd.DatastoreMemifSecretsDeleteAll(l2.MemifSecretsKeyPrefix()); err != nil {
+		log.Error("DatastoreReInitialize: DatastoreMemifSecretsDeleteAll: ", err)
+		return err
+	}
 
 	return nil
 }
@@ -336,7 +358,9 @@ func (cnpd *sfcCtlrL2CNPDriver) DatastoreHE2HEIDsDelete(ee *l2.HE2HEIDs) error {
 
 // DatastoreSFCIDsCreate creates the specified entity in the sfc db in etcd
 func (cnpd *sfcCtlrL2CNPDriver) DatastoreSFCIDsCreate(sfcName string, container string,
-	port string, ipID uint32, macAddrID uint32, memifID uint32, vethID uint32) (string, *l2.SFCIDs, error) {
+	port string, ipID uint32, macAddrID uint32, memifID uint32, vethID uint32,
+	ipv6ID uint32, vswitchIfName string, hostIfName string, memifSocket string,
+	containerIfName string) (string, *l2.SFCIDs, error) {
 
 	sfc := &l2.SFCIDs{
 		SfcName: sfcName,
@@ -346,6 +370,11 @@ func (cnpd *sfcCtlrL2CNPDriver) DatastoreSFCIDsCreate(sfcName string, container
 		MacAddrId: macAddrID,
 		MemifId: memifID,
 		VethId: vethID,
+		Ipv6Id: ipv6ID,
+		VswitchIfName: vswitchIfName,
+		HostIfName: hostIfName,
+		MemifSocket: memifSocket,
+		ContainerIfName: containerIfName,
 	}
 
 	key := l2.SFCContainerPortIDsNameKey(sfcName, container, port)
@@ -431,3 +460,425 @@ func (cnpd *sfcCtlrL2CNPDriver) DatastoreSFCIDsDelete(ee *l2.HE2EEIDs) error {
 
 	return nil
 }
+
+// DatastoreSFCHostIDsCreate creates the specified entity in the sfc db in etcd
+func (cnpd *sfcCtlrL2CNPDriver) DatastoreSFCHostIDsCreate(sfcName string, hostName string,
+	vrfID uint32) (string, *l2.SFCHostIDs, error) {
+
+	sfcHost := &l2.SFCHostIDs{
+		SfcName:  sfcName,
+		HostName: hostName,
+		VrfId:    vrfID,
+	}
+
+	key := l2.SFCHostIDsNameKey(sfcName, hostName)
+
+	log.Infof("DatastoreSFCHostIDsCreate: setting key: '%s'", key)
+
+	err := cnpd.db.Put(key, sfcHost)
+	if err != nil {
+		log.Errorf("DatastoreSFCHostIDsCreate: error storing key: '%s'", key)
+		log.Error("DatastoreSFCHostIDsCreate: databroker put: ", err)
+		return "", nil, err
+	}
+
+	return key, sfcHost, nil
+}
+
+// DatastoreSFCHostIDsRetrieve gets the specified entity from the sfc db in etcd
+func (cnpd *sfcCtlrL2CNPDriver) DatastoreSFCHostIDsRetrieve(sfcName string, hostName string) (*l2.SFCHostIDs, error) {
+
+	key := l2.SFCHostIDsNameKey(sfcName, hostName)
+	sfcHost := &l2.SFCHostIDs{}
+	found, _, err := cnpd.db.GetValue(key, sfcHost)
+	if err != nil {
+		log.Fatal(err)
+		return nil, err
+	}
+	if !found {
+		err = fmt.Errorf("DatastoreSFCHostIDsRetrieve: not found: %s", key)
+		log.Info(err)
+		return nil, err
+	}
+	return sfcHost, err
+}
+
+// DatastoreSFCHostIDsDeleteAll removes the specified entities from the sfc db in etcd
+func (cnpd *sfcCtlrL2CNPDriver) DatastoreSFCHostIDsDeleteAll() error {
+
+	log.Info("DatastoreSFCHostIDsDeleteAll: begin ...")
+	defer log.Info("DatastoreSFCHostIDsDeleteAll: exit ...")
+
+	return cnpd.DatastoreSFCHostIDsIterate(func(key string, sfcHost *l2.SFCHostIDs) {
+		log.Infof("DatastoreSFCHostIDsDeleteAll: deleting sfc host ids: '%s': %v", key, *sfcHost)
+		cnpd.db.Delete(key)
+	})
+}
+
+// DatastoreSFCHostIDsIterate iterates over the set of specified entities in the sfc tree in etcd
+func (cnpd *sfcCtlrL2CNPDriver) DatastoreSFCHostIDsIterate(actionFunc func(key string,
+	sfcHost *l2.SFCHostIDs)) error {
+
+	kvi, err := cnpd.db.ListValues(l2.SFCHostIDsKeyPrefix())
+	if err != nil {
+		log.Fatal(err)
+		return nil
+	}
+
+	for {
+		kv, allReceived := kvi.GetNext()
+		if allReceived {
+			return nil
+		}
+		sfcHost := &l2.SFCHostIDs{}
+		err := kv.GetValue(sfcHost)
+		if err != nil {
+			log.Fatal(err)
+			return nil
+		}
+
+		log.Infof("DatastoreSFCHostIDsIterate: getting sfc host ids: '%s': ", kv.GetKey())
+		actionFunc(kv.GetKey(), sfcHost)
+	}
+}
+
+// DatastoreIPAMAllocationCreate creates the specified entity in the sfc db in etcd
+func (cnpd *sfcCtlrL2CNPDriver) DatastoreIPAMAllocationCreate(tenant string, subnet string, ipID uint32,
+	ipAddress string, sfcName string, container string, port string, owner string) (string, *l2.IPAMAllocation, error) {
+
+	alloc := &l2.IPAMAllocation{
+		Tenant:    tenant,
+		Subnet:    subnet,
+		IpId:      ipID,
+		IpAddress: ipAddress,
+		SfcName:   sfcName,
+		Container: container,
+		Port:      port,
+		Owner:     owner,
+	}
+
+	key := l2.IPAMAllocationKey(tenant, subnet, ipID)
+
+	log.Infof("DatastoreIPAMAllocationCreate: setting key: '%s'", key)
+
+	err := cnpd.db.Put(key, alloc)
+	if err != nil {
+		log.Errorf("DatastoreIPAMAllocationCreate: error storing key: '%s'", key)
+		log.Error("DatastoreIPAMAllocationCreate: databroker put: ", err)
+		return "", nil, err
+	}
+	return key, alloc, nil
+}
+
+// DatastoreIPAMAllocationDelete deletes the specified entity from the sfc db in the etcd tree
+func (cnpd *sfcCtlrL2CNPDriver) DatastoreIPAMAllocationDelete(tenant string, subnet string, ipID uint32) error {
+
+	key := l2.IPAMAllocationKey(tenant, subnet, ipID)
+
+	log.Infof("DatastoreIPAMAllocationDelete: deleting key: '%s'", key)
+
+	if _, err := cnpd.db.Delete(key); err != nil {
+		log.Error("DatastoreIPAMAllocationDelete: databroker delete: ", err)
+		return err
+	}
+	return nil
+}
+
+// DatastoreIPAMAllocationsDeleteAll removes the specified entities from the sfc db in etcd
+func (cnpd *sfcCtlrL2CNPDriver) DatastoreIPAMAllocationsDeleteAll() error {
+
+	log.Info("DatastoreIPAMAllocationsDeleteAll: begin ...")
+	defer log.Info("DatastoreIPAMAllocationsDeleteAll: exit ...")
+
+	return cnpd.DatastoreIPAMAllocationsIterate(func(key string, alloc *l2.IPAMAllocation) {
+		log.Infof("DatastoreIPAMAllocationsDeleteAll: deleting allocation: '%s': %v", key, *alloc)
+		cnpd.db.Delete(key)
+	})
+}
+
+// DatastoreIPAMAllocationsIterate iterates over the set of specified entities in the sfc tree in etcd
+func (cnpd *sfcCtlrL2CNPDriver) DatastoreIPAMAllocationsIterate(actionFunc func(key string,
+	alloc *l2.IPAMAllocation)) error {
+
+	kvi, err := cnpd.db.ListValues(l2.IPAMAllocationsKeyPrefix())
+	if err != nil {
+		log.Fatal(err)
+		return nil
+	}
+
+	for {
+		kv, allReceived := kvi.GetNext()
+		if allReceived {
+			return nil
+		}
+		alloc := &l2.IPAMAllocation{}
+		err := kv.GetValue(alloc)
+		if err != nil {
+			log.Fatal(err)
+			return nil
+		}
+
+		log.Infof("DatastoreIPAMAllocationsIterate: getting allocation: '%s': %v", kv.GetKey(), alloc)
+		actionFunc(kv.GetKey(), alloc)
+	}
+}
+
+// DatastoreIDClaimCreate stores the claim only if the id is not claimed yet, the put if not exists is atomic so
+// concurrent controllers cannot both claim an id, false is returned if the id is already claimed
+func (cnpd *sfcCtlrL2CNPDriver) DatastoreIDClaimCreate(rangeName string, id uint32, owner string,
+	instance string) (bool, error) {
+
+	claim := &l2.IDClaim{
+		Range:    rangeName,
+		Id:       id,
+		Owner:    owner,
+		Instance: instance,
+	}
+
+	key := l2.IDClaimKey(rangeName, id)
+
+	log.Infof("DatastoreIDClaimCreate: claiming key: '%s'", key)
+
+	// the etcd brokers serialize as json so the claim reads back like any other entity
+	data, err := json.Marshal(claim)
+	if err != nil {
+		return false, err
+	}
+	claimed, err := cnpd.putIfNotExists(key, data)
+	if err != nil {
+		log.Errorf("DatastoreIDClaimCreate: error claiming key: '%s'", key)
+		log.Error("DatastoreIDClaimCreate: put if not exists: ", err)
+		return false, err
+	}
+	return claimed, nil
+}
+
+// DatastoreIDClaimRetrieve returns the claim of the id, nil if the id is not claimed
+func (cnpd *sfcCtlrL2CNPDriver) DatastoreIDClaimRetrieve(rangeName string, id uint32) (*l2.IDClaim, error) {
+
+	key := l2.IDClaimKey(rangeName, id)
+	claim := &l2.IDClaim{}
+	found, _, err := cnpd.db.GetValue(key, claim)
+	if err != nil {
+		log.Error("DatastoreIDClaimRetrieve: databroker get: ", err)
+		return nil, err
+	}
+	if !found {
+		return nil, nil
+	}
+	return claim, nil
+}
+
+// DatastoreIDClaimDelete deletes the specified entity from the sfc db in the etcd tree
+func (cnpd *sfcCtlrL2CNPDriver) DatastoreIDClaimDelete(rangeName string, id uint32) error {
+
+	key := l2.IDClaimKey(rangeName, id)
+
+	log.Infof("DatastoreIDClaimDelete: deleting key: '%s'", key)
+
+	if _, err := cnpd.db.Delete(key); err != nil {
+		log.Error("DatastoreIDClaimDelete: databroker delete: ", err)
+		return err
+	}
+	return nil
+}
+
+// DatastoreIDClaimsDeleteAll removes the claims of this controller instance from the sfc db in etcd, the
+// claims of the other instances are left as is
+func (cnpd *sfcCtlrL2CNPDriver) DatastoreIDClaimsDeleteAll() error {
+
+	log.Info("DatastoreIDClaimsDeleteAll: begin ...")
+	defer log.Info("DatastoreIDClaimsDeleteAll: exit ...")
+
+	return cnpd.DatastoreIDClaimsIterate(func(key string, claim *l2.IDClaim) {
+		if claim.Instance != "" && claim.Instance != cnpd.instance {
+			return
+		}
+		log.Infof("DatastoreIDClaimsDeleteAll: deleting claim: '%s': %v", key, *claim)
+		cnpd.db.Delete(key)
+	})
+}
+
+// DatastoreIDClaimsIterate iterates over the set of specified entities in the sfc tree in etcd
+func (cnpd *sfcCtlrL2CNPDriver) DatastoreIDClaimsIterate(actionFunc func(key string,
+	claim *l2.IDClaim)) error {
+
+	kvi, err := cnpd.db.ListValues(l2.IDClaimsKeyPrefix())
+	if err != nil {
+		log.Error("DatastoreIDClaimsIterate: databroker list: ", err)
+		return err
+	}
+
+	for {
+		kv, allReceived := kvi.GetNext()
+		if allReceived {
+			return nil
+		}
+		claim := &l2.IDClaim{}
+		if err := kv.GetValue(claim); err != nil {
+			log.Error("DatastoreIDClaimsIterate: databroker get: ", err)
+			return err
+		}
+
+		log.Debugf("DatastoreIDClaimsIterate: getting claim: '%s': %v", kv.GetKey(), claim)
+		actionFunc(kv.GetKey(), claim)
+	}
+}
+
+// DatastoreMacAddressClaimCreate claims the mac in the sfc db in etcd, false if the mac is already claimed
+func (cnpd *sfcCtlrL2CNPDriver) DatastoreMacAddressClaimCreate(claim *l2.MacAddressClaim) (bool, error) {
+
+	key := l2.MacAddressClaimKey(claim.MacAddress)
+
+	log.Infof("DatastoreMacAddressClaimCreate: claiming key: '%s'", key)
+
+	// the etcd brokers serialize as json so the claim reads back like any other entity
+	data, err := json.Marshal(claim)
+	if err != nil {
+		return false, err
+	}
+	claimed, err := cnpd.putIfNotExists(key, data)
+	if err != nil {
+		log.Errorf("DatastoreMacAddressClaimCreate: error claiming key: '%s'", key)
+		log.Error("DatastoreMacAddressClaimCreate: put if not exists: ", err)
+		return false, err
+	}
+	return claimed, nil
+}
+
+// DatastoreMacAddressClaimRetrieve gets the claim of the mac from the sfc db in etcd, nil if not claimed
+func (cnpd *sfcCtlrL2CNPDriver) DatastoreMacAddressClaimRetrieve(macAddress string) (*l2.MacAddressClaim, error) {
+
+	key := l2.MacAddressClaimKey(macAddress)
+	claim := &l2.MacAddressClaim{}
+	found, _, err := cnpd.db.GetValue(key, claim)
+	if err != nil {
+		log.Error("DatastoreMacAddressClaimRetrieve: databroker get: ", err)
+		return nil, err
+	}
+	if !found {
+		return nil, nil
+	}
+	return claim, nil
+}
+
+// DatastoreMacAddressClaimDelete deletes the claim of the mac from the sfc db in the etcd tree
+func (cnpd *sfcCtlrL2CNPDriver) DatastoreMacAddressClaimDelete(macAddress string) error {
+
+	key := l2.MacAddressClaimKey(macAddress)
+
+	log.Infof("DatastoreMacAddressClaimDelete: deleting key: '%s'", key)
+
+	if _, err := cnpd.db.Delete(key); err != nil {
+		log.Error("DatastoreMacAddressClaimDelete: databroker delete: ", err)
+		return err
+	}
+	return nil
+}
+
+// DatastoreMacAddressClaimsDeleteAll removes the mac claims of this controller instance from the sfc db in etcd
+func (cnpd *sfcCtlrL2CNPDriver) DatastoreMacAddressClaimsDeleteAll() error {
+
+	log.Info("DatastoreMacAddressClaimsDeleteAll: begin ...")
+	defer log.Info("DatastoreMacAddressClaimsDeleteAll: exit ...")
+
+	return cnpd.DatastoreMacAddressClaimsIterate(func(key string, claim *l2.MacAddressClaim) {
+		if claim.Instance != cnpd.instance {
+			return
+		}
+		log.Infof("DatastoreMacAddressClaimsDeleteAll: deleting claim: '%s': %v", key, *claim)
+		cnpd.db.Delete(key)
+	})
+}
+
+// DatastoreMacAddressClaimsIterate iterates over the set of specified entities in the sfc tree in etcd
+func (cnpd *sfcCtlrL2CNPDriver) DatastoreMacAddressClaimsIterate(actionFunc func(key string,
+	claim *l2.MacAddressClaim)) error {
+
+	kvi, err := cnpd.db.ListValues(l2.MacAddressClaimsKeyPrefix())
+	if err != nil {
+		log.Error("DatastoreMacAddressClaimsIterate: databroker list: ", err)
+		return err
+	}
+
+	for {
+		kv, allReceived := kvi.GetNext()
+		if allReceived {
+			return nil
+		}
+		claim := &l2.MacAddressClaim{}
+		if err := kv.GetValue(claim); err != nil {
+			log.Error("DatastoreMacAddressClaimsIterate: databroker get: ", err)
+			return err
+		}
+
+		log.Debugf("DatastoreMacAddressClaimsIterate: getting claim: '%s': %v", kv.GetKey(), claim)
+		actionFunc(kv.GetKey(), claim)
+	}
+}
+
+// DatastoreMemifSecretCreate keeps the generated secret of the memif pair of the container port in etcd, the
+// secret is never logged
+func (cnpd *sfcCtlrL2CNPDriver) DatastoreMemifSecretCreate(sfcName string, container string, port string,
+	secret string) error {
+
+	key := l2.MemifSecretKey(sfcName, container, port)
+
+	log.Infof("DatastoreMemifSecretCreate: setting key: '%s'", key)
+
+	memifSecret := &l2.MemifSecret{
+		SfcName:   sfcName,
+		Container: container,
+		Port:      port,
+		Secret:    secret,
+	}
+	if err := cnpd.db.Put(key, memifSecret); err != nil {
+		log.Errorf("DatastoreMemifSecretCreate: error storing key: '%s'", key)
+		return err
+	}
+	return nil
+}
+
+// DatastoreMemifSecretRetrieve gets the generated secret of the memif pair of the container port from etcd, ""
+// if none was generated
+func (cnpd *sfcCtlrL2CNPDriver) DatastoreMemifSecretRetrieve(sfcName string, container string,
+	port string) (string, error) {
+
+	key := l2.MemifSecretKey(sfcName, container, port)
+	memifSecret := &l2.MemifSecret{}
+	found, _, err := cnpd.db.GetValue(key, memifSecret)
+	if err != nil {
+		log.Errorf("DatastoreMemifSecretRetrieve: error getting key: '%s'", key)
+		return "", err
+	}
+	if !found {
+		return "", nil
+	}
+	return memifSecret.Secret, nil
+}
+
+// DatastoreMemifSecretDelete deletes the generated secret of the memif pair of the container port from etcd
+func (cnpd *sfcCtlrL2CNPDriver) DatastoreMemifSecretDelete(sfcName string, container string, port string) error {
+
+	key := l2.MemifSecretKey(sfcName, container, port)
+
+	log.Infof("DatastoreMemifSecretDelete: deleting key: '%s'", key)
+
+	if _, err := cnpd.db.Delete(key); err != nil {
+		log.Error("DatastoreMemifSecretDelete: databroker delete: ", err)
+		return err
+	}
+	return nil
+}
+
+// DatastoreMemifSecretsDeleteAll removes the generated memif secrets with the prefix from etcd
+func (cnpd *sfcCtlrL2CNPDriver) DatastoreMemifSecretsDeleteAll(prefix string) error {
+
+	log.Infof("DatastoreMemifSecretsDeleteAll: deleting keys: '%s'", prefix)
+
+	if _, err := cnpd.db.Delete(prefix, datasync.WithPrefix()); err != nil {
+		log.Error("DatastoreMemifSecretsDeleteAll: databroker delete: ", err)
+		return err
+	}
+	return nil
+}
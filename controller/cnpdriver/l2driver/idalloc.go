@@ -0,0 +1,261 @@
+// Copyright (c) 2017 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// The vlan/vni, mac instance, memif, veth and auto vrf ids of the driver are
+// handed out by the driver's id allocator.  Each kind of id is a named range, a
+// tenant has its own vni range, ie: "vni/<tenant>", its macs are a window of the
+// mac range, and a host has its own vrf range, ie: "vrf/<host>".  An id is claimed in
+// etcd for the key of the ids record it is allocated for, ie: the HE2EE ids
+// of a tunnel, and it is released when the record is removed by a reconcile,
+// or when the sfc owning the record is deleted.
+
+package l2driver
+
+import (
+	"strings"
+
+	"github.com/ligato/cn-infra/datasync"
+	l2driver "github.com/ligato/sfc-controller/controller/cnpdriver/l2driver/model"
+	"github.com/ligato/sfc-controller/controller/utils/idalloc"
+)
+
+const (
+	idRangeVni   = "vni"
+	idRangeMac   = "mac"
+	idRangeMemif = "memif"
+	idRangeVeth  = "veth"
+	idRangeVrf   = "vrf"
+
+	maxVni = 0xFFFFFF // a vni is 24 bits
+	maxID  = 0xFFFFFFFF
+
+	defaultAutoVrfRangeStart = 1000 // see validate.go
+	defaultAutoVrfRangeEnd   = 1999
+)
+
+// idClaimStore keeps the claims of the id allocator in etcd
+type idClaimStore struct {
+	cnpd *sfcCtlrL2CNPDriver
+}
+
+func (s *idClaimStore) Claim(claim *idalloc.Claim) (bool, error) {
+	return s.cnpd.DatastoreIDClaimCreate(claim.Range, claim.ID, claim.Owner, claim.Instance)
+}
+
+func (s *idClaimStore) Get(rangeName string, id uint32) (*idalloc.Claim, error) {
+	claim, err := s.cnpd.DatastoreIDClaimRetrieve(rangeName, id)
+	if err != nil || claim == nil {
+		return nil, err
+	}
+	return s.toClaim(claim), nil
+}
+
+func (s *idClaimStore) Release(rangeName string, id uint32) error {
+	return s.cnpd.DatastoreIDClaimDelete(rangeName, id)
+}
+
+func (s *idClaimStore) Claims() ([]idalloc.Claim, error) {
+	claims := make([]idalloc.Claim, 0)
+	err := s.cnpd.DatastoreIDClaimsIterate(func(key string, claim *l2driver.IDClaim) {
+		claims = append(claims, *s.toClaim(claim))
+	})
+	return claims, err
+}
+
+// toClaim converts a claim of the db, a claim stored before the instance was recorded is adopted by this
+// instance
+func (s *idClaimStore) toClaim(claim *l2driver.IDClaim) *idalloc.Claim {
+	instance := claim.Instance
+	if instance == "" {
+		instance = s.cnpd.instance
+	}
+	return &idalloc.Claim{Range: claim.Range, ID: claim.Id, Owner: claim.Owner, Instance: instance}
+}
+
+// tenantIDRange returns the name of the tenant's range of the kind, or the global range if no tenant
+func tenantIDRange(rangeName string, tenantName string) string {
+	if tenantName == "" {
+		return rangeName
+	}
+	return rangeName + "/" + tenantName
+}
+
+// hostIDRange returns the name of the host's range of the kind
+func hostIDRange(rangeName string, hostName string) string {
+	return rangeName + "/" + hostName
+}
+
+// initIDRanges defines the global ranges, the vni range is moved up to the starting vlan id by the
+// system parameters
+func (cnpd *sfcCtlrL2CNPDriver) initIDRanges() {
+	cnpd.ids.DefineRange(idRangeVni, 1, maxVni)
+	cnpd.ids.DefineRange(idRangeMac, 1, maxID)
+	cnpd.ids.DefineRange(idRangeMemif, 1, maxID)
+	cnpd.ids.DefineRange(idRangeVeth, 1, maxID)
+}
+
+// allocateMemifID returns a free memif id for the ids record with the key
+func (cnpd *sfcCtlrL2CNPDriver) allocateMemifID(owner string) (uint32, error) {
+	return cnpd.ids.Allocate(idRangeMemif, owner)
+}
+
+// allocateVethID returns a free veth id for the ids record with the key
+func (cnpd *sfcCtlrL2CNPDriver) allocateVethID(owner string) (uint32, error) {
+	return cnpd.ids.Allocate(idRangeVeth, owner)
+}
+
+// allocateAutoVrfID returns a free vrf of the host's auto vrf range for the ids record with the key, the range
+// follows the system parameters
+func (cnpd *sfcCtlrL2CNPDriver) allocateAutoVrfID(hostName string, owner string) (uint32, error) {
+
+	first := cnpd.l2CNPEntityCache.SysParms.AutoVrfRangeStart
+	last := cnpd.l2CNPEntityCache.SysParms.AutoVrfRangeEnd
+	if first == 0 || last == 0 {
+		first, last = defaultAutoVrfRangeStart, defaultAutoVrfRangeEnd
+	}
+	vrfRange := hostIDRange(idRangeVrf, hostName)
+	if err := cnpd.ids.DefineRange(vrfRange, first, last); err != nil {
+		return 0, err
+	}
+	return cnpd.ids.Allocate(vrfRange, owner)
+}
+
+// recordIDs returns the ids of the kind held by the ids records of the cache, by the key of the record
+func recordIDs(cache *reconcileCacheType, rangeKind string) map[string]uint32 {
+
+	ids := make(map[string]uint32)
+	switch rangeKind {
+	case idRangeVni:
+		for key, he2ee := range cache.he2eeIDs {
+			ids[key] = he2ee.VlanId
+		}
+		for key, he2he := range cache.he2heIDs {
+			ids[key] = he2he.VlanId
+		}
+	case idRangeMac:
+		for key, heID := range cache.heIDs {
+			ids[key] = heID.LoopbackMacAddrId
+		}
+		for key, sfcID := range cache.sfcIDs {
+			ids[key] = sfcID.MacAddrId
+		}
+	case idRangeMemif:
+		for key, sfcID := range cache.sfcIDs {
+			ids[key] = sfcID.MemifId
+		}
+	case idRangeVeth:
+		for key, sfcID := range cache.sfcIDs {
+			ids[key] = sfcID.VethId
+		}
+	case idRangeVrf:
+		for key, sfcHostID := range cache.sfcHostIDs {
+			ids[key] = sfcHostID.VrfId
+		}
+	}
+	return ids
+}
+
+// claimRecordIDs claims the ids of the records loaded from the db which fall in the range, this covers the
+// records written before the ids were claimed, the ids already claimed for their records are left as is
+func (cnpd *sfcCtlrL2CNPDriver) claimRecordIDs(rangeName string, first uint32, last uint32) {
+
+	rangeKind := strings.Split(rangeName, "/")[0]
+	for key, id := range recordIDs(&cnpd.reconcileBefore, rangeKind) {
+		if id == 0 || id < first || id > last || cnpd.ids.Owner(rangeName, id) != "" {
+			continue
+		}
+		if err := cnpd.ids.Claim(rangeName, id, key); err != nil {
+			log.Warnf("claimRecordIDs: '%s': %s", key, err)
+		}
+	}
+}
+
+// idsInitFromReconcileCache loads the claims from the db so the ids in use are never handed out again
+func (cnpd *sfcCtlrL2CNPDriver) idsInitFromReconcileCache() {
+
+	if err := cnpd.ids.Load(); err != nil {
+		log.Errorf("idsInitFromReconcileCache: cannot load the id claims: %s", err)
+	}
+	cnpd.claimRecordIDs(idRangeMac, 1, maxID)
+	cnpd.claimRecordIDs(idRangeMemif, 1, maxID)
+	cnpd.claimRecordIDs(idRangeVeth, 1, maxID)
+	// the vrf range of a record is the one of its host
+	for key, sfcHostID := range cnpd.reconcileBefore.sfcHostIDs {
+		vrfRange := hostIDRange(idRangeVrf, sfcHostID.HostName)
+		if sfcHostID.VrfId == 0 || cnpd.ids.Owner(vrfRange, sfcHostID.VrfId) != "" {
+			continue
+		}
+		if err := cnpd.ids.Claim(vrfRange, sfcHostID.VrfId, key); err != nil {
+			log.Warnf("idsInitFromReconcileCache: '%s': %s", key, err)
+		}
+	}
+
+	log.Infof("idsInitFromReconcileCache: id ranges after loading id's: %s", cnpd.ids)
+}
+
+// reconcileIDClaims releases the ids whose records were not rendered, or no longer hold the id, it is called
+// at the end of a reconcile when the after cache holds every rendered record.  The vni of a tunnel is shared
+// by both of its directions so it is handed over to the remaining direction's record.
+func (cnpd *sfcCtlrL2CNPDriver) reconcileIDClaims() {
+
+	held := make(map[string]map[string]uint32)
+	for _, rangeKind := range []string{idRangeVni, idRangeMac, idRangeMemif, idRangeVeth, idRangeVrf} {
+		held[rangeKind] = recordIDs(&cnpd.reconcileAfter, rangeKind)
+	}
+
+	for _, claim := range cnpd.ids.Claims() {
+		rangeKind := strings.Split(claim.Range, "/")[0]
+		if id, exists := held[rangeKind][claim.Owner]; exists && id == claim.ID {
+			continue
+		}
+		if err := cnpd.ids.Release(claim.Range, claim.ID); err != nil {
+			log.Errorf("reconcileIDClaims: error releasing id %d of '%s': %s", claim.ID, claim.Range, err)
+			continue
+		}
+		log.Infof("reconcileIDClaims: released id %d of '%s' from '%s'", claim.ID, claim.Range, claim.Owner)
+		if rangeKind != idRangeVni {
+			continue
+		}
+		for key, id := range held[rangeKind] {
+			if id == claim.ID {
+				if err := cnpd.ids.Claim(claim.Range, claim.ID, key); err != nil {
+					log.Errorf("reconcileIDClaims: '%s': %s", key, err)
+				}
+				break
+			}
+		}
+	}
+}
+
+// releaseSfcIDs removes the ids records of the sfc and releases the ids claimed for them
+func (cnpd *sfcCtlrL2CNPDriver) releaseSfcIDs(sfcName string) error {
+
+	for _, prefix := range []string{
+		l2driver.SFCIDsNameKey(sfcName) + "/",
+		l2driver.SFCHostIDsKeyPrefix() + sfcName + "/",
+	} {
+		released, err := cnpd.ids.ReleaseOwnerPrefix(prefix)
+		for _, claim := range released {
+			log.Infof("releaseSfcIDs: released id %d of '%s' from '%s'", claim.ID, claim.Range, claim.Owner)
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := cnpd.db.Delete(prefix, datasync.WithPrefix()); err != nil {
+			log.Errorf("releaseSfcIDs: error deleting keys: '%s'", prefix)
+			return err
+		}
+	}
+	return nil
+}
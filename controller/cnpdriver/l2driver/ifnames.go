@@ -0,0 +1,222 @@
+// Copyright (c) 2017 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// The interfaces of an sfc element on its vswitch are named after the
+// element's container and port followed by the element's memif or veth id in
+// base 36, ie: IF_MEMIF_VSWITCH_<container>_<port>_<id>.  The ids are unique
+// and never contain a '_' so two elements never get the same name, even when
+// their container and port join up the same, eg: a_b/c and a/b_c.  A name is
+// never longer than where it is used allows: the linux name of a veth is at
+// most IFNAMSIZ-1 chars, the name of a vpp interface is at most 63 chars as it
+// is the interface's tag in vpp.  A name which is too long keeps what fits of
+// the container and port followed by the id.  The names are reserved on their
+// host, and they are kept in the element's SFCIDs so an interface keeps its
+// name across restarts, even a name from before the ids were appended.  A kept
+// name is only replaced if another element of the host uses it or it is too
+// long.  A container wired directly to another container has
+// no vswitch interface, its end of the veth is named like the vswitch
+// interfaces, its end of a memif is named by its port in the container.
+
+package l2driver
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	l2driver "github.com/ligato/sfc-controller/controller/cnpdriver/l2driver/model"
+	"github.com/ligato/sfc-controller/controller/model/controller"
+)
+
+const (
+	maxLinuxIfNameLen = 15 // IFNAMSIZ less the terminating NULL
+	maxVppIfNameLen   = 63 // the tag of a vpp interface less the terminating NULL
+)
+
+// InterfaceName holds the names of the interfaces of an sfc element on its vswitch, or of the element's end of
+// a pair wired directly to another container
+type InterfaceName struct {
+	Sfc             string `json:"sfc"`
+	Container       string `json:"container"`
+	Port            string `json:"port"`
+	Host            string `json:"host"`
+	VswitchIfName   string `json:"vswitch_if_name,omitempty"`
+	HostIfName      string `json:"host_if_name,omitempty"`
+	ContainerIfName string `json:"container_if_name,omitempty"`
+
+	containerIfOnHost bool // the container i/f is configured on the host so its name is reserved on the host
+}
+
+// hostIfNames returns the names of the interfaces which are reserved on the host
+func (ifNames *InterfaceName) hostIfNames() []string {
+	names := []string{ifNames.VswitchIfName, ifNames.HostIfName}
+	if ifNames.containerIfOnHost {
+		names = append(names, ifNames.ContainerIfName)
+	}
+	return names
+}
+
+// ifNameIDSuffix returns the suffix of the names of the interfaces of the id
+func ifNameIDSuffix(id uint32) string {
+	return "_" + strconv.FormatUint(uint64(id), 36)
+}
+
+// vppIfName returns the name of a vpp interface of the container's port, ie prefix_container_port_id, if it is
+// too long what fits of the container and port is followed by the id
+func vppIfName(prefix string, container string, port string, id uint32) string {
+
+	idStr := ifNameIDSuffix(id)
+	name := prefix + container + "_" + port + idStr
+	if len(name) <= maxVppIfNameLen {
+		return name
+	}
+	budget := maxVppIfNameLen - len(prefix) - len(idStr)
+
+	return prefix + stringFirstNLastM(budget/2, budget-budget/2, container+"_"+port) + idStr
+}
+
+// keepIfName returns true if the name kept for the owner is still of the kind of the prefix, fits in maxLen
+// chars and is not used by another owner on the host
+func (cnpd *sfcCtlrL2CNPDriver) keepIfName(name string, prefix string, maxLen int, host string, owner string) bool {
+	if name == "" || !strings.HasPrefix(name, prefix) || len(name) > maxLen {
+		return false
+	}
+	current, exists := cnpd.l2CNPStateCache.IfNames[host+"/"+name]
+	return !exists || current == owner
+}
+
+// linuxHostIfName returns the linux name of the vswitch end of the container port's veth, the name ends in
+// the unique veth id so it is unique on the host however short the container and port have to be made
+func linuxHostIfName(container string, port string, vethID uint32) string {
+	vethIDStr := strconv.FormatUint(uint64(vethID), 36)
+	return constructBaseHostName(container, port, vethIDStr) + "_" + vethIDStr
+}
+
+// reserveSfcIfNames reserves the names of the interfaces of the element on its host, it fails if a name is
+// used by another element of the host
+func (cnpd *sfcCtlrL2CNPDriver) reserveSfcIfNames(ifNames *InterfaceName) error {
+
+	owner := sfcInterfaceOwner(ifNames.Sfc, ifNames.Container, ifNames.Port)
+	names := ifNames.hostIfNames()
+
+	for _, name := range names {
+		if current, exists := cnpd.l2CNPStateCache.IfNames[ifNames.Host+"/"+name]; name != "" && exists &&
+			current != owner {
+			return fmt.Errorf("interface name: '%s' of '%s' is already used on host: '%s' by '%s'", name, owner,
+				ifNames.Host, current)
+		}
+	}
+	if previous, exists := cnpd.l2CNPStateCache.SFCIfNames[owner]; exists {
+		for _, name := range previous.hostIfNames() {
+			delete(cnpd.l2CNPStateCache.IfNames, previous.Host+"/"+name)
+		}
+	}
+	for _, name := range names {
+		if name != "" {
+			cnpd.l2CNPStateCache.IfNames[ifNames.Host+"/"+name] = owner
+		}
+	}
+	cnpd.l2CNPStateCache.SFCIfNames[owner] = *ifNames
+
+	return nil
+}
+
+// releaseSfcIfNames forgets the interface names of the owners with the prefix, the names are reserved again
+// as the interfaces are rendered
+func (cnpd *sfcCtlrL2CNPDriver) releaseSfcIfNames(ownerPrefix string) {
+	for owner, ifNames := range cnpd.l2CNPStateCache.SFCIfNames {
+		if !strings.HasPrefix(owner, ownerPrefix) {
+			continue
+		}
+		for _, name := range ifNames.hostIfNames() {
+			delete(cnpd.l2CNPStateCache.IfNames, ifNames.Host+"/"+name)
+		}
+		delete(cnpd.l2CNPStateCache.SFCIfNames, owner)
+	}
+}
+
+// sfcVswitchIfNames returns the names of the element's interfaces on its vswitch, the names kept in the
+// element's SFCIDs are used if they are still of the same kind of interface, unique on the host and not too
+// long, the host name is only for an element wired with a veth, ie: vethID is set
+func (cnpd *sfcCtlrL2CNPDriver) sfcVswitchIfNames(sfc *controller.SfcEntity,
+	vnfChainElement *controller.SfcEntity_SfcElement, sfcID *l2driver.SFCIDs, vswitchIfPrefix string,
+	id uint32, vethID uint32) (*InterfaceName, error) {
+
+	ifNames := &InterfaceName{
+		Sfc:       sfc.Name,
+		Container: vnfChainElement.Container,
+		Port:      vnfChainElement.PortLabel,
+		Host:      vnfChainElement.EtcdVppSwitchKey,
+	}
+	owner := sfcInterfaceOwner(sfc.Name, vnfChainElement.Container, vnfChainElement.PortLabel)
+
+	if sfcID != nil && cnpd.keepIfName(sfcID.VswitchIfName, vswitchIfPrefix, maxVppIfNameLen, ifNames.Host, owner) {
+		ifNames.VswitchIfName = sfcID.VswitchIfName
+	} else {
+		ifNames.VswitchIfName = vppIfName(vswitchIfPrefix, vnfChainElement.Container, vnfChainElement.PortLabel, id)
+	}
+	if vethID != 0 {
+		if sfcID != nil && sfcID.HostIfName != ifNames.VswitchIfName &&
+			cnpd.keepIfName(sfcID.HostIfName, "", maxLinuxIfNameLen, ifNames.Host, owner) {
+			ifNames.HostIfName = sfcID.HostIfName
+		} else {
+			ifNames.HostIfName = linuxHostIfName(vnfChainElement.Container, vnfChainElement.PortLabel, vethID)
+		}
+	}
+
+	if err := cnpd.reserveSfcIfNames(ifNames); err != nil {
+		log.Errorf("sfcVswitchIfNames: %s", err)
+		return nil, err
+	}
+
+	return ifNames, nil
+}
+
+// sfcContainerIfNames returns the name of the element's end of a pair wired directly to another container, the
+// name of an end configured on the host, ie a veth, is reserved on the host, the end of a memif is configured in
+// the container where it is named by its port
+func (cnpd *sfcCtlrL2CNPDriver) sfcContainerIfNames(sfcName string, container string, port string, host string,
+	containerIfName string, onHost bool) (*InterfaceName, error) {
+
+	ifNames := &InterfaceName{
+		Sfc:               sfcName,
+		Container:         container,
+		Port:              port,
+		Host:              host,
+		ContainerIfName:   containerIfName,
+		containerIfOnHost: onHost,
+	}
+
+	if err := cnpd.reserveSfcIfNames(ifNames); err != nil {
+		log.Errorf("sfcContainerIfNames: %s", err)
+		return nil, err
+	}
+
+	return ifNames, nil
+}
+
+// InterfaceNames returns the names of the interfaces of the sfc elements on their vswitches
+func (cnpd *sfcCtlrL2CNPDriver) InterfaceNames() []InterfaceName {
+
+	ifNames := make([]InterfaceName, 0, len(cnpd.l2CNPStateCache.SFCIfNames))
+	for _, names := range cnpd.l2CNPStateCache.SFCIfNames {
+		ifNames = append(ifNames, names)
+	}
+	sort.Slice(ifNames, func(i, j int) bool {
+		return sfcInterfaceOwner(ifNames[i].Sfc, ifNames[i].Container, ifNames[i].Port) <
+			sfcInterfaceOwner(ifNames[j].Sfc, ifNames[j].Container, ifNames[j].Port)
+	})
+	return ifNames
+}
@@ -0,0 +1,177 @@
+// Copyright (c) 2017 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package l2driver
+
+import (
+	"strings"
+	"testing"
+
+	l2driver "github.com/ligato/sfc-controller/controller/cnpdriver/l2driver/model"
+	"github.com/ligato/sfc-controller/controller/model/controller"
+)
+
+func TestVppIfName(t *testing.T) {
+	long := strings.Repeat("c", 40)
+
+	tests := []struct {
+		container string
+		port      string
+		id        uint32
+		want      string
+	}{
+		{"a_b", "c", 1, "IF_MEMIF_VSWITCH_a_b_c_1"},
+		{"a", "b_c", 2, "IF_MEMIF_VSWITCH_a_b_c_2"},
+		{"vnf1", "port1", 36, "IF_MEMIF_VSWITCH_vnf1_port1_10"},
+		{long, "port1", 3, "IF_MEMIF_VSWITCH_" + strings.Repeat("c", 38) + "_port1_3"},
+		{long, "port1", 4, "IF_MEMIF_VSWITCH_" + strings.Repeat("c", 38) + "_port1_4"},
+	}
+
+	names := make(map[string]bool)
+	for _, test := range tests {
+		name := vppIfName("IF_MEMIF_VSWITCH_", test.container, test.port, test.id)
+		if name != test.want {
+			t.Errorf("vppIfName(%s, %s, %d) = %s, want %s", test.container, test.port, test.id, name, test.want)
+		}
+		if len(name) > maxVppIfNameLen {
+			t.Errorf("vppIfName(%s, %s, %d) = %s is longer than %d chars", test.container, test.port, test.id,
+				name, maxVppIfNameLen)
+		}
+		if names[name] {
+			t.Errorf("vppIfName(%s, %s, %d) = %s is not unique", test.container, test.port, test.id, name)
+		}
+		names[name] = true
+	}
+}
+
+func TestSfcVswitchIfNames(t *testing.T) {
+	sfc := &controller.SfcEntity{Name: "s1"}
+	element := &controller.SfcEntity_SfcElement{Container: "vnf1", PortLabel: "port1", EtcdVppSwitchKey: "h1"}
+
+	tests := []struct {
+		name        string
+		sfcID       *l2driver.SFCIDs
+		wantVswitch string
+		wantHost    string
+	}{
+		{"new", nil, "IF_AFPIF_VSWITCH_vnf1_port1_5", "vnf1_port1_5"},
+		{"kept", &l2driver.SFCIDs{VswitchIfName: "IF_AFPIF_VSWITCH_v1_p1_5", HostIfName: "v1_p1_5"},
+			"IF_AFPIF_VSWITCH_v1_p1_5", "v1_p1_5"},
+		{"kept without the id", &l2driver.SFCIDs{VswitchIfName: "IF_AFPIF_VSWITCH_vnf1_port1",
+			HostIfName: "vnf1_port1"}, "IF_AFPIF_VSWITCH_vnf1_port1", "vnf1_port1"},
+		{"kept of another id", &l2driver.SFCIDs{VswitchIfName: "IF_AFPIF_VSWITCH_vnf1_port1_4",
+			HostIfName: "vnf1_port1_4"}, "IF_AFPIF_VSWITCH_vnf1_port1_4", "vnf1_port1_4"},
+		{"kept of another kind", &l2driver.SFCIDs{VswitchIfName: "IF_TAP_VSWITCH_vnf1_port1_5"},
+			"IF_AFPIF_VSWITCH_vnf1_port1_5", "vnf1_port1_5"},
+		{"kept too long", &l2driver.SFCIDs{VswitchIfName: "IF_AFPIF_VSWITCH_vnf1_port1",
+			HostIfName: "vnf1_port1_too_long"}, "IF_AFPIF_VSWITCH_vnf1_port1", "vnf1_port1_5"},
+		{"kept used on the host", &l2driver.SFCIDs{VswitchIfName: "IF_AFPIF_VSWITCH_vnf",
+			HostIfName: "vnf"}, "IF_AFPIF_VSWITCH_vnf1_port1_5", "vnf1_port1_5"},
+		{"kept used on another host", &l2driver.SFCIDs{VswitchIfName: "IF_AFPIF_VSWITCH_other",
+			HostIfName: "other"}, "IF_AFPIF_VSWITCH_other", "other"},
+	}
+
+	for _, test := range tests {
+		cnpd := newMacTestDriver("c1", newMemMacClaimStore())
+		// the names kept by the elements of another sfc from before the ids were appended to them
+		for _, other := range []InterfaceName{
+			{Sfc: "s2", Container: "vnf", Port: "port1", Host: "h1", VswitchIfName: "IF_AFPIF_VSWITCH_vnf",
+				HostIfName: "vnf"},
+			{Sfc: "s2", Container: "other", Port: "port1", Host: "h2", VswitchIfName: "IF_AFPIF_VSWITCH_other",
+				HostIfName: "other"},
+		} {
+			if err := cnpd.reserveSfcIfNames(&other); err != nil {
+				t.Fatalf("%s: unexpected error: %s", test.name, err)
+			}
+		}
+		ifNames, err := cnpd.sfcVswitchIfNames(sfc, element, test.sfcID, "IF_AFPIF_VSWITCH_", 5, 5)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", test.name, err)
+			continue
+		}
+		if ifNames.VswitchIfName != test.wantVswitch || ifNames.HostIfName != test.wantHost {
+			t.Errorf("%s: names %s, %s, want %s, %s", test.name, ifNames.VswitchIfName, ifNames.HostIfName,
+				test.wantVswitch, test.wantHost)
+		}
+	}
+}
+
+func TestReserveSfcIfNames(t *testing.T) {
+	cnpd := newMacTestDriver("c1", newMemMacClaimStore())
+
+	tests := []struct {
+		name    string
+		ifNames InterfaceName
+		wantErr bool
+	}{
+		{"first", InterfaceName{Sfc: "s1", Container: "a_b", Port: "c", Host: "h1",
+			VswitchIfName: "IF_MEMIF_VSWITCH_x"}, false},
+		{"renamed by its owner", InterfaceName{Sfc: "s1", Container: "a_b", Port: "c", Host: "h1",
+			VswitchIfName: "IF_MEMIF_VSWITCH_x"}, false},
+		{"used on the host", InterfaceName{Sfc: "s1", Container: "a", Port: "b_c", Host: "h1",
+			VswitchIfName: "IF_MEMIF_VSWITCH_x"}, true},
+		{"used on another host", InterfaceName{Sfc: "s1", Container: "a", Port: "b_c", Host: "h2",
+			VswitchIfName: "IF_MEMIF_VSWITCH_x"}, false},
+		{"veth end on the host", InterfaceName{Sfc: "s2", Container: "v", Port: "p", Host: "h1",
+			ContainerIfName: "IF_MEMIF_VSWITCH_x", containerIfOnHost: true}, true},
+		{"memif end in the container", InterfaceName{Sfc: "s2", Container: "v", Port: "p", Host: "h1",
+			ContainerIfName: "IF_MEMIF_VSWITCH_x"}, false},
+	}
+
+	for _, test := range tests {
+		ifNames := test.ifNames
+		if err := cnpd.reserveSfcIfNames(&ifNames); (err != nil) != test.wantErr {
+			t.Errorf("%s: error %v, want error %t", test.name, err, test.wantErr)
+		}
+	}
+
+	cnpd.releaseSfcIfNames("s1/")
+	ifNames := InterfaceName{Sfc: "s3", Container: "v", Port: "p", Host: "h1", VswitchIfName: "IF_MEMIF_VSWITCH_x"}
+	if err := cnpd.reserveSfcIfNames(&ifNames); err != nil {
+		t.Errorf("released name: unexpected error: %s", err)
+	}
+	if names := cnpd.InterfaceNames(); len(names) != 2 {
+		t.Errorf("InterfaceNames() = %v, want the names of s2 and s3", names)
+	}
+}
+
+func TestSfcContainerIfNames(t *testing.T) {
+	cnpd := newMacTestDriver("c1", newMemMacClaimStore())
+
+	// the memifs of containers wired directly are named by their port in their containers
+	for _, container := range []string{"vnf1", "vnf2"} {
+		if _, err := cnpd.sfcContainerIfNames("s1", container, "port1", "h1", "port1", false); err != nil {
+			t.Errorf("memif end of %s: unexpected error: %s", container, err)
+		}
+	}
+
+	// the veths are configured on the host
+	veth1Name := vppIfName("IF_VETH_VNF_", "a_b", "c", 1)
+	veth2Name := vppIfName("IF_VETH_VNF_", "a", "b_c", 2)
+	if _, err := cnpd.sfcContainerIfNames("s2", "a_b", "c", "h1", veth1Name, true); err != nil {
+		t.Errorf("veth end of a_b: unexpected error: %s", err)
+	}
+	if _, err := cnpd.sfcContainerIfNames("s2", "a", "b_c", "h1", veth2Name, true); err != nil {
+		t.Errorf("veth end of a: unexpected error: %s", err)
+	}
+	if _, err := cnpd.sfcContainerIfNames("s3", "a_b", "c", "h1", veth1Name, true); err == nil {
+		t.Errorf("veth end of s3: the name of s2 is reserved on the host")
+	}
+
+	for _, ifNames := range cnpd.InterfaceNames() {
+		if ifNames.ContainerIfName == "" {
+			t.Errorf("%s/%s/%s has no container i/f name", ifNames.Sfc, ifNames.Container, ifNames.Port)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright (c) 2017 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// The manual ipam reservations.  An operator can reserve an address for a
+// workload outside of the controller, the reservation is stored in etcd like
+// the allocations of the sfc interfaces but it is never rendered so it is
+// kept across reconciles until it is released by hand.
+
+package l2driver
+
+import (
+	"fmt"
+	"strings"
+
+	l2driver "github.com/ligato/sfc-controller/controller/cnpdriver/l2driver/model"
+	"github.com/ligato/sfc-controller/controller/utils/ipam"
+)
+
+// ipamManualOwnerPrefix qualifies the owner of a manual reservation so it never matches an sfc interface
+const ipamManualOwnerPrefix = "manual:"
+
+// ipamAllocationOwner returns the ipam owner of an allocation from the db
+func ipamAllocationOwner(alloc *l2driver.IPAMAllocation) string {
+	if alloc.Owner != "" {
+		return alloc.Owner
+	}
+	return sfcInterfaceOwner(alloc.SfcName, alloc.Container, alloc.Port)
+}
+
+// IpamReserve reserves the address of the tenant's subnet for the owner, the address must not be in use
+func (cnpd *sfcCtlrL2CNPDriver) IpamReserve(tenant string, subnet string, ipAddress string, owner string) error {
+
+	owner = ipamManualOwnerPrefix + owner
+
+	ipAddrStr, ipID, err := cnpd.ipam.ReserveIpAddrInSubnet(tenant, subnet, ipAddress, owner)
+	if err != nil {
+		return err
+	}
+	if _, _, err := cnpd.DatastoreIPAMAllocationCreate(tenant, subnet, ipID, ipAddrStr, "", "", "", owner); err != nil {
+		cnpd.ipam.ReleaseIpIDInSubnet(tenant, subnet, ipID)
+		return err
+	}
+
+	log.Infof("IpamReserve: '%s' reserved in '%s' for '%s'", ipAddrStr, subnet, owner)
+
+	return nil
+}
+
+// IpamRelease releases a manually reserved address, the addresses of sfc interfaces are released when the sfc
+// no longer uses them
+func (cnpd *sfcCtlrL2CNPDriver) IpamRelease(tenant string, subnet string, ipAddress string) error {
+
+	ipID, inside := ipam.IpIDOfAddr(subnet, ipAddress)
+	if !inside {
+		return fmt.Errorf("IpamRelease: '%s' is not an address of subnet '%s'", ipAddress, subnet)
+	}
+	owner := cnpd.ipam.OwnerOfIpID(tenant, subnet, ipID)
+	if owner == "" {
+		return fmt.Errorf("IpamRelease: '%s' is not allocated in subnet '%s'", ipAddress, subnet)
+	}
+	if !strings.HasPrefix(owner, ipamManualOwnerPrefix) {
+		return fmt.Errorf("IpamRelease: '%s' is allocated to sfc interface '%s'", ipAddress, owner)
+	}
+
+	cnpd.ipam.ReleaseIpIDInSubnet(tenant, subnet, ipID)
+	if err := cnpd.DatastoreIPAMAllocationDelete(tenant, subnet, ipID); err != nil {
+		return err
+	}
+
+	log.Infof("IpamRelease: '%s' released in '%s' from '%s'", ipAddress, subnet, owner)
+
+	return nil
+}
+
+// IpamPools returns the utilization of the subnets in use
+func (cnpd *sfcCtlrL2CNPDriver) IpamPools() []ipam.PoolUsage {
+	return cnpd.ipam.Pools()
+}
+
+// IpamAllocations returns the allocated addresses and their owners
+func (cnpd *sfcCtlrL2CNPDriver) IpamAllocations() []ipam.Allocation {
+	return cnpd.ipam.Allocations()
+}
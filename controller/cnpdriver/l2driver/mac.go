@@ -0,0 +1,278 @@
+// Copyright (c) 2017 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// The macs of the interfaces are configured, or generated.  A generated mac
+// starts with the mac_prefix octets of the system parameters, the rest is a
+// mac id handed out by the id allocator from the one mac range shared by all
+// the tenants, a tenant's mac range is its window of it, the macs of no tenant
+// are handed out above the tenants' windows, or with the hash
+// scheme, a hash of the interface's tenant, sfc, container and port so the
+// mac is the same whichever controller renders it, and after the etcd is
+// rebuilt, a hash which collides is salted and hashed again.  Every rendered
+// mac is claimed in etcd for the interface it is on, so a mac is never
+// rendered on two interfaces, even by two controllers sharing the etcd.
+
+package l2driver
+
+import (
+	"fmt"
+	"hash/fnv"
+	"net"
+	"strconv"
+	"strings"
+
+	l2driver "github.com/ligato/sfc-controller/controller/cnpdriver/l2driver/model"
+	"github.com/ligato/sfc-controller/controller/model/controller"
+	"github.com/ligato/sfc-controller/controller/utils"
+)
+
+const (
+	defaultMacPrefix = 0x02 // locally administered, unicast
+	hashMacAttempts  = 8    // the salted hashes tried before a hash scheme mac gives up
+)
+
+// macClaimStore keeps the claims of the rendered macs, Claim must be atomic, ie: an etcd put if not exists,
+// as it is what keeps concurrent controllers from rendering the same mac
+type macClaimStore interface {
+	Claim(claim *l2driver.MacAddressClaim) (bool, error)
+	Get(macAddress string) (*l2driver.MacAddressClaim, error)
+	Release(macAddress string) error
+	Claims() ([]l2driver.MacAddressClaim, error)
+}
+
+// etcdMacClaimStore keeps the mac claims in etcd
+type etcdMacClaimStore struct {
+	cnpd *sfcCtlrL2CNPDriver
+}
+
+func (s *etcdMacClaimStore) Claim(claim *l2driver.MacAddressClaim) (bool, error) {
+	return s.cnpd.DatastoreMacAddressClaimCreate(claim)
+}
+
+func (s *etcdMacClaimStore) Get(macAddress string) (*l2driver.MacAddressClaim, error) {
+	return s.cnpd.DatastoreMacAddressClaimRetrieve(macAddress)
+}
+
+func (s *etcdMacClaimStore) Release(macAddress string) error {
+	return s.cnpd.DatastoreMacAddressClaimDelete(macAddress)
+}
+
+func (s *etcdMacClaimStore) Claims() ([]l2driver.MacAddressClaim, error) {
+	claims := make([]l2driver.MacAddressClaim, 0)
+	err := s.cnpd.DatastoreMacAddressClaimsIterate(func(key string, claim *l2driver.MacAddressClaim) {
+		claims = append(claims, *claim)
+	})
+	return claims, err
+}
+
+// macInUseError is returned when a mac is already on the interface of another owner
+type macInUseError struct {
+	macAddress string
+	owner      string
+	current    string
+}
+
+func (e *macInUseError) Error() string {
+	return fmt.Sprintf("mac address: '%s' of '%s' is already used by '%s'", e.macAddress, e.owner, e.current)
+}
+
+// macPrefix returns the leading octets of the generated macs
+func (cnpd *sfcCtlrL2CNPDriver) macPrefix() []byte {
+	prefix, err := utils.ParseMacPrefix(cnpd.l2CNPEntityCache.SysParms.MacPrefix)
+	if err != nil {
+		return []byte{defaultMacPrefix}
+	}
+	return prefix
+}
+
+// maxMacID returns the highest mac id, the mac ids fill the octets after the mac prefix
+func (cnpd *sfcCtlrL2CNPDriver) maxMacID() uint32 {
+	return utils.MaxMacID(cnpd.macPrefix())
+}
+
+// formatMacAddress returns the mac of the mac id
+func (cnpd *sfcCtlrL2CNPDriver) formatMacAddress(macInstanceID uint32) string {
+	return utils.FormatMacAddress(cnpd.macPrefix(), macInstanceID)
+}
+
+// hashMacScheme is true if the macs are generated from a hash of the interface's name
+func (cnpd *sfcCtlrL2CNPDriver) hashMacScheme() bool {
+	return cnpd.l2CNPEntityCache.SysParms.MacAddressScheme == controller.MacAddressScheme_MAC_ADDRESS_SCHEME_HASH
+}
+
+// hashMacAddress returns the mac prefix followed by a hash of the name, the attempt salts the hash after a
+// collision
+func (cnpd *sfcCtlrL2CNPDriver) hashMacAddress(name string, attempt int) string {
+
+	if attempt != 0 {
+		name += "#" + strconv.Itoa(attempt)
+	}
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	sum := h.Sum64()
+
+	prefix := cnpd.macPrefix()
+	mac := make(net.HardwareAddr, 6)
+	copy(mac, prefix)
+	for i := len(prefix); i < 6; i++ {
+		mac[i] = byte(sum >> uint(8*(5-i)))
+	}
+	return strings.ToUpper(mac.String())
+}
+
+// reserveHashMacAddress reserves the hash scheme mac of the name for the owner, a mac which is already on
+// another interface is hashed again with a salt
+func (cnpd *sfcCtlrL2CNPDriver) reserveHashMacAddress(name string, owner string) (string, error) {
+
+	for attempt := 0; attempt < hashMacAttempts; attempt++ {
+		macAddress := cnpd.hashMacAddress(name, attempt)
+		err := cnpd.reserveMacAddress(macAddress, owner)
+		if _, inUse := err.(*macInUseError); !inUse {
+			return macAddress, err
+		}
+		log.Warnf("reserveHashMacAddress: %s, hashing again", err)
+	}
+	return "", fmt.Errorf("no free hash mac address for '%s' after %d attempts", owner, hashMacAttempts)
+}
+
+// macAddressKey returns the form of the mac used to find duplicates, ie: 02:AA:.. and 02:aa:.. are the same mac
+func macAddressKey(macAddress string) string {
+	if mac, err := net.ParseMAC(macAddress); err == nil {
+		return mac.String()
+	}
+	return strings.ToLower(macAddress)
+}
+
+// heMacOwner is the owner of the macs of a host's interfaces
+func heMacOwner(heName string) string {
+	return "he/" + heName
+}
+
+// sfcMacOwnerPrefix is the prefix of the owners of the macs of an sfc's interfaces
+func sfcMacOwnerPrefix(sfcName string) string {
+	return "sfc/" + sfcName + "/"
+}
+
+// sfcMacOwner is the owner of the mac of an sfc interface
+func sfcMacOwner(sfcName string, container string, port string) string {
+	return "sfc/" + sfcInterfaceOwner(sfcName, container, port)
+}
+
+// reserveMacAddress claims the mac for the interface of the owner, it fails with a macInUseError if the mac
+// is already on the interface of another owner, or of another controller instance
+func (cnpd *sfcCtlrL2CNPDriver) reserveMacAddress(macAddress string, owner string) error {
+
+	key := macAddressKey(macAddress)
+	if current, exists := cnpd.l2CNPStateCache.MacAddrs[key]; exists {
+		if current != owner {
+			return &macInUseError{macAddress: macAddress, owner: owner, current: current}
+		}
+		return nil
+	}
+
+	claim := &l2driver.MacAddressClaim{MacAddress: key, Owner: owner, Instance: cnpd.instance}
+	for retry := 0; retry < 2; retry++ {
+		claimed, err := cnpd.macClaims.Claim(claim)
+		if err != nil {
+			return err
+		}
+		if claimed {
+			break
+		}
+		stored, err := cnpd.macClaims.Get(key)
+		if err != nil {
+			return err
+		}
+		if stored == nil {
+			// released between the claim and the lookup, try once more
+			continue
+		}
+		if stored.Owner != owner || stored.Instance != cnpd.instance {
+			return &macInUseError{macAddress: macAddress, owner: owner,
+				current: stored.Owner + " of " + stored.Instance}
+		}
+		break
+	}
+	cnpd.l2CNPStateCache.MacAddrs[key] = owner
+
+	return nil
+}
+
+// releaseMacAddresses releases the macs of the owners with the prefix, the macs are reserved again as the
+// interfaces are rendered
+func (cnpd *sfcCtlrL2CNPDriver) releaseMacAddresses(ownerPrefix string) {
+	for key, owner := range cnpd.l2CNPStateCache.MacAddrs {
+		if !strings.HasPrefix(owner, ownerPrefix) {
+			continue
+		}
+		delete(cnpd.l2CNPStateCache.MacAddrs, key)
+		if err := cnpd.macClaims.Release(key); err != nil {
+			log.Errorf("releaseMacAddresses: error releasing '%s' of '%s': %s", key, owner, err)
+		}
+	}
+}
+
+// reconcileMacClaims releases the claims of this instance whose macs were not rendered, it is called at the
+// end of a reconcile when every rendered mac is reserved
+func (cnpd *sfcCtlrL2CNPDriver) reconcileMacClaims() {
+
+	claims, err := cnpd.macClaims.Claims()
+	if err != nil {
+		log.Errorf("reconcileMacClaims: cannot read the mac claims: %s", err)
+		return
+	}
+	for _, claim := range claims {
+		if claim.Instance != cnpd.instance || cnpd.l2CNPStateCache.MacAddrs[claim.MacAddress] == claim.Owner {
+			continue
+		}
+		if err := cnpd.macClaims.Release(claim.MacAddress); err != nil {
+			log.Errorf("reconcileMacClaims: error releasing '%s': %s", claim.MacAddress, err)
+			continue
+		}
+		log.Infof("reconcileMacClaims: released '%s' from '%s'", claim.MacAddress, claim.Owner)
+	}
+}
+
+// allocateMacAddress returns the mac of the mac id, or of a newly allocated id if the interface has no id
+// yet, or the mac of its id is already on another interface, ie: it is configured on one.  The ids skipped
+// stay claimed until the next reconcile so they are not handed out again in the meantime.
+func (cnpd *sfcCtlrL2CNPDriver) allocateMacAddress(tenantName string, idOwner string, macAddrID uint32,
+	owner string) (string, uint32, error) {
+
+	if macAddrID != 0 {
+		macAddress := cnpd.formatMacAddress(macAddrID)
+		err := cnpd.reserveMacAddress(macAddress, owner)
+		if err == nil {
+			return macAddress, macAddrID, nil
+		}
+		if _, inUse := err.(*macInUseError); !inUse {
+			return "", 0, err
+		}
+	}
+	for {
+		macAddrID, err := cnpd.allocateMacInstanceID(tenantName, idOwner)
+		if err != nil {
+			return "", 0, err
+		}
+		macAddress := cnpd.formatMacAddress(macAddrID)
+		err = cnpd.reserveMacAddress(macAddress, owner)
+		if err == nil {
+			return macAddress, macAddrID, nil
+		}
+		if _, inUse := err.(*macInUseError); !inUse {
+			return "", 0, err
+		}
+		log.Warnf("allocateMacAddress: skipping mac id %d: %s", macAddrID, err)
+	}
+}
@@ -0,0 +1,206 @@
+// Copyright (c) 2017 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package l2driver
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	l2driver "github.com/ligato/sfc-controller/controller/cnpdriver/l2driver/model"
+	"github.com/ligato/sfc-controller/controller/model/controller"
+	"github.com/ligato/sfc-controller/controller/utils/idalloc"
+)
+
+// memMacClaimStore is a mac claim store shared by the drivers of a test, like etcd shared by controllers
+type memMacClaimStore struct {
+	mu     sync.Mutex
+	claims map[string]l2driver.MacAddressClaim
+}
+
+func newMemMacClaimStore() *memMacClaimStore {
+	return &memMacClaimStore{claims: make(map[string]l2driver.MacAddressClaim)}
+}
+
+func (s *memMacClaimStore) Claim(claim *l2driver.MacAddressClaim) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.claims[claim.MacAddress]; exists {
+		return false, nil
+	}
+	s.claims[claim.MacAddress] = *claim
+	return true, nil
+}
+
+func (s *memMacClaimStore) Get(macAddress string) (*l2driver.MacAddressClaim, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if claim, exists := s.claims[macAddress]; exists {
+		return &claim, nil
+	}
+	return nil, nil
+}
+
+func (s *memMacClaimStore) Release(macAddress string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.claims, macAddress)
+	return nil
+}
+
+func (s *memMacClaimStore) Claims() ([]l2driver.MacAddressClaim, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	claims := make([]l2driver.MacAddressClaim, 0, len(s.claims))
+	for _, claim := range s.claims {
+		claims = append(claims, claim)
+	}
+	return claims, nil
+}
+
+// newMacTestDriver returns a driver of the instance with the mac claims in the store and the ids in memory
+func newMacTestDriver(instance string, store macClaimStore) *sfcCtlrL2CNPDriver {
+	cnpd := &sfcCtlrL2CNPDriver{
+		instance:  instance,
+		ids:       idalloc.New(nil, instance),
+		macClaims: store,
+	}
+	cnpd.initL2CNPCache()
+	cnpd.initIDRanges()
+	return cnpd
+}
+
+func TestTenantMacsDoNotCollide(t *testing.T) {
+	cnpd := newMacTestDriver("c1", newMemMacClaimStore())
+	cnpd.l2CNPEntityCache.Tenants["t1"] = controller.Tenant{Name: "t1", MacRangeStart: 1, MacRangeEnd: 2}
+	cnpd.l2CNPEntityCache.Tenants["t2"] = controller.Tenant{Name: "t2", MacRangeStart: 3, MacRangeEnd: 4}
+
+	tests := []struct {
+		tenant  string
+		owner   string
+		wantMac string // "" when the tenant's window is exhausted
+	}{
+		{"t1", "sfc/s1/c/p1", "02:00:00:00:00:01"},
+		{"t2", "sfc/s2/c/p1", "02:00:00:00:00:03"},
+		{"", "sfc/s3/c/p1", "02:00:00:00:00:05"}, // the global macs skip the ids of the tenants
+		{"t1", "sfc/s1/c/p2", "02:00:00:00:00:02"},
+		{"t2", "sfc/s2/c/p2", "02:00:00:00:00:04"},
+		{"t1", "sfc/s1/c/p3", ""},
+	}
+	for _, test := range tests {
+		macAddress, _, err := cnpd.allocateMacAddress(test.tenant, test.owner, 0, test.owner)
+		if (err != nil) != (test.wantMac == "") || macAddress != test.wantMac {
+			t.Errorf("allocateMacAddress('%s', '%s') = '%s', %v, want '%s'", test.tenant, test.owner, macAddress,
+				err, test.wantMac)
+		}
+	}
+}
+
+func TestMacPrefix(t *testing.T) {
+	tests := []struct {
+		prefix     string
+		wantPrefix string
+		wantMac    string // the mac of id 1
+	}{
+		{"", "02:", "02:00:00:00:00:01"},
+		{"0A", "0A:", "0A:00:00:00:00:01"},
+		{"02:5A", "02:5A:", "02:5A:00:00:00:01"},
+		{"02:5A:01", "02:5A:01:", "02:5A:01:00:00:01"},
+	}
+	for _, test := range tests {
+		cnpd := newMacTestDriver("c1", newMemMacClaimStore())
+		cnpd.l2CNPEntityCache.SysParms.MacPrefix = test.prefix
+		if mac := cnpd.formatMacAddress(1); mac != test.wantMac {
+			t.Errorf("prefix '%s': formatMacAddress(1) = '%s', want '%s'", test.prefix, mac, test.wantMac)
+		}
+		if mac := cnpd.hashMacAddress("t1/sfc/s1/c/p1", 0); !strings.HasPrefix(mac, test.wantPrefix) {
+			t.Errorf("prefix '%s': hashMacAddress = '%s', want prefix '%s'", test.prefix, mac, test.wantPrefix)
+		}
+	}
+}
+
+func TestReserveMacAddressAcrossInstances(t *testing.T) {
+	store := newMemMacClaimStore()
+	c1 := newMacTestDriver("c1", store)
+	c2 := newMacTestDriver("c2", store)
+
+	if err := c1.reserveMacAddress("02:00:00:00:00:AA", "sfc/s1/c/p"); err != nil {
+		t.Fatalf("c1 reserveMacAddress error = %v", err)
+	}
+	if err := c1.reserveMacAddress("02:00:00:00:00:aa", "sfc/s1/c/p"); err != nil {
+		t.Errorf("c1 reserveMacAddress again by its owner error = %v", err)
+	}
+	if err := c1.reserveMacAddress("02:00:00:00:00:AA", "sfc/s2/c/p"); err == nil {
+		t.Errorf("c1 reserveMacAddress of a mac of another interface succeeded")
+	}
+	// c2 does not have the mac in memory, the claim in the store catches it
+	if err := c2.reserveMacAddress("02:00:00:00:00:AA", "sfc/s1/c/p"); err == nil {
+		t.Errorf("c2 reserveMacAddress of c1's mac succeeded")
+	}
+
+	c1.releaseMacAddresses(sfcMacOwnerPrefix("s1"))
+	if err := c2.reserveMacAddress("02:00:00:00:00:AA", "sfc/s1/c/p"); err != nil {
+		t.Errorf("c2 reserveMacAddress after c1's release error = %v", err)
+	}
+}
+
+func TestHashMacCollisionIsHashedAgain(t *testing.T) {
+	cnpd := newMacTestDriver("c1", newMemMacClaimStore())
+	name := "t1/sfc/s1/c/p1"
+
+	// someone else has the mac of the first hash, ie: it is configured on another interface
+	if err := cnpd.reserveMacAddress(cnpd.hashMacAddress(name, 0), "sfc/s2/c/p1"); err != nil {
+		t.Fatalf("reserveMacAddress error = %v", err)
+	}
+	macAddress, err := cnpd.reserveHashMacAddress(name, "sfc/s1/c/p1")
+	if err != nil {
+		t.Fatalf("reserveHashMacAddress error = %v", err)
+	}
+	if macAddress != cnpd.hashMacAddress(name, 1) {
+		t.Errorf("reserveHashMacAddress = '%s', want the salted hash '%s'", macAddress, cnpd.hashMacAddress(name, 1))
+	}
+	// rendered again, the interface keeps its mac
+	if again, err := cnpd.reserveHashMacAddress(name, "sfc/s1/c/p1"); err != nil || again != macAddress {
+		t.Errorf("reserveHashMacAddress again = '%s', %v, want '%s'", again, err, macAddress)
+	}
+}
+
+func TestReconcileMacClaims(t *testing.T) {
+	store := newMemMacClaimStore()
+	c1 := newMacTestDriver("c1", store)
+	c2 := newMacTestDriver("c2", store)
+
+	c1.reserveMacAddress("02:00:00:00:00:01", "sfc/s1/c/p1")
+	c1.reserveMacAddress("02:00:00:00:00:02", "sfc/s1/c/p2")
+	c2.reserveMacAddress("02:00:00:00:00:03", "sfc/s2/c/p1")
+
+	// c1 restarts and the reconcile renders only the first interface
+	c1 = newMacTestDriver("c1", store)
+	c1.reserveMacAddress("02:00:00:00:00:01", "sfc/s1/c/p1")
+	c1.reconcileMacClaims()
+
+	for _, test := range []struct {
+		mac      string
+		wantKept bool
+	}{
+		{"02:00:00:00:00:01", true},
+		{"02:00:00:00:00:02", false},
+		{"02:00:00:00:00:03", true}, // c2's
+	} {
+		if claim, _ := store.Get(test.mac); (claim != nil) != test.wantKept {
+			t.Errorf("claim of '%s' after the reconcile = %v, want kept %v", test.mac, claim, test.wantKept)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+// Copyright (c) 2017 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// The memif parms of an sfc element are the memif parms of the system
+// parameters overridden by the ones set in the element.  Both ends of a memif
+// pair share a secret, if none is configured a random one is generated for
+// the pair.  A generated secret is kept under its own etcd prefix, apart from
+// the ids, so it does not change across restarts and etcd role based access
+// control can restrict it to the controller.  A secret generated before is
+// moved there from the element's SFCIDs.  The secrets are never logged, nor
+// returned by the REST api.
+//
+// The memif sockets are in the memif socket dir of the host, or of the system
+// parameters, by default /tmp.  By default a master has one socket for all of
+// its memifs, a socket per sfc, or per memif pair, can be configured so a pod
+// only mounts the sockets it needs.  The socket of a pair is kept in the
+// SFCIDs of its element.
+
+package l2driver
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"strconv"
+
+	l2driver "github.com/ligato/sfc-controller/controller/cnpdriver/l2driver/model"
+	"github.com/ligato/sfc-controller/controller/model/controller"
+	"github.com/ligato/vpp-agent/plugins/defaultplugins/common/model/interfaces"
+)
+
+const (
+	memifSecretLen        = 24     // the longest secret vpp accepts
+	defaultMemifSocketDir = "/tmp" // see validate.go
+	maxSocketFilenameLen  = 107    // the sun_path of a unix socket less the terminating NULL
+)
+
+// newMemifSecret returns a random secret for a memif pair
+func newMemifSecret() (string, error) {
+	b := make([]byte, memifSecretLen/2)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// memifParms returns the memif parms of the container port's element, the system parms overridden by the
+// element's, with the configured secret, or the one generated for the element's memif pair
+func (cnpd *sfcCtlrL2CNPDriver) memifParms(sfcName string, container string, port string,
+	vnfChainElement *controller.SfcEntity_SfcElement) (*controller.MemifParms, error) {
+
+	parms := &controller.MemifParms{}
+	for _, p := range []*controller.MemifParms{cnpd.l2CNPEntityCache.SysParms.GetMemifParms(),
+		vnfChainElement.GetMemifParms()} {
+		if p == nil {
+			continue
+		}
+		if p.Mode != controller.MemifMode_MEMIF_MODE_ETHERNET {
+			parms.Mode = p.Mode
+		}
+		if p.Secret != "" {
+			parms.Secret = p.Secret
+		}
+		if p.RingSize != 0 {
+			parms.RingSize = p.RingSize
+		}
+		if p.BufferSize != 0 {
+			parms.BufferSize = p.BufferSize
+		}
+		if p.RxQueues != 0 {
+			parms.RxQueues = p.RxQueues
+		}
+		if p.TxQueues != 0 {
+			parms.TxQueues = p.TxQueues
+		}
+	}
+
+	if parms.Secret == "" {
+		secret, err := cnpd.generatedMemifSecret(sfcName, container, port)
+		if err != nil {
+			return nil, err
+		}
+		parms.Secret = secret
+	}
+
+	return parms, nil
+}
+
+// generatedMemifSecret returns the secret generated for the memif pair of the container port, a new secret is
+// generated if there is none
+func (cnpd *sfcCtlrL2CNPDriver) generatedMemifSecret(sfcName string, container string, port string) (string, error) {
+
+	secret, err := cnpd.DatastoreMemifSecretRetrieve(sfcName, container, port)
+	if err != nil {
+		return "", err
+	}
+	if secret == "" {
+		if secret, err = newMemifSecret(); err != nil {
+			log.Errorf("generatedMemifSecret: cannot generate a memif secret: %s", err)
+			return "", err
+		}
+		if err := cnpd.DatastoreMemifSecretCreate(sfcName, container, port, secret); err != nil {
+			return "", err
+		}
+	}
+	if cnpd.reconcileInProgress {
+		cnpd.reconcileAfter.memifSecrets[l2driver.MemifSecretKey(sfcName, container, port)] = struct{}{}
+	}
+
+	return secret, nil
+}
+
+// reconcileMemifSecrets deletes the generated secrets of the memif pairs which were not rendered, it is called at
+// the end of a reconcile
+func (cnpd *sfcCtlrL2CNPDriver) reconcileMemifSecrets() {
+
+	keys, err := cnpd.db.ListKeys(l2driver.MemifSecretsKeyPrefix())
+	if err != nil {
+		log.Errorf("reconcileMemifSecrets: cannot list the memif secrets: %s", err)
+		return
+	}
+	for {
+		key, _, allReceived := keys.GetNext()
+		if allReceived {
+			return
+		}
+		if _, rendered := cnpd.reconcileAfter.memifSecrets[key]; rendered {
+			continue
+		}
+		log.Infof("reconcileMemifSecrets: deleting key: '%s'", key)
+		if _, err := cnpd.db.Delete(key); err != nil {
+			log.Errorf("reconcileMemifSecrets: error deleting key: '%s': %s", key, err)
+		}
+	}
+}
+
+// memifSocketFilename returns the socket of the memif pair of the master on the host
+func (cnpd *sfcCtlrL2CNPDriver) memifSocketFilename(sfcName string, hostName string, masterContainer string,
+	memifID uint32) (string, error) {
+
+	dir := defaultMemifSocketDir
+	if cnpd.l2CNPEntityCache.SysParms.MemifSocketDir != "" {
+		dir = cnpd.l2CNPEntityCache.SysParms.MemifSocketDir
+	}
+	if he, exists := cnpd.l2CNPEntityCache.HEs[hostName]; exists && he.MemifSocketDir != "" {
+		dir = he.MemifSocketDir
+	}
+
+	var filename string
+	switch cnpd.l2CNPEntityCache.SysParms.MemifSocketScheme {
+	case controller.MemifSocketScheme_MEMIF_SOCKET_PER_SFC:
+		filename = "memif_" + sfcName + "_" + masterContainer + ".sock"
+	case controller.MemifSocketScheme_MEMIF_SOCKET_PER_PAIR:
+		filename = "memif_" + strconv.FormatUint(uint64(memifID), 10) + ".sock"
+	default:
+		filename = "memif_" + masterContainer + ".sock"
+	}
+
+	socketFilename := path.Join(dir, filename)
+	if len(socketFilename) > maxSocketFilenameLen {
+		err := fmt.Errorf("memifSocketFilename: memif socket: '%s' is longer than %d chars", socketFilename,
+			maxSocketFilenameLen)
+		log.Error(err.Error())
+		return "", err
+	}
+
+	return socketFilename, nil
+}
+
+// interfaceForLog returns a copy of the interface without the secret of its memif, if any
+func interfaceForLog(iface *interfaces.Interfaces_Interface) interfaces.Interfaces_Interface {
+	logIf := *iface
+	if iface.Memif != nil && iface.Memif.Secret != "" {
+		memif := *iface.Memif
+		memif.Secret = "<redacted>"
+		logIf.Memif = &memif
+	}
+	return logIf
+}
+
+func memifModeControllerToInterface(mode controller.MemifMode) interfaces.Interfaces_Interface_Memif_MemifMode {
+	switch mode {
+	case controller.MemifMode_MEMIF_MODE_IP:
+		return interfaces.Interfaces_Interface_Memif_IP
+	case controller.MemifMode_MEMIF_MODE_PUNT_INJECT:
+		return interfaces.Interfaces_Interface_Memif_PUNT_INJECT
+	default:
+		return interfaces.Interfaces_Interface_Memif_ETHERNET
+	}
+}
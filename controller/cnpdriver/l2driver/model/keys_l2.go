@@ -15,6 +15,9 @@
 package l2
 
 import (
+	"strconv"
+	"strings"
+
 	"github.com/ligato/sfc-controller/controller/model/controller"
 )
 
@@ -42,6 +45,32 @@ func SFCIDsKeyPrefix() string {
 	return sfcControllerIDsKeyPrefix() + "SFC/"
 }
 
+// SFCHostIDsKeyPrefix returns the ETCD prefix
+func SFCHostIDsKeyPrefix() string {
+	return sfcControllerIDsKeyPrefix() + "SFCHOST/"
+}
+
+// IPAMAllocationsKeyPrefix returns the ETCD prefix
+func IPAMAllocationsKeyPrefix() string {
+	return sfcControllerIDsKeyPrefix() + "IPAM/"
+}
+
+// IDClaimsKeyPrefix provides sfc controller's id claims prefix
+func IDClaimsKeyPrefix() string {
+	return sfcControllerIDsKeyPrefix() + "claim/"
+}
+
+// MacAddressClaimsKeyPrefix provides sfc controller's mac address claims prefix
+func MacAddressClaimsKeyPrefix() string {
+	return sfcControllerIDsKeyPrefix() + "macclaim/"
+}
+
+// MemifSecretsKeyPrefix provides sfc controller's generated memif secrets prefix, the secrets are not kept with
+// the ids so that etcd role based access control can restrict the prefix to the controller
+func MemifSecretsKeyPrefix() string {
+	return controller.SfcControllerPrefix() + "secret/memif/"
+}
+
 // HEIDsNameKey returns the ETCD key
 func HEIDsNameKey(name string) string {
 	return HEIDsKeyPrefix() + name
@@ -66,3 +95,37 @@ func SFCIDsNameKey(name string) string {
 func SFCContainerPortIDsNameKey(sfcName string, container string, port string) string {
 	return SFCIDsNameKey(sfcName) + "/" + container + "_" + port
 }
+
+// SFCHostIDsNameKey returns the ETCD key
+func SFCHostIDsNameKey(sfcName string, hostName string) string {
+	return SFCHostIDsKeyPrefix() + sfcName + "/" + hostName
+}
+
+// IPAMAllocationKey returns the ETCD key, the subnet's "/" is replaced as the subnet is one level of the key
+func IPAMAllocationKey(tenant string, subnet string, ipID uint32) string {
+	key := IPAMAllocationsKeyPrefix()
+	if tenant != "" {
+		key += tenant + "/"
+	}
+	return key + strings.Replace(subnet, "/", "_", -1) + "/" + strconv.FormatUint(uint64(ipID), 10)
+}
+
+// IDClaimKey returns the ETCD key of the claim of an id of a range, a tenant range's name has a "/"
+func IDClaimKey(rangeName string, id uint32) string {
+	return IDClaimsKeyPrefix() + rangeName + "/" + strconv.FormatUint(uint64(id), 10)
+}
+
+// MemifSecretsSfcKeyPrefix returns the ETCD prefix of the generated memif secrets of the sfc
+func MemifSecretsSfcKeyPrefix(sfcName string) string {
+	return MemifSecretsKeyPrefix() + sfcName + "/"
+}
+
+// MemifSecretKey returns the ETCD key of the generated secret of the memif pair of the container port
+func MemifSecretKey(sfcName string, container string, port string) string {
+	return MemifSecretsSfcKeyPrefix(sfcName) + container + "_" + port
+}
+
+// MacAddressClaimKey returns the ETCD key of the claim of a mac address
+func MacAddressClaimKey(macAddress string) string {
+	return MacAddressClaimsKeyPrefix() + macAddress
+}
@@ -13,6 +13,11 @@ It has these top-level messages:
 	HE2EEIDs
 	HE2HEIDs
 	SFCIDs
+	SFCHostIDs
+	IPAMAllocation
+	IDClaim
+	MacAddressClaim
+	MemifSecret
 */
 package l2
 
@@ -51,15 +56,77 @@ func (m *HE2HEIDs) String() string { return proto.CompactTextString(m) }
 func (*HE2HEIDs) ProtoMessage()    {}
 
 type SFCIDs struct {
-	SfcName   string `protobuf:"bytes,1,opt,name=sfc_name,proto3" json:"sfc_name,omitempty"`
-	Container string `protobuf:"bytes,2,opt,name=container,proto3" json:"container,omitempty"`
-	Port      string `protobuf:"bytes,3,opt,name=port,proto3" json:"port,omitempty"`
-	IpId      uint32 `protobuf:"varint,4,opt,name=ip_id,proto3" json:"ip_id,omitempty"`
-	MacAddrId uint32 `protobuf:"varint,5,opt,name=mac_addr_id,proto3" json:"mac_addr_id,omitempty"`
-	MemifId   uint32 `protobuf:"varint,6,opt,name=memif_id,proto3" json:"memif_id,omitempty"`
-	VethId    uint32 `protobuf:"varint,7,opt,name=veth_id,proto3" json:"veth_id,omitempty"`
+	SfcName         string `protobuf:"bytes,1,opt,name=sfc_name,proto3" json:"sfc_name,omitempty"`
+	Container       string `protobuf:"bytes,2,opt,name=container,proto3" json:"container,omitempty"`
+	Port            string `protobuf:"bytes,3,opt,name=port,proto3" json:"port,omitempty"`
+	IpId            uint32 `protobuf:"varint,4,opt,name=ip_id,proto3" json:"ip_id,omitempty"`
+	MacAddrId       uint32 `protobuf:"varint,5,opt,name=mac_addr_id,proto3" json:"mac_addr_id,omitempty"`
+	MemifId         uint32 `protobuf:"varint,6,opt,name=memif_id,proto3" json:"memif_id,omitempty"`
+	VethId          uint32 `protobuf:"varint,7,opt,name=veth_id,proto3" json:"veth_id,omitempty"`
+	Ipv6Id          uint32 `protobuf:"varint,8,opt,name=ipv6_id,proto3" json:"ipv6_id,omitempty"`
+	VswitchIfName   string `protobuf:"bytes,9,opt,name=vswitch_if_name,proto3" json:"vswitch_if_name,omitempty"`
+	HostIfName      string `protobuf:"bytes,10,opt,name=host_if_name,proto3" json:"host_if_name,omitempty"`
+	MemifSocket     string `protobuf:"bytes,12,opt,name=memif_socket,proto3" json:"memif_socket,omitempty"`
+	ContainerIfName string `protobuf:"bytes,13,opt,name=container_if_name,proto3" json:"container_if_name,omitempty"`
 }
 
 func (m *SFCIDs) Reset()         { *m = SFCIDs{} }
 func (m *SFCIDs) String() string { return proto.CompactTextString(m) }
 func (*SFCIDs) ProtoMessage()    {}
+
+type SFCHostIDs struct {
+	SfcName  string `protobuf:"bytes,1,opt,name=sfc_name,proto3" json:"sfc_name,omitempty"`
+	HostName string `protobuf:"bytes,2,opt,name=host_name,proto3" json:"host_name,omitempty"`
+	VrfId    uint32 `protobuf:"varint,3,opt,name=vrf_id,proto3" json:"vrf_id,omitempty"`
+}
+
+func (m *SFCHostIDs) Reset()         { *m = SFCHostIDs{} }
+func (m *SFCHostIDs) String() string { return proto.CompactTextString(m) }
+func (*SFCHostIDs) ProtoMessage()    {}
+
+type IPAMAllocation struct {
+	Tenant    string `protobuf:"bytes,1,opt,name=tenant,proto3" json:"tenant,omitempty"`
+	Subnet    string `protobuf:"bytes,2,opt,name=subnet,proto3" json:"subnet,omitempty"`
+	IpId      uint32 `protobuf:"varint,3,opt,name=ip_id,proto3" json:"ip_id,omitempty"`
+	IpAddress string `protobuf:"bytes,4,opt,name=ip_address,proto3" json:"ip_address,omitempty"`
+	SfcName   string `protobuf:"bytes,5,opt,name=sfc_name,proto3" json:"sfc_name,omitempty"`
+	Container string `protobuf:"bytes,6,opt,name=container,proto3" json:"container,omitempty"`
+	Port      string `protobuf:"bytes,7,opt,name=port,proto3" json:"port,omitempty"`
+	Owner     string `protobuf:"bytes,8,opt,name=owner,proto3" json:"owner,omitempty"`
+}
+
+func (m *IPAMAllocation) Reset()         { *m = IPAMAllocation{} }
+func (m *IPAMAllocation) String() string { return proto.CompactTextString(m) }
+func (*IPAMAllocation) ProtoMessage()    {}
+
+type IDClaim struct {
+	Range    string `protobuf:"bytes,1,opt,name=range,proto3" json:"range,omitempty"`
+	Id       uint32 `protobuf:"varint,2,opt,name=id,proto3" json:"id,omitempty"`
+	Owner    string `protobuf:"bytes,3,opt,name=owner,proto3" json:"owner,omitempty"`
+	Instance string `protobuf:"bytes,4,opt,name=instance,proto3" json:"instance,omitempty"`
+}
+
+func (m *IDClaim) Reset()         { *m = IDClaim{} }
+func (m *IDClaim) String() string { return proto.CompactTextString(m) }
+func (*IDClaim) ProtoMessage()    {}
+
+type MacAddressClaim struct {
+	MacAddress string `protobuf:"bytes,1,opt,name=mac_address,proto3" json:"mac_address,omitempty"`
+	Owner      string `protobuf:"bytes,2,opt,name=owner,proto3" json:"owner,omitempty"`
+	Instance   string `protobuf:"bytes,3,opt,name=instance,proto3" json:"instance,omitempty"`
+}
+
+func (m *MacAddressClaim) Reset()         { *m = MacAddressClaim{} }
+func (m *MacAddressClaim) String() string { return proto.CompactTextString(m) }
+func (*MacAddressClaim) ProtoMessage()    {}
+
+type MemifSecret struct {
+	SfcName   string `protobuf:"bytes,1,opt,name=sfc_name,proto3" json:"sfc_name,omitempty"`
+	Container string `protobuf:"bytes,2,opt,name=container,proto3" json:"container,omitempty"`
+	Port      string `protobuf:"bytes,3,opt,name=port,proto3" json:"port,omitempty"`
+	Secret    string `protobuf:"bytes,4,opt,name=secret,proto3" json:"secret,omitempty"`
+}
+
+func (m *MemifSecret) Reset()         { *m = MemifSecret{} }
+func (m *MemifSecret) String() string { return proto.CompactTextString(m) }
+func (*MemifSecret) ProtoMessage()    {}
@@ -22,10 +22,12 @@ import (
 	"github.com/ligato/cn-infra/utils/addrs"
 	l2driver "github.com/ligato/sfc-controller/controller/cnpdriver/l2driver/model"
 	"github.com/ligato/sfc-controller/controller/utils"
+	"github.com/ligato/vpp-agent/plugins/defaultplugins/common/model/acl"
 	"github.com/ligato/vpp-agent/plugins/defaultplugins/common/model/interfaces"
 	"github.com/ligato/vpp-agent/plugins/defaultplugins/common/model/l2"
 	"github.com/ligato/vpp-agent/plugins/defaultplugins/common/model/l3"
 	linuxIntf "github.com/ligato/vpp-agent/plugins/linuxplugin/ifplugin/model/interfaces"
+	linuxL3 "github.com/ligato/vpp-agent/plugins/linuxplugin/l3plugin/model/l3"
 )
 
 type reconcileCacheType struct {
@@ -33,13 +35,30 @@ type reconcileCacheType struct {
 	ifs      map[string]interfaces.Interfaces_Interface
 	lifs     map[string]linuxIntf.LinuxInterfaces_Interface
 	bds      map[string]l2.BridgeDomains_BridgeDomain
+	l2Fibs   map[string]l2.FibTableEntries_FibTableEntry
+	xconns   map[string]l2.XConnectPairs_XConnectPair
 	l3Routes map[string]l3.StaticRoutes_Route
+	arps     map[string]l3.ArpTable_ArpTableEntry
+
+	proxyArpRanges map[string]l3.ProxyArpRanges_ProxyArpRange
+	proxyArpIfs    map[string]l3.ProxyArpInterfaces_ProxyArpInterface
+
+	lroutes map[string]linuxL3.LinuxStaticRoutes_Route
+	larps   map[string]linuxL3.LinuxStaticArpEntries_ArpEntry
+
+	acls map[string]acl.AccessLists_Acl
 
 	// maps of ETCD entries indexed by ETCD key
 	heIDs    map[string]l2driver.HEIDs
 	he2eeIDs map[string]l2driver.HE2EEIDs
 	he2heIDs map[string]l2driver.HE2HEIDs
 	sfcIDs   map[string]l2driver.SFCIDs
+
+	sfcHostIDs map[string]l2driver.SFCHostIDs
+
+	ipamAllocs map[string]l2driver.IPAMAllocation
+
+	memifSecrets map[string]struct{} // the keys of the generated memif secrets which are rendered
 }
 
 func (cnpd *sfcCtlrL2CNPDriver) initReconcileCache() error {
@@ -47,20 +66,41 @@ func (cnpd *sfcCtlrL2CNPDriver) initReconcileCache() error {
 	cnpd.reconcileBefore.ifs = make(map[string]interfaces.Interfaces_Interface)
 	cnpd.reconcileBefore.lifs = make(map[string]linuxIntf.LinuxInterfaces_Interface)
 	cnpd.reconcileBefore.bds = make(map[string]l2.BridgeDomains_BridgeDomain)
+	cnpd.reconcileBefore.l2Fibs = make(map[string]l2.FibTableEntries_FibTableEntry)
+	cnpd.reconcileBefore.xconns = make(map[string]l2.XConnectPairs_XConnectPair)
 	cnpd.reconcileBefore.l3Routes = make(map[string]l3.StaticRoutes_Route)
+	cnpd.reconcileBefore.arps = make(map[string]l3.ArpTable_ArpTableEntry)
+	cnpd.reconcileBefore.proxyArpRanges = make(map[string]l3.ProxyArpRanges_ProxyArpRange)
+	cnpd.reconcileBefore.proxyArpIfs = make(map[string]l3.ProxyArpInterfaces_ProxyArpInterface)
+	cnpd.reconcileBefore.lroutes = make(map[string]linuxL3.LinuxStaticRoutes_Route)
+	cnpd.reconcileBefore.larps = make(map[string]linuxL3.LinuxStaticArpEntries_ArpEntry)
+	cnpd.reconcileBefore.acls = make(map[string]acl.AccessLists_Acl)
 	cnpd.reconcileBefore.heIDs = make(map[string]l2driver.HEIDs)
 	cnpd.reconcileBefore.he2eeIDs = make(map[string]l2driver.HE2EEIDs)
 	cnpd.reconcileBefore.he2heIDs = make(map[string]l2driver.HE2HEIDs)
 	cnpd.reconcileBefore.sfcIDs = make(map[string]l2driver.SFCIDs)
+	cnpd.reconcileBefore.sfcHostIDs = make(map[string]l2driver.SFCHostIDs)
+	cnpd.reconcileBefore.ipamAllocs = make(map[string]l2driver.IPAMAllocation)
 
 	cnpd.reconcileAfter.ifs = make(map[string]interfaces.Interfaces_Interface)
 	cnpd.reconcileAfter.lifs = make(map[string]linuxIntf.LinuxInterfaces_Interface)
 	cnpd.reconcileAfter.bds = make(map[string]l2.BridgeDomains_BridgeDomain)
+	cnpd.reconcileAfter.l2Fibs = make(map[string]l2.FibTableEntries_FibTableEntry)
+	cnpd.reconcileAfter.xconns = make(map[string]l2.XConnectPairs_XConnectPair)
 	cnpd.reconcileAfter.l3Routes = make(map[string]l3.StaticRoutes_Route)
+	cnpd.reconcileAfter.arps = make(map[string]l3.ArpTable_ArpTableEntry)
+	cnpd.reconcileAfter.proxyArpRanges = make(map[string]l3.ProxyArpRanges_ProxyArpRange)
+	cnpd.reconcileAfter.proxyArpIfs = make(map[string]l3.ProxyArpInterfaces_ProxyArpInterface)
+	cnpd.reconcileAfter.lroutes = make(map[string]linuxL3.LinuxStaticRoutes_Route)
+	cnpd.reconcileAfter.larps = make(map[string]linuxL3.LinuxStaticArpEntries_ArpEntry)
+	cnpd.reconcileAfter.acls = make(map[string]acl.AccessLists_Acl)
 	cnpd.reconcileAfter.heIDs = make(map[string]l2driver.HEIDs)
 	cnpd.reconcileAfter.he2eeIDs = make(map[string]l2driver.HE2EEIDs)
 	cnpd.reconcileAfter.he2heIDs = make(map[string]l2driver.HE2HEIDs)
 	cnpd.reconcileAfter.sfcIDs = make(map[string]l2driver.SFCIDs)
+	cnpd.reconcileAfter.sfcHostIDs = make(map[string]l2driver.SFCHostIDs)
+	cnpd.reconcileAfter.ipamAllocs = make(map[string]l2driver.IPAMAllocation)
+	cnpd.reconcileAfter.memifSecrets = make(map[string]struct{})
 
 	return nil
 }
@@ -92,24 +132,51 @@ func (cnpd *sfcCtlrL2CNPDriver) ReconcileStart(vppEtcdLabels map[string]struct{}
 	// reconcile resync is to ONLY make changes if there are new and/or obselete configs.  Existing configs should
 	// reamin un-affected by the resync process.
 
+	// A reconcile can also be run after the controller has started, ie: when an sfc is deleted, so the state
+	// of the previous render is dropped and everything is rendered again as it is on startup.  The ids, macs
+	// and addresses are reloaded from the db, so the objects keep them.
+
 	log.Info("ReconcileStart: begin ...")
 	defer log.Info("ReconcileStart: exit ...")
 
+	cnpd.initL2CNPCache()
+	cnpd.initReconcileCache()
 	cnpd.reconcileStateSet(true)
 
 	for vppEtdLabel := range vppEtcdLabels {
 		cnpd.reconcileLoadInterfacesIntoCache(vppEtdLabel)
 		cnpd.reconcileLoadLinuxInterfacesIntoCache(vppEtdLabel)
 		cnpd.reconcileLoadBridgeDomainsIntoCache(vppEtdLabel)
+		cnpd.reconcileLoadL2FibEntriesIntoCache(vppEtdLabel)
+		cnpd.reconcileLoadXConnectsIntoCache(vppEtdLabel)
 		cnpd.reconcileLoadStaticRoutesIntoCache(vppEtdLabel)
+		cnpd.reconcileLoadStaticArpEntriesIntoCache(vppEtdLabel)
+		cnpd.reconcileLoadProxyArpRangesIntoCache(vppEtdLabel)
+		cnpd.reconcileLoadProxyArpInterfacesIntoCache(vppEtdLabel)
+		cnpd.reconcileLoadLinuxRoutesIntoCache(vppEtdLabel)
+		cnpd.reconcileLoadLinuxArpEntriesIntoCache(vppEtdLabel)
+		cnpd.reconcileLoadAclsIntoCache(vppEtdLabel)
 	}
 
 	cnpd.reconcileLoadHEIDsIntoCache()
 	cnpd.reconcileLoadHE2EEIDsIntoCache()
 	cnpd.reconcileLoadHE2HEIDsIntoCache()
 	cnpd.reconcileLoadSFCIDsIntoCache()
+	cnpd.reconcileLoadSFCHostIDsIntoCache()
+	cnpd.reconcileLoadIPAMAllocationsIntoCache()
 
-	cnpd.sequencerInitFromReconcileCache()
+	cnpd.idsInitFromReconcileCache()
+	cnpd.ipamInitFromReconcileCache()
+
+	return nil
+}
+
+// ReconcileAbort ends a reconcile whose config could not be rendered, the db is left as is, the next reconcile
+// renders it again
+func (cnpd *sfcCtlrL2CNPDriver) ReconcileAbort() error {
+
+	log.Info("ReconcileAbort: the db is not changed")
+	cnpd.reconcileStateSet(false)
 
 	return nil
 }
@@ -151,7 +218,7 @@ func (cnpd *sfcCtlrL2CNPDriver) ReconcileEnd() error {
 	// Interfaces: now post process the after cache
 	for key := range cnpd.reconcileAfter.ifs {
 		afterIF := cnpd.reconcileAfter.ifs[key]
-		log.Info("ReconcileEnd: add i/f key to etcd: ", key, afterIF)
+		log.Info("ReconcileEnd: add i/f key to etcd: ", key, interfaceForLog(&afterIF))
 		err := cnpd.db.Put(key, &afterIF)
 		if err != nil {
 			log.Error("ReconcileEnd: error storing i/f: '%s'", key, err)
@@ -211,6 +278,56 @@ func (cnpd *sfcCtlrL2CNPDriver) ReconcileEnd() error {
 		}
 	}
 
+	// L2 FIB entries: traverse the before cache
+	for key := range cnpd.reconcileBefore.l2Fibs {
+		beforeFib := cnpd.reconcileBefore.l2Fibs[key]
+		afterFib, existsInAfterCache := cnpd.reconcileAfter.l2Fibs[key]
+		if !existsInAfterCache {
+			exists, err := cnpd.db.Delete(key)
+			log.Info("ReconcileEnd: remove l2fib key from etcd and reconcile cache: ", key, exists, err)
+			delete(cnpd.reconcileAfter.l2Fibs, key)
+		} else {
+			if beforeFib.String() == afterFib.String() {
+				delete(cnpd.reconcileAfter.l2Fibs, key)
+			}
+		}
+	}
+	// L2 FIB entries: now post process the after cache
+	for key := range cnpd.reconcileAfter.l2Fibs {
+		afterFib := cnpd.reconcileAfter.l2Fibs[key]
+		log.Info("ReconcileEnd: add l2fib key to etcd: ", key, afterFib)
+		err := cnpd.db.Put(key, &afterFib)
+		if err != nil {
+			log.Errorf("ReconcileEnd: error storing l2fib: '%s': %s", key, err)
+			return err
+		}
+	}
+
+	// L2 XConnects: traverse the before cache
+	for key := range cnpd.reconcileBefore.xconns {
+		beforeXConn := cnpd.reconcileBefore.xconns[key]
+		afterXConn, existsInAfterCache := cnpd.reconcileAfter.xconns[key]
+		if !existsInAfterCache {
+			exists, err := cnpd.db.Delete(key)
+			log.Info("ReconcileEnd: remove l2xconnect key from etcd and reconcile cache: ", key, exists, err)
+			delete(cnpd.reconcileAfter.xconns, key)
+		} else {
+			if beforeXConn.String() == afterXConn.String() {
+				delete(cnpd.reconcileAfter.xconns, key)
+			}
+		}
+	}
+	// L2 XConnects: now post process the after cache
+	for key := range cnpd.reconcileAfter.xconns {
+		afterXConn := cnpd.reconcileAfter.xconns[key]
+		log.Info("ReconcileEnd: add l2xconnect key to etcd: ", key, afterXConn)
+		err := cnpd.db.Put(key, &afterXConn)
+		if err != nil {
+			log.Errorf("ReconcileEnd: error storing l2xconnect: '%s': %s", key, err)
+			return err
+		}
+	}
+
 	// Static Routes: traverse the before cache
 	for key := range cnpd.reconcileBefore.l3Routes {
 		beforeSR := cnpd.reconcileBefore.l3Routes[key]
@@ -248,6 +365,161 @@ func (cnpd *sfcCtlrL2CNPDriver) ReconcileEnd() error {
 		}
 	}
 
+	// Proxy ARP ranges: traverse the before cache
+	for key := range cnpd.reconcileBefore.proxyArpRanges {
+		beforePAR := cnpd.reconcileBefore.proxyArpRanges[key]
+		afterPAR, existsInAfterCache := cnpd.reconcileAfter.proxyArpRanges[key]
+		if !existsInAfterCache {
+			exists, err := cnpd.db.Delete(key)
+			log.Info("ReconcileEnd: remove proxy arp range key from etcd and reconcile cache: ", key, exists, err)
+			delete(cnpd.reconcileAfter.proxyArpRanges, key)
+		} else {
+			if beforePAR.String() == afterPAR.String() {
+				delete(cnpd.reconcileAfter.proxyArpRanges, key)
+			}
+		}
+	}
+	// Proxy ARP ranges: now post process the after cache
+	for key := range cnpd.reconcileAfter.proxyArpRanges {
+		afterPAR := cnpd.reconcileAfter.proxyArpRanges[key]
+		log.Info("ReconcileEnd: add proxy arp range key to etcd: ", key, afterPAR)
+		err := cnpd.db.Put(key, &afterPAR)
+		if err != nil {
+			log.Errorf("ReconcileEnd: error storing proxy arp range: '%s': %s", key, err)
+			return err
+		}
+	}
+
+	// Proxy ARP interfaces: traverse the before cache
+	for key := range cnpd.reconcileBefore.proxyArpIfs {
+		beforePAI := cnpd.reconcileBefore.proxyArpIfs[key]
+		afterPAI, existsInAfterCache := cnpd.reconcileAfter.proxyArpIfs[key]
+		if !existsInAfterCache {
+			exists, err := cnpd.db.Delete(key)
+			log.Info("ReconcileEnd: remove proxy arp i/f key from etcd and reconcile cache: ", key, exists, err)
+			delete(cnpd.reconcileAfter.proxyArpIfs, key)
+		} else {
+			if beforePAI.String() == afterPAI.String() {
+				delete(cnpd.reconcileAfter.proxyArpIfs, key)
+			}
+		}
+	}
+	// Proxy ARP interfaces: now post process the after cache
+	for key := range cnpd.reconcileAfter.proxyArpIfs {
+		afterPAI := cnpd.reconcileAfter.proxyArpIfs[key]
+		log.Info("ReconcileEnd: add proxy arp i/f key to etcd: ", key, afterPAI)
+		err := cnpd.db.Put(key, &afterPAI)
+		if err != nil {
+			log.Errorf("ReconcileEnd: error storing proxy arp i/f: '%s': %s", key, err)
+			return err
+		}
+	}
+
+	// Linux Routes: traverse the before cache
+	for key := range cnpd.reconcileBefore.lroutes {
+		beforeLR := cnpd.reconcileBefore.lroutes[key]
+		afterLR, existsInAfterCache := cnpd.reconcileAfter.lroutes[key]
+		if !existsInAfterCache {
+			exists, err := cnpd.db.Delete(key)
+			log.Info("ReconcileEnd: remove linux route key from etcd and reconcile cache: ", key, exists, err)
+			delete(cnpd.reconcileAfter.lroutes, key)
+		} else {
+			if beforeLR.String() == afterLR.String() {
+				delete(cnpd.reconcileAfter.lroutes, key)
+			}
+		}
+	}
+	// Linux Routes: now post process the after cache
+	for key := range cnpd.reconcileAfter.lroutes {
+		afterLR := cnpd.reconcileAfter.lroutes[key]
+		log.Info("ReconcileEnd: add linux route key to etcd: ", key, afterLR)
+		err := cnpd.db.Put(key, &afterLR)
+		if err != nil {
+			log.Errorf("ReconcileEnd: error storing linux route: '%s': %s", key, err)
+			return err
+		}
+	}
+
+	// Linux ARP entries: traverse the before cache
+	for key := range cnpd.reconcileBefore.larps {
+		beforeAE := cnpd.reconcileBefore.larps[key]
+		afterAE, existsInAfterCache := cnpd.reconcileAfter.larps[key]
+		if !existsInAfterCache {
+			exists, err := cnpd.db.Delete(key)
+			log.Info("ReconcileEnd: remove linux arp entry key from etcd and reconcile cache: ", key, exists, err)
+			delete(cnpd.reconcileAfter.larps, key)
+		} else {
+			if beforeAE.String() == afterAE.String() {
+				delete(cnpd.reconcileAfter.larps, key)
+			}
+		}
+	}
+	// Linux ARP entries: now post process the after cache
+	for key := range cnpd.reconcileAfter.larps {
+		afterAE := cnpd.reconcileAfter.larps[key]
+		log.Info("ReconcileEnd: add linux arp entry key to etcd: ", key, afterAE)
+		err := cnpd.db.Put(key, &afterAE)
+		if err != nil {
+			log.Errorf("ReconcileEnd: error storing linux arp entry: '%s': %s", key, err)
+			return err
+		}
+	}
+
+	// Static ARP entries: traverse the before cache
+	for key := range cnpd.reconcileBefore.arps {
+		beforeAE := cnpd.reconcileBefore.arps[key]
+		afterAE, existsInAfterCache := cnpd.reconcileAfter.arps[key]
+		if !existsInAfterCache {
+			exists, err := cnpd.db.Delete(key)
+			log.Info("ReconcileEnd: remove arp entry key from etcd and reconcile cache: ", key, exists, err)
+			delete(cnpd.reconcileAfter.arps, key)
+		} else {
+			if beforeAE.String() == afterAE.String() {
+				delete(cnpd.reconcileAfter.arps, key)
+			}
+		}
+	}
+	// Static ARP entries: now post process the after cache
+	for key := range cnpd.reconcileAfter.arps {
+		afterAE := cnpd.reconcileAfter.arps[key]
+		log.Info("ReconcileEnd: add arp entry key to etcd: ", key, afterAE)
+		err := cnpd.db.Put(key, &afterAE)
+		if err != nil {
+			log.Errorf("ReconcileEnd: error storing arp entry: '%s': %s", key, err)
+			return err
+		}
+	}
+
+	// ACLs: traverse the before cache
+	for key := range cnpd.reconcileBefore.acls {
+		beforeACL := cnpd.reconcileBefore.acls[key]
+		afterACL, existsInAfterCache := cnpd.reconcileAfter.acls[key]
+		if !existsInAfterCache {
+			exists, err := cnpd.db.Delete(key)
+			log.Info("ReconcileEnd: remove acl key from etcd and reconcile cache: ", key, exists, err)
+			delete(cnpd.reconcileAfter.acls, key)
+		} else {
+			if beforeACL.String() == afterACL.String() {
+				delete(cnpd.reconcileAfter.acls, key)
+			}
+		}
+	}
+	// ACLs: now post process the after cache
+	for key := range cnpd.reconcileAfter.acls {
+		afterACL := cnpd.reconcileAfter.acls[key]
+		log.Info("ReconcileEnd: add acl key to etcd: ", key, afterACL)
+		err := cnpd.db.Put(key, &afterACL)
+		if err != nil {
+			log.Errorf("ReconcileEnd: error storing acl: '%s': %s", key, err)
+			return err
+		}
+	}
+
+	// the ids of the id records which are removed, or changed, are released before the records are processed
+	cnpd.reconcileIDClaims()
+	cnpd.reconcileMacClaims()
+	cnpd.reconcileMemifSecrets()
+
 	// HE IDs: traverse the before cache
 	for key := range cnpd.reconcileBefore.heIDs {
 		beforeHEID := cnpd.reconcileBefore.heIDs[key]
@@ -348,6 +620,58 @@ func (cnpd *sfcCtlrL2CNPDriver) ReconcileEnd() error {
 		}
 	}
 
+	// SFC host IDs: traverse the before cache
+	for key := range cnpd.reconcileBefore.sfcHostIDs {
+		beforeSFCHostID := cnpd.reconcileBefore.sfcHostIDs[key]
+		afterSFCHostID, existsInAfterCache := cnpd.reconcileAfter.sfcHostIDs[key]
+		if !existsInAfterCache {
+			exists, err := cnpd.db.Delete(key)
+			log.Info("ReconcileEnd: remove SFC host ID key from etcd and reconcile cache: ", key, exists, err)
+			delete(cnpd.reconcileAfter.sfcHostIDs, key)
+		} else {
+			if beforeSFCHostID.String() == afterSFCHostID.String() {
+				delete(cnpd.reconcileAfter.sfcHostIDs, key)
+			}
+		}
+	}
+	// SFC host IDs: now post process the after cache
+	for key := range cnpd.reconcileAfter.sfcHostIDs {
+		afterSFCHostID := cnpd.reconcileAfter.sfcHostIDs[key]
+		log.Info("ReconcileEnd: add SFC host ID key to etcd: ", key, afterSFCHostID)
+		err := cnpd.db.Put(key, &afterSFCHostID)
+		if err != nil {
+			log.Errorf("ReconcileEnd: error storing SFC host ID: '%s': %s", key, err)
+			return err
+		}
+	}
+
+	// IPAM allocations: traverse the before cache, an allocation that was not rendered is released
+	for key := range cnpd.reconcileBefore.ipamAllocs {
+		beforeAlloc := cnpd.reconcileBefore.ipamAllocs[key]
+		afterAlloc, existsInAfterCache := cnpd.reconcileAfter.ipamAllocs[key]
+		if !existsInAfterCache {
+			exists, err := cnpd.db.Delete(key)
+			log.Info("ReconcileEnd: release IPAM allocation, remove key from etcd and reconcile cache: ",
+				key, exists, err)
+			cnpd.ipam.ReleaseIpIDInSubnet(beforeAlloc.Tenant, beforeAlloc.Subnet, beforeAlloc.IpId)
+			delete(cnpd.reconcileAfter.ipamAllocs, key)
+		} else {
+			if beforeAlloc.String() == afterAlloc.String() {
+				delete(cnpd.reconcileAfter.ipamAllocs, key)
+			}
+		}
+	}
+	// IPAM allocations: now post process the after cache
+	for key := range cnpd.reconcileAfter.ipamAllocs {
+		afterAlloc := cnpd.reconcileAfter.ipamAllocs[key]
+		log.Info("ReconcileEnd: add IPAM allocation key to etcd: ", key, afterAlloc)
+		err := cnpd.db.Put(key, &afterAlloc)
+		if err != nil {
+			log.Errorf("ReconcileEnd: error storing IPAM allocation: '%s': %s", key, err)
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -356,6 +680,16 @@ func (cnpd *sfcCtlrL2CNPDriver) reconcileBridgeDomain(etcdVppSwitchKey string, b
 	cnpd.reconcileAfter.bds[bdKey] = *bd
 }
 
+func (cnpd *sfcCtlrL2CNPDriver) reconcileL2FibEntry(etcdPrefix string, l2fib *l2.FibTableEntries_FibTableEntry) {
+	key := utils.L2FibKey(etcdPrefix, l2fib.BridgeDomain, l2fib.PhysAddress)
+	cnpd.reconcileAfter.l2Fibs[key] = *l2fib
+}
+
+func (cnpd *sfcCtlrL2CNPDriver) reconcileXConnect(etcdPrefix string, xconn *l2.XConnectPairs_XConnectPair) {
+	key := utils.L2XConnectKey(etcdPrefix, xconn.ReceiveInterface)
+	cnpd.reconcileAfter.xconns[key] = *xconn
+}
+
 func (cnpd *sfcCtlrL2CNPDriver) reconcileInterface(etcdVppSwitchKey string, currIf *interfaces.Interfaces_Interface) {
 	ifKey := utils.InterfaceKey(etcdVppSwitchKey, currIf.Name)
 	cnpd.reconcileAfter.ifs[ifKey] = *currIf
@@ -375,6 +709,38 @@ func (cnpd *sfcCtlrL2CNPDriver) reconcileStaticRoute(etcdPrefix string, sr *l3.S
 	cnpd.reconcileAfter.l3Routes[key] = *sr
 }
 
+func (cnpd *sfcCtlrL2CNPDriver) reconcileStaticArpEntry(etcdPrefix string, ae *l3.ArpTable_ArpTableEntry) {
+	key := utils.ArpEntryKey(etcdPrefix, ae.Interface, ae.IpAddress)
+	cnpd.reconcileAfter.arps[key] = *ae
+}
+
+func (cnpd *sfcCtlrL2CNPDriver) reconcileProxyArpRange(etcdPrefix string, par *l3.ProxyArpRanges_ProxyArpRange) {
+	key := utils.ProxyArpRangeKey(etcdPrefix, par.RangeIpStart, par.RangeIpEnd)
+	cnpd.reconcileAfter.proxyArpRanges[key] = *par
+}
+
+func (cnpd *sfcCtlrL2CNPDriver) reconcileProxyArpInterface(etcdPrefix string,
+	pai *l3.ProxyArpInterfaces_ProxyArpInterface) {
+
+	key := utils.ProxyArpInterfaceKey(etcdPrefix, pai.Interface)
+	cnpd.reconcileAfter.proxyArpIfs[key] = *pai
+}
+
+func (cnpd *sfcCtlrL2CNPDriver) reconcileLinuxRoute(etcdPrefix string, lr *linuxL3.LinuxStaticRoutes_Route) {
+	key := utils.LinuxRouteKey(etcdPrefix, lr.Name)
+	cnpd.reconcileAfter.lroutes[key] = *lr
+}
+
+func (cnpd *sfcCtlrL2CNPDriver) reconcileLinuxArpEntry(etcdPrefix string, ae *linuxL3.LinuxStaticArpEntries_ArpEntry) {
+	key := utils.LinuxArpEntryKey(etcdPrefix, ae.Name)
+	cnpd.reconcileAfter.larps[key] = *ae
+}
+
+func (cnpd *sfcCtlrL2CNPDriver) reconcileAcl(etcdPrefix string, currACL *acl.AccessLists_Acl) {
+	key := utils.AclKey(etcdPrefix, currACL.AclName)
+	cnpd.reconcileAfter.acls[key] = *currACL
+}
+
 func (cnpd *sfcCtlrL2CNPDriver) reconcileLoadInterfacesIntoCache(etcdVppLabel string) error {
 
 	kvi, err := cnpd.db.ListValues(utils.InterfacePrefixKey(etcdVppLabel))
@@ -395,7 +761,7 @@ func (cnpd *sfcCtlrL2CNPDriver) reconcileLoadInterfacesIntoCache(etcdVppLabel st
 			return nil
 		}
 
-		fmt.Println("reconcileLoadInterfacesIntoCache: adding Interface: ", etcdVppLabel, kv.GetKey(), entry)
+		fmt.Println("reconcileLoadInterfacesIntoCache: adding Interface: ", etcdVppLabel, kv.GetKey(), interfaceForLog(entry))
 		cnpd.reconcileBefore.ifs[kv.GetKey()] = *entry
 	}
 }
@@ -445,12 +811,68 @@ func (cnpd *sfcCtlrL2CNPDriver) reconcileLoadBridgeDomainsIntoCache(etcdVppLabel
 			log.Fatal(err)
 			return nil
 		}
+		if utils.IsL2FibKey(etcdVppLabel, kv.GetKey()) {
+			// the l2fib entries of the bridge domains are under the bridge domain key prefix
+			continue
+		}
 		fmt.Println("reconcileLoadBridgeDomainsIntoCache: adding bridge doamin: ",
 			etcdVppLabel, kv.GetKey(), entry)
 		cnpd.reconcileBefore.bds[kv.GetKey()] = *entry
 	}
 }
 
+func (cnpd *sfcCtlrL2CNPDriver) reconcileLoadL2FibEntriesIntoCache(etcdVppLabel string) error {
+
+	kvi, err := cnpd.db.ListValues(utils.L2BridgeDomainKeyPrefix(etcdVppLabel))
+	if err != nil {
+		log.Fatal(err)
+		return nil
+	}
+
+	for {
+		kv, allReceived := kvi.GetNext()
+		if allReceived {
+			return nil
+		}
+		if !utils.IsL2FibKey(etcdVppLabel, kv.GetKey()) {
+			continue
+		}
+		entry := &l2.FibTableEntries_FibTableEntry{}
+		err := kv.GetValue(entry)
+		if err != nil {
+			log.Fatal(err)
+			return nil
+		}
+		log.Debugf("reconcileLoadL2FibEntriesIntoCache: adding l2fib: %s, %s, %v", etcdVppLabel, kv.GetKey(), entry)
+		cnpd.reconcileBefore.l2Fibs[kv.GetKey()] = *entry
+	}
+}
+
+func (cnpd *sfcCtlrL2CNPDriver) reconcileLoadXConnectsIntoCache(etcdVppLabel string) error {
+
+	kvi, err := cnpd.db.ListValues(utils.L2XConnectKeyPrefix(etcdVppLabel))
+	if err != nil {
+		log.Fatal(err)
+		return nil
+	}
+
+	for {
+		kv, allReceived := kvi.GetNext()
+		if allReceived {
+			return nil
+		}
+		entry := &l2.XConnectPairs_XConnectPair{}
+		err := kv.GetValue(entry)
+		if err != nil {
+			log.Fatal(err)
+			return nil
+		}
+		log.Debugf("reconcileLoadXConnectsIntoCache: adding l2xconnect: %s, %s, %v", etcdVppLabel, kv.GetKey(),
+			entry)
+		cnpd.reconcileBefore.xconns[kv.GetKey()] = *entry
+	}
+}
+
 func (cnpd *sfcCtlrL2CNPDriver) reconcileLoadStaticRoutesIntoCache(etcdVppLabel string) error {
 
 	kvi, err := cnpd.db.ListValues(utils.L3RouteKeyPrefix(etcdVppLabel))
@@ -475,6 +897,151 @@ func (cnpd *sfcCtlrL2CNPDriver) reconcileLoadStaticRoutesIntoCache(etcdVppLabel
 	}
 }
 
+func (cnpd *sfcCtlrL2CNPDriver) reconcileLoadStaticArpEntriesIntoCache(etcdVppLabel string) error {
+
+	kvi, err := cnpd.db.ListValues(utils.ArpEntryKeyPrefix(etcdVppLabel))
+	if err != nil {
+		log.Fatal(err)
+		return nil
+	}
+
+	for {
+		kv, allReceived := kvi.GetNext()
+		if allReceived {
+			return nil
+		}
+		entry := &l3.ArpTable_ArpTableEntry{}
+		err := kv.GetValue(entry)
+		if err != nil {
+			log.Fatal(err)
+			return nil
+		}
+		log.Debugf("reconcileLoadStaticArpEntriesIntoCache: adding arp entry: %s, %s, %v", etcdVppLabel,
+			kv.GetKey(), entry)
+		cnpd.reconcileBefore.arps[kv.GetKey()] = *entry
+	}
+}
+
+func (cnpd *sfcCtlrL2CNPDriver) reconcileLoadProxyArpRangesIntoCache(etcdVppLabel string) error {
+
+	kvi, err := cnpd.db.ListValues(utils.ProxyArpRangeKeyPrefix(etcdVppLabel))
+	if err != nil {
+		log.Fatal(err)
+		return nil
+	}
+
+	for {
+		kv, allReceived := kvi.GetNext()
+		if allReceived {
+			return nil
+		}
+		entry := &l3.ProxyArpRanges_ProxyArpRange{}
+		err := kv.GetValue(entry)
+		if err != nil {
+			log.Fatal(err)
+			return nil
+		}
+		log.Debugf("reconcileLoadProxyArpRangesIntoCache: adding proxy arp range: %s, %s, %v", etcdVppLabel, kv.GetKey(), entry)
+		cnpd.reconcileBefore.proxyArpRanges[kv.GetKey()] = *entry
+	}
+}
+
+func (cnpd *sfcCtlrL2CNPDriver) reconcileLoadProxyArpInterfacesIntoCache(etcdVppLabel string) error {
+
+	kvi, err := cnpd.db.ListValues(utils.ProxyArpInterfaceKeyPrefix(etcdVppLabel))
+	if err != nil {
+		log.Fatal(err)
+		return nil
+	}
+
+	for {
+		kv, allReceived := kvi.GetNext()
+		if allReceived {
+			return nil
+		}
+		entry := &l3.ProxyArpInterfaces_ProxyArpInterface{}
+		err := kv.GetValue(entry)
+		if err != nil {
+			log.Fatal(err)
+			return nil
+		}
+		log.Debugf("reconcileLoadProxyArpInterfacesIntoCache: adding proxy arp i/f: %s, %s, %v", etcdVppLabel, kv.GetKey(), entry)
+		cnpd.reconcileBefore.proxyArpIfs[kv.GetKey()] = *entry
+	}
+}
+
+func (cnpd *sfcCtlrL2CNPDriver) reconcileLoadLinuxRoutesIntoCache(etcdVppLabel string) error {
+
+	kvi, err := cnpd.db.ListValues(utils.LinuxRouteKeyPrefix(etcdVppLabel))
+	if err != nil {
+		log.Fatal(err)
+		return nil
+	}
+
+	for {
+		kv, allReceived := kvi.GetNext()
+		if allReceived {
+			return nil
+		}
+		entry := &linuxL3.LinuxStaticRoutes_Route{}
+		err := kv.GetValue(entry)
+		if err != nil {
+			log.Fatal(err)
+			return nil
+		}
+		log.Debugf("reconcileLoadLinuxRoutesIntoCache: adding linux route: %s, %s, %v", etcdVppLabel, kv.GetKey(), entry)
+		cnpd.reconcileBefore.lroutes[kv.GetKey()] = *entry
+	}
+}
+
+func (cnpd *sfcCtlrL2CNPDriver) reconcileLoadLinuxArpEntriesIntoCache(etcdVppLabel string) error {
+
+	kvi, err := cnpd.db.ListValues(utils.LinuxArpEntryKeyPrefix(etcdVppLabel))
+	if err != nil {
+		log.Fatal(err)
+		return nil
+	}
+
+	for {
+		kv, allReceived := kvi.GetNext()
+		if allReceived {
+			return nil
+		}
+		entry := &linuxL3.LinuxStaticArpEntries_ArpEntry{}
+		err := kv.GetValue(entry)
+		if err != nil {
+			log.Fatal(err)
+			return nil
+		}
+		log.Debugf("reconcileLoadLinuxArpEntriesIntoCache: adding linux arp entry: %s, %s, %v", etcdVppLabel, kv.GetKey(), entry)
+		cnpd.reconcileBefore.larps[kv.GetKey()] = *entry
+	}
+}
+
+func (cnpd *sfcCtlrL2CNPDriver) reconcileLoadAclsIntoCache(etcdVppLabel string) error {
+
+	kvi, err := cnpd.db.ListValues(utils.AclKeyPrefix(etcdVppLabel))
+	if err != nil {
+		log.Fatal(err)
+		return nil
+	}
+
+	for {
+		kv, allReceived := kvi.GetNext()
+		if allReceived {
+			return nil
+		}
+		entry := &acl.AccessLists_Acl{}
+		err := kv.GetValue(entry)
+		if err != nil {
+			log.Fatal(err)
+			return nil
+		}
+		log.Debugf("reconcileLoadAclsIntoCache: adding acl: %s, %s, %v", etcdVppLabel, kv.GetKey(), entry)
+		cnpd.reconcileBefore.acls[kv.GetKey()] = *entry
+	}
+}
+
 func (cnpd *sfcCtlrL2CNPDriver) reconcileLoadHEIDsIntoCache() error {
 
 	kvi, err := cnpd.db.ListValues(l2driver.HEIDsKeyPrefix())
@@ -571,46 +1138,67 @@ func (cnpd *sfcCtlrL2CNPDriver) reconcileLoadSFCIDsIntoCache() error {
 	}
 }
 
-func (cnpd *sfcCtlrL2CNPDriver) sequencerInitFromReconcileCache() {
-
-	// the sequencer is responsible fore choosing unique id's ... after pulling in all the data from
-	// the db, the data is traversed and the max value is remembered
+func (cnpd *sfcCtlrL2CNPDriver) reconcileLoadSFCHostIDsIntoCache() error {
 
-	maxVlanID := uint32(0)
-	maxMemifID := uint32(0)
-	maxMacAddrID := uint32(0)
-
-	// traverse the id caches recording max id's
+	kvi, err := cnpd.db.ListValues(l2driver.SFCHostIDsKeyPrefix())
+	if err != nil {
+		log.Fatal(err)
+		return nil
+	}
 
-	for _, heId := range cnpd.reconcileBefore.heIDs {
-		if heId.LoopbackMacAddrId > maxMacAddrID {
-			maxMacAddrID = heId.LoopbackMacAddrId
+	for {
+		kv, allReceived := kvi.GetNext()
+		if allReceived {
+			return nil
 		}
-	}
-	for _, he2ee := range cnpd.reconcileBefore.he2eeIDs {
-		if he2ee.VlanId > maxVlanID {
-			maxVlanID = he2ee.VlanId
+		entry := &l2driver.SFCHostIDs{}
+		err := kv.GetValue(entry)
+		if err != nil {
+			log.Fatal(err)
+			return nil
 		}
+		fmt.Println("reconcileLoadSFCHostIDsIntoCache: adding SFC host IDs: ", kv.GetKey(), entry)
+		cnpd.reconcileBefore.sfcHostIDs[kv.GetKey()] = *entry
 	}
-	for _, he2he := range cnpd.reconcileBefore.he2heIDs {
-		if he2he.VlanId > maxVlanID {
-			maxVlanID = he2he.VlanId
-		}
+}
+
+func (cnpd *sfcCtlrL2CNPDriver) reconcileLoadIPAMAllocationsIntoCache() error {
+
+	kvi, err := cnpd.db.ListValues(l2driver.IPAMAllocationsKeyPrefix())
+	if err != nil {
+		log.Fatal(err)
+		return nil
 	}
-	for _, sfc := range cnpd.reconcileBefore.sfcIDs {
-		if sfc.MacAddrId > maxMacAddrID {
-			maxMacAddrID = sfc.MacAddrId
+
+	for {
+		kv, allReceived := kvi.GetNext()
+		if allReceived {
+			return nil
 		}
-		if sfc.MemifId > maxMemifID {
-			maxMemifID = sfc.MemifId
+		entry := &l2driver.IPAMAllocation{}
+		err := kv.GetValue(entry)
+		if err != nil {
+			log.Fatal(err)
+			return nil
 		}
+		fmt.Println("reconcileLoadIPAMAllocationsIntoCache: adding IPAM allocation: ", kv.GetKey(), entry)
+		cnpd.reconcileBefore.ipamAllocs[kv.GetKey()] = *entry
 	}
+}
 
-	// now prime the sequencer with the max values from the db to avoid collisions
-
-	cnpd.seq.VLanID = maxVlanID
-	cnpd.seq.MemIfID = maxMemifID
-	cnpd.seq.MacInstanceID = maxMacAddrID
+// ipamInitFromReconcileCache marks the allocations from the db as allocated to their owners so the addresses
+// are not handed to someone else while the config is rendered, the ones not rendered are released at the end
+// of the reconcile, except for the manual reservations which are never rendered
+func (cnpd *sfcCtlrL2CNPDriver) ipamInitFromReconcileCache() {
 
-	fmt.Println("sequencerInitFromReconcileCache: sequence IDs after loading id's: ", cnpd.seq)
+	for key, alloc := range cnpd.reconcileBefore.ipamAllocs {
+		owner := ipamAllocationOwner(&alloc)
+		if _, err := cnpd.ipam.SetIpIDInSubnet(alloc.Tenant, alloc.Subnet, alloc.IpId, owner); err != nil {
+			log.Errorf("ipamInitFromReconcileCache: cannot restore allocation '%s': %s", key, err)
+			continue
+		}
+		if alloc.Owner != "" {
+			cnpd.reconcileAfter.ipamAllocs[key] = alloc
+		}
+	}
 }
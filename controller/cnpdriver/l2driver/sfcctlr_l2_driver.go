@@ -34,6 +34,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"syscall"
 
 	"github.com/ligato/cn-infra/db/keyval"
 	"github.com/ligato/cn-infra/logging/logrus"
@@ -42,13 +43,16 @@ import (
 	"github.com/ligato/sfc-controller/controller/extentitydriver"
 	"github.com/ligato/sfc-controller/controller/model/controller"
 	"github.com/ligato/sfc-controller/controller/utils"
+	"github.com/ligato/sfc-controller/controller/utils/idalloc"
 	"github.com/ligato/sfc-controller/controller/utils/ipam"
 	"github.com/ligato/vpp-agent/clientv1/linux"
 	"github.com/ligato/vpp-agent/clientv1/linux/remoteclient"
+	vppAcl "github.com/ligato/vpp-agent/plugins/defaultplugins/common/model/acl"
 	"github.com/ligato/vpp-agent/plugins/defaultplugins/common/model/interfaces"
 	"github.com/ligato/vpp-agent/plugins/defaultplugins/common/model/l2"
 	"github.com/ligato/vpp-agent/plugins/defaultplugins/common/model/l3"
 	linuxIntf "github.com/ligato/vpp-agent/plugins/linuxplugin/ifplugin/model/interfaces"
+	linuxL3 "github.com/ligato/vpp-agent/plugins/linuxplugin/l3plugin/model/l3"
 )
 
 var (
@@ -59,27 +63,22 @@ type sfcCtlrL2CNPDriver struct {
 	dbFactory           func(string) keyval.ProtoBroker
 	db                  keyval.ProtoBroker
 	name                string
+	instance            string
 	l2CNPEntityCache    l2CNPEntityCacheType
 	l2CNPStateCache     l2CNPStateCacheType
 	reconcileBefore     reconcileCacheType
 	reconcileAfter      reconcileCacheType
 	reconcileInProgress bool
-	seq                 sequencer
-}
-
-// sequencer groups all sequences used by L2 driver.
-// (instead of using global variables that caused
-// problems while running automated tests)
-type sequencer struct {
-	VLanID        uint32
-	MemIfID       uint32
-	MacInstanceID uint32
-	VethID        uint32
+	putIfNotExists      func(key string, value []byte) (bool, error)
+	ids                 *idalloc.Allocator
+	macClaims           macClaimStore
+	ipam                *ipam.IPAM
 }
 
 type sfcInterfaceAddressStateType struct {
-	ipAddress  string
-	macAddress string
+	ipAddress   string
+	macAddress  string
+	ipv6Address string
 }
 
 type heToEEStateType struct {
@@ -100,17 +99,22 @@ type heStateType struct {
 }
 
 type l2CNPStateCacheType struct {
-	HEToEEs   map[string]map[string]*heToEEStateType
-	HEToHEs   map[string]map[string]*heToHEStateType
-	SFCToHEs  map[string]map[string]*heStateType
-	HE        map[string]*heStateType
-	SFCIFAddr map[string]sfcInterfaceAddressStateType
+	HEToEEs     map[string]map[string]*heToEEStateType
+	HEToHEs     map[string]map[string]*heToHEStateType
+	SFCToHEs    map[string]map[string]*heStateType
+	TenantToHEs map[string]map[string]*heStateType
+	HE          map[string]*heStateType
+	SFCIFAddr   map[string]sfcInterfaceAddressStateType
+	MacAddrs    map[string]string // rendered mac -> owner of the interface it is on
+	IfNames     map[string]string // host/interface name -> owner of the interface
+	SFCIfNames  map[string]InterfaceName
 }
 
 type l2CNPEntityCacheType struct {
 	EEs      map[string]controller.ExternalEntity
 	HEs      map[string]controller.HostEntity
 	SFCs     map[string]controller.SfcEntity
+	Tenants  map[string]controller.Tenant
 	SysParms controller.SystemParameters
 }
 
@@ -125,16 +129,25 @@ func NewRemoteClientTxn(microserviceLabel string, dbFactory func(string) keyval.
 
 // NewSfcCtlrL2CNPDriver creates new driver/mode for Native SFC Controller L2 Container Networking Policy
 // <name> of the driver/plugin
+// <instance> the instance id of the controller, the ids it claims are recorded as claimed by it
 // <dbFactory> returns new instance of DataBroker for accessing key-val DB (ETCD)
-func NewSfcCtlrL2CNPDriver(name string, dbFactory func(string) keyval.ProtoBroker) *sfcCtlrL2CNPDriver {
+// <putIfNotExists> atomically puts a key-val if the key does not exist, the ids are claimed with it
+func NewSfcCtlrL2CNPDriver(name string, instance string, dbFactory func(string) keyval.ProtoBroker,
+	putIfNotExists func(key string, value []byte) (bool, error)) *sfcCtlrL2CNPDriver {
 
 	cnpd := &sfcCtlrL2CNPDriver{}
 	cnpd.name = "Sfc Controller L2 Plugin: " + name
+	cnpd.instance = instance
 	cnpd.dbFactory = dbFactory
 	cnpd.db = dbFactory(keyval.Root)
+	cnpd.putIfNotExists = putIfNotExists
+	cnpd.ids = idalloc.New(&idClaimStore{cnpd: cnpd}, instance)
+	cnpd.macClaims = &etcdMacClaimStore{cnpd: cnpd}
+	cnpd.ipam = ipam.NewIPAM()
 
 	cnpd.initL2CNPCache()
 	cnpd.initReconcileCache()
+	cnpd.initIDRanges()
 
 	return cnpd
 }
@@ -143,12 +156,17 @@ func (cnpd *sfcCtlrL2CNPDriver) initL2CNPCache() {
 	cnpd.l2CNPStateCache.HEToEEs = make(map[string]map[string]*heToEEStateType)
 	cnpd.l2CNPStateCache.HEToHEs = make(map[string]map[string]*heToHEStateType)
 	cnpd.l2CNPStateCache.SFCToHEs = make(map[string]map[string]*heStateType)
+	cnpd.l2CNPStateCache.TenantToHEs = make(map[string]map[string]*heStateType)
 	cnpd.l2CNPStateCache.HE = make(map[string]*heStateType)
 	cnpd.l2CNPStateCache.SFCIFAddr = make(map[string]sfcInterfaceAddressStateType)
+	cnpd.l2CNPStateCache.MacAddrs = make(map[string]string)
+	cnpd.l2CNPStateCache.IfNames = make(map[string]string)
+	cnpd.l2CNPStateCache.SFCIfNames = make(map[string]InterfaceName)
 
 	cnpd.l2CNPEntityCache.EEs = make(map[string]controller.ExternalEntity)
 	cnpd.l2CNPEntityCache.HEs = make(map[string]controller.HostEntity)
 	cnpd.l2CNPEntityCache.SFCs = make(map[string]controller.SfcEntity)
+	cnpd.l2CNPEntityCache.Tenants = make(map[string]controller.Tenant)
 }
 
 // Perform plugin specific initializations
@@ -169,11 +187,33 @@ func (cnpd *sfcCtlrL2CNPDriver) GetName() string {
 // SetSystemParameters caches the current settings for the system
 func (cnpd *sfcCtlrL2CNPDriver) SetSystemParameters(sp *controller.SystemParameters) error {
 	cnpd.l2CNPEntityCache.SysParms = *sp
-	if cnpd.seq.VLanID == 0 { // only init if this is the first time being set
-		cnpd.seq.VLanID = cnpd.l2CNPEntityCache.SysParms.StartingVlanId - 1
-		log.Infof("SetSystemParameters: setting starting valnId: ", cnpd.seq.VLanID)
+	// the tenant vnis are below the starting vlan id, the global vnis start at it
+	if startingVlanID := cnpd.l2CNPEntityCache.SysParms.StartingVlanId; startingVlanID != 0 {
+		if err := cnpd.ids.DefineRange(idRangeVni, startingVlanID, maxVni); err != nil {
+			log.Errorf("SetSystemParameters: %s", err)
+			return err
+		}
+		cnpd.claimRecordIDs(idRangeVni, startingVlanID, maxVni)
+		log.Infof("SetSystemParameters: setting starting valnId: %d", startingVlanID)
+	}
+	if err := cnpd.ids.DefineRange(idRangeMac, 1, cnpd.maxMacID()); err != nil {
+		log.Errorf("SetSystemParameters: %s", err)
+		return err
+	}
+	cnpd.claimRecordIDs(idRangeMac, 1, cnpd.maxMacID())
+	// the reserved ranges, gateway and strategy of the named pools apply to the pool's prefix of every tenant
+	for _, pool := range sp.GetIpamPools() {
+		cfg := ipam.SubnetConfig{
+			ReservedRanges: pool.ReservedRanges,
+			Gateway:        pool.Gateway,
+			Sequential:     pool.Strategy == controller.IpamStrategy_IPAM_STRATEGY_SEQUENTIAL,
+		}
+		if err := cnpd.ipam.ConfigureSubnet(pool.Prefix, cfg); err != nil {
+			log.Errorf("SetSystemParameters: ipam pool '%s': %s", pool.Name, err)
+			return err
+		}
 	}
-	log.Infof("SetSystemParameters: SP", sp)
+	log.Infof("SetSystemParameters: SP", utils.RedactSystemParameters(sp))
 	return nil
 }
 
@@ -219,7 +259,7 @@ func (cnpd *sfcCtlrL2CNPDriver) WireHostEntityToDestinationHostEntity(sh *contro
 // Perform CNP specific wiring for "connecting" an external router to a host server, called from
 // WireHostEntityToExternalEntity after host is wired to ee
 func (cnpd *sfcCtlrL2CNPDriver) wireExternalEntityToHostEntity(ee *controller.ExternalEntity,
-	he *controller.HostEntity) error {
+	he *controller.HostEntity, stateName string, bdID uint32) error {
 
 	log.Infof("wireExternalEntityToHostEntity: he", he)
 	log.Infof("wireExternalEntityToHostEntity: ee", ee)
@@ -231,7 +271,7 @@ func (cnpd *sfcCtlrL2CNPDriver) wireExternalEntityToHostEntity(ee *controller.Ex
 	}
 
 	// now ensure this HE has not yet been wired to the EE, if it has then wire the EE to the HE
-	heToEEState, exists := heToEEMap[ee.Name]
+	heToEEState, exists := heToEEMap[stateName]
 	if !exists {
 		return nil
 	}
@@ -262,7 +302,12 @@ func (cnpd *sfcCtlrL2CNPDriver) wireExternalEntityToHostEntity(ee *controller.Ex
 
 	// configure static route from this external router to the host
 	description := "IF_STATIC_ROUTE_E2H_" + he.Name
-	sr, err := cnpd.createStaticRoute(0, ee.Name, description, he.VxlanTunnelIpv4, he.EthIpv4, ee.HostInterface.IfName,
+	dstAddr, nextHopAddr, err := e2hRoute(ee, he)
+	if err != nil {
+		log.Errorf("wireExternalEntityToHostEntity: %s", err)
+		return err
+	}
+	sr, err := cnpd.createStaticRoute(0, ee.Name, description, dstAddr, nextHopAddr, ee.HostInterface.IfName,
 		cnpd.l2CNPEntityCache.SysParms.DefaultStaticRouteWeight, cnpd.l2CNPEntityCache.SysParms.DefaultStaticRoutePreference)
 	if err != nil {
 		log.Errorf("wireExternalEntityToHostEntity: error creating static route i/f: '%s'", description)
@@ -274,7 +319,7 @@ func (cnpd *sfcCtlrL2CNPDriver) wireExternalEntityToHostEntity(ee *controller.Ex
 
 	// call the external entity api to queue a msg so that the external router config will be sent to the router
 	// this will be replace perhaps by a watcher in the ext-ent driver
-	extentitydriver.SfcCtlrL2WireExternalEntityToHostEntity(*ee, *he, tmpVlanid, sr)
+	extentitydriver.SfcCtlrL2WireExternalEntityToHostEntity(*ee, *he, tmpVlanid, bdID, sr)
 	return nil
 }
 
@@ -341,7 +386,8 @@ func (cnpd *sfcCtlrL2CNPDriver) WireInternalsForHostEntity(he *controller.HostEn
 
 	// configure the nic/ethernet
 	if he.EthIfName != "" {
-		if err := cnpd.createEthernet(he.Name, he.EthIfName, he.EthIpv4, "", he.EthIpv6, mtu, he.RxMode); err != nil {
+		if err := cnpd.createEthernet(he.Name, he.EthIfName, utils.FormatLabels(he.Labels), he.EthIpv4, "", he.EthIpv6, mtu, he.RxMode,
+			he.EthVrfId, vswitchUnnumbered(he.EthUnnumbered, he.Name)); err != nil {
 			log.Errorf("WireInternalsForHostEntity: error creating ethernet i/f: '%s'", he.EthIfName)
 			return err
 		}
@@ -354,25 +400,37 @@ func (cnpd *sfcCtlrL2CNPDriver) WireInternalsForHostEntity(he *controller.HostEn
 
 		var loopbackMacAddress string
 
+		cnpd.releaseMacAddresses(heMacOwner(he.Name))
+
 		if he.LoopbackMacAddr == "" { // if not supplied, generate one
-			heID, _ = cnpd.DatastoreHEIDsRetrieve(he.Name)
-			if heID == nil || heID.LoopbackMacAddrId == 0 {
-				cnpd.seq.MacInstanceID++
-				loopbackMacAddress = formatMacAddress(cnpd.seq.MacInstanceID)
-				loopbackMacAddrID = cnpd.seq.MacInstanceID
+			var err error
+			if cnpd.hashMacScheme() {
+				loopbackMacAddress, err = cnpd.reserveHashMacAddress(heMacOwner(he.Name), heMacOwner(he.Name))
 			} else {
-				loopbackMacAddress = formatMacAddress(heID.LoopbackMacAddrId)
-				loopbackMacAddrID = heID.LoopbackMacAddrId
+				heID, _ = cnpd.DatastoreHEIDsRetrieve(he.Name)
+				if heID != nil {
+					loopbackMacAddrID = heID.LoopbackMacAddrId
+				}
+				loopbackMacAddress, loopbackMacAddrID, err = cnpd.allocateMacAddress("", l2driver.HEIDsNameKey(he.Name),
+					loopbackMacAddrID, heMacOwner(he.Name))
+			}
+			if err != nil {
+				log.Errorf("WireInternalsForHostEntity: %s", err)
+				return err
 			}
 		} else {
 			loopbackMacAddress = he.LoopbackMacAddr
+			if err := cnpd.reserveMacAddress(loopbackMacAddress, heMacOwner(he.Name)); err != nil {
+				log.Errorf("WireInternalsForHostEntity: %s", err)
+				return err
+			}
 		}
 
 		mtu := cnpd.getMtu(he.Mtu)
 
 		// configure loopback interface
-		loopIfName := "IF_LOOPBACK_H_" + he.Name
-		if err := cnpd.createLoopback(he.Name, loopIfName, loopbackMacAddress, he.LoopbackIpv4, he.LoopbackIpv6, mtu,
+		loopIfName := heLoopbackIfName(he.Name)
+		if err := cnpd.createLoopback(he.Name, loopIfName, utils.FormatLabels(he.Labels), loopbackMacAddress, he.LoopbackIpv4, he.LoopbackIpv6, mtu,
 			he.RxMode); err != nil {
 			log.Errorf("WireInternalsForHostEntity: error creating loopback i/f: '%s'", loopIfName)
 			return err
@@ -419,6 +477,11 @@ func (cnpd *sfcCtlrL2CNPDriver) WireInternalsForExternalEntity(ee *controller.Ex
 func (cnpd *sfcCtlrL2CNPDriver) WireSfcEntity(sfc *controller.SfcEntity) error {
 
 	var err error
+
+	cnpd.releaseStaleSfcAddresses(sfc)
+	cnpd.releaseMacAddresses(sfcMacOwnerPrefix(sfc.Name))
+	cnpd.releaseSfcIfNames(sfc.Name + "/")
+
 	// the semantic difference between a north_south vs an east-west sfc entity, it what is the bridge that
 	// the memIf/afPkt if's will be associated.
 	switch sfc.Type {
@@ -448,11 +511,28 @@ func (cnpd *sfcCtlrL2CNPDriver) WireSfcEntity(sfc *controller.SfcEntity) error {
 		cnpd.l2CNPEntityCache.SFCs[sfc.Name] = *sfc
 		err = cnpd.wireSfcEastWestElements(sfc)
 
+	case controller.SfcType_SFC_EW_VETH:
+		// east/west veth type, each container pair is wired directly via a veth
+		cnpd.l2CNPEntityCache.SFCs[sfc.Name] = *sfc
+		err = cnpd.wireSfcEastWestVEthElements(sfc)
+
+	case controller.SfcType_SFC_EW_VRF_FIB:
+		// east/west vrf type, memIfs/cntrs are routed via static routes in a vrf on the host
+		cnpd.l2CNPEntityCache.SFCs[sfc.Name] = *sfc
+		err = cnpd.wireSfcEastWestVRFElements(sfc)
+
 	default:
 		err = fmt.Errorf("WireSfcEntity: unknown entity type: '%s'", sfc.Type)
 		log.Error(err.Error())
 	}
 
+	if err == nil {
+		err = cnpd.createSfcAcls(sfc)
+	}
+	if err == nil {
+		err = cnpd.createSfcClassifiers(sfc)
+	}
+
 	return err
 }
 
@@ -462,87 +542,65 @@ func (cnpd *sfcCtlrL2CNPDriver) wireSfcNorthSouthVXLANElements(sfc *controller.S
 	var err error
 	var bd *l2.BridgeDomains_BridgeDomain
 
-	eeCount := 0
-	eeName := ""
-	var eeSfcElement *controller.SfcEntity_SfcElement
+	// the ees and dest hosts the chain is attached to, see createVxLANsAndBridgeToPeers
+	var peers []*controller.SfcEntity_SfcElement
 
-	dhCount := 0
-	dhName := ""
-	var dhSfcElement *controller.SfcEntity_SfcElement
-
-	// find the external entity and ensure there is only one allowed
 	for i, sfcEntityElement := range sfc.GetElements() {
 
-		log.Infof("wireSfcNorthSouthVXLANElements: sfc entity element[%d]: ", i, sfcEntityElement)
+		log.Infof("wireSfcNorthSouthVXLANElements: sfc entity element[%d]: ", i, utils.RedactSfcElement(sfcEntityElement))
 
 		switch sfcEntityElement.Type {
 		case controller.SfcElementType_EXTERNAL_ENTITY:
-			eeCount++
-			if eeCount > 1 {
-				err := fmt.Errorf("wireSfcNorthSouthVXLANElements: only one ee allowed for n/s sfc: '%s'",
-					sfc.Name)
-				log.Error(err.Error())
-				return err
-			}
-
-			eeName = sfcEntityElement.Container
 			if _, exists := cnpd.l2CNPEntityCache.EEs[sfcEntityElement.Container]; !exists {
 				err := fmt.Errorf("wireSfcNorthSouthVXLANElements: ee not found: '%s' for n/s sfc: '%s'",
-					eeName, sfc.Name)
+					sfcEntityElement.Container, sfc.Name)
 				log.Error(err.Error())
 				return err
 			}
-			eeSfcElement = sfcEntityElement
+			peers = append(peers, sfcEntityElement)
 
 		case controller.SfcElementType_HOST_ENTITY:
-			dhCount++
-			if dhCount > 1 {
-				err := fmt.Errorf("wireSfcNorthSouthVXLANElements: only one dest host allowed for n/s sfc: '%s'",
-					sfc.Name)
-				log.Error(err.Error())
-				return err
-			}
-
-			dhName = sfcEntityElement.Container
 			if _, exists := cnpd.l2CNPEntityCache.HEs[sfcEntityElement.Container]; !exists {
 				err := fmt.Errorf("wireSfcNorthSouthVXLANElements: dest host not found: '%s' for n/s sfc: '%s'",
-					dhName, sfc.Name)
+					sfcEntityElement.Container, sfc.Name)
 				log.Error(err.Error())
 				return err
 			}
-			dhSfcElement = sfcEntityElement
+			peers = append(peers, sfcEntityElement)
 		}
 	}
 
-	if eeCount == 0 && dhCount == 0 {
+	if len(peers) == 0 {
 		err := fmt.Errorf("wireSfcNorthSouthVXLANElements: NO ee or dh specified for n/s sfc: '%s'", sfc.Name)
 		log.Error(err.Error())
 		return err
 	}
 
-	// now wire each container to the bridge wired from the host to the ee
+	// the tunnels to all the peers go into one bridge on the host so they must be dedicated to this sfc
+	if len(peers) > 1 && !sfc.DedicatedVni {
+		err := fmt.Errorf("wireSfcNorthSouthVXLANElements: more than one ee/dh requires a dedicated vni for n/s sfc: '%s'",
+			sfc.Name)
+		log.Error(err.Error())
+		return err
+	}
+
+	// now wire each container to the bridge wired from the host to the ees/dest hosts
 	for i, sfcEntityElement := range sfc.GetElements() {
 
-		log.Infof("wireSfcNorthSouthVXLANElements: sfc entity element[%d]: ", i, sfcEntityElement)
+		log.Infof("wireSfcNorthSouthVXLANElements: sfc entity element[%d]: ", i, utils.RedactSfcElement(sfcEntityElement))
 
 		switch sfcEntityElement.Type {
 
-		case controller.SfcElementType_VPP_CONTAINER_AFP:
+		case controller.SfcElementType_VPP_CONTAINER_AFP, controller.SfcElementType_VPP_CONTAINER_TAP:
 			fallthrough
 		case controller.SfcElementType_NON_VPP_CONTAINER_AFP:
 
-			if eeCount != 0 {
-				bd, err = cnpd.createVxLANAndBridgeToExtEntity(sfc, sfcEntityElement.EtcdVppSwitchKey, eeName,
-					eeSfcElement.VlanId)
-			} else {
-				bd, err = cnpd.createVxLANAndBridgeToDestHost(sfc, sfcEntityElement.EtcdVppSwitchKey, dhName,
-					dhSfcElement.VlanId)
-			}
+			bd, err = cnpd.createVxLANsAndBridgeToPeers(sfc, sfcEntityElement.EtcdVppSwitchKey, peers)
 			if err != nil {
 				return err
 			}
 
-			if _, err := cnpd.createAFPacketVEthPairAndAddToBridge(sfc, bd, sfcEntityElement); err != nil {
+			if _, err := cnpd.createVEthOrTapPairAndAddToBridge(sfc, bd, sfcEntityElement); err != nil {
 				log.Errorf("wireSfcNorthSouthVXLANElements: error creating memIf pair: sfc: '%s', Container: '%s'",
 					sfc.Name, sfcEntityElement.Container)
 				return err
@@ -552,13 +610,7 @@ func (cnpd *sfcCtlrL2CNPDriver) wireSfcNorthSouthVXLANElements(sfc *controller.S
 			fallthrough
 		case controller.SfcElementType_NON_VPP_CONTAINER_MEMIF:
 
-			if eeCount != 0 {
-				bd, err = cnpd.createVxLANAndBridgeToExtEntity(sfc, sfcEntityElement.EtcdVppSwitchKey, eeName,
-					eeSfcElement.VlanId)
-			} else {
-				bd, err = cnpd.createVxLANAndBridgeToDestHost(sfc, sfcEntityElement.EtcdVppSwitchKey, dhName,
-					dhSfcElement.VlanId)
-			}
+			bd, err = cnpd.createVxLANsAndBridgeToPeers(sfc, sfcEntityElement.EtcdVppSwitchKey, peers)
 			if err != nil {
 				return err
 			}
@@ -575,22 +627,118 @@ func (cnpd *sfcCtlrL2CNPDriver) wireSfcNorthSouthVXLANElements(sfc *controller.S
 	return nil
 }
 
-// createVxLANAndBridgeToExtEntity and ensure vxlan and bridge are created if not already done yet
+// createVxLANsAndBridgeToPeers ensures a vxlan tunnel is created from the host to each of the ees/dest hosts of
+// the sfc.  In active/standby redundancy, only the tunnel of the primary peer is in the bridge, the tunnels to the
+// standby peers are created but kept out of it, so traffic only goes to the primary.  The primary is the peer with
+// the lowest route preference, then the highest route weight, then the first one, the peers without them use the
+// system default static route preference and weight.  Failing over is re-posting the sfc with other preferences,
+// the new primary's tunnel then replaces the former one in the bridge.  In active/active redundancy, the tunnels
+// of all the peers are in the bridge, in one split horizon group so the peers do not reach each other through it.
+func (cnpd *sfcCtlrL2CNPDriver) createVxLANsAndBridgeToPeers(sfc *controller.SfcEntity, hostName string,
+	peers []*controller.SfcEntity_SfcElement) (*l2.BridgeDomains_BridgeDomain, error) {
+
+	primary := cnpd.primaryPeer(peers)
+
+	// the primary peer is wired first so the bridge is its bridge
+	ordered := append([]*controller.SfcEntity_SfcElement{peers[primary]}, peers[:primary]...)
+	ordered = append(ordered, peers[primary+1:]...)
+
+	var bd *l2.BridgeDomains_BridgeDomain
+	var activeIfs []*l2.BridgeDomains_BridgeDomain_Interfaces
+	var standbyIfNames []string
+
+	for _, peer := range ordered {
+
+		var peerBD *l2.BridgeDomains_BridgeDomain
+		var vlanIf *interfaces.Interfaces_Interface
+		var err error
+		if peer.Type == controller.SfcElementType_EXTERNAL_ENTITY {
+			peerBD, vlanIf, err = cnpd.createVxLANAndBridgeToExtEntity(sfc, hostName, peer.Container, peer.VlanId, bd)
+		} else {
+			peerBD, vlanIf, err = cnpd.createVxLANAndBridgeToDestHost(sfc, hostName, peer.Container, peer.VlanId, bd)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if bd == nil {
+			bd = peerBD
+		}
+		if len(activeIfs) == 0 || sfc.PeerRedundancy == controller.PeerRedundancyType_PEER_REDUNDANCY_ACTIVE_ACTIVE {
+			activeIfs = append(activeIfs, &l2.BridgeDomains_BridgeDomain_Interfaces{Name: vlanIf.Name})
+		} else {
+			standbyIfNames = append(standbyIfNames, vlanIf.Name)
+		}
+	}
+
+	if len(ordered) > 1 {
+		if sfc.PeerRedundancy == controller.PeerRedundancyType_PEER_REDUNDANCY_ACTIVE_ACTIVE {
+			for _, activeIf := range activeIfs {
+				activeIf.SplitHorizonGroup = 1
+			}
+		}
+		if err := cnpd.bridgedDomainSetActiveIfs(hostName, bd, activeIfs, standbyIfNames); err != nil {
+			log.Errorf("createVxLANsAndBridgeToPeers: error setting active vxlans in BD: '%s'", bd.Name)
+			return nil, err
+		}
+	}
+
+	return bd, nil
+}
+
+// primaryPeer returns the index of the peer with the lowest route preference, then the highest route weight
+func (cnpd *sfcCtlrL2CNPDriver) primaryPeer(peers []*controller.SfcEntity_SfcElement) int {
+
+	sysParms := cnpd.l2CNPEntityCache.SysParms
+
+	routeParms := func(peer *controller.SfcEntity_SfcElement) (uint32, uint32) {
+		preference, weight := peer.RoutePreference, peer.RouteWeight
+		if preference == 0 {
+			preference = sysParms.DefaultStaticRoutePreference
+		}
+		if weight == 0 {
+			weight = sysParms.DefaultStaticRouteWeight
+		}
+		return preference, weight
+	}
+
+	primary := 0
+	primaryPreference, primaryWeight := routeParms(peers[0])
+	for i, peer := range peers[1:] {
+		preference, weight := routeParms(peer)
+		if preference < primaryPreference || (preference == primaryPreference && weight > primaryWeight) {
+			primary = i + 1
+			primaryPreference, primaryWeight = preference, weight
+		}
+	}
+
+	return primary
+}
+
+// createVxLANAndBridgeToExtEntity and ensure vxlan and bridge are created if not already done yet, if the bridge
+// of the primary peer is provided, this ee is not the primary and its vxlan is bridged by createVxLANsAndBridgeToPeers
 func (cnpd *sfcCtlrL2CNPDriver) createVxLANAndBridgeToExtEntity(sfc *controller.SfcEntity,
-	hostName string, eeName string, vlanID uint32) (*l2.BridgeDomains_BridgeDomain, error) {
+	hostName string, eeName string, vlanID uint32,
+	primaryBD *l2.BridgeDomains_BridgeDomain) (*l2.BridgeDomains_BridgeDomain, *interfaces.Interfaces_Interface, error) {
 
 	// the container has which host it is assoc'ed with, get the ee bridge
 	heToEEMap, exists := cnpd.l2CNPStateCache.HEToEEs[hostName]
 	if !exists {
 		err := fmt.Errorf("createVxLANAndBridgeToExtEntity: host not found: '%s' for this sfc: '%s'",
 			hostName, sfc.Name)
-		return nil, err
+		return nil, nil, err
 	}
-	heToEEState, exists := heToEEMap[eeName]
-	if !exists {
+	if _, exists := heToEEMap[eeName]; !exists {
 		err := fmt.Errorf("createVxLANAndBridgeToExtEntity: host '%s' not wired to this ee: '%s' for this sfc: '%s'",
 			hostName, eeName, sfc.Name)
-		return nil, err
+		return nil, nil, err
+	}
+
+	// the sfcs of a tenant, or an sfc with a dedicated vni, get their own tunnel and bridge to the ee
+	stateName := tunnelScopedName(eeName, sfc)
+	heToEEState, exists := heToEEMap[stateName]
+	if !exists {
+		heToEEState = &heToEEStateType{}
+		heToEEMap[stateName] = heToEEState
 	}
 
 	if heToEEState.vlanIf == nil {
@@ -601,32 +749,41 @@ func (cnpd *sfcCtlrL2CNPDriver) createVxLANAndBridgeToExtEntity(sfc *controller.
 		ee := cnpd.l2CNPEntityCache.EEs[eeName]
 
 		// create the vxlan i'f before the BD
-		ifName := "IF_VXLAN_H2E_" + he.Name + "_" + ee.Name
+		ifName := "IF_VXLAN_H2E_" + he.Name + "_" + stateName
 
 		if vlanID == 0 {
-			he2eeID, _ := cnpd.DatastoreHE2EEIDsRetrieve(he.Name, ee.Name)
+			he2eeID, _ := cnpd.DatastoreHE2EEIDsRetrieve(he.Name, stateName)
 			if he2eeID == nil || he2eeID.VlanId == 0 {
-				cnpd.seq.VLanID++
-				vlanID = cnpd.seq.VLanID
+				var err error
+				if vlanID, err = cnpd.allocateVLanID(sfc.Tenant,
+					l2driver.HE2EEIDsNameKey(he.Name, stateName)); err != nil {
+					return nil, nil, err
+				}
 			} else {
 				vlanID = he2eeID.VlanId
 			}
 		}
-		vlanIf, err := cnpd.vxLanCreate(he.Name, ifName, vlanID, he.VxlanTunnelIpv4, ee.HostVxlan.SourceIpv4)
+		srcAddr, dstAddr, _, err := h2eUnderlay(&he, &ee)
+		if err != nil {
+			log.Errorf("createVxLANAndBridgeToExtEntity: %s", err)
+			return nil, nil, err
+		}
+		vlanIf, err := cnpd.vxLanCreate(he.Name, ifName, utils.FormatLabels(ee.Labels), vlanID, srcAddr, dstAddr)
 		if err != nil {
 			log.Errorf("createVxLANAndBridgeToExtEntity: error creating vxlan: '%s'", ifName)
-			return nil, err
+			return nil, nil, err
 		}
 
 		heToEEState.vlanIf = vlanIf
 
-		key, he2eeID, err := cnpd.DatastoreHE2EEIDsCreate(he.Name, ee.Name, vlanID)
+		key, he2eeID, err := cnpd.DatastoreHE2EEIDsCreate(he.Name, stateName, vlanID)
 		if err == nil && cnpd.reconcileInProgress {
 			cnpd.reconcileAfter.he2eeIDs[key] = *he2eeID
 		}
 	}
 
-	if heToEEState.l3Route == nil {
+	// the route to the ee is shared by all tunnels to the ee so it is kept in the non tenant state
+	if heToEEMap[eeName].l3Route == nil {
 
 		he := cnpd.l2CNPEntityCache.HEs[hostName]
 		ee := cnpd.l2CNPEntityCache.EEs[eeName]
@@ -634,16 +791,21 @@ func (cnpd *sfcCtlrL2CNPDriver) createVxLANAndBridgeToExtEntity(sfc *controller.
 		// configure static route from this host to the dest host
 		if he.CreateVxlanStaticRoute {
 			description := "IF_STATIC_ROUTE_H2E_" + ee.Name
-			sr, err := cnpd.createStaticRoute(0, he.Name, description, ee.HostVxlan.SourceIpv4, ee.HostInterface.Ipv4Addr,
+			_, dstAddr, nextHopAddr, err := h2eUnderlay(&he, &ee)
+			if err != nil {
+				log.Errorf("createVxLANAndBridgeToExtEntity: %s", err)
+				return nil, nil, err
+			}
+			sr, err := cnpd.createStaticRoute(0, he.Name, description, dstAddr, nextHopAddr,
 				he.EthIfName,
 				cnpd.l2CNPEntityCache.SysParms.DefaultStaticRouteWeight,
 				cnpd.l2CNPEntityCache.SysParms.DefaultStaticRoutePreference)
 			if err != nil {
 				log.Errorf("createVxLANAndBridgeToExtEntity: error creating static route i/f: '%s'", description)
-				return nil, err
+				return nil, nil, err
 			}
 
-			heToEEState.l3Route = sr
+			heToEEMap[eeName].l3Route = sr
 		}
 	}
 
@@ -654,7 +816,7 @@ func (cnpd *sfcCtlrL2CNPDriver) createVxLANAndBridgeToExtEntity(sfc *controller.
 		he := cnpd.l2CNPEntityCache.HEs[hostName]
 		ee := cnpd.l2CNPEntityCache.EEs[eeName]
 
-		bdName := "BD_H2E_" + he.Name + "_" + ee.Name
+		bdName := "BD_H2E_" + he.Name + "_" + stateName
 
 		ifs := make([]*l2.BridgeDomains_BridgeDomain_Interfaces, 1)
 		ifEntry := l2.BridgeDomains_BridgeDomain_Interfaces{
@@ -662,38 +824,57 @@ func (cnpd *sfcCtlrL2CNPDriver) createVxLANAndBridgeToExtEntity(sfc *controller.
 		}
 		ifs[0] = &ifEntry
 
-		// now create the bridge
-		bd, err := cnpd.bridgedDomainCreateWithIfs(he.Name, bdName, ifs, cnpd.l2CNPEntityCache.SysParms.DynamicBridgeParms)
-		if err != nil {
-			log.Errorf("createVxLANAndBridgeToExtEntity: error creating BD: '%s'", bd.Name)
-			return nil, err
+		if primaryBD != nil {
+			// not the primary ee of the sfc, its vxlan goes into the primary peer's bridge if it is active
+			heToEEState.bd = primaryBD
+		} else {
+			// now create the bridge
+			bd, err := cnpd.bridgedDomainCreateWithIfs(he.Name, bdName, ifs, cnpd.l2CNPEntityCache.SysParms.DynamicBridgeParms)
+			if err != nil {
+				log.Errorf("createVxLANAndBridgeToExtEntity: error creating BD: '%s'", bd.Name)
+				return nil, nil, err
+			}
+			heToEEState.bd = bd
 		}
 
-		heToEEState.bd = bd
-
 		// now we can wire the external entity to this host
-		cnpd.wireExternalEntityToHostEntity(&ee, &he)
+		// a dedicated or tenant vni is bridged into its own bridge on the ee instead of the shared host_bd
+		bdID := sfc.EeBdId
+		if bdID == 0 {
+			bdID = cnpd.l2CNPEntityCache.Tenants[sfc.Tenant].EeBdId
+		}
+		cnpd.wireExternalEntityToHostEntity(&ee, &he, stateName, bdID)
 	}
 
-	return heToEEState.bd, nil
+	return heToEEState.bd, heToEEState.vlanIf, nil
 }
 
-// createVxLANAndBridgeToDestHost and ensure vxlan and bridge are created if not already done yet
+// createVxLANAndBridgeToDestHost and ensure vxlan and bridge are created if not already done yet, if the bridge
+// of the primary peer is provided, this dest host is not the primary and its vxlan is bridged by
+// createVxLANsAndBridgeToPeers
 func (cnpd *sfcCtlrL2CNPDriver) createVxLANAndBridgeToDestHost(sfc *controller.SfcEntity,
-	shName string, dhName string, vlanID uint32) (*l2.BridgeDomains_BridgeDomain, error) {
+	shName string, dhName string, vlanID uint32,
+	primaryBD *l2.BridgeDomains_BridgeDomain) (*l2.BridgeDomains_BridgeDomain, *interfaces.Interfaces_Interface, error) {
 
 	// the container has which host it is assoc'ed with, get the dh bridge
 	heToHEMap, exists := cnpd.l2CNPStateCache.HEToHEs[shName]
 	if !exists {
 		err := fmt.Errorf("createVxLANAndBridgeToDestHost: host not found: '%s' for this sfc: '%s'",
 			shName, sfc.Name)
-		return nil, err
+		return nil, nil, err
 	}
-	heToHEState, exists := heToHEMap[dhName]
-	if !exists {
+	if _, exists := heToHEMap[dhName]; !exists {
 		err := fmt.Errorf("createVxLANAndBridgeToDestHost: host '%s' not wired to this ee: '%s' for this sfc: '%s'",
 			shName, dhName, sfc.Name)
-		return nil, err
+		return nil, nil, err
+	}
+
+	// the sfcs of a tenant, or an sfc with a dedicated vni, get their own tunnel and bridge to the dest host
+	stateName := tunnelScopedName(dhName, sfc)
+	heToHEState, exists := heToHEMap[stateName]
+	if !exists {
+		heToHEState = &heToHEStateType{}
+		heToHEMap[stateName] = heToHEState
 	}
 
 	if heToHEState.vlanIf == nil {
@@ -704,50 +885,43 @@ func (cnpd *sfcCtlrL2CNPDriver) createVxLANAndBridgeToDestHost(sfc *controller.S
 		dh := cnpd.l2CNPEntityCache.HEs[dhName]
 
 		// create the vxlan i'f before the BD
-		ifName := "IF_VXLAN_H2H_" + sh.Name + "_" + dh.Name
+		ifName := "IF_VXLAN_H2H_" + sh.Name + "_" + stateName
 
 		if vlanID == 0 {
-			he2eeID, _ := cnpd.DatastoreHE2EEIDsRetrieve(sh.Name, dh.Name)
+			he2eeID, _ := cnpd.DatastoreHE2EEIDsRetrieve(sh.Name, stateName)
 			if he2eeID == nil || he2eeID.VlanId == 0 {
-				cnpd.seq.VLanID++
-				vlanID = cnpd.seq.VLanID
+				var err error
+				if vlanID, err = cnpd.allocateVLanID(sfc.Tenant,
+					l2driver.HE2HEIDsNameKey(sh.Name, stateName)); err != nil {
+					return nil, nil, err
+				}
 			} else {
 				vlanID = he2eeID.VlanId
 			}
 		}
-		vlanIf, err := cnpd.vxLanCreate(sh.Name, ifName, vlanID, sh.VxlanTunnelIpv4, dh.VxlanTunnelIpv4)
+		srcAddr, dstAddr, _, err := h2hUnderlay(&sh, &dh)
+		if err != nil {
+			log.Errorf("createVxLANAndBridgeToDestHost: %s", err)
+			return nil, nil, err
+		}
+		vlanIf, err := cnpd.vxLanCreate(sh.Name, ifName, utils.FormatLabels(dh.Labels), vlanID, srcAddr, dstAddr)
 		if err != nil {
 			log.Errorf("createVxLANAndBridgeToDestHost: error creating vxlan: '%s'", ifName)
-			return nil, err
+			return nil, nil, err
 		}
 
 		heToHEState.vlanIf = vlanIf
 
-		key, sh2dhID, err := cnpd.DatastoreHE2HEIDsCreate(sh.Name, dh.Name, vlanID)
+		key, sh2dhID, err := cnpd.DatastoreHE2HEIDsCreate(sh.Name, stateName, vlanID)
 		if err == nil && cnpd.reconcileInProgress {
 			cnpd.reconcileAfter.he2heIDs[key] = *sh2dhID
 		}
 	}
 
-	if heToHEState.l3Route == nil {
-
-		sh := cnpd.l2CNPEntityCache.HEs[shName]
-		dh := cnpd.l2CNPEntityCache.HEs[dhName]
-
-		// configure static route from this host to the dest host
-		if sh.CreateVxlanStaticRoute {
-			description := "IF_STATIC_ROUTE_H2H_" + dh.Name
-			sr, err := cnpd.createStaticRoute(0, sh.Name, description, dh.VxlanTunnelIpv4, dh.EthIpv4,
-				sh.EthIfName,
-				cnpd.l2CNPEntityCache.SysParms.DefaultStaticRouteWeight,
-				cnpd.l2CNPEntityCache.SysParms.DefaultStaticRoutePreference)
-			if err != nil {
-				log.Errorf("createVxLANAndBridgeToDestHost: error creating static route i/f: '%s'", description)
-				return nil, err
-			}
-
-			heToHEState.l3Route = sr
-		}
+	// the route to the dest host is shared by all tunnels to the host so it is kept in the non tenant state
+	if err := cnpd.createStaticRouteToDestHost(heToHEMap, shName, dhName,
+		cnpd.l2CNPEntityCache.SysParms.DefaultStaticRoutePreference); err != nil {
+		return nil, nil, err
 	}
 
 	if heToHEState.bd == nil {
@@ -755,9 +929,8 @@ func (cnpd *sfcCtlrL2CNPDriver) createVxLANAndBridgeToDestHost(sfc *controller.S
 		// first time sfc is wired from this host to this external ee so create a bridge
 
 		sh := cnpd.l2CNPEntityCache.HEs[shName]
-		dh := cnpd.l2CNPEntityCache.HEs[dhName]
 
-		bdName := "BD_H2H_" + sh.Name + "_" + dh.Name
+		bdName := "BD_H2H_" + sh.Name + "_" + stateName
 
 		ifs := make([]*l2.BridgeDomains_BridgeDomain_Interfaces, 1)
 		ifEntry := l2.BridgeDomains_BridgeDomain_Interfaces{
@@ -765,17 +938,21 @@ func (cnpd *sfcCtlrL2CNPDriver) createVxLANAndBridgeToDestHost(sfc *controller.S
 		}
 		ifs[0] = &ifEntry
 
-		// now create the bridge
-		bd, err := cnpd.bridgedDomainCreateWithIfs(sh.Name, bdName, ifs, cnpd.l2CNPEntityCache.SysParms.DynamicBridgeParms)
-		if err != nil {
-			log.Errorf("createVxLANAndBridgeToDestHost: error creating BD: '%s'", bd.Name)
-			return nil, err
+		if primaryBD != nil {
+			// not the primary dest host of the sfc, its vxlan goes into the primary peer's bridge if it is active
+			heToHEState.bd = primaryBD
+		} else {
+			// now create the bridge
+			bd, err := cnpd.bridgedDomainCreateWithIfs(sh.Name, bdName, ifs, cnpd.l2CNPEntityCache.SysParms.DynamicBridgeParms)
+			if err != nil {
+				log.Errorf("createVxLANAndBridgeToDestHost: error creating BD: '%s'", bd.Name)
+				return nil, nil, err
+			}
+			heToHEState.bd = bd
 		}
-
-		heToHEState.bd = bd
 	}
 
-	return heToHEState.bd, nil
+	return heToHEState.bd, heToHEState.vlanIf, nil
 }
 
 // north/south NIC type, memIfs/cntrs connect to physical NIC
@@ -790,7 +967,7 @@ func (cnpd *sfcCtlrL2CNPDriver) wireSfcNorthSouthNICElements(sfc *controller.Sfc
 	// find the host entity and ensure there is only one allowed
 	for i, sfcEntityElement := range sfc.GetElements() {
 
-		log.Infof("wireSfcNorthSouthNICElements: sfc entity element[%d]: ", i, sfcEntityElement)
+		log.Infof("wireSfcNorthSouthNICElements: sfc entity element[%d]: ", i, utils.RedactSfcElement(sfcEntityElement))
 
 		switch sfcEntityElement.Type {
 		case controller.SfcElementType_HOST_ENTITY:
@@ -814,7 +991,20 @@ func (cnpd *sfcCtlrL2CNPDriver) wireSfcNorthSouthNICElements(sfc *controller.Sfc
 
 	mtu := cnpd.getMtu(he.Mtu)
 	// physical NIC
-	if err := cnpd.createEthernet(he.Container, he.PortLabel, "", he.MacAddr, he.Ipv6Addr, mtu, he.RxMode); err != nil {
+	if he.MacAddr != "" {
+		if err := cnpd.reserveMacAddress(he.MacAddr, sfcMacOwner(sfc.Name, he.Container, he.PortLabel)); err != nil {
+			log.Errorf("wireSfcNorthSouthNICElements: %s", err)
+			return err
+		}
+	}
+	var heVrfID uint32
+	if sfc.Type == controller.SfcType_SFC_NS_NIC_VRF {
+		if heVrfID, err = cnpd.elementVrfID(sfc, he); err != nil {
+			return err
+		}
+	}
+	if err := cnpd.createEthernet(he.Container, he.PortLabel, utils.FormatLabels(sfc.Labels), "", he.MacAddr, he.Ipv6Addr, mtu, he.RxMode,
+		heVrfID, vswitchUnnumbered(he.Unnumbered, he.Container)); err != nil {
 		log.Errorf("wireSfcNorthSouthNICElements: error creating ethernet i/f: '%s'", he.PortLabel)
 		return err
 	}
@@ -853,7 +1043,8 @@ func (cnpd *sfcCtlrL2CNPDriver) wireSfcNorthSouthNICElements(sfc *controller.Sfc
 
 	if sfc.Type == controller.SfcType_SFC_NS_NIC_VRF {
 
-		err := cnpd.createVRFEntries(he.Container, he, he.PortLabel, "VRF_"+sfc.Name+"_"+he.Container+"_"+he.PortLabel)
+		err := cnpd.createVRFEntries(sfc, he.Container, he, he.PortLabel, "VRF_"+sfc.Name+"_"+he.Container+"_"+he.PortLabel,
+			heVrfID)
 		if err != nil {
 			log.Errorf("wireSfcNorthSouthNICElements: error creating processing vrf entries i/f: %s/'%s'", he.PortLabel, he)
 			return err
@@ -863,17 +1054,17 @@ func (cnpd *sfcCtlrL2CNPDriver) wireSfcNorthSouthNICElements(sfc *controller.Sfc
 	// now wire each container to the bridge on the he
 	for i, sfcEntityElement := range sfc.GetElements() {
 
-		log.Infof("wireSfcNorthSouthNICElements: sfc entity element[%d]: ", i, sfcEntityElement)
+		log.Infof("wireSfcNorthSouthNICElements: sfc entity element[%d]: ", i, utils.RedactSfcElement(sfcEntityElement))
 
 		switch sfcEntityElement.Type {
 
-		case controller.SfcElementType_VPP_CONTAINER_AFP:
+		case controller.SfcElementType_VPP_CONTAINER_AFP, controller.SfcElementType_VPP_CONTAINER_TAP:
 			fallthrough
 		case controller.SfcElementType_NON_VPP_CONTAINER_AFP:
 
 			if sfc.Type == controller.SfcType_SFC_NS_NIC_BD {
 				// veth pair
-				if ifName, err = cnpd.createAFPacketVEthPairAndAddToBridge(sfc, bd, sfcEntityElement); err != nil {
+				if ifName, err = cnpd.createVEthOrTapPairAndAddToBridge(sfc, bd, sfcEntityElement); err != nil {
 					log.Errorf("wireSfcNorthSouthNICElements: error creating veth pair: sfc: '%s', Container: '%s'",
 						sfc.Name, sfcEntityElement.Container)
 					return err
@@ -893,23 +1084,27 @@ func (cnpd *sfcCtlrL2CNPDriver) wireSfcNorthSouthNICElements(sfc *controller.Sfc
 
 			} else if sfc.Type == controller.SfcType_SFC_NS_NIC_VRF {
 				// vrf
-				afIfName, err := cnpd.createAFPacketVEthPair(sfc, sfcEntityElement)
+				vrfID, err := cnpd.elementVrfID(sfc, sfcEntityElement)
+				if err != nil {
+					return err
+				}
+				afIfName, err := cnpd.createVEthOrTapPair(sfc, sfcEntityElement, vrfID)
 				if err != nil {
 					log.Errorf("wireSfcNorthSouthNICElements: error creating veth pair: sfc: '%s', Container: '%s'",
 						sfc.Name, sfcEntityElement.Container)
 					return err
 				}
 
-				err = cnpd.createVRFEntries(sfcEntityElement.EtcdVppSwitchKey, sfcEntityElement, afIfName,
-					"VRF_"+sfc.Name+"_"+sfcEntityElement.Container+"_"+sfcEntityElement.PortLabel)
+				err = cnpd.createVRFEntries(sfc, sfcEntityElement.EtcdVppSwitchKey, sfcEntityElement, afIfName,
+					"VRF_"+sfc.Name+"_"+sfcEntityElement.Container+"_"+sfcEntityElement.PortLabel, vrfID)
 				if err != nil {
-					log.Errorf("wireSfcNorthSouthNICElements: error creating processing vrf entries i/f: %s/'%s'", afIfName, sfcEntityElement)
+					log.Errorf("wireSfcNorthSouthNICElements: error creating processing vrf entries i/f: %s/'%s'", afIfName, utils.RedactSfcElement(sfcEntityElement))
 					return err
 				}
 
 			} else {
 				// l2xconnect -based wiring
-				afIfName, err := cnpd.createAFPacketVEthPair(sfc, sfcEntityElement)
+				afIfName, err := cnpd.createVEthOrTapPair(sfc, sfcEntityElement, 0)
 				if err != nil {
 					log.Errorf("wireSfcNorthSouthNICElements: error creating veth pair: sfc: '%s', Container: '%s'",
 						sfc.Name, sfcEntityElement.Container)
@@ -948,24 +1143,28 @@ func (cnpd *sfcCtlrL2CNPDriver) wireSfcNorthSouthNICElements(sfc *controller.Sfc
 
 			} else if sfc.Type == controller.SfcType_SFC_NS_NIC_VRF {
 				// vrf
-				afIfName, err := cnpd.createAFPacketVEthPair(sfc, sfcEntityElement)
+				vrfID, err := cnpd.elementVrfID(sfc, sfcEntityElement)
+				if err != nil {
+					return err
+				}
+				afIfName, err := cnpd.createVEthOrTapPair(sfc, sfcEntityElement, vrfID)
 				if err != nil {
 					log.Errorf("wireSfcNorthSouthNICElements: error creating veth pair: sfc: '%s', Container: '%s'",
 						sfc.Name, sfcEntityElement.Container)
 					return err
 				}
 
-				err = cnpd.createVRFEntries(sfcEntityElement.EtcdVppSwitchKey, sfcEntityElement, afIfName,
-					"VRF_"+sfc.Name+"_"+sfcEntityElement.Container+"_"+sfcEntityElement.PortLabel)
+				err = cnpd.createVRFEntries(sfc, sfcEntityElement.EtcdVppSwitchKey, sfcEntityElement, afIfName,
+					"VRF_"+sfc.Name+"_"+sfcEntityElement.Container+"_"+sfcEntityElement.PortLabel, vrfID)
 				if err != nil {
-					log.Errorf("wireSfcNorthSouthNICElements: error creating processing vrf entries i/f: %s/'%s'", afIfName, sfcEntityElement)
+					log.Errorf("wireSfcNorthSouthNICElements: error creating processing vrf entries i/f: %s/'%s'", afIfName, utils.RedactSfcElement(sfcEntityElement))
 					return err
 				}
 
 			} else {
 				// l2xconnect-based wiring
 				memIfName, err := cnpd.createMemIfPair(sfc, sfcEntityElement.EtcdVppSwitchKey, sfcEntityElement,
-					false)
+					false, 0)
 				if err != nil {
 					log.Errorf("wireSfcNorthSouthNICElements: error creating memIf pair: sfc: '%s', Container: '%s'",
 						sfc.Name, sfcEntityElement.Container)
@@ -982,8 +1181,12 @@ func (cnpd *sfcCtlrL2CNPDriver) wireSfcNorthSouthNICElements(sfc *controller.Sfc
 	return nil
 }
 
-func (cnpd *sfcCtlrL2CNPDriver) createVRFEntries(etcdVppSwitchKey string, sfcEntityElement *controller.SfcEntity_SfcElement,
-	ifaceName string, defaultDescription string) error {
+// createVRFEntries creates the routes and arp entries of the element via its i/f, a route without a vrf is in the
+// vrf of the i/f, and the proxy arp ranges of the element with proxy arp enabled on the i/f.  The ranges of the sfc
+// are only for the i/fs of the chain's containers, the nic of a host entity element is shared with other sfcs
+func (cnpd *sfcCtlrL2CNPDriver) createVRFEntries(sfc *controller.SfcEntity, etcdVppSwitchKey string,
+	sfcEntityElement *controller.SfcEntity_SfcElement, ifaceName string, defaultDescription string,
+	ifVrfID uint32) error {
 
 	for i, l3VRFRoute := range sfcEntityElement.GetL3VrfRoutes() {
 
@@ -1002,7 +1205,12 @@ func (cnpd *sfcCtlrL2CNPDriver) createVRFEntries(etcdVppSwitchKey string, sfcEnt
 			vrfDescription = l3VRFRoute.Description
 		}
 
-		sr, err := cnpd.createStaticRoute(l3VRFRoute.VrfId, etcdVppSwitchKey, vrfDescription, l3VRFRoute.DstIpAddr,
+		vrfID := l3VRFRoute.VrfId
+		if vrfID == 0 {
+			vrfID = ifVrfID
+		}
+
+		sr, err := cnpd.createStaticRoute(vrfID, etcdVppSwitchKey, vrfDescription, l3VRFRoute.DstIpAddr,
 			l3VRFRoute.NextHopAddr, ifaceName, weight, pref)
 		if err != nil {
 			log.Errorf("createVRFEntries: error creating static route i/f: %d/'%s'", i, l3VRFRoute)
@@ -1023,298 +1231,1322 @@ func (cnpd *sfcCtlrL2CNPDriver) createVRFEntries(etcdVppSwitchKey string, sfcEnt
 		log.Info("createVRFEntries: creating vrf arp entry: '%s'", ae)
 	}
 
+	var proxyArpRanges []*controller.ProxyArpRange
+	if sfcEntityElement.Type != controller.SfcElementType_HOST_ENTITY {
+		proxyArpRanges = append(proxyArpRanges, sfc.GetProxyArpRanges()...)
+	}
+	proxyArpRanges = append(proxyArpRanges, sfcEntityElement.GetProxyArpRanges()...)
+	if len(proxyArpRanges) == 0 {
+		return nil
+	}
+
+	for i, proxyArpRange := range proxyArpRanges {
+		if _, err := cnpd.createProxyArpRange(etcdVppSwitchKey, proxyArpRange.RangeIpStart,
+			proxyArpRange.RangeIpEnd); err != nil {
+			log.Errorf("createVRFEntries: error creating proxy arp range: %d/'%v'", i, proxyArpRange)
+			return err
+		}
+	}
+
+	if _, err := cnpd.createProxyArpInterface(etcdVppSwitchKey, ifaceName); err != nil {
+		log.Errorf("createVRFEntries: error enabling proxy arp on i/f: '%s'", ifaceName)
+		return err
+	}
+
 	return nil
 }
 
-// This is a group of containers that need to be wired to an e/w bridge.
-func (cnpd *sfcCtlrL2CNPDriver) wireSfcEastWestElements(sfc *controller.SfcEntity) error {
+// createSfcAcls creates the acls of the elements on their vswitch i/fs, and the acls of the sfc on the vswitch i/fs
+// of the chain's entry and exit, ie: its first and last container.  The nic of a host entity element is shared with
+// other sfcs so it never gets the acls of the sfc.
+func (cnpd *sfcCtlrL2CNPDriver) createSfcAcls(sfc *controller.SfcEntity) error {
 
-	var ifName string
-	var err error
-	var bd *l2.BridgeDomains_BridgeDomain
+	var containerIfs []vswitchIf
 
-	prevMemIfName := ""
+	for _, sfcEntityElement := range sfc.GetElements() {
 
-	if sfc.Type == controller.SfcType_SFC_EW_MEMIF {
-		if len(sfc.GetElements())%2 != 0 {
-			err := fmt.Errorf("wireSfcEastWestElements: e-w memif sfc should have pairs of entries: '%s'", sfc.Name)
-			log.Error(err.Error())
-			return err
+		vswitch, ifName := cnpd.sfcElementVswitchIf(sfc, sfcEntityElement)
+		if ifName == "" {
+			continue
+		}
+		if sfcEntityElement.Type != controller.SfcElementType_HOST_ENTITY {
+			containerIfs = append(containerIfs, vswitchIf{vswitch: vswitch, ifName: ifName})
 		}
-	}
 
-	for i, sfcEntityElement := range sfc.GetElements() {
+		for _, sfcAcl := range sfcEntityElement.GetAcls() {
+			aclName := "ACL_" + sfc.Name + "_" + sfcEntityElement.Container + "_" + sfcEntityElement.PortLabel +
+				"_" + sfcAcl.Name
+			if _, err := cnpd.createAcl(vswitch, aclName, sfcAcl, []string{ifName}); err != nil {
+				log.Errorf("createSfcAcls: error creating acl: '%s'", aclName)
+				return err
+			}
+		}
+	}
 
-		log.Infof("wireSfcEastWestElements: sfc entity element[%d]: ", i, sfcEntityElement)
+	for vswitch, ifNames := range chainEndIfNames(containerIfs) {
+		for _, sfcAcl := range sfc.GetAcls() {
+			aclName := "ACL_" + sfc.Name + "_" + sfcAcl.Name
+			if _, err := cnpd.createAcl(vswitch, aclName, sfcAcl, ifNames); err != nil {
+				log.Errorf("createSfcAcls: error creating acl: '%s' on: '%s'", aclName, vswitch)
+				return err
+			}
+		}
+	}
 
-		switch sfcEntityElement.Type {
+	return nil
+}
 
-		case controller.SfcElementType_EXTERNAL_ENTITY:
-			err := fmt.Errorf("wireSfcEastWestElements: external entity not allowed in e-w sfc: '%s'", sfc.Name)
-			log.Error(err.Error())
-			return err
+// vswitchIf is an i/f of an sfc element on a vswitch
+type vswitchIf struct {
+	vswitch string
+	ifName  string
+}
 
-		case controller.SfcElementType_VPP_CONTAINER_AFP:
-			fallthrough
-		case controller.SfcElementType_NON_VPP_CONTAINER_AFP:
+// chainEndIfNames returns the names of the first and last of the chain's i/fs per vswitch, the chain's entry and
+// exit can be on different vswitches
+func chainEndIfNames(chainIfs []vswitchIf) map[string][]string {
 
-			if sfc.Type == controller.SfcType_SFC_EW_BD || sfc.Type == controller.SfcType_SFC_EW_BD_L2FIB {
+	ifNames := make(map[string][]string)
+	if len(chainIfs) == 0 {
+		return ifNames
+	}
+	first := chainIfs[0]
+	ifNames[first.vswitch] = []string{first.ifName}
+	if last := chainIfs[len(chainIfs)-1]; last != first {
+		ifNames[last.vswitch] = append(ifNames[last.vswitch], last.ifName)
+	}
+	return ifNames
+}
 
-				// TODO: need to revisit when sfc span hosts ...
-				heState, exists := cnpd.l2CNPStateCache.HE[sfcEntityElement.EtcdVppSwitchKey]
-				if !exists {
-					err := fmt.Errorf("wireSfcEastWestElements: cannot find host/bridge: '%s' for this sfc: '%s'",
-						sfcEntityElement.EtcdVppSwitchKey, sfc.Name)
-					return err
-				}
+// sfcElementVswitchIf returns the vswitch of the element and the name of the element's i/f on it, the name is
+// "" if the element has no i/f on a vswitch eg a container wired directly to another container
+func (cnpd *sfcCtlrL2CNPDriver) sfcElementVswitchIf(sfc *controller.SfcEntity,
+	sfcEntityElement *controller.SfcEntity_SfcElement) (string, string) {
+
+	switch sfcEntityElement.Type {
+	case controller.SfcElementType_EXTERNAL_ENTITY:
+		return "", ""
+	case controller.SfcElementType_HOST_ENTITY:
+		// the nic of the host is the i/f of a n/s nic sfc, the host of other sfc types is a vxlan peer
+		if sfc.Type == controller.SfcType_SFC_NS_NIC_BD || sfc.Type == controller.SfcType_SFC_NS_NIC_VRF ||
+			sfc.Type == controller.SfcType_SFC_NS_NIC_L2XCONN {
+			return sfcEntityElement.Container, sfcEntityElement.PortLabel
+		}
+		return "", ""
+	}
 
-				if sfc.Type == controller.SfcType_SFC_EW_BD { // always use dynamic sys default for this sfc type
-					bd = heState.ewBD
-				} else if sfc.BdParms == nil { // if l2fib bridge, use static sys default
-					bd = heState.ewBDL2Fib
-				} else { // bd parms are provided so create bridge using these parms
-					sfcToHEMap, exists := cnpd.l2CNPStateCache.SFCToHEs[sfc.Name]
-					if !exists {
-						cnpd.l2CNPStateCache.SFCToHEs[sfc.Name] = make(map[string]*heStateType, 0)
-						sfcToHEMap = cnpd.l2CNPStateCache.SFCToHEs[sfc.Name]
-					}
-					heState, exists := sfcToHEMap[sfcEntityElement.EtcdVppSwitchKey]
-					if !exists {
-						bdName := "BD_INTERNAL_EW_" + sfc.Name + "_" + sfcEntityElement.EtcdVppSwitchKey
-						bd, err = cnpd.bridgedDomainCreateWithIfs(sfcEntityElement.EtcdVppSwitchKey, bdName, nil, sfc.BdParms)
-						if err != nil {
-							log.Errorf("WireInternalsForHostEntity: error creating BD: '%s'", bd.Name)
-							return err
-						}
-						heState = &heStateType{
-							ewBDL2Fib: bd,
-						}
-						sfcToHEMap[sfcEntityElement.EtcdVppSwitchKey] = heState
-					} else {
-						bd = heState.ewBDL2Fib
-					}
-				}
+	sfcID, err := cnpd.DatastoreSFCIDsRetrieve(sfc.Name, sfcEntityElement.Container, sfcEntityElement.PortLabel)
+	if err != nil || sfcID.VswitchIfName == "" {
+		return "", ""
+	}
+	return sfcEntityElement.EtcdVppSwitchKey, sfcID.VswitchIfName
+}
 
-				if ifName, err = cnpd.createAFPacketVEthPairAndAddToBridge(sfc, bd, sfcEntityElement); err != nil {
-					log.Errorf("wireSfcEastWestElements: error creating memIf pair: sfc: '%s', Container: '%s'",
-						sfc.Name, sfcEntityElement.Container)
-					return err
-				}
+// createSfcClassifiers classifies the traffic of the nic, or the tunnel bridge, the sfc shares with other n/s
+// sfcs.  The traffic is classified where it enters the vswitch, the nic, or the tunnel, gets an ingress acl
+// permitting the traffic matching the rules of the sfcs sharing it, so the traffic matching no rule is dropped if
+// there is no default sfc.  An l3vrf sfc gets routes in the vrf of the nic via its first container for the
+// destinations of its rules, the default sfc gets the default routes.  On a shared bridge the traffic is not
+// routed, the i/f of the first container of each sfc gets an acl permitting the traffic matching the sfc's rules,
+// the default sfc gets an acl denying the traffic matching the rules of the other sfcs.  The classifiers of all
+// the sfcs sharing the nic, or bridge, are rendered again as the rules of one affect the others.
+func (cnpd *sfcCtlrL2CNPDriver) createSfcClassifiers(sfc *controller.SfcEntity) error {
+
+	attachment := utils.SfcClassifierAttachment(sfc)
+	if attachment == "" {
+		return nil
+	}
 
-				// now create the l2fib entries
-				if sfcEntityElement.L2FibMacs != nil {
-					for _, macAddr := range sfcEntityElement.L2FibMacs {
-						if _, err := cnpd.createL2FibEntry(sfcEntityElement.EtcdVppSwitchKey, bd.Name, macAddr,
-							ifName); err != nil {
-							log.Errorf("wireSfcNorthSouthNICElements: error creating l2fib: ewBD: '%s', mac: '%s', i/f: '%s'",
-								bd.Name, macAddr, ifName)
-							return err
-						}
-					}
-				}
+	var sfcNames []string
+	for name, other := range cnpd.l2CNPEntityCache.SFCs {
+		if utils.SfcClassifierAttachment(&other) == attachment &&
+			(len(other.GetClassifierRules()) != 0 || other.ClassifierDefault) {
+			sfcNames = append(sfcNames, name)
+		}
+	}
+	sort.Strings(sfcNames)
 
-			} else {
-				// l2xconnect -based wiring
-				afIfName, err := cnpd.createAFPacketVEthPair(sfc, sfcEntityElement)
-				if err != nil {
-					log.Errorf("wireSfcEastWestElements: error creating veth pair: sfc: '%s', Container: '%s'",
-						sfc.Name, sfcEntityElement.Container)
-					return err
-				}
-				if prevMemIfName != "" {
-					err = cnpd.createXConnectPair(sfcEntityElement.EtcdVppSwitchKey, afIfName, prevMemIfName)
-					prevMemIfName = ""
-					if err != nil {
-						return err
-					}
-				} else {
-					prevMemIfName = afIfName
-				}
-			}
+	var classifiedSfcs []*controller.SfcEntity
+	for _, name := range sfcNames {
+		classifiedSfc := cnpd.l2CNPEntityCache.SFCs[name]
+		classifiedSfcs = append(classifiedSfcs, &classifiedSfc)
+	}
 
-		case controller.SfcElementType_VPP_CONTAINER_MEMIF:
-			fallthrough
-		case controller.SfcElementType_NON_VPP_CONTAINER_MEMIF:
+	for _, classifiedSfc := range classifiedSfcs {
+		if err := cnpd.createSfcClassifier(classifiedSfc, classifiedSfcs); err != nil {
+			log.Errorf("createSfcClassifiers: error creating classifier of sfc: '%s'", classifiedSfc.Name)
+			return err
+		}
+	}
 
-			if sfc.Type == controller.SfcType_SFC_EW_MEMIF {
-				if i%2 == 0 {
-					// need to create an inter-container memif, use the left of the pair to create the pair
-					if err := cnpd.createOneOrMoreInterContainerMemIfPairs(sfc.Name, sfc.Elements[i], sfc.Elements[i+1],
-						sfc.VnfRepeatCount); err != nil {
-						log.Errorf("wireSfcEastWestElements: error creating memIf pair: sfc: '%s', Container: '%s', i='%d'",
-							sfc.Name, sfcEntityElement.Container, i)
-						return err
-					}
-				}
-			} else if sfc.Type == controller.SfcType_SFC_EW_BD || sfc.Type == controller.SfcType_SFC_EW_BD_L2FIB {
+	return nil
+}
 
-				// TODO: need to revisit when sfc span hosts ...
-				heState, exists := cnpd.l2CNPStateCache.HE[sfcEntityElement.EtcdVppSwitchKey]
-				if !exists {
-					err := fmt.Errorf("wireSfcEastWestElements: cannot find host/bridge: '%s' for this sfc: '%s'",
-						sfcEntityElement.EtcdVppSwitchKey, sfc.Name)
-					return err
-				}
+func (cnpd *sfcCtlrL2CNPDriver) createSfcClassifier(sfc *controller.SfcEntity,
+	classifiedSfcs []*controller.SfcEntity) error {
 
-				if sfc.Type == controller.SfcType_SFC_EW_BD { // always use dynamic sys default for this sfc type
-					bd = heState.ewBD
-				} else if sfc.BdParms == nil { // if l2fib bridge, use static sys default
-					bd = heState.ewBDL2Fib
-				} else { // bd parms are provided so create bridge using these parms
-					sfcToHEMap, exists := cnpd.l2CNPStateCache.SFCToHEs[sfc.Name]
-					if !exists {
-						cnpd.l2CNPStateCache.SFCToHEs[sfc.Name] = make(map[string]*heStateType, 0)
-						sfcToHEMap = cnpd.l2CNPStateCache.SFCToHEs[sfc.Name]
-					}
-					heState, exists := sfcToHEMap[sfcEntityElement.EtcdVppSwitchKey]
-					if !exists {
-						bdName := "BD_INTERNAL_EW_" + sfc.Name + "_" + sfcEntityElement.EtcdVppSwitchKey
-						bd, err = cnpd.bridgedDomainCreateWithIfs(sfcEntityElement.EtcdVppSwitchKey, bdName, nil, sfc.BdParms)
-						if err != nil {
-							log.Errorf("WireInternalsForHostEntity: error creating BD: '%s'", bd.Name)
-							return err
-						}
-						heState = &heStateType{
-							ewBDL2Fib: bd,
-						}
-						sfcToHEMap[sfcEntityElement.EtcdVppSwitchKey] = heState
-					} else {
-						bd = heState.ewBDL2Fib
-					}
+	var ingress, peer *controller.SfcEntity_SfcElement
+	for _, sfcEntityElement := range sfc.GetElements() {
+		switch sfcEntityElement.Type {
+		case controller.SfcElementType_EXTERNAL_ENTITY, controller.SfcElementType_HOST_ENTITY:
+			if peer == nil {
+				peer = sfcEntityElement
+			}
+		default:
+			if ingress == nil {
+				ingress = sfcEntityElement
+			}
+		}
+	}
+	if ingress == nil || peer == nil {
+		return nil
+	}
+	// the sfc is classified once its first container is wired
+	vswitch, ifName := cnpd.sfcElementVswitchIf(sfc, ingress)
+	if ifName == "" {
+		return nil
+	}
+
+	attachmentVswitch, attachmentIfName := cnpd.sfcClassifierAttachmentIf(sfc, ingress, peer)
+	if attachmentIfName != "" {
+		if _, err := cnpd.createAcl(attachmentVswitch, "ACL_CLASSIFIER_IF_"+attachmentIfName,
+			classifierAttachmentAcl(classifiedSfcs), []string{attachmentIfName}); err != nil {
+			return err
+		}
+	}
+
+	if sfc.Type == controller.SfcType_SFC_NS_NIC_VRF {
+		return cnpd.createSfcClassifierRoutes(sfc, ingress, peer, vswitch, ifName)
+	}
+
+	classifierAcl := &controller.Acl{
+		Direction: controller.AclDirection_ACL_DIRECTION_EGRESS,
+	}
+	if sfc.ClassifierDefault {
+		for _, other := range classifiedSfcs {
+			if other.Name == sfc.Name {
+				continue
+			}
+			for _, rule := range other.GetClassifierRules() {
+				classifierAcl.Rules = append(classifierAcl.Rules,
+					classifierAclRule(rule, controller.AclAction_ACL_ACTION_DENY))
+			}
+		}
+		classifierAcl.Rules = append(classifierAcl.Rules, &controller.AclRule{
+			Action: controller.AclAction_ACL_ACTION_PERMIT,
+		})
+	} else {
+		for _, rule := range sfc.GetClassifierRules() {
+			classifierAcl.Rules = append(classifierAcl.Rules,
+				classifierAclRule(rule, controller.AclAction_ACL_ACTION_PERMIT))
+		}
+	}
+	if _, err := cnpd.createAcl(vswitch, "ACL_CLASSIFIER_"+sfc.Name, classifierAcl, []string{ifName}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// createSfcClassifierRoutes routes the destinations of the sfc's rules in the vrf of the nic to the sfc's first
+// container, the default sfc gets the default routes of the address families of its first container
+func (cnpd *sfcCtlrL2CNPDriver) createSfcClassifierRoutes(sfc *controller.SfcEntity,
+	ingress *controller.SfcEntity_SfcElement, he *controller.SfcEntity_SfcElement, vswitch string,
+	ifName string) error {
+
+	heVrfID, err := cnpd.elementVrfID(sfc, he)
+	if err != nil {
+		return err
+	}
+	ipv4NextHopAddr, _, _ := cnpd.GetSfcInterfaceIPAndMac(ingress.Container, ingress.PortLabel)
+	ipv6NextHopAddr := cnpd.sfcInterfaceIpv6Address(ingress.Container, ingress.PortLabel)
+
+	dstNetworks := classifierDstNetworks(sfc, ipv4NextHopAddr != "", ipv6NextHopAddr != "")
+	if sfc.ClassifierDefault && len(dstNetworks) == 0 {
+		err := fmt.Errorf("createSfcClassifierRoutes: sfc: '%s', container: '%s', port: '%s' has no address for "+
+			"the default routes", sfc.Name, ingress.Container, ingress.PortLabel)
+		log.Error(err.Error())
+		return err
+	}
+	for _, dstNetwork := range dstNetworks {
+		nextHopAddr := ipv4NextHopAddr
+		if strings.Contains(dstNetwork, ":") {
+			nextHopAddr = ipv6NextHopAddr
+		}
+		if nextHopAddr == "" {
+			err := fmt.Errorf("createSfcClassifierRoutes: sfc: '%s', container: '%s', port: '%s' has no address "+
+				"for the classifier route: '%s'", sfc.Name, ingress.Container, ingress.PortLabel, dstNetwork)
+			log.Error(err.Error())
+			return err
+		}
+		if _, err := cnpd.createStaticRoute(heVrfID, vswitch, "CLASSIFIER_"+sfc.Name, dstNetwork, nextHopAddr,
+			ifName, cnpd.l2CNPEntityCache.SysParms.DefaultStaticRouteWeight,
+			cnpd.l2CNPEntityCache.SysParms.DefaultStaticRoutePreference); err != nil {
+			log.Errorf("createSfcClassifierRoutes: error creating classifier route: '%s' for sfc: '%s'", dstNetwork,
+				sfc.Name)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// classifierDstNetworks returns the destinations routed into the sfc, the default sfc gets the default route of
+// each address family its first container has an address of
+func classifierDstNetworks(sfc *controller.SfcEntity, hasIpv4 bool, hasIpv6 bool) []string {
+
+	var dstNetworks []string
+	if sfc.ClassifierDefault {
+		if hasIpv4 {
+			dstNetworks = append(dstNetworks, "0.0.0.0/0")
+		}
+		if hasIpv6 {
+			dstNetworks = append(dstNetworks, "::/0")
+		}
+	}
+	for _, rule := range sfc.GetClassifierRules() {
+		if rule.DstNetwork != "" {
+			dstNetworks = append(dstNetworks, rule.DstNetwork)
+		}
+	}
+	return dstNetworks
+}
+
+// sfcClassifierAttachmentIf returns the vswitch and the name of the i/f the sfc shares with the other classified
+// sfcs, the nic of the host entity, or the tunnel from the vswitch of the sfc's first container to the peer, the
+// name is "" if the tunnel is not wired yet
+func (cnpd *sfcCtlrL2CNPDriver) sfcClassifierAttachmentIf(sfc *controller.SfcEntity,
+	ingress *controller.SfcEntity_SfcElement, peer *controller.SfcEntity_SfcElement) (string, string) {
+
+	if sfc.Type == controller.SfcType_SFC_NS_NIC_BD || sfc.Type == controller.SfcType_SFC_NS_NIC_VRF {
+		return peer.Container, peer.PortLabel
+	}
+
+	hostName := ingress.EtcdVppSwitchKey
+	stateName := tunnelScopedName(peer.Container, sfc)
+	if peer.Type == controller.SfcElementType_EXTERNAL_ENTITY {
+		if heToEEState, exists := cnpd.l2CNPStateCache.HEToEEs[hostName][stateName]; exists &&
+			heToEEState.vlanIf != nil {
+			return hostName, heToEEState.vlanIf.Name
+		}
+		return "", ""
+	}
+	if heToHEState, exists := cnpd.l2CNPStateCache.HEToHEs[hostName][stateName]; exists &&
+		heToHEState.vlanIf != nil {
+		return hostName, heToHEState.vlanIf.Name
+	}
+	return "", ""
+}
+
+// classifierAttachmentAcl returns the ingress acl of the nic, or tunnel, shared by the classified sfcs, it
+// permits the traffic matching the rules of the sfcs, or all of it if one of them is the default
+func classifierAttachmentAcl(classifiedSfcs []*controller.SfcEntity) *controller.Acl {
+
+	attachmentAcl := &controller.Acl{
+		Direction: controller.AclDirection_ACL_DIRECTION_INGRESS,
+	}
+	for _, classifiedSfc := range classifiedSfcs {
+		if classifiedSfc.ClassifierDefault {
+			attachmentAcl.Rules = []*controller.AclRule{{Action: controller.AclAction_ACL_ACTION_PERMIT}}
+			return attachmentAcl
+		}
+		for _, rule := range classifiedSfc.GetClassifierRules() {
+			attachmentAcl.Rules = append(attachmentAcl.Rules,
+				classifierAclRule(rule, controller.AclAction_ACL_ACTION_PERMIT))
+		}
+	}
+	return attachmentAcl
+}
+
+// classifierAclRule converts a classifier rule to an acl rule with the action
+func classifierAclRule(rule *controller.ClassifierRule, action controller.AclAction) *controller.AclRule {
+	return &controller.AclRule{
+		Action:       action,
+		Protocol:     rule.Protocol,
+		SrcNetwork:   rule.SrcNetwork,
+		DstNetwork:   rule.DstNetwork,
+		SrcPortLower: rule.SrcPortLower,
+		SrcPortUpper: rule.SrcPortUpper,
+		DstPortLower: rule.DstPortLower,
+		DstPortUpper: rule.DstPortUpper,
+	}
+}
+
+// This is a group of containers that need to be wired to an e/w bridge.  The containers of the chain can
+// be on different hosts, the bridges of the hosts are then joined with vxlan tunnels, and for l2xconnect,
+// a pair of containers on different hosts is xconnected through a vxlan tunnel between the hosts.
+func (cnpd *sfcCtlrL2CNPDriver) wireSfcEastWestElements(sfc *controller.SfcEntity) error {
+
+	var ifName string
+	var err error
+	var bd *l2.BridgeDomains_BridgeDomain
+
+	prevMemIfName := ""
+	var prevSfcElement *controller.SfcEntity_SfcElement
+
+	// the hosts of the chain's containers, in chain order
+	var hostNames []string
+
+	if sfc.Type == controller.SfcType_SFC_EW_MEMIF {
+		if len(sfc.GetElements())%2 != 0 {
+			err := fmt.Errorf("wireSfcEastWestElements: e-w memif sfc should have pairs of entries: '%s'", sfc.Name)
+			log.Error(err.Error())
+			return err
+		}
+	}
+
+	for i, sfcEntityElement := range sfc.GetElements() {
+
+		log.Infof("wireSfcEastWestElements: sfc entity element[%d]: ", i, utils.RedactSfcElement(sfcEntityElement))
+
+		switch sfcEntityElement.Type {
+
+		case controller.SfcElementType_EXTERNAL_ENTITY:
+			err := fmt.Errorf("wireSfcEastWestElements: external entity not allowed in e-w sfc: '%s'", sfc.Name)
+			log.Error(err.Error())
+			return err
+
+		case controller.SfcElementType_HOST_ENTITY:
+			continue
+		}
+
+		if sfc.Type == controller.SfcType_SFC_EW_MEMIF &&
+			(sfcEntityElement.Type == controller.SfcElementType_VPP_CONTAINER_MEMIF ||
+				sfcEntityElement.Type == controller.SfcElementType_NON_VPP_CONTAINER_MEMIF) {
+			if i%2 == 0 {
+				// need to create an inter-container memif, use the left of the pair to create the pair
+				if err := cnpd.createOneOrMoreInterContainerMemIfPairs(sfc.Name, utils.FormatLabels(sfc.Labels), sfc.Elements[i], sfc.Elements[i+1],
+					sfc.VnfRepeatCount); err != nil {
+					log.Errorf("wireSfcEastWestElements: error creating memIf pair: sfc: '%s', Container: '%s', i='%d'",
+						sfc.Name, sfcEntityElement.Container, i)
+					return err
 				}
+			}
+			continue
+		}
+
+		if !containsString(hostNames, sfcEntityElement.EtcdVppSwitchKey) {
+			hostNames = append(hostNames, sfcEntityElement.EtcdVppSwitchKey)
+		}
+
+		if sfc.Type == controller.SfcType_SFC_EW_BD || sfc.Type == controller.SfcType_SFC_EW_BD_L2FIB {
+
+			if bd, _, err = cnpd.eastWestBD(sfc, sfcEntityElement.EtcdVppSwitchKey); err != nil {
+				return err
+			}
 
+			switch sfcEntityElement.Type {
+			case controller.SfcElementType_VPP_CONTAINER_AFP, controller.SfcElementType_NON_VPP_CONTAINER_AFP,
+				controller.SfcElementType_VPP_CONTAINER_TAP:
+				if ifName, err = cnpd.createVEthOrTapPairAndAddToBridge(sfc, bd, sfcEntityElement); err != nil {
+					log.Errorf("wireSfcEastWestElements: error creating memIf pair: sfc: '%s', Container: '%s'",
+						sfc.Name, sfcEntityElement.Container)
+					return err
+				}
+			case controller.SfcElementType_VPP_CONTAINER_MEMIF, controller.SfcElementType_NON_VPP_CONTAINER_MEMIF:
 				if ifName, err = cnpd.createMemIfPairAndAddToBridge(sfc, sfcEntityElement.EtcdVppSwitchKey, bd,
 					sfcEntityElement, true); err != nil {
 					log.Errorf("wireSfcEastWestElements: error creating memIf pair: sfc: '%s', Container: '%s'",
 						sfc.Name, sfcEntityElement.Container)
 					return err
 				}
+			default:
+				continue
+			}
 
-				// now create the l2fib entries
-				if sfcEntityElement.L2FibMacs != nil {
-					for _, macAddr := range sfcEntityElement.L2FibMacs {
-						if _, err := cnpd.createL2FibEntry(sfcEntityElement.EtcdVppSwitchKey, bd.Name, macAddr,
-							ifName); err != nil {
-							log.Errorf("wireSfcNorthSouthNICElements: error creating l2fib: ewBD: '%s', mac: '%s', i/f: '%s'",
-								bd.Name, macAddr, ifName)
-							return err
-						}
+			// now create the l2fib entries
+			if sfcEntityElement.L2FibMacs != nil {
+				for _, macAddr := range sfcEntityElement.L2FibMacs {
+					if _, err := cnpd.createL2FibEntry(sfcEntityElement.EtcdVppSwitchKey, bd.Name, macAddr,
+						ifName); err != nil {
+						log.Errorf("wireSfcNorthSouthNICElements: error creating l2fib: ewBD: '%s', mac: '%s', i/f: '%s'",
+							bd.Name, macAddr, ifName)
+						return err
+					}
+				}
+			}
+
+		} else {
+
+			// l2xconnect -based wiring
+			switch sfcEntityElement.Type {
+			case controller.SfcElementType_VPP_CONTAINER_AFP, controller.SfcElementType_NON_VPP_CONTAINER_AFP,
+				controller.SfcElementType_VPP_CONTAINER_TAP:
+				if ifName, err = cnpd.createVEthOrTapPair(sfc, sfcEntityElement, 0); err != nil {
+					log.Errorf("wireSfcEastWestElements: error creating veth pair: sfc: '%s', Container: '%s'",
+						sfc.Name, sfcEntityElement.Container)
+					return err
+				}
+			case controller.SfcElementType_VPP_CONTAINER_MEMIF, controller.SfcElementType_NON_VPP_CONTAINER_MEMIF:
+				if ifName, err = cnpd.createMemIfPair(sfc, sfcEntityElement.EtcdVppSwitchKey, sfcEntityElement,
+					false, 0); err != nil {
+					log.Errorf("wireSfcEastWestElements: error creating memIf pair: sfc: '%s', Container: '%s'",
+						sfc.Name, sfcEntityElement.Container)
+					return err
+				}
+			default:
+				continue
+			}
+
+			if prevMemIfName == "" {
+				prevMemIfName = ifName
+				prevSfcElement = sfcEntityElement
+				continue
+			}
+
+			if prevSfcElement.EtcdVppSwitchKey == sfcEntityElement.EtcdVppSwitchKey {
+				err = cnpd.createXConnectPair(sfcEntityElement.EtcdVppSwitchKey, ifName, prevMemIfName)
+			} else {
+				err = cnpd.createXConnectPairViaVxLAN(sfc, prevSfcElement, prevMemIfName, sfcEntityElement, ifName)
+			}
+			prevMemIfName = ""
+			prevSfcElement = nil
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if sfc.Type == controller.SfcType_SFC_EW_BD || sfc.Type == controller.SfcType_SFC_EW_BD_L2FIB {
+		if len(hostNames) > 1 {
+			return cnpd.joinEastWestBDsViaVxLAN(sfc, hostNames)
+		}
+	}
+
+	return nil
+}
+
+// eastWestBD returns the host's e/w bridge for the sfc, and the name of the segment the bridge is part of, the
+// bridges of a segment on different hosts are joined by vxlan tunnels
+func (cnpd *sfcCtlrL2CNPDriver) eastWestBD(sfc *controller.SfcEntity,
+	hostName string) (*l2.BridgeDomains_BridgeDomain, string, error) {
+
+	heState, exists := cnpd.l2CNPStateCache.HE[hostName]
+	if !exists {
+		err := fmt.Errorf("eastWestBD: cannot find host/bridge: '%s' for this sfc: '%s'",
+			hostName, sfc.Name)
+		return nil, "", err
+	}
+
+	if sfc.Tenant != "" && (sfc.Type == controller.SfcType_SFC_EW_BD || sfc.BdParms == nil) {
+		// tenant sfcs never share the host's default bridges with other tenants
+		bd, err := cnpd.tenantEastWestBD(sfc, hostName)
+		if err != nil {
+			return nil, "", err
+		}
+		if sfc.Type == controller.SfcType_SFC_EW_BD {
+			return bd, tenantScopedName("EW", sfc.Tenant), nil
+		}
+		return bd, tenantScopedName("EW_L2FIB", sfc.Tenant), nil
+	}
+
+	if sfc.Type == controller.SfcType_SFC_EW_BD { // always use dynamic sys default for this sfc type
+		return heState.ewBD, "EW", nil
+	}
+
+	if sfc.BdParms == nil { // if l2fib bridge, use static sys default
+		return heState.ewBDL2Fib, "EW_L2FIB", nil
+	}
+
+	// bd parms are provided so create bridge using these parms
+	sfcToHEMap, exists := cnpd.l2CNPStateCache.SFCToHEs[sfc.Name]
+	if !exists {
+		cnpd.l2CNPStateCache.SFCToHEs[sfc.Name] = make(map[string]*heStateType, 0)
+		sfcToHEMap = cnpd.l2CNPStateCache.SFCToHEs[sfc.Name]
+	}
+	heState, exists = sfcToHEMap[hostName]
+	if !exists {
+		bdName := "BD_INTERNAL_EW_" + sfc.Name + "_" + hostName
+		bd, err := cnpd.bridgedDomainCreateWithIfs(hostName, bdName, nil, sfc.BdParms)
+		if err != nil {
+			log.Errorf("eastWestBD: error creating BD: '%s'", bdName)
+			return nil, "", err
+		}
+		heState = &heStateType{
+			ewBDL2Fib: bd,
+		}
+		sfcToHEMap[hostName] = heState
+	}
+
+	return heState.ewBDL2Fib, "EW_SFC_" + sfc.Name, nil
+}
+
+// joinEastWestBDsViaVxLAN puts a vxlan tunnel to each of the other hosts of the chain into the e/w bridge on
+// each host, the tunnels are in the same split horizon group so a host never forwards between them, and the
+// l2fib macs of a container are pointed at the tunnel to the container's host on the other hosts
+func (cnpd *sfcCtlrL2CNPDriver) joinEastWestBDsViaVxLAN(sfc *controller.SfcEntity, hostNames []string) error {
+
+	for _, shName := range hostNames {
+
+		bd, segment, err := cnpd.eastWestBD(sfc, shName)
+		if err != nil {
+			return err
+		}
+
+		for _, dhName := range hostNames {
+
+			if shName == dhName {
+				continue
+			}
+
+			vlanIf, err := cnpd.createEastWestVxLANToDestHost(sfc, segment, shName, dhName)
+			if err != nil {
+				return err
+			}
+
+			ifs := []*l2.BridgeDomains_BridgeDomain_Interfaces{
+				{
+					Name:              vlanIf.Name,
+					SplitHorizonGroup: 1,
+				},
+			}
+			if err := cnpd.bridgedDomainAssociateWithIfs(shName, bd, ifs); err != nil {
+				log.Errorf("joinEastWestBDsViaVxLAN: error adding vxlan to BD: '%s'", bd.Name)
+				return err
+			}
+
+			for _, sfcEntityElement := range sfc.GetElements() {
+				if sfcEntityElement.EtcdVppSwitchKey != dhName {
+					continue
+				}
+				for _, macAddr := range sfcEntityElement.L2FibMacs {
+					if _, err := cnpd.createL2FibEntry(shName, bd.Name, macAddr, vlanIf.Name); err != nil {
+						log.Errorf("joinEastWestBDsViaVxLAN: error creating l2fib: ewBD: '%s', mac: '%s', i/f: '%s'",
+							bd.Name, macAddr, vlanIf.Name)
+						return err
 					}
 				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// createXConnectPairViaVxLAN xconnects a pair of containers on different hosts, each container's if is
+// xconnected to a vxlan tunnel, dedicated to the pair, to the other container's host
+func (cnpd *sfcCtlrL2CNPDriver) createXConnectPairViaVxLAN(sfc *controller.SfcEntity,
+	sfcElement1 *controller.SfcEntity_SfcElement, ifName1 string,
+	sfcElement2 *controller.SfcEntity_SfcElement, ifName2 string) error {
+
+	segment := "EW_XCONN_" + sfc.Name + "_" + sfcElement1.Container + "_" + sfcElement1.PortLabel
+
+	vlanIf1, err := cnpd.createEastWestVxLANToDestHost(sfc, segment, sfcElement1.EtcdVppSwitchKey,
+		sfcElement2.EtcdVppSwitchKey)
+	if err != nil {
+		return err
+	}
+	if err := cnpd.createXConnectPair(sfcElement1.EtcdVppSwitchKey, ifName1, vlanIf1.Name); err != nil {
+		return err
+	}
+
+	vlanIf2, err := cnpd.createEastWestVxLANToDestHost(sfc, segment, sfcElement2.EtcdVppSwitchKey,
+		sfcElement1.EtcdVppSwitchKey)
+	if err != nil {
+		return err
+	}
+
+	return cnpd.createXConnectPair(sfcElement2.EtcdVppSwitchKey, ifName2, vlanIf2.Name)
+}
+
+// createEastWestVxLANToDestHost ensures the vxlan tunnel of an e/w segment from the source host to the dest
+// host is created, both directions of the segment's tunnel between two hosts use the same vni
+func (cnpd *sfcCtlrL2CNPDriver) createEastWestVxLANToDestHost(sfc *controller.SfcEntity, segment string,
+	shName string, dhName string) (*interfaces.Interfaces_Interface, error) {
+
+	heToHEMap, exists := cnpd.l2CNPStateCache.HEToHEs[shName]
+	if !exists {
+		err := fmt.Errorf("createEastWestVxLANToDestHost: host not found: '%s' for this sfc: '%s'",
+			shName, sfc.Name)
+		return nil, err
+	}
+	if _, exists := heToHEMap[dhName]; !exists {
+		err := fmt.Errorf("createEastWestVxLANToDestHost: host '%s' not wired to dest host: '%s' for this sfc: '%s'",
+			shName, dhName, sfc.Name)
+		return nil, err
+	}
+
+	stateName := segment + "_" + dhName
+	heToHEState, exists := heToHEMap[stateName]
+	if !exists {
+		heToHEState = &heToHEStateType{}
+		heToHEMap[stateName] = heToHEState
+	}
+
+	if heToHEState.vlanIf == nil {
+
+		sh := cnpd.l2CNPEntityCache.HEs[shName]
+		dh := cnpd.l2CNPEntityCache.HEs[dhName]
+
+		// the reverse direction may already have a vni for the segment
+		reverseStateName := segment + "_" + shName
+		vlanID := uint32(0)
+		if reverseState, exists := cnpd.l2CNPStateCache.HEToHEs[dhName][reverseStateName]; exists &&
+			reverseState.vlanIf != nil {
+			vlanID = reverseState.vlanIf.Vxlan.Vni
+		} else if he2heID, _ := cnpd.DatastoreHE2HEIDsRetrieve(sh.Name, stateName); he2heID != nil && he2heID.VlanId != 0 {
+			vlanID = he2heID.VlanId
+		} else if he2heID, _ := cnpd.DatastoreHE2HEIDsRetrieve(dh.Name, reverseStateName); he2heID != nil && he2heID.VlanId != 0 {
+			vlanID = he2heID.VlanId
+		} else {
+			var err error
+			if vlanID, err = cnpd.allocateVLanID(sfc.Tenant, l2driver.HE2HEIDsNameKey(sh.Name, stateName)); err != nil {
+				return nil, err
+			}
+		}
+
+		ifName := "IF_VXLAN_H2H_" + sh.Name + "_" + stateName
+		srcAddr, dstAddr, _, err := h2hUnderlay(&sh, &dh)
+		if err != nil {
+			log.Errorf("createEastWestVxLANToDestHost: %s", err)
+			return nil, err
+		}
+		vlanIf, err := cnpd.vxLanCreate(sh.Name, ifName, utils.FormatLabels(dh.Labels), vlanID, srcAddr, dstAddr)
+		if err != nil {
+			log.Errorf("createEastWestVxLANToDestHost: error creating vxlan: '%s'", ifName)
+			return nil, err
+		}
+
+		heToHEState.vlanIf = vlanIf
+
+		key, sh2dhID, err := cnpd.DatastoreHE2HEIDsCreate(sh.Name, stateName, vlanID)
+		if err == nil && cnpd.reconcileInProgress {
+			cnpd.reconcileAfter.he2heIDs[key] = *sh2dhID
+		}
+	}
+
+	// the route to the dest host is shared by all tunnels to the host so it is kept in the non segment state
+	if err := cnpd.createStaticRouteToDestHost(heToHEMap, shName, dhName,
+		cnpd.l2CNPEntityCache.SysParms.DefaultStaticRoutePreference); err != nil {
+		return nil, err
+	}
+
+	return heToHEState.vlanIf, nil
+}
+
+// createStaticRouteToDestHost creates the route from the source host to the dest host's vxlan tunnel
+// endpoint if the source host wants one and it has not been created yet
+func (cnpd *sfcCtlrL2CNPDriver) createStaticRouteToDestHost(heToHEMap map[string]*heToHEStateType,
+	shName string, dhName string, pref uint32) error {
+
+	if heToHEMap[dhName].l3Route != nil {
+		return nil
+	}
+
+	sh := cnpd.l2CNPEntityCache.HEs[shName]
+	dh := cnpd.l2CNPEntityCache.HEs[dhName]
+
+	// configure static route from this host to the dest host
+	if sh.CreateVxlanStaticRoute {
+		description := "IF_STATIC_ROUTE_H2H_" + dh.Name
+		_, dstAddr, nextHopAddr, err := h2hUnderlay(&sh, &dh)
+		if err != nil {
+			log.Errorf("createStaticRouteToDestHost: %s", err)
+			return err
+		}
+		sr, err := cnpd.createStaticRoute(0, sh.Name, description, dstAddr, nextHopAddr,
+			sh.EthIfName,
+			cnpd.l2CNPEntityCache.SysParms.DefaultStaticRouteWeight, pref)
+		if err != nil {
+			log.Errorf("createStaticRouteToDestHost: error creating static route i/f: '%s'", description)
+			return err
+		}
+
+		heToHEMap[dhName].l3Route = sr
+	}
+
+	return nil
+}
+
+// east/west vrf type, the vswitch end of each container's if is placed into the vrf of the element's routes,
+// and the element's l3vrf routes and arp entries are created on it
+func (cnpd *sfcCtlrL2CNPDriver) wireSfcEastWestVRFElements(sfc *controller.SfcEntity) error {
+
+	for i, sfcEntityElement := range sfc.GetElements() {
+
+		log.Infof("wireSfcEastWestVRFElements: sfc entity element[%d]: %v", i, utils.RedactSfcElement(sfcEntityElement))
+
+		var ifName string
+		var vrfID uint32
+		var err error
+
+		switch sfcEntityElement.Type {
+
+		case controller.SfcElementType_EXTERNAL_ENTITY:
+			err := fmt.Errorf("wireSfcEastWestVRFElements: external entity not allowed in e-w sfc: '%s'", sfc.Name)
+			log.Error(err.Error())
+			return err
+
+		case controller.SfcElementType_VPP_CONTAINER_AFP, controller.SfcElementType_VPP_CONTAINER_TAP:
+			fallthrough
+		case controller.SfcElementType_NON_VPP_CONTAINER_AFP:
+
+			if vrfID, err = cnpd.elementVrfID(sfc, sfcEntityElement); err != nil {
+				return err
+			}
+			if ifName, err = cnpd.createVEthOrTapPair(sfc, sfcEntityElement, vrfID); err != nil {
+				log.Errorf("wireSfcEastWestVRFElements: error creating veth pair: sfc: '%s', Container: '%s'",
+					sfc.Name, sfcEntityElement.Container)
+				return err
+			}
+
+		case controller.SfcElementType_VPP_CONTAINER_MEMIF:
+			fallthrough
+		case controller.SfcElementType_NON_VPP_CONTAINER_MEMIF:
+
+			if vrfID, err = cnpd.elementVrfID(sfc, sfcEntityElement); err != nil {
+				return err
+			}
+			if ifName, err = cnpd.createMemIfPair(sfc, sfcEntityElement.EtcdVppSwitchKey, sfcEntityElement,
+				true, vrfID); err != nil {
+				log.Errorf("wireSfcEastWestVRFElements: error creating memIf pair: sfc: '%s', Container: '%s'",
+					sfc.Name, sfcEntityElement.Container)
+				return err
+			}
+
+		default:
+			continue
+		}
+
+		err = cnpd.createVRFEntries(sfc, sfcEntityElement.EtcdVppSwitchKey, sfcEntityElement, ifName,
+			"VRF_"+sfc.Name+"_"+sfcEntityElement.Container+"_"+sfcEntityElement.PortLabel, vrfID)
+		if err != nil {
+			log.Errorf("wireSfcEastWestVRFElements: error creating processing vrf entries i/f: %s/'%s'", ifName,
+				sfcEntityElement)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// elementVrfID returns the vrf of the element's vswitch i/f, the auto vrf of the sfc on the element's host if the
+// sfc has auto vrfs
+func (cnpd *sfcCtlrL2CNPDriver) elementVrfID(sfc *controller.SfcEntity,
+	sfcEntityElement *controller.SfcEntity_SfcElement) (uint32, error) {
+
+	if !sfc.AutoVrf {
+		vrfID, err := utils.VrfIDOfElement(sfc, sfcEntityElement)
+		if err != nil {
+			log.Error(err.Error())
+		}
+		return vrfID, err
+	}
+
+	// the i/f of a host entity element is on the host itself
+	hostName := sfcEntityElement.EtcdVppSwitchKey
+	if sfcEntityElement.Type == controller.SfcElementType_HOST_ENTITY {
+		hostName = sfcEntityElement.Container
+	}
+
+	var vrfID uint32
+	if sfcHostID, _ := cnpd.DatastoreSFCHostIDsRetrieve(sfc.Name, hostName); sfcHostID != nil && sfcHostID.VrfId != 0 {
+		vrfID = sfcHostID.VrfId
+	} else {
+		var err error
+		if vrfID, err = cnpd.allocateAutoVrfID(hostName, l2driver.SFCHostIDsNameKey(sfc.Name, hostName)); err != nil {
+			log.Errorf("elementVrfID: sfc: '%s', host: '%s', cannot allocate an auto vrf: %s", sfc.Name, hostName, err)
+			return 0, err
+		}
+	}
+
+	key, sfcHostID, err := cnpd.DatastoreSFCHostIDsCreate(sfc.Name, hostName, vrfID)
+	if err == nil && cnpd.reconcileInProgress {
+		cnpd.reconcileAfter.sfcHostIDs[key] = *sfcHostID
+	}
+
+	return vrfID, err
+}
+
+// heLoopbackIfName returns the name of the loopback i/f of the host
+func heLoopbackIfName(heName string) string {
+	return "IF_LOOPBACK_H_" + heName
+}
+
+// vswitchUnnumbered returns the unnumbered settings of a vswitch i/f borrowing the address of the host's loopback,
+// nil if the i/f is not unnumbered
+func vswitchUnnumbered(unnumbered bool, heName string) *interfaces.Interfaces_Interface_Unnumbered {
+	if !unnumbered {
+		return nil
+	}
+	return &interfaces.Interfaces_Interface_Unnumbered{
+		IsUnnumbered:    true,
+		InterfaceWithIP: heLoopbackIfName(heName),
+	}
+}
+
+// createOneOrMoreInterContainerMemIfPairs creates memif pair and returns vswitch-end memif interface name
+func (cnpd *sfcCtlrL2CNPDriver) createOneOrMoreInterContainerMemIfPairs(
+	sfcName string,
+	description string,
+	vnfElement1 *controller.SfcEntity_SfcElement,
+	vnfElement2 *controller.SfcEntity_SfcElement,
+	vnfRepeatCount uint32) error {
+
+	log.Infof("createInterContainerMemIfPair: sfc: '%s', vnf1: '%s', vnf2: '%s', repeatCount: '%d'",
+		sfcName, vnfElement1.Container, vnfElement2.Container, vnfRepeatCount)
+
+	vnf1Port := ""
+	vnf2Port := ""
+	mtu := cnpd.getMtu(vnfElement1.Mtu)
+	rxMode := vnfElement1.RxMode
+	container1Name := ""
+	container2Name := ""
+
+	for repeatCount := uint32(0); repeatCount <= vnfRepeatCount; repeatCount++ {
+
+		if repeatCount == 0 {
+			container1Name = vnfElement1.Container
+			vnf1Port = vnfElement1.PortLabel
+		} else {
+			container1Name = "vnfx-" + strconv.Itoa(int(repeatCount-1))
+			vnf1Port = vnfElement1.PortLabel
+		}
+		if repeatCount == vnfRepeatCount {
+			container2Name = vnfElement2.Container
+			vnf2Port = vnfElement2.PortLabel
+		} else {
+			container2Name = "vnfx-" + strconv.Itoa(int(repeatCount))
+			vnf2Port = vnfElement2.PortLabel
+		}
+
+		var memifID uint32
+
+		sfcID, _ := cnpd.DatastoreSFCIDsRetrieve(sfcName, container1Name, vnf1Port)
+		if sfcID == nil || sfcID.MemifId == 0 {
+			var err error
+			if memifID, err = cnpd.allocateMemifID(
+				l2driver.SFCContainerPortIDsNameKey(sfcName, container1Name, vnf1Port)); err != nil {
+				return err
+			}
+		} else {
+			memifID = sfcID.MemifId
+		}
+
+		memifParms, err := cnpd.memifParms(sfcName, container1Name, vnf1Port, vnfElement1)
+		if err != nil {
+			return err
+		}
+		socketFilename, err := cnpd.memifSocketFilename(sfcName, vnfElement1.EtcdVppSwitchKey, container1Name, memifID)
+		if err != nil {
+			return err
+		}
+
+		// create a memif in the vnf container
+		if err := cnpd.createInterContainerMemIfPair(
+			sfcName,
+			description,
+			container1Name, vnf1Port,
+			container2Name, vnf2Port,
+			mtu,
+			rxMode,
+			memifParms,
+			socketFilename,
+			memifID); err != nil {
+			return err
+		}
+
+		// each container's end of the memif is named by its port in the container, the names are kept in the
+		// records of both ends, the memif id and secret in the one of the left end which created the pair
+		if _, err := cnpd.sfcContainerIfNames(sfcName, container1Name, vnf1Port, vnfElement1.EtcdVppSwitchKey,
+			vnf1Port, false); err != nil {
+			return err
+		}
+		if _, err := cnpd.sfcContainerIfNames(sfcName, container2Name, vnf2Port, vnfElement2.EtcdVppSwitchKey,
+			vnf2Port, false); err != nil {
+			return err
+		}
+
+		key, sfcID, err := cnpd.DatastoreSFCIDsCreate(sfcName, container1Name, vnf1Port,
+			0, 0, memifID, 0, 0, "", "", socketFilename, vnf1Port)
+		if err == nil && cnpd.reconcileInProgress {
+			cnpd.reconcileAfter.sfcIDs[key] = *sfcID
+		}
+		key, sfcID, err = cnpd.DatastoreSFCIDsCreate(sfcName, container2Name, vnf2Port,
+			0, 0, 0, 0, 0, "", "", "", vnf2Port)
+		if err == nil && cnpd.reconcileInProgress {
+			cnpd.reconcileAfter.sfcIDs[key] = *sfcID
+		}
+	}
+
+	return nil
+}
+
+// east/west veth type, each pair of containers is wired directly with a veth so the traffic never goes via
+// the vswitch, the containers of a pair must be on the same host
+func (cnpd *sfcCtlrL2CNPDriver) wireSfcEastWestVEthElements(sfc *controller.SfcEntity) error {
+
+	if len(sfc.GetElements())%2 != 0 {
+		err := fmt.Errorf("wireSfcEastWestVEthElements: e-w veth sfc should have pairs of entries: '%s'", sfc.Name)
+		log.Error(err.Error())
+		return err
+	}
+
+	for i := 0; i < len(sfc.Elements); i += 2 {
+
+		vnfElement1 := sfc.Elements[i]
+		vnfElement2 := sfc.Elements[i+1]
+
+		log.Infof("wireSfcEastWestVEthElements: sfc entity elements[%d,%d]: %v, %v", i, i+1,
+			utils.RedactSfcElement(vnfElement1), utils.RedactSfcElement(vnfElement2))
+
+		for _, vnfElement := range []*controller.SfcEntity_SfcElement{vnfElement1, vnfElement2} {
+			switch vnfElement.Type {
+			case controller.SfcElementType_EXTERNAL_ENTITY, controller.SfcElementType_HOST_ENTITY:
+				err := fmt.Errorf("wireSfcEastWestVEthElements: only containers allowed in e-w veth sfc: '%s'",
+					sfc.Name)
+				log.Error(err.Error())
+				return err
+			}
+		}
+		if vnfElement1.EtcdVppSwitchKey != vnfElement2.EtcdVppSwitchKey {
+			err := fmt.Errorf("wireSfcEastWestVEthElements: containers '%s' and '%s' are on different hosts, sfc: '%s'",
+				vnfElement1.Container, vnfElement2.Container, sfc.Name)
+			log.Error(err.Error())
+			return err
+		}
+
+		if err := cnpd.createInterContainerVEthPair(sfc, vnfElement1, vnfElement2); err != nil {
+			log.Errorf("wireSfcEastWestVEthElements: error creating veth pair: sfc: '%s', Containers: '%s'/'%s'",
+				sfc.Name, vnfElement1.Container, vnfElement2.Container)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// createInterContainerVEthPair creates a veth with one end in each of the containers, a vpp container gets an
+// af_packet on its end of the veth
+func (cnpd *sfcCtlrL2CNPDriver) createInterContainerVEthPair(sfc *controller.SfcEntity,
+	vnfElement1 *controller.SfcEntity_SfcElement, vnfElement2 *controller.SfcEntity_SfcElement) error {
+
+	log.Infof("createInterContainerVEthPair: sfc: '%s', vnf1: '%s'/'%s', vnf2: '%s'/'%s'", sfc.Name,
+		vnfElement1.Container, vnfElement1.PortLabel, vnfElement2.Container, vnfElement2.PortLabel)
+
+	mtu := cnpd.getMtu(vnfElement1.Mtu)
+
+	vnfElements := []*controller.SfcEntity_SfcElement{vnfElement1, vnfElement2}
+	sfcIDs := make([]*l2driver.SFCIDs, len(vnfElements))
+	vethIDs := make([]uint32, len(vnfElements))
+	vethNames := make([]string, len(vnfElements))
+
+	// both ends are named before either is created as each end refers to its peer, an end is named after its
+	// container, port and veth id like the vswitch i/fs as the veth is configured on the host
+	for i, vnfElement := range vnfElements {
+
+		sfcIDs[i], _ = cnpd.DatastoreSFCIDsRetrieve(sfc.Name, vnfElement.Container, vnfElement.PortLabel)
+		if sfcIDs[i] == nil || sfcIDs[i].VethId == 0 {
+			var err error
+			if vethIDs[i], err = cnpd.allocateVethID(
+				l2driver.SFCContainerPortIDsNameKey(sfc.Name, vnfElement.Container, vnfElement.PortLabel)); err != nil {
+				return err
+			}
+		} else {
+			vethIDs[i] = sfcIDs[i].VethId
+		}
+
+		vethNames[i] = vppIfName("IF_VETH_VNF_", vnfElement.Container, vnfElement.PortLabel, vethIDs[i])
+		if sfcIDs[i] != nil && cnpd.keepIfName(sfcIDs[i].ContainerIfName, "IF_VETH_VNF_", maxVppIfNameLen,
+			vnfElement.EtcdVppSwitchKey, sfcInterfaceOwner(sfc.Name, vnfElement.Container, vnfElement.PortLabel)) {
+			vethNames[i] = sfcIDs[i].ContainerIfName
+		}
+		if _, err := cnpd.sfcContainerIfNames(sfc.Name, vnfElement.Container, vnfElement.PortLabel,
+			vnfElement.EtcdVppSwitchKey, vethNames[i], true); err != nil {
+			return err
+		}
+	}
+
+	for i, vnfElement := range vnfElements {
+
+		sfcID := sfcIDs[i]
+
+		ipv4Address, ipID, err := cnpd.allocateSfcInterfaceIpv4Address(sfc, vnfElement, sfcID)
+		if err != nil {
+			return err
+		}
+		ipv6Address, ipv6ID, err := cnpd.allocateSfcInterfaceIpv6Address(sfc, vnfElement, sfcID)
+		if err != nil {
+			return err
+		}
+		macAddress, macAddrID, err := cnpd.allocateSfcInterfaceMacAddress(sfc, vnfElement, sfcID)
+		if err != nil {
+			return err
+		}
 
-			} else {
-				// l2xconnect -based wiring
-				memIfName, err := cnpd.createMemIfPair(sfc, sfcEntityElement.EtcdVppSwitchKey, sfcEntityElement,
-					false)
-				if err != nil {
-					log.Errorf("wireSfcEastWestElements: error creating memIf pair: sfc: '%s', Container: '%s'",
-						sfc.Name, sfcEntityElement.Container)
-					return err
-				}
-				if prevMemIfName != "" {
-					err = cnpd.createXConnectPair(sfcEntityElement.EtcdVppSwitchKey, memIfName, prevMemIfName)
-					prevMemIfName = ""
-					if err != nil {
-						return err
-					}
-				} else {
-					prevMemIfName = memIfName
-				}
+		// the veth is in the container's namespace, it is named by the port label in the container, a vpp
+		// container uses the veth via an af_packet which gets the addresses instead of the veth
+		isVppContainer := vnfElement.Type == controller.SfcElementType_VPP_CONTAINER_AFP ||
+			vnfElement.Type == controller.SfcElementType_VPP_CONTAINER_TAP ||
+			vnfElement.Type == controller.SfcElementType_VPP_CONTAINER_MEMIF
+		ipv4AddrForVEth := ipv4Address
+		ipv6AddrForVEth := ipv6Address
+		if isVppContainer {
+			ipv4AddrForVEth = ""
+			ipv6AddrForVEth = ""
+		}
+		if err := cnpd.vEthIfCreate(vnfElement1.EtcdVppSwitchKey, vethNames[i], utils.FormatLabels(sfc.Labels),
+			vnfElement.PortLabel, vethNames[1-i], vnfElement.Container, macAddress, ipv4AddrForVEth,
+			ipv6AddrForVEth, mtu); err != nil {
+			log.Errorf("createInterContainerVEthPair: error creating veth if '%s' for container: '%s'", vethNames[i],
+				vnfElement.Container)
+			return err
+		}
+		if err := cnpd.createContainerL3Entries(sfc, vnfElement); err != nil {
+			return err
+		}
+
+		if isVppContainer {
+			if _, err := cnpd.afPacketCreate(vnfElement.Container, vnfElement.PortLabel, utils.FormatLabels(sfc.Labels),
+				vnfElement.PortLabel, ipv4Address, macAddress, ipv6Address, mtu, vnfElement.RxMode, 0, nil); err != nil {
+				log.Errorf("createInterContainerVEthPair: error creating afpacket for container: '%s'",
+					vnfElement.Container)
+				return err
 			}
 		}
+
+		key, sfcID, err := cnpd.DatastoreSFCIDsCreate(sfc.Name, vnfElement.Container, vnfElement.PortLabel,
+			ipID, macAddrID, 0, vethIDs[i], ipv6ID, "", "", "", vethNames[i])
+		if err == nil && cnpd.reconcileInProgress {
+			cnpd.reconcileAfter.sfcIDs[key] = *sfcID
+		}
+
+		cnpd.setSfcInterfaceIPAndMac(vnfElement.Container, vnfElement.PortLabel, ipv4Address, macAddress,
+			ipv6Address)
 	}
 
 	return nil
 }
 
-// createOneOrMoreInterContainerMemIfPairs creates memif pair and returns vswitch-end memif interface name
-func (cnpd *sfcCtlrL2CNPDriver) createOneOrMoreInterContainerMemIfPairs(
-	sfcName string,
-	vnfElement1 *controller.SfcEntity_SfcElement,
-	vnfElement2 *controller.SfcEntity_SfcElement,
-	vnfRepeatCount uint32) error {
+// allocateSfcInterfaceIpv4Address returns the configured address of the element, or one allocated from the
+// sfc_ipv4_prefix (reusing the id from the db if the element already has one)
+func (cnpd *sfcCtlrL2CNPDriver) allocateSfcInterfaceIpv4Address(sfc *controller.SfcEntity,
+	vnfElement *controller.SfcEntity_SfcElement, sfcID *l2driver.SFCIDs) (string, uint32, error) {
 
-	log.Infof("createInterContainerMemIfPair: sfc: '%s', vnf1: '%s', vnf2: '%s', repeatCount: '%d'",
-		sfcName, vnfElement1.Container, vnfElement2.Container, vnfRepeatCount)
+	prefix := cnpd.sfcIpv4Prefix(sfc)
 
-	vnf1Port := ""
-	vnf2Port := ""
-	mtu := cnpd.getMtu(vnfElement1.Mtu)
-	rxMode := vnfElement1.RxMode
-	container1Name := ""
-	container2Name := ""
+	if vnfElement.Ipv4Addr != "" {
+		strs := strings.Split(vnfElement.Ipv4Addr, "/")
+		if prefix != "" {
+			cnpd.ipam.SetIpAddrIfInsideSubnet(sfc.Tenant, prefix, strs[0])
+		}
+		if len(strs) == 2 {
+			return vnfElement.Ipv4Addr, 0, nil
+		}
+		return vnfElement.Ipv4Addr + "/24", 0, nil
+	}
 
-	for repeatCount := uint32(0); repeatCount <= vnfRepeatCount; repeatCount++ {
+	if prefix == "" {
+		return "", 0, nil
+	}
+	var ipID uint32
+	if sfcID != nil {
+		ipID = sfcID.IpId
+	}
 
-		if repeatCount == 0 {
-			container1Name = vnfElement1.Container
-			vnf1Port = vnfElement1.PortLabel
-		} else {
-			container1Name = "vnfx-" + strconv.Itoa(int(repeatCount-1))
-			vnf1Port = vnfElement1.PortLabel
+	return cnpd.allocateFromSfcPrefix(sfc, vnfElement, prefix, ipID)
+}
+
+// allocateSfcInterfaceIpv6Address returns the configured ipv6 address of the element, or one allocated from the
+// sfc_ipv6_prefix (reusing the id from the db if the element already has one)
+func (cnpd *sfcCtlrL2CNPDriver) allocateSfcInterfaceIpv6Address(sfc *controller.SfcEntity,
+	vnfElement *controller.SfcEntity_SfcElement, sfcID *l2driver.SFCIDs) (string, uint32, error) {
+
+	prefix := cnpd.sfcIpv6Prefix(sfc)
+
+	if vnfElement.Ipv6Addr != "" {
+		if prefix != "" {
+			cnpd.ipam.SetIpAddrIfInsideSubnet(sfc.Tenant, prefix, strings.Split(vnfElement.Ipv6Addr, "/")[0])
 		}
-		if repeatCount == vnfRepeatCount {
-			container2Name = vnfElement2.Container
-			vnf2Port = vnfElement2.PortLabel
-		} else {
-			container2Name = "vnfx-" + strconv.Itoa(int(repeatCount))
-			vnf2Port = vnfElement2.PortLabel
+		return vnfElement.Ipv6Addr, 0, nil
+	}
+
+	if prefix == "" {
+		return "", 0, nil
+	}
+	var ipv6ID uint32
+	if sfcID != nil {
+		ipv6ID = sfcID.Ipv6Id
+	}
+
+	return cnpd.allocateFromSfcPrefix(sfc, vnfElement, prefix, ipv6ID)
+}
+
+// allocateFromSfcPrefix allocates an address for the element from the prefix and records the allocation in the
+// db, the id from the db is reused unless the prefix changed underneath it or it was given to someone else
+func (cnpd *sfcCtlrL2CNPDriver) allocateFromSfcPrefix(sfc *controller.SfcEntity,
+	vnfElement *controller.SfcEntity_SfcElement, prefix string, ipID uint32) (string, uint32, error) {
+
+	owner := sfcInterfaceOwner(sfc.Name, vnfElement.Container, vnfElement.PortLabel)
+
+	var ipAddress string
+	var err error
+	if ipID != 0 {
+		if ipAddress, err = cnpd.ipam.SetIpIDInSubnet(sfc.Tenant, prefix, ipID, owner); err != nil {
+			log.Infof("allocateFromSfcPrefix: cannot reuse ipID for '%s': %s", owner, err)
+			if cnpd.ipam.OwnerOfIpID(sfc.Tenant, prefix, ipID) == owner {
+				// ie the id is now reserved
+				cnpd.ipam.ReleaseIpIDInSubnet(sfc.Tenant, prefix, ipID)
+				cnpd.DatastoreIPAMAllocationDelete(sfc.Tenant, prefix, ipID)
+			}
+			ipID = 0
+		}
+	}
+	if ipID == 0 {
+		if ipAddress, ipID, err = cnpd.ipam.AllocateFromSubnet(sfc.Tenant, prefix, owner); err != nil {
+			return "", 0, err
 		}
+	}
 
-		var memifID uint32
+	key, alloc, err := cnpd.DatastoreIPAMAllocationCreate(sfc.Tenant, prefix, ipID, ipAddress, sfc.Name,
+		vnfElement.Container, vnfElement.PortLabel, "")
+	if err == nil && cnpd.reconcileInProgress {
+		cnpd.reconcileAfter.ipamAllocs[key] = *alloc
+	}
 
-		sfcID, _ := cnpd.DatastoreSFCIDsRetrieve(sfcName, container1Name, vnf1Port)
-		if sfcID == nil || sfcID.MemifId == 0 {
-			cnpd.seq.MemIfID++
-			memifID = cnpd.seq.MemIfID
-		} else {
-			memifID = sfcID.MemifId
+	return ipAddress, ipID, nil
+}
+
+// sfcIpv4Prefix returns the prefix the sfc allocates its ipv4 addresses from, ie its ipam pool's or its own
+func (cnpd *sfcCtlrL2CNPDriver) sfcIpv4Prefix(sfc *controller.SfcEntity) string {
+	if sfc.Ipv4IpamPool != "" {
+		return cnpd.ipamPoolPrefix(sfc.Ipv4IpamPool)
+	}
+	return sfc.SfcIpv4Prefix
+}
+
+// sfcIpv6Prefix returns the prefix the sfc allocates its ipv6 addresses from, ie its ipam pool's or its own
+func (cnpd *sfcCtlrL2CNPDriver) sfcIpv6Prefix(sfc *controller.SfcEntity) string {
+	if sfc.Ipv6IpamPool != "" {
+		return cnpd.ipamPoolPrefix(sfc.Ipv6IpamPool)
+	}
+	return sfc.SfcIpv6Prefix
+}
+
+func (cnpd *sfcCtlrL2CNPDriver) ipamPoolPrefix(poolName string) string {
+	for _, pool := range cnpd.l2CNPEntityCache.SysParms.GetIpamPools() {
+		if pool.Name == poolName {
+			return pool.Prefix
 		}
+	}
+	log.Errorf("ipamPoolPrefix: ipam pool not found: '%s'", poolName)
+	return ""
+}
 
-		// create a memif in the vnf container
-		if err := cnpd.createInterContainerMemIfPair(
-			sfcName,
-			container1Name, vnf1Port,
-			container2Name, vnf2Port,
-			mtu,
-			rxMode,
-			memifID); err != nil {
-			return err
+// sfcInterfaceOwner is the ipam owner of the addresses allocated to the container port of the sfc
+func sfcInterfaceOwner(sfcName string, container string, port string) string {
+	return sfcName + "/" + container + "/" + port
+}
+
+// releaseStaleSfcAddresses releases the addresses of a re-posted sfc which it no longer uses, ie the element is
+// gone, it now has a configured address, or the sfc's prefix changed, on startup the reconcile does this
+func (cnpd *sfcCtlrL2CNPDriver) releaseStaleSfcAddresses(sfc *controller.SfcEntity) {
+
+	if cnpd.reconcileInProgress {
+		return
+	}
+
+	ipv4Prefix := cnpd.sfcIpv4Prefix(sfc)
+	ipv6Prefix := cnpd.sfcIpv6Prefix(sfc)
+
+	cnpd.releaseSfcAddresses(sfc.Name, func(alloc *ipam.Allocation) bool {
+		if alloc.Tenant != sfc.Tenant {
+			return false
+		}
+		for _, vnfElement := range sfc.GetElements() {
+			if alloc.Owner != sfcInterfaceOwner(sfc.Name, vnfElement.Container, vnfElement.PortLabel) {
+				continue
+			}
+			if (alloc.Subnet == ipv4Prefix && vnfElement.Ipv4Addr == "") ||
+				(alloc.Subnet == ipv6Prefix && vnfElement.Ipv6Addr == "") {
+				return true
+			}
 		}
+		return false
+	})
+}
 
-		key, sfcID, err := cnpd.DatastoreSFCIDsCreate(sfcName, container1Name, vnf1Port,
-			0, 0, memifID, 0)
-		if err == nil && cnpd.reconcileInProgress {
-			cnpd.reconcileAfter.sfcIDs[key] = *sfcID
+// releaseSfcAddresses releases the addresses allocated to the interfaces of the sfc, except the ones to keep,
+// the allocations are looked up in the in memory ipam which holds the ones of the db since the reconcile
+func (cnpd *sfcCtlrL2CNPDriver) releaseSfcAddresses(sfcName string, keep func(alloc *ipam.Allocation) bool) {
+
+	for _, alloc := range cnpd.ipam.OwnerAllocations(sfcName + "/") {
+		if keep(&alloc) {
+			continue
 		}
+		log.Infof("releaseSfcAddresses: releasing '%s' of '%s'", alloc.IpAddress, alloc.Owner)
+		cnpd.ipam.ReleaseIpIDInSubnet(alloc.Tenant, alloc.Subnet, alloc.IpID)
+		cnpd.DatastoreIPAMAllocationDelete(alloc.Tenant, alloc.Subnet, alloc.IpID)
 	}
+}
 
-	return nil
+// ReleaseSfcEntity releases the addresses, ids, macs, interface names and memif secrets allocated to the deleted
+// sfc
+func (cnpd *sfcCtlrL2CNPDriver) ReleaseSfcEntity(sfc *controller.SfcEntity) error {
+
+	log.Infof("ReleaseSfcEntity: releasing sfc: '%s'", sfc.Name)
+
+	cnpd.releaseSfcAddresses(sfc.Name, func(alloc *ipam.Allocation) bool {
+		return false
+	})
+	cnpd.releaseMacAddresses(sfcMacOwnerPrefix(sfc.Name))
+	cnpd.releaseSfcIfNames(sfc.Name + "/")
+	if err := cnpd.DatastoreMemifSecretsDeleteAll(l2driver.MemifSecretsSfcKeyPrefix(sfc.Name)); err != nil {
+		return err
+	}
+
+	return cnpd.releaseSfcIDs(sfc.Name)
+}
+
+// allocateSfcInterfaceMacAddress returns the configured mac of the element, or one generated by the mac scheme
+// (reusing the id from the db if the element already has one), the mac must not be on another interface
+func (cnpd *sfcCtlrL2CNPDriver) allocateSfcInterfaceMacAddress(sfc *controller.SfcEntity,
+	vnfElement *controller.SfcEntity_SfcElement, sfcID *l2driver.SFCIDs) (string, uint32, error) {
+
+	owner := sfcMacOwner(sfc.Name, vnfElement.Container, vnfElement.PortLabel)
+
+	if vnfElement.MacAddr != "" {
+		return vnfElement.MacAddr, 0, cnpd.reserveMacAddress(vnfElement.MacAddr, owner)
+	}
+	if cnpd.hashMacScheme() {
+		macAddress, err := cnpd.reserveHashMacAddress(sfc.Tenant+"/"+owner, owner)
+		return macAddress, 0, err
+	}
+	var macAddrID uint32
+	if sfcID != nil {
+		macAddrID = sfcID.MacAddrId
+	}
+
+	return cnpd.allocateMacAddress(sfc.Tenant,
+		l2driver.SFCContainerPortIDsNameKey(sfc.Name, vnfElement.Container, vnfElement.PortLabel), macAddrID, owner)
 }
 
 // createInterContainerMemIfPair creates memif pair and returns vswitch-end memif interface name
 func (cnpd *sfcCtlrL2CNPDriver) createInterContainerMemIfPair(
 	sfcName string,
+	description string,
 	vnf1Container string, vnf1Port string,
 	vnf2Container string, vnf2Port string,
 	mtu uint32,
 	rxMode controller.RxModeType,
+	memifParms *controller.MemifParms,
+	socketFilename string,
 	memIFID uint32) error {
 
 	log.Infof("createInterContainerMemIfPair: vnf1: '%s'/'%s', vnf2: '%s'/'%s', memIfID: '%d'",
 		vnf1Container, vnf1Port, vnf2Container, vnf2Port, memIFID)
 
 	// create a memif in the vnf container 1
-	if _, err := cnpd.memIfCreate(vnf1Container, vnf1Port, memIFID, true, vnf1Container,
-		"", "", "", mtu, rxMode); err != nil {
+	if _, err := cnpd.memIfCreate(vnf1Container, vnf1Port, description, memIFID, true, socketFilename,
+		"", "", "", mtu, rxMode, memifParms, 0, nil); err != nil {
 		log.Errorf("createInterContainerMemIfPair: error creating memIf for container: '%s'/'%s', memIF: '%d'",
 			vnf1Container, vnf1Port, memIFID)
 		return err
 	}
 
 	// create a memif in the vnf container 2
-	if _, err := cnpd.memIfCreate(vnf2Container, vnf2Port, memIFID, false, vnf1Container,
-		"", "", "", mtu, rxMode); err != nil {
+	if _, err := cnpd.memIfCreate(vnf2Container, vnf2Port, description, memIFID, false, socketFilename,
+		"", "", "", mtu, rxMode, memifParms, 0, nil); err != nil {
 
 		log.Errorf("createInterContainerMemIfPair: error creating memIf for container: '%s'/'%s', memIF: '%d'",
 			vnf1Container, vnf1Port, memIFID)
@@ -1326,7 +2558,7 @@ func (cnpd *sfcCtlrL2CNPDriver) createInterContainerMemIfPair(
 
 // createMemIfPair creates memif pair and returns vswitch-end memif interface name
 func (cnpd *sfcCtlrL2CNPDriver) createMemIfPair(sfc *controller.SfcEntity, hostName string,
-	vnfChainElement *controller.SfcEntity_SfcElement, generateAddresses bool) (string, error) {
+	vnfChainElement *controller.SfcEntity_SfcElement, generateAddresses bool, vrfID uint32) (string, error) {
 
 	log.Infof("createMemIfPair: vnf: '%s', host: '%s'", vnfChainElement.Container, hostName)
 
@@ -1334,10 +2566,12 @@ func (cnpd *sfcCtlrL2CNPDriver) createMemIfPair(sfc *controller.SfcEntity, hostN
 	var macAddrID uint32
 	var ipID uint32
 
+	sfcIDKey := l2driver.SFCContainerPortIDsNameKey(sfc.Name, vnfChainElement.Container, vnfChainElement.PortLabel)
 	sfcID, err := cnpd.DatastoreSFCIDsRetrieve(sfc.Name, vnfChainElement.Container, vnfChainElement.PortLabel)
 	if sfcID == nil || sfcID.MemifId == 0 {
-		cnpd.seq.MemIfID++
-		memifID = cnpd.seq.MemIfID
+		if memifID, err = cnpd.allocateMemifID(sfcIDKey); err != nil {
+			return "", err
+		}
 	} else {
 		memifID = sfcID.MemifId
 	}
@@ -1346,80 +2580,73 @@ func (cnpd *sfcCtlrL2CNPDriver) createMemIfPair(sfc *controller.SfcEntity, hostN
 	var ipv4Address string
 
 	// the sfc controller can generate addresses if not provided
-	if vnfChainElement.Ipv4Addr == "" {
-		if generateAddresses {
-			if sfc.SfcIpv4Prefix != "" {
-				if sfcID == nil || sfcID.IpId == 0 {
-					ipv4Address, ipID, err = ipam.AllocateFromSubnet(sfc.SfcIpv4Prefix)
-					if err != nil {
-						return "", err
-					}
-				} else {
-					ipv4Address, err = ipam.SetIpIDInSubnet(sfc.SfcIpv4Prefix, sfcID.IpId)
-					if err != nil {
-						return "", err
-					}
-					ipID = sfcID.IpId
-				}
-			}
-		}
-	} else {
-		strs := strings.Split(vnfChainElement.Ipv4Addr, "/")
-		if len(strs) == 2 {
-			ipv4Address = vnfChainElement.Ipv4Addr
-		} else {
-			ipv4Address = vnfChainElement.Ipv4Addr + "/24"
-		}
-		if sfc.SfcIpv4Prefix != "" {
-			ipam.SetIpAddrIfInsideSubnet(sfc.SfcIpv4Prefix, strs[0])
+	if vnfChainElement.Ipv4Addr != "" || generateAddresses {
+		if ipv4Address, ipID, err = cnpd.allocateSfcInterfaceIpv4Address(sfc, vnfChainElement, sfcID); err != nil {
+			return "", err
 		}
 	}
-	if sfc.SfcIpv4Prefix != "" {
-		log.Info("createMemIfPair: ", ipam.DumpSubnet(sfc.SfcIpv4Prefix), ipv4Address)
+	if prefix := cnpd.sfcIpv4Prefix(sfc); prefix != "" {
+		log.Info("createMemIfPair: ", cnpd.ipam.DumpSubnet(sfc.Tenant, prefix), ipv4Address)
 	}
 
-	if vnfChainElement.MacAddr == "" {
-		if generateAddresses {
-			if sfcID == nil || sfcID.MacAddrId == 0 {
-				cnpd.seq.MacInstanceID++
-				macAddress = formatMacAddress(cnpd.seq.MacInstanceID)
-				macAddrID = cnpd.seq.MacInstanceID
-			} else {
-				macAddress = formatMacAddress(sfcID.MacAddrId)
-				macAddrID = sfcID.MacAddrId
-			}
+	var ipv6Address string
+	var ipv6ID uint32
+	if vnfChainElement.Ipv6Addr != "" || generateAddresses {
+		if ipv6Address, ipv6ID, err = cnpd.allocateSfcInterfaceIpv6Address(sfc, vnfChainElement, sfcID); err != nil {
+			return "", err
+		}
+	}
+
+	if vnfChainElement.MacAddr != "" || generateAddresses {
+		if macAddress, macAddrID, err = cnpd.allocateSfcInterfaceMacAddress(sfc, vnfChainElement, sfcID); err != nil {
+			return "", err
 		}
-	} else {
-		macAddress = vnfChainElement.MacAddr
 	}
 
 	mtu := cnpd.getMtu(vnfChainElement.Mtu)
 	rxMode := vnfChainElement.RxMode
 
+	memifParms, err := cnpd.memifParms(sfc.Name, vnfChainElement.Container, vnfChainElement.PortLabel,
+		vnfChainElement)
+	if err != nil {
+		return "", err
+	}
+	socketFilename, err := cnpd.memifSocketFilename(sfc.Name, vnfChainElement.EtcdVppSwitchKey,
+		vnfChainElement.EtcdVppSwitchKey, memifID)
+	if err != nil {
+		return "", err
+	}
+
 	// create a memif in the vnf container
 	memIfName := vnfChainElement.PortLabel
-	if _, err := cnpd.memIfCreate(vnfChainElement.Container, memIfName, memifID, false, vnfChainElement.EtcdVppSwitchKey,
-		ipv4Address, macAddress, vnfChainElement.Ipv6Addr, mtu, rxMode); err != nil {
+	if _, err := cnpd.memIfCreate(vnfChainElement.Container, memIfName, utils.FormatLabels(sfc.Labels), memifID, false, socketFilename,
+		ipv4Address, macAddress, ipv6Address, mtu, rxMode, memifParms, 0, nil); err != nil {
 		log.Errorf("createMemIfPair: error creating memIf for container: '%s'", memIfName)
 		return "", err
 	}
 
 	// now create a memif for the vpp switch
-	memIfName = "IF_MEMIF_VSWITCH_" + vnfChainElement.Container + "_" + vnfChainElement.PortLabel
-	memIf, err := cnpd.memIfCreate(vnfChainElement.EtcdVppSwitchKey, memIfName, memifID,
-		true, vnfChainElement.EtcdVppSwitchKey, "", "", "", mtu, rxMode)
+	ifNames, err := cnpd.sfcVswitchIfNames(sfc, vnfChainElement, sfcID, "IF_MEMIF_VSWITCH_", memifID, 0)
+	if err != nil {
+		return "", err
+	}
+	memIfName = ifNames.VswitchIfName
+	memIf, err := cnpd.memIfCreate(vnfChainElement.EtcdVppSwitchKey, memIfName, utils.FormatLabels(sfc.Labels), memifID,
+		true, socketFilename, "", "", "", mtu, rxMode, memifParms, vrfID,
+		vswitchUnnumbered(vnfChainElement.Unnumbered, vnfChainElement.EtcdVppSwitchKey))
 	if err != nil {
 		log.Errorf("createMemIfPair: error creating memIf for vpp switch: '%s'", memIf.Name)
 		return "", err
 	}
 
 	key, sfcID, err := cnpd.DatastoreSFCIDsCreate(sfc.Name, vnfChainElement.Container, vnfChainElement.PortLabel,
-		ipID, macAddrID, memifID, 0)
+		ipID, macAddrID, memifID, 0, ipv6ID, ifNames.VswitchIfName, "", socketFilename, "")
 	if err == nil && cnpd.reconcileInProgress {
 		cnpd.reconcileAfter.sfcIDs[key] = *sfcID
 	}
 
-	cnpd.setSfcInterfaceIPAndMac(vnfChainElement.Container, vnfChainElement.PortLabel, ipv4Address, macAddress)
+	cnpd.setSfcInterfaceIPAndMac(vnfChainElement.Container, vnfChainElement.PortLabel, ipv4Address, macAddress,
+		ipv6Address)
 
 	return memIfName, err
 }
@@ -1429,7 +2656,7 @@ func (cnpd *sfcCtlrL2CNPDriver) createMemIfPairAndAddToBridge(sfc *controller.Sf
 	bd *l2.BridgeDomains_BridgeDomain, vnfChainElement *controller.SfcEntity_SfcElement,
 	generateAddresses bool) (string, error) {
 
-	memIfName, err := cnpd.createMemIfPair(sfc, hostName, vnfChainElement, generateAddresses)
+	memIfName, err := cnpd.createMemIfPair(sfc, hostName, vnfChainElement, generateAddresses, 0)
 	if err != nil {
 		return "", err
 	}
@@ -1449,7 +2676,7 @@ func (cnpd *sfcCtlrL2CNPDriver) createMemIfPairAndAddToBridge(sfc *controller.Sf
 }
 
 func (cnpd *sfcCtlrL2CNPDriver) createAFPacketVEthPair(sfc *controller.SfcEntity,
-	vnfChainElement *controller.SfcEntity_SfcElement) (string, error) {
+	vnfChainElement *controller.SfcEntity_SfcElement, vrfID uint32) (string, error) {
 
 	log.Infof("createAFPacketVEthPair: vnf: '%s', host: '%s'", vnfChainElement.Container,
 		vnfChainElement.EtcdVppSwitchKey)
@@ -1460,58 +2687,31 @@ func (cnpd *sfcCtlrL2CNPDriver) createAFPacketVEthPair(sfc *controller.SfcEntity
 	var macAddress string
 	var ipv4Address string
 
-	ipv6Address := vnfChainElement.Ipv6Addr
-
+	sfcIDKey := l2driver.SFCContainerPortIDsNameKey(sfc.Name, vnfChainElement.Container, vnfChainElement.PortLabel)
 	sfcID, err := cnpd.DatastoreSFCIDsRetrieve(sfc.Name, vnfChainElement.Container, vnfChainElement.PortLabel)
 
 	if sfcID == nil || sfcID.VethId == 0 {
-		cnpd.seq.VethID++
-		vethID = cnpd.seq.VethID
+		if vethID, err = cnpd.allocateVethID(sfcIDKey); err != nil {
+			return "", err
+		}
 	} else {
 		vethID = sfcID.VethId
 	}
 
-	if vnfChainElement.Ipv4Addr == "" {
-		if sfc.SfcIpv4Prefix != "" {
-			if sfcID == nil || sfcID.IpId == 0 {
-				ipv4Address, ipID, err = ipam.AllocateFromSubnet(sfc.SfcIpv4Prefix)
-				if err != nil {
-					return "", err
-				}
-			} else {
-				ipv4Address, err = ipam.SetIpIDInSubnet(sfc.SfcIpv4Prefix, sfcID.IpId)
-				if err != nil {
-					return "", err
-				}
-				ipID = sfcID.IpId
-			}
-		}
-	} else {
-		strs := strings.Split(vnfChainElement.Ipv4Addr, "/")
-		if len(strs) == 2 {
-			ipv4Address = vnfChainElement.Ipv4Addr
-		} else {
-			ipv4Address = vnfChainElement.Ipv4Addr + "/24"
-		}
-		if sfc.SfcIpv4Prefix != "" {
-			ipam.SetIpAddrIfInsideSubnet(sfc.SfcIpv4Prefix, strs[0])
-		}
+	if ipv4Address, ipID, err = cnpd.allocateSfcInterfaceIpv4Address(sfc, vnfChainElement, sfcID); err != nil {
+		return "", err
 	}
-	if sfc.SfcIpv4Prefix != "" {
-		log.Info("createAFPacketVEthPair: ", ipam.DumpSubnet(sfc.SfcIpv4Prefix), ipv4Address)
+	if prefix := cnpd.sfcIpv4Prefix(sfc); prefix != "" {
+		log.Info("createAFPacketVEthPair: ", cnpd.ipam.DumpSubnet(sfc.Tenant, prefix), ipv4Address)
 	}
 
-	if vnfChainElement.MacAddr == "" {
-		if sfcID == nil || sfcID.MacAddrId == 0 {
-			cnpd.seq.MacInstanceID++
-			macAddress = formatMacAddress(cnpd.seq.MacInstanceID)
-			macAddrID = cnpd.seq.MacInstanceID
-		} else {
-			macAddress = formatMacAddress(sfcID.MacAddrId)
-			macAddrID = sfcID.MacAddrId
-		}
-	} else {
-		macAddress = vnfChainElement.MacAddr
+	ipv6Address, ipv6ID, err := cnpd.allocateSfcInterfaceIpv6Address(sfc, vnfChainElement, sfcID)
+	if err != nil {
+		return "", err
+	}
+
+	if macAddress, macAddrID, err = cnpd.allocateSfcInterfaceMacAddress(sfc, vnfChainElement, sfcID); err != nil {
+		return "", err
 	}
 
 	mtu := cnpd.getMtu(vnfChainElement.Mtu)
@@ -1521,16 +2721,18 @@ func (cnpd *sfcCtlrL2CNPDriver) createAFPacketVEthPair(sfc *controller.SfcEntity
 	// Note: In Linux kernel the length of an interface name is limited by the constant IFNAMSIZ.
 	//       In most distributions this is 16 characters including the terminating NULL character.
 	//		 The hostname uses chars from the container, and port name plus a unique id base 36
-	//       for a total of at most 15 chars. 3 chars for base36 given 36x36x36 = lots of interfaces
+	//       for a total of at most 15 chars, see ifnames.go
+
+	ifNames, err := cnpd.sfcVswitchIfNames(sfc, vnfChainElement, sfcID, "IF_AFPIF_VSWITCH_", vethID, vethID)
+	if err != nil {
+		return "", err
+	}
 
-	veth1Name := "IF_VETH_VNF_" + vnfChainElement.Container + "_" + vnfChainElement.PortLabel
-	veth2Name := "IF_VETH_VSWITCH_" + vnfChainElement.Container + "_" + vnfChainElement.PortLabel
+	veth1Name := vppIfName("IF_VETH_VNF_", vnfChainElement.Container, vnfChainElement.PortLabel, vethID)
+	veth2Name := vppIfName("IF_VETH_VSWITCH_", vnfChainElement.Container, vnfChainElement.PortLabel, vethID)
 
 	host1Name := vnfChainElement.PortLabel
-
-	vethIDStr := strconv.FormatUint(uint64(vethID), 36)
-	baseHostName := constructBaseHostName(vnfChainElement.Container, vnfChainElement.PortLabel, vethIDStr)
-	host2Name := baseHostName + "_" + vethIDStr
+	host2Name := ifNames.HostIfName
 
 	ipv4AddrForVEth := ipv4Address
 	ipv4AddrForAFP := ipv4Address
@@ -1540,72 +2742,169 @@ func (cnpd *sfcCtlrL2CNPDriver) createAFPacketVEthPair(sfc *controller.SfcEntity
 		ipv4AddrForVEth = ""
 		ipv6AddrForVEth = ""
 	}
-	// Configure the VETH interface for the VNF end
-	if err := cnpd.vEthIfCreate(vnfChainElement.EtcdVppSwitchKey, veth1Name, host1Name, veth2Name,
-		vnfChainElement.Container, macAddress, ipv4AddrForVEth, ipv6AddrForVEth, mtu); err != nil {
-		log.Errorf("createAFPacketVEthPair: error creating veth if '%s' for container: '%s'", veth1Name,
-			vnfChainElement.Container)
+	// Configure the VETH interface for the VNF end
+	if err := cnpd.vEthIfCreate(vnfChainElement.EtcdVppSwitchKey, veth1Name, utils.FormatLabels(sfc.Labels), host1Name, veth2Name,
+		vnfChainElement.Container, macAddress, ipv4AddrForVEth, ipv6AddrForVEth, mtu); err != nil {
+		log.Errorf("createAFPacketVEthPair: error creating veth if '%s' for container: '%s'", veth1Name,
+			vnfChainElement.Container)
+		return "", err
+	}
+	if err := cnpd.createContainerL3Entries(sfc, vnfChainElement); err != nil {
+		return "", err
+	}
+	// Configure the VETH interface for the VSWITCH end
+	if err := cnpd.vEthIfCreate(vnfChainElement.EtcdVppSwitchKey, veth2Name, utils.FormatLabels(sfc.Labels), host2Name, veth1Name,
+		vnfChainElement.EtcdVppSwitchKey, "", "", "", mtu); err != nil {
+		log.Errorf("createAFPacketVEthPair: error creating veth if '%s' for container: '%s'", veth2Name,
+			vnfChainElement.EtcdVppSwitchKey)
+		return "", err
+	}
+	// create af_packet for the vnf -end of the veth
+	if vnfChainElement.Type == controller.SfcElementType_VPP_CONTAINER_AFP {
+		afPktIf1, err := cnpd.afPacketCreate(vnfChainElement.Container, vnfChainElement.PortLabel, utils.FormatLabels(sfc.Labels),
+			host1Name, ipv4AddrForAFP, macAddress, ipv6AddrForAFP, mtu, rxMode, 0, nil)
+		if err != nil {
+			log.Errorf("createAFPacketVEthPair: error creating afpacket for vpp switch: '%s'", afPktIf1.Name)
+			return "", err
+		}
+	}
+	// create af_packet for the vswitch -end of the veth
+	afPktName := ifNames.VswitchIfName
+	afPktIf2, err := cnpd.afPacketCreate(vnfChainElement.EtcdVppSwitchKey, afPktName, utils.FormatLabels(sfc.Labels), host2Name,
+		"", "", "", mtu, rxMode, vrfID, vswitchUnnumbered(vnfChainElement.Unnumbered, vnfChainElement.EtcdVppSwitchKey))
+	if err != nil {
+		log.Errorf("createAFPacketVEthPair: error creating afpacket for vpp switch: '%s'", afPktIf2.Name)
+		return "", err
+	}
+
+	key, sfcID, err := cnpd.DatastoreSFCIDsCreate(sfc.Name, vnfChainElement.Container, vnfChainElement.PortLabel,
+		ipID, macAddrID, 0, vethID, ipv6ID, ifNames.VswitchIfName, ifNames.HostIfName, "", "")
+	if err == nil && cnpd.reconcileInProgress {
+		cnpd.reconcileAfter.sfcIDs[key] = *sfcID
+	}
+
+	cnpd.setSfcInterfaceIPAndMac(vnfChainElement.Container, vnfChainElement.PortLabel, ipv4Address, macAddress,
+		ipv6Address)
+
+	return afPktIf2.Name, nil
+}
+
+// createTapPair creates a tap on the vswitch whose host end is the container's port in the container's named
+// netns, the container's vpp uses the port via an af_packet which gets the addresses, mac and mtu.  A non vpp
+// container cannot use a tap as the vpp-agent cannot configure the container end of a tap, see validate.go.
+func (cnpd *sfcCtlrL2CNPDriver) createTapPair(sfc *controller.SfcEntity,
+	vnfChainElement *controller.SfcEntity_SfcElement, vrfID uint32) (string, error) {
+
+	log.Infof("createTapPair: vnf: '%s', host: '%s'", vnfChainElement.Container,
+		vnfChainElement.EtcdVppSwitchKey)
+
+	var macAddrID uint32
+	var vethID uint32
+	var ipID uint32
+	var macAddress string
+	var ipv4Address string
+
+	sfcIDKey := l2driver.SFCContainerPortIDsNameKey(sfc.Name, vnfChainElement.Container, vnfChainElement.PortLabel)
+	sfcID, err := cnpd.DatastoreSFCIDsRetrieve(sfc.Name, vnfChainElement.Container, vnfChainElement.PortLabel)
+
+	// the tap is a kernel interface like a veth so its unique id is a veth id
+	if sfcID == nil || sfcID.VethId == 0 {
+		if vethID, err = cnpd.allocateVethID(sfcIDKey); err != nil {
+			return "", err
+		}
+	} else {
+		vethID = sfcID.VethId
+	}
+
+	if ipv4Address, ipID, err = cnpd.allocateSfcInterfaceIpv4Address(sfc, vnfChainElement, sfcID); err != nil {
+		return "", err
+	}
+	if prefix := cnpd.sfcIpv4Prefix(sfc); prefix != "" {
+		log.Info("createTapPair: ", cnpd.ipam.DumpSubnet(sfc.Tenant, prefix), ipv4Address)
+	}
+
+	ipv6Address, ipv6ID, err := cnpd.allocateSfcInterfaceIpv6Address(sfc, vnfChainElement, sfcID)
+	if err != nil {
+		return "", err
+	}
+
+	if macAddress, macAddrID, err = cnpd.allocateSfcInterfaceMacAddress(sfc, vnfChainElement, sfcID); err != nil {
 		return "", err
 	}
-	// Configure the VETH interface for the VSWITCH end
-	if err := cnpd.vEthIfCreate(vnfChainElement.EtcdVppSwitchKey, veth2Name, host2Name, veth1Name,
-		vnfChainElement.EtcdVppSwitchKey, "", "", "", mtu); err != nil {
-		log.Errorf("createAFPacketVEthPair: error creating veth if '%s' for container: '%s'", veth2Name,
-			vnfChainElement.EtcdVppSwitchKey)
+
+	mtu := cnpd.getMtu(vnfChainElement.Mtu)
+	rxMode := vnfChainElement.RxMode
+
+	// the host end of the tap is named by the port label in the container's namespace so only the vswitch
+	// end needs a name unique on the host
+	ifNames, err := cnpd.sfcVswitchIfNames(sfc, vnfChainElement, sfcID, "IF_TAP_VSWITCH_", vethID, 0)
+	if err != nil {
 		return "", err
 	}
-	// create af_packet for the vnf -end of the veth
-	if vnfChainElement.Type == controller.SfcElementType_VPP_CONTAINER_AFP {
-		afPktIf1, err := cnpd.afPacketCreate(vnfChainElement.Container, vnfChainElement.PortLabel,
-			host1Name, ipv4AddrForAFP, macAddress, ipv6AddrForAFP, mtu, rxMode)
-		if err != nil {
-			log.Errorf("createAFPacketVEthPair: error creating afpacket for vpp switch: '%s'", afPktIf1.Name)
-			return "", err
-		}
+
+	// create af_packet for the vnf -end of the tap
+	if _, err := cnpd.afPacketCreate(vnfChainElement.Container, vnfChainElement.PortLabel, utils.FormatLabels(sfc.Labels),
+		vnfChainElement.PortLabel, ipv4Address, macAddress, ipv6Address, mtu, rxMode, 0, nil); err != nil {
+		log.Errorf("createTapPair: error creating afpacket for container: '%s'", vnfChainElement.Container)
+		return "", err
 	}
-	// create af_packet for the vswitch -end of the veth
-	afPktName := "IF_AFPIF_VSWITCH_" + vnfChainElement.Container + "_" + vnfChainElement.PortLabel
-	afPktIf2, err := cnpd.afPacketCreate(vnfChainElement.EtcdVppSwitchKey, afPktName, host2Name,
-		"", "", "", mtu, rxMode)
+	// create the tap on the vswitch, vpp moves its host end into the container's netns
+	tapIf, err := cnpd.tapCreate(vnfChainElement.EtcdVppSwitchKey, ifNames.VswitchIfName, utils.FormatLabels(sfc.Labels),
+		vnfChainElement.PortLabel, vnfChainElement.TapNamespace, mtu, rxMode, vrfID,
+		vswitchUnnumbered(vnfChainElement.Unnumbered, vnfChainElement.EtcdVppSwitchKey))
 	if err != nil {
-		log.Errorf("createAFPacketVEthPair: error creating afpacket for vpp switch: '%s'", afPktIf2.Name)
+		log.Errorf("createTapPair: error creating tap for vpp switch: '%s'", ifNames.VswitchIfName)
 		return "", err
 	}
 
 	key, sfcID, err := cnpd.DatastoreSFCIDsCreate(sfc.Name, vnfChainElement.Container, vnfChainElement.PortLabel,
-		ipID, macAddrID, 0, vethID)
+		ipID, macAddrID, 0, vethID, ipv6ID, ifNames.VswitchIfName, "", "", "")
 	if err == nil && cnpd.reconcileInProgress {
 		cnpd.reconcileAfter.sfcIDs[key] = *sfcID
 	}
 
-	cnpd.setSfcInterfaceIPAndMac(vnfChainElement.Container, vnfChainElement.PortLabel, ipv4Address, macAddress)
+	cnpd.setSfcInterfaceIPAndMac(vnfChainElement.Container, vnfChainElement.PortLabel, ipv4Address, macAddress,
+		ipv6Address)
 
-	return afPktIf2.Name, nil
+	return tapIf.Name, nil
+}
+
+// createVEthOrTapPair wires the container's port to the vswitch with a tap or an af_packet and veth pair
+// depending on the type of the element
+func (cnpd *sfcCtlrL2CNPDriver) createVEthOrTapPair(sfc *controller.SfcEntity,
+	vnfChainElement *controller.SfcEntity_SfcElement, vrfID uint32) (string, error) {
+
+	switch vnfChainElement.Type {
+	case controller.SfcElementType_VPP_CONTAINER_TAP:
+		return cnpd.createTapPair(sfc, vnfChainElement, vrfID)
+	default:
+		return cnpd.createAFPacketVEthPair(sfc, vnfChainElement, vrfID)
+	}
 }
 
-func (cnpd *sfcCtlrL2CNPDriver) createAFPacketVEthPairAndAddToBridge(sfc *controller.SfcEntity,
+func (cnpd *sfcCtlrL2CNPDriver) createVEthOrTapPairAndAddToBridge(sfc *controller.SfcEntity,
 	bd *l2.BridgeDomains_BridgeDomain, vnfChainElement *controller.SfcEntity_SfcElement) (string, error) {
 
-	log.Infof("createAFPacketVEthPairAndAddToBridge: vnf: '%s', host: '%s'", vnfChainElement.Container,
+	log.Infof("createVEthOrTapPairAndAddToBridge: vnf: '%s', host: '%s'", vnfChainElement.Container,
 		vnfChainElement.EtcdVppSwitchKey)
 
-	afPktIfName, err := cnpd.createAFPacketVEthPair(sfc, vnfChainElement)
+	ifName, err := cnpd.createVEthOrTapPair(sfc, vnfChainElement, 0)
 	if err != nil {
 		return "", err
 	}
 
 	ifEntry := l2.BridgeDomains_BridgeDomain_Interfaces{
-		Name: afPktIfName,
+		Name: ifName,
 	}
 	ifs := make([]*l2.BridgeDomains_BridgeDomain_Interfaces, 1)
 	ifs[0] = &ifEntry
 
 	if err := cnpd.bridgedDomainAssociateWithIfs(vnfChainElement.EtcdVppSwitchKey, bd, ifs); err != nil {
-		log.Errorf("createAFPacketVEthPairAndAddToBridge: error creating BD: '%s'", bd.Name)
+		log.Errorf("createVEthOrTapPairAndAddToBridge: error creating BD: '%s'", bd.Name)
 		return "", err
 	}
 
-	return afPktIfName, nil
+	return ifName, nil
 }
 
 func (cnpd *sfcCtlrL2CNPDriver) bridgedDomainCreateWithIfs(etcdVppSwitchKey string, bdName string,
@@ -1679,16 +2978,47 @@ func (cnpd *sfcCtlrL2CNPDriver) bridgedDomainAssociateWithIfs(etcdVppSwitchKey s
 	return nil
 }
 
-func (cnpd *sfcCtlrL2CNPDriver) vxLanCreate(etcdVppSwitchKey string, ifname string, vni uint32,
+// bridgedDomainSetActiveIfs makes the active ifs the only ones of the ifs in the bridge, the standby ifs are
+// removed from it, and the active ifs already in it get the split horizon group of the provided ones
+func (cnpd *sfcCtlrL2CNPDriver) bridgedDomainSetActiveIfs(etcdVppSwitchKey string,
+	bd *l2.BridgeDomains_BridgeDomain, activeIfs []*l2.BridgeDomains_BridgeDomain_Interfaces,
+	standbyIfNames []string) error {
+
+	ifs := make([]*l2.BridgeDomains_BridgeDomain_Interfaces, 0, len(bd.Interfaces))
+	for _, bi := range bd.Interfaces {
+		replaced := false
+		for _, ifName := range standbyIfNames {
+			if bi.Name == ifName {
+				replaced = true
+				break
+			}
+		}
+		for _, activeIf := range activeIfs {
+			if bi.Name == activeIf.Name {
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			ifs = append(ifs, bi)
+		}
+	}
+	bd.Interfaces = ifs
+
+	return cnpd.bridgedDomainAssociateWithIfs(etcdVppSwitchKey, bd, activeIfs)
+}
+
+func (cnpd *sfcCtlrL2CNPDriver) vxLanCreate(etcdVppSwitchKey string, ifname string, description string, vni uint32,
 	srcStr string, dstStr string) (*interfaces.Interfaces_Interface, error) {
 
 	src := stripSlashAndSubnetIpv4Address(srcStr)
 	dst := stripSlashAndSubnetIpv4Address(dstStr)
 
 	iface := &interfaces.Interfaces_Interface{
-		Name:    ifname,
-		Type:    interfaces.InterfaceType_VXLAN_TUNNEL,
-		Enabled: true,
+		Name:        ifname,
+		Description: description,
+		Type:        interfaces.InterfaceType_VXLAN_TUNNEL,
+		Enabled:     true,
 		Vxlan: &interfaces.Interfaces_Interface_Vxlan{
 			SrcAddress: src,
 			DstAddress: dst,
@@ -1736,21 +3066,31 @@ func constructIpv4AndV6AddressArray(ipv4 string, ipv6 string) []string {
 	return ipAddrArray
 }
 
-func (cnpd *sfcCtlrL2CNPDriver) memIfCreate(etcdPrefix string, memIfName string, memifID uint32, isMaster bool,
-	masterContainer string, ipv4 string, macAddress string, ipv6 string, mtu uint32,
-	rxMode controller.RxModeType) (*interfaces.Interfaces_Interface, error) {
+func (cnpd *sfcCtlrL2CNPDriver) memIfCreate(etcdPrefix string, memIfName string, description string, memifID uint32, isMaster bool,
+	socketFilename string, ipv4 string, macAddress string, ipv6 string, mtu uint32,
+	rxMode controller.RxModeType, memifParms *controller.MemifParms, vrfID uint32,
+	unnumbered *interfaces.Interfaces_Interface_Unnumbered) (*interfaces.Interfaces_Interface, error) {
 
 	memIf := &interfaces.Interfaces_Interface{
 		Name:        memIfName,
+		Description: description,
 		Type:        interfaces.InterfaceType_MEMORY_INTERFACE,
 		Enabled:     true,
 		PhysAddress: macAddress,
 		Mtu:         mtu,
 		IpAddresses: constructIpv4AndV6AddressArray(ipv4, ipv6),
+		Vrf:         vrfID,
+		Unnumbered:  unnumbered,
 		Memif: &interfaces.Interfaces_Interface_Memif{
 			Id:             memifID,
 			Master:         isMaster,
-			SocketFilename: "/tmp/memif_" + masterContainer + ".sock",
+			SocketFilename: socketFilename,
+			Mode:           memifModeControllerToInterface(memifParms.Mode),
+			Secret:         memifParms.Secret,
+			RingSize:       memifParms.RingSize,
+			BufferSize:     memifParms.BufferSize,
+			RxQueues:       memifParms.RxQueues,
+			TxQueues:       memifParms.TxQueues,
 		},
 	}
 
@@ -1760,7 +3100,7 @@ func (cnpd *sfcCtlrL2CNPDriver) memIfCreate(etcdPrefix string, memIfName string,
 		cnpd.reconcileInterface(etcdPrefix, memIf)
 	} else {
 
-		log.Println(*memIf)
+		log.Println(interfaceForLog(memIf))
 
 		rc := NewRemoteClientTxn(etcdPrefix, cnpd.dbFactory)
 		err := rc.Put().VppInterface(memIf).Send().ReceiveReply()
@@ -1789,16 +3129,20 @@ func rxModeControllerToInterface(contrtollerRxMode controller.RxModeType) *inter
 	return nil
 }
 
-func (cnpd *sfcCtlrL2CNPDriver) createEthernet(etcdPrefix string, ifname string, ipv4 string, macAddr string,
-	ipv6 string, mtu uint32, rxMode controller.RxModeType) error {
+func (cnpd *sfcCtlrL2CNPDriver) createEthernet(etcdPrefix string, ifname string, description string, ipv4 string, macAddr string,
+	ipv6 string, mtu uint32, rxMode controller.RxModeType, vrfID uint32,
+	unnumbered *interfaces.Interfaces_Interface_Unnumbered) error {
 
 	iface := &interfaces.Interfaces_Interface{
 		Name:        ifname,
+		Description: description,
 		Type:        interfaces.InterfaceType_ETHERNET_CSMACD,
 		Enabled:     true,
 		PhysAddress: macAddr,
 		IpAddresses: constructIpv4AndV6AddressArray(ipv4, ipv6),
 		Mtu:         mtu,
+		Vrf:         vrfID,
+		Unnumbered:  unnumbered,
 	}
 
 	iface.RxModeSettings = rxModeControllerToInterface(rxMode)
@@ -1822,16 +3166,20 @@ func (cnpd *sfcCtlrL2CNPDriver) createEthernet(etcdPrefix string, ifname string,
 	return nil
 }
 
-func (cnpd *sfcCtlrL2CNPDriver) afPacketCreate(etcdPrefix string, ifName string, hostIfName string, ipv4 string,
-	macAddress string, ipv6 string, mtu uint32, rxMode controller.RxModeType) (*interfaces.Interfaces_Interface, error) {
+func (cnpd *sfcCtlrL2CNPDriver) afPacketCreate(etcdPrefix string, ifName string, description string, hostIfName string, ipv4 string,
+	macAddress string, ipv6 string, mtu uint32, rxMode controller.RxModeType, vrfID uint32,
+	unnumbered *interfaces.Interfaces_Interface_Unnumbered) (*interfaces.Interfaces_Interface, error) {
 
 	afPacketIf := &interfaces.Interfaces_Interface{
 		Name:        ifName,
+		Description: description,
 		Type:        interfaces.InterfaceType_AF_PACKET_INTERFACE,
 		Enabled:     true,
 		PhysAddress: macAddress,
 		IpAddresses: constructIpv4AndV6AddressArray(ipv4, ipv6),
 		Mtu:         mtu,
+		Vrf:         vrfID,
+		Unnumbered:  unnumbered,
 		Afpacket: &interfaces.Interfaces_Interface_Afpacket{
 			HostIfName: hostIfName,
 		},
@@ -1858,11 +3206,53 @@ func (cnpd *sfcCtlrL2CNPDriver) afPacketCreate(etcdPrefix string, ifName string,
 	return afPacketIf, nil
 }
 
-func (cnpd *sfcCtlrL2CNPDriver) createLoopback(etcdPrefix string, ifname string, physAddr string, ipv4 string,
+// tapCreate creates a tap v2 whose host end is the host interface in the named linux netns
+func (cnpd *sfcCtlrL2CNPDriver) tapCreate(etcdPrefix string, ifName string, description string, hostIfName string,
+	namespace string, mtu uint32, rxMode controller.RxModeType, vrfID uint32,
+	unnumbered *interfaces.Interfaces_Interface_Unnumbered) (*interfaces.Interfaces_Interface, error) {
+
+	tapIf := &interfaces.Interfaces_Interface{
+		Name:        ifName,
+		Description: description,
+		Type:        interfaces.InterfaceType_TAP_INTERFACE,
+		Enabled:     true,
+		Mtu:         mtu,
+		Vrf:         vrfID,
+		Unnumbered:  unnumbered,
+		Tap: &interfaces.Interfaces_Interface_Tap{
+			Version:    2,
+			HostIfName: hostIfName,
+			Namespace:  namespace,
+		},
+	}
+
+	tapIf.RxModeSettings = rxModeControllerToInterface(rxMode)
+
+	if cnpd.reconcileInProgress {
+		cnpd.reconcileInterface(etcdPrefix, tapIf)
+	} else {
+
+		log.Println(*tapIf)
+
+		rc := NewRemoteClientTxn(etcdPrefix, cnpd.dbFactory)
+		err := rc.Put().VppInterface(tapIf).Send().ReceiveReply()
+
+		if err != nil {
+			log.Error("tapCreate: databroker.Store: ", err)
+			return nil, err
+
+		}
+	}
+
+	return tapIf, nil
+}
+
+func (cnpd *sfcCtlrL2CNPDriver) createLoopback(etcdPrefix string, ifname string, description string, physAddr string, ipv4 string,
 	ipv6 string, mtu uint32, rxMode controller.RxModeType) error {
 
 	iface := &interfaces.Interfaces_Interface{
 		Name:        ifname,
+		Description: description,
 		Type:        interfaces.InterfaceType_SOFTWARE_LOOPBACK,
 		Enabled:     true,
 		PhysAddress: physAddr,
@@ -1891,11 +3281,12 @@ func (cnpd *sfcCtlrL2CNPDriver) createLoopback(etcdPrefix string, ifname string,
 	return nil
 }
 
-func (cnpd *sfcCtlrL2CNPDriver) vEthIfCreate(etcdPrefix string, ifname string, hostIfName, peerIfName string, container string,
+func (cnpd *sfcCtlrL2CNPDriver) vEthIfCreate(etcdPrefix string, ifname string, description string, hostIfName, peerIfName string, container string,
 	physAddr string, ipv4 string, ipv6 string, mtu uint32) error {
 
 	linuxif := &linuxIntf.LinuxInterfaces_Interface{
 		Name:        ifname,
+		Description: description,
 		Type:        linuxIntf.LinuxInterfaces_VETH,
 		Enabled:     true,
 		PhysAddress: physAddr,
@@ -1929,6 +3320,133 @@ func (cnpd *sfcCtlrL2CNPDriver) vEthIfCreate(etcdPrefix string, ifname string, h
 	return nil
 }
 
+// createContainerL3Entries creates the routes, the default route, and the static arp entries of a non vpp
+// container in the container's namespace via its port, the vswitch's agent renders them with its linux plugin
+func (cnpd *sfcCtlrL2CNPDriver) createContainerL3Entries(sfc *controller.SfcEntity,
+	vnfChainElement *controller.SfcEntity_SfcElement) error {
+
+	if vnfChainElement.Type != controller.SfcElementType_NON_VPP_CONTAINER_AFP {
+		return nil
+	}
+
+	namePrefix := vnfChainElement.Container + "_" + vnfChainElement.PortLabel + "_"
+
+	for i, containerRoute := range vnfChainElement.GetContainerRoutes() {
+		routeName := "LINUX_ROUTE_" + namePrefix + strings.Replace(containerRoute.DstIpAddr, "/", "_", -1)
+		if _, err := cnpd.createLinuxRoute(vnfChainElement.EtcdVppSwitchKey, routeName,
+			utils.FormatLabels(sfc.Labels), vnfChainElement.Container, vnfChainElement.PortLabel,
+			containerRoute.DstIpAddr, containerRoute.GwAddr, containerRoute.Metric, false); err != nil {
+			log.Errorf("createContainerL3Entries: error creating linux route: %d/'%v'", i, containerRoute)
+			return err
+		}
+	}
+
+	if vnfChainElement.ContainerDefaultGw != "" {
+		if _, err := cnpd.createLinuxRoute(vnfChainElement.EtcdVppSwitchKey, "LINUX_ROUTE_"+namePrefix+"DEFAULT",
+			utils.FormatLabels(sfc.Labels), vnfChainElement.Container, vnfChainElement.PortLabel, "",
+			vnfChainElement.ContainerDefaultGw, 0, true); err != nil {
+			log.Errorf("createContainerL3Entries: error creating linux default route via: '%s'",
+				vnfChainElement.ContainerDefaultGw)
+			return err
+		}
+	}
+
+	for i, arpEntry := range vnfChainElement.GetContainerArpEntries() {
+		arpName := "LINUX_ARP_" + namePrefix + arpEntry.IpAddress
+		if _, err := cnpd.createLinuxArpEntry(vnfChainElement.EtcdVppSwitchKey, arpName,
+			vnfChainElement.Container, vnfChainElement.PortLabel, arpEntry.IpAddress,
+			arpEntry.PhysAddress); err != nil {
+			log.Errorf("createContainerL3Entries: error creating linux arp entry: %d/'%v'", i, arpEntry)
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (cnpd *sfcCtlrL2CNPDriver) createLinuxRoute(etcdPrefix string, name string, description string,
+	container string, ifName string, dstIPAddr string, gwAddr string, metric uint32,
+	isDefault bool) (*linuxL3.LinuxStaticRoutes_Route, error) {
+
+	lr := &linuxL3.LinuxStaticRoutes_Route{
+		Name:        name,
+		Description: description,
+		Default:     isDefault,
+		Namespace: &linuxL3.LinuxStaticRoutes_Route_Namespace{
+			Type:         linuxL3.LinuxStaticRoutes_Route_Namespace_MICROSERVICE_REF_NS,
+			Microservice: container,
+		},
+		Interface: ifName,
+		DstIpAddr: dstIPAddr,
+		GwAddr:    stripSlashAndSubnetIpv4Address(gwAddr),
+		Metric:    metric,
+	}
+	// a route without a gateway is directly connected via the port
+	if gwAddr == "" {
+		lr.Scope = &linuxL3.LinuxStaticRoutes_Route_Scope{
+			Type: linuxL3.LinuxStaticRoutes_Route_Scope_LINK,
+		}
+	}
+
+	if cnpd.reconcileInProgress {
+		cnpd.reconcileLinuxRoute(etcdPrefix, lr)
+	} else {
+
+		log.Println(lr)
+
+		rc := NewRemoteClientTxn(etcdPrefix, cnpd.dbFactory)
+		err := rc.Put().LinuxRoute(lr).Send().ReceiveReply()
+
+		if err != nil {
+			log.Error("createLinuxRoute: databroker.Store: ", err)
+			return nil, err
+		}
+	}
+
+	return lr, nil
+}
+
+func (cnpd *sfcCtlrL2CNPDriver) createLinuxArpEntry(etcdPrefix string, name string, container string,
+	ifName string, ipAddress string, physAddress string) (*linuxL3.LinuxStaticArpEntries_ArpEntry, error) {
+
+	family := uint32(syscall.AF_INET)
+	if strings.Contains(ipAddress, ":") {
+		family = uint32(syscall.AF_INET6)
+	}
+
+	ae := &linuxL3.LinuxStaticArpEntries_ArpEntry{
+		Name: name,
+		Namespace: &linuxL3.LinuxStaticArpEntries_ArpEntry_Namespace{
+			Type:         linuxL3.LinuxStaticArpEntries_ArpEntry_Namespace_MICROSERVICE_REF_NS,
+			Microservice: container,
+		},
+		Interface: ifName,
+		Family:    family,
+		State: &linuxL3.LinuxStaticArpEntries_ArpEntry_NudState{
+			Type: linuxL3.LinuxStaticArpEntries_ArpEntry_NudState_PERMANENT,
+		},
+		IpAddr:    ipAddress,
+		HwAddress: physAddress,
+	}
+
+	if cnpd.reconcileInProgress {
+		cnpd.reconcileLinuxArpEntry(etcdPrefix, ae)
+	} else {
+
+		log.Println(ae)
+
+		rc := NewRemoteClientTxn(etcdPrefix, cnpd.dbFactory)
+		err := rc.Put().LinuxArpEntry(ae).Send().ReceiveReply()
+
+		if err != nil {
+			log.Error("createLinuxArpEntry: databroker.Store: ", err)
+			return nil, err
+		}
+	}
+
+	return ae, nil
+}
+
 func (cnpd *sfcCtlrL2CNPDriver) createStaticRoute(vrfID uint32, etcdPrefix string, description string, destIpv4AddrStr string,
 	netHopIpv4Addr string, outGoingIf string, weight uint32, pref uint32) (*l3.StaticRoutes_Route, error) {
 
@@ -1971,29 +3489,180 @@ func (cnpd *sfcCtlrL2CNPDriver) createStaticArpEntry(etcdPrefix string, destIPAd
 		PhysAddress: physAddress,
 	}
 
-	//if cnpd.reconcileInProgress {
-	//cnpd.reconcileStaticArpEntry(etcdPrefix, ae)
-	//} else {
+	if cnpd.reconcileInProgress {
+		cnpd.reconcileStaticArpEntry(etcdPrefix, ae)
+	} else {
 
-	key := utils.ArpEntryKey(etcdPrefix, outGoingIf, destIPAddress)
+		key := utils.ArpEntryKey(etcdPrefix, outGoingIf, destIPAddress)
 
-	log.Println(key)
-	log.Println(ae)
+		log.Info("createStaticArpEntry: arp entry: : ", key, ae)
 
-	log.Info("createStaticArpEntry: arp entry: : ", key, ae)
+		err := cnpd.db.Put(key, ae)
+		if err != nil {
+			log.Error("createStaticArpEntry: databroker.Store: ", err)
+			return nil, err
 
-	//rc := NewRemoteClientTxn(etcdPrefix, cnpd.dbFactory)
-	err := cnpd.db.Put(key, ae)
-	//err := rc.Put(key, ae)
+		}
+	}
 
-	if err != nil {
-		log.Error("createStaticArpEntry: databroker.Store: ", err)
-		return nil, err
+	return ae, nil
+}
+
+func (cnpd *sfcCtlrL2CNPDriver) createAcl(etcdPrefix string, aclName string, sfcAcl *controller.Acl,
+	ifNames []string) (*vppAcl.AccessLists_Acl, error) {
 
+	acl := &vppAcl.AccessLists_Acl{
+		AclName:    aclName,
+		Interfaces: &vppAcl.AccessLists_Acl_Interfaces{},
+	}
+	for i, rule := range sfcAcl.GetRules() {
+		acl.Rules = append(acl.Rules, aclRule(aclName, i, rule))
+	}
+	if sfcAcl.Direction == controller.AclDirection_ACL_DIRECTION_EGRESS {
+		acl.Interfaces.Egress = ifNames
+	} else {
+		acl.Interfaces.Ingress = ifNames
 	}
-	//}
 
-	return ae, nil
+	if cnpd.reconcileInProgress {
+		cnpd.reconcileAcl(etcdPrefix, acl)
+	} else {
+
+		log.Println(acl)
+
+		rc := NewRemoteClientTxn(etcdPrefix, cnpd.dbFactory)
+		err := rc.Put().ACL(acl).Send().ReceiveReply()
+
+		if err != nil {
+			log.Error("createAcl: databroker.Store: ", err)
+			return nil, err
+		}
+	}
+
+	return acl, nil
+}
+
+// aclRule converts the rule of an sfc acl to a vpp acl rule, the actions of both models have the same values
+func aclRule(aclName string, i int, rule *controller.AclRule) *vppAcl.AccessLists_Acl_Rule {
+
+	ipRule := &vppAcl.AccessLists_Acl_Rule_Matches_IpRule{
+		Ip: &vppAcl.AccessLists_Acl_Rule_Matches_IpRule_Ip{
+			SourceNetwork:      rule.SrcNetwork,
+			DestinationNetwork: rule.DstNetwork,
+		},
+	}
+
+	srcLower, srcUpper := aclPortRange(rule.SrcPortLower, rule.SrcPortUpper)
+	dstLower, dstUpper := aclPortRange(rule.DstPortLower, rule.DstPortUpper)
+
+	switch rule.Protocol {
+	case controller.AclProtocol_ACL_PROTOCOL_TCP:
+		ipRule.Tcp = &vppAcl.AccessLists_Acl_Rule_Matches_IpRule_Tcp{
+			SourcePortRange: &vppAcl.AccessLists_Acl_Rule_Matches_IpRule_Tcp_SourcePortRange{
+				LowerPort: srcLower,
+				UpperPort: srcUpper,
+			},
+			DestinationPortRange: &vppAcl.AccessLists_Acl_Rule_Matches_IpRule_Tcp_DestinationPortRange{
+				LowerPort: dstLower,
+				UpperPort: dstUpper,
+			},
+		}
+	case controller.AclProtocol_ACL_PROTOCOL_UDP:
+		ipRule.Udp = &vppAcl.AccessLists_Acl_Rule_Matches_IpRule_Udp{
+			SourcePortRange: &vppAcl.AccessLists_Acl_Rule_Matches_IpRule_Udp_SourcePortRange{
+				LowerPort: srcLower,
+				UpperPort: srcUpper,
+			},
+			DestinationPortRange: &vppAcl.AccessLists_Acl_Rule_Matches_IpRule_Udp_DestinationPortRange{
+				LowerPort: dstLower,
+				UpperPort: dstUpper,
+			},
+		}
+	case controller.AclProtocol_ACL_PROTOCOL_ICMP, controller.AclProtocol_ACL_PROTOCOL_ICMPV6:
+		ipRule.Icmp = &vppAcl.AccessLists_Acl_Rule_Matches_IpRule_Icmp{
+			Icmpv6: rule.Protocol == controller.AclProtocol_ACL_PROTOCOL_ICMPV6,
+			IcmpCodeRange: &vppAcl.AccessLists_Acl_Rule_Matches_IpRule_Icmp_IcmpCodeRange{
+				First: 0,
+				Last:  255,
+			},
+			IcmpTypeRange: &vppAcl.AccessLists_Acl_Rule_Matches_IpRule_Icmp_IcmpTypeRange{
+				First: 0,
+				Last:  255,
+			},
+		}
+	}
+
+	return &vppAcl.AccessLists_Acl_Rule{
+		RuleName: aclName + "_" + strconv.Itoa(i),
+		Actions: &vppAcl.AccessLists_Acl_Rule_Actions{
+			AclAction: vppAcl.AclAction(rule.Action),
+		},
+		Matches: &vppAcl.AccessLists_Acl_Rule_Matches{
+			IpRule: ipRule,
+		},
+	}
+}
+
+// aclPortRange returns the port range of a rule, all ports if no bound is given, the lower port if only it is
+func aclPortRange(lower uint32, upper uint32) (uint32, uint32) {
+	if lower == 0 && upper == 0 {
+		return 0, 65535
+	}
+	if upper == 0 {
+		return lower, lower
+	}
+	return lower, upper
+}
+
+func (cnpd *sfcCtlrL2CNPDriver) createProxyArpRange(etcdPrefix string, rangeIPStart string,
+	rangeIPEnd string) (*l3.ProxyArpRanges_ProxyArpRange, error) {
+
+	par := &l3.ProxyArpRanges_ProxyArpRange{
+		RangeIpStart: rangeIPStart,
+		RangeIpEnd:   rangeIPEnd,
+	}
+
+	if cnpd.reconcileInProgress {
+		cnpd.reconcileProxyArpRange(etcdPrefix, par)
+	} else {
+
+		key := utils.ProxyArpRangeKey(etcdPrefix, rangeIPStart, rangeIPEnd)
+
+		log.Info("createProxyArpRange: proxy arp range: ", key, par)
+
+		err := cnpd.db.Put(key, par)
+		if err != nil {
+			log.Error("createProxyArpRange: databroker.Store: ", err)
+			return nil, err
+		}
+	}
+
+	return par, nil
+}
+
+func (cnpd *sfcCtlrL2CNPDriver) createProxyArpInterface(etcdPrefix string,
+	ifName string) (*l3.ProxyArpInterfaces_ProxyArpInterface, error) {
+
+	pai := &l3.ProxyArpInterfaces_ProxyArpInterface{
+		Interface: ifName,
+	}
+
+	if cnpd.reconcileInProgress {
+		cnpd.reconcileProxyArpInterface(etcdPrefix, pai)
+	} else {
+
+		key := utils.ProxyArpInterfaceKey(etcdPrefix, ifName)
+
+		log.Info("createProxyArpInterface: proxy arp i/f: ", key, pai)
+
+		err := cnpd.db.Put(key, pai)
+		if err != nil {
+			log.Error("createProxyArpInterface: databroker.Store: ", err)
+			return nil, err
+		}
+	}
+
+	return pai, nil
 }
 
 func (cnpd *sfcCtlrL2CNPDriver) createXConnectPair(etcdPrefix, if1, if2 string) error {
@@ -2018,13 +3687,18 @@ func (cnpd *sfcCtlrL2CNPDriver) createXConnect(etcdPrefix, rxIf, txIf string) er
 		TransmitInterface: txIf,
 	}
 
-	log.Debugf("Storing l2xconnect config: %s", xconn)
+	if cnpd.reconcileInProgress {
+		cnpd.reconcileXConnect(etcdPrefix, xconn)
+	} else {
 
-	rc := NewRemoteClientTxn(etcdPrefix, cnpd.dbFactory)
-	err := rc.Put().XConnect(xconn).Send().ReceiveReply()
-	if err != nil {
-		log.Errorf("Error by storing l2xconnect: %s", err)
-		return err
+		log.Debugf("Storing l2xconnect config: %s", xconn)
+
+		rc := NewRemoteClientTxn(etcdPrefix, cnpd.dbFactory)
+		err := rc.Put().XConnect(xconn).Send().ReceiveReply()
+		if err != nil {
+			log.Errorf("Error by storing l2xconnect: %s", err)
+			return err
+		}
 	}
 
 	return nil
@@ -2041,32 +3715,50 @@ func (cnpd *sfcCtlrL2CNPDriver) createL2FibEntry(etcdPrefix string, bdName strin
 		StaticConfig:      true,
 	}
 
-	//if cnpd.reconcileInProgress {
-	//	cnpd.reconcileL2FibEntry(etcdPrefix, l2fib)
-	//} else {
+	if cnpd.reconcileInProgress {
+		cnpd.reconcileL2FibEntry(etcdPrefix, l2fib)
+	} else {
 
-	log.Println(l2fib)
+		log.Println(l2fib)
 
-	rc := NewRemoteClientTxn(etcdPrefix, cnpd.dbFactory)
-	err := rc.Put().BDFIB(l2fib).Send().ReceiveReply()
+		rc := NewRemoteClientTxn(etcdPrefix, cnpd.dbFactory)
+		err := rc.Put().BDFIB(l2fib).Send().ReceiveReply()
 
-	if err != nil {
-		log.Error("createL2Fib: databroker.Store: ", err)
-		return nil, err
+		if err != nil {
+			log.Error("createL2Fib: databroker.Store: ", err)
+			return nil, err
 
+		}
 	}
-	//}
 
 	return l2fib, nil
 }
 
 // Debug dump routine
 func (cnpd *sfcCtlrL2CNPDriver) Dump() {
-	log.Println(cnpd.seq)
-	log.Println(cnpd.l2CNPEntityCache)
+	log.Println(cnpd.ids)
+	log.Println(cnpd.l2CNPEntityCache.forLog())
 	log.Println(cnpd.l2CNPStateCache)
 }
 
+// forLog returns a copy of the entity cache without the memif secrets and the api_tokens of the tenants
+func (cache *l2CNPEntityCacheType) forLog() l2CNPEntityCacheType {
+	logCache := l2CNPEntityCacheType{
+		EEs:      cache.EEs,
+		HEs:      cache.HEs,
+		SFCs:     make(map[string]controller.SfcEntity, len(cache.SFCs)),
+		Tenants:  make(map[string]controller.Tenant, len(cache.Tenants)),
+		SysParms: *utils.RedactSystemParameters(&cache.SysParms),
+	}
+	for name, sfc := range cache.SFCs {
+		logCache.SFCs[name] = *utils.RedactSfcEntity(&sfc)
+	}
+	for name, tenant := range cache.Tenants {
+		logCache.Tenants[name] = *utils.RedactTenant(&tenant)
+	}
+	return logCache
+}
+
 func (cnpd *sfcCtlrL2CNPDriver) getHEToEEState(heName string, eeName string) *heToEEStateType {
 
 	eeMap, exists := cnpd.l2CNPStateCache.HEToEEs[heName]
@@ -2095,33 +3787,58 @@ func replaceSlashesWithUScores(slashesString string) string {
 	return UScoresString
 }
 
-func formatMacAddress(macInstanceID32 uint32) string {
-
-	// uint32 is 4Billion interfaces ... lets not worry about it
-
-	var macOctets [6]uint64
-	macInstanceID := uint64(macInstanceID32)
-
-	macOctets[0] = 0x02
-	macOctets[1] = 0xFF & (macInstanceID >> (8 * 4))
-	macOctets[2] = 0xFF & (macInstanceID >> (8 * 3))
-	macOctets[3] = 0xFF & (macInstanceID >> (8 * 2))
-	macOctets[4] = 0xFF & (macInstanceID >> (8 * 1))
-	macOctets[5] = 0xFF & (macInstanceID >> (8 * 0))
+// if the ip address has a /xx subnet attached, it is stripped off
+func stripSlashAndSubnetIpv4Address(ipAndSubnetStr string) string {
+	strs := strings.Split(ipAndSubnetStr, "/")
+	return strs[0]
+}
 
-	macOctetString := ""
-	for i := 0; i < 5; i++ {
-		macOctetString += fmt.Sprintf("%02X:", macOctets[i])
+// h2hUnderlay returns the vxlan tunnel endpoints from the source host to the dest host, and the next hop of
+// the route to the dest host, ipv6 is used when both hosts have an ipv6 tunnel endpoint, ipv4 when both have
+// an ipv4 one, the hosts cannot be peers if they have no address family in common
+func h2hUnderlay(sh *controller.HostEntity, dh *controller.HostEntity) (string, string, string, error) {
+	if sh.VxlanTunnelIpv6 != "" && dh.VxlanTunnelIpv6 != "" {
+		return sh.VxlanTunnelIpv6, dh.VxlanTunnelIpv6, dh.EthIpv6, nil
+	}
+	if sh.VxlanTunnelIpv4 != "" && dh.VxlanTunnelIpv4 != "" {
+		return sh.VxlanTunnelIpv4, dh.VxlanTunnelIpv4, dh.EthIpv4, nil
 	}
-	macOctetString += fmt.Sprintf("%02X", macOctets[5])
+	return "", "", "", fmt.Errorf("h2hUnderlay: hosts '%s' and '%s' have no vxlan tunnel address family in common",
+		sh.Name, dh.Name)
+}
 
-	return macOctetString
+// h2eUnderlay returns the vxlan tunnel endpoints from the host to the ee, and the next hop of the route to
+// the ee, ipv6 is used when both the host and the ee have an ipv6 tunnel endpoint, ipv4 when both have an
+// ipv4 one, the host and the ee cannot be peers if they have no address family in common
+func h2eUnderlay(he *controller.HostEntity, ee *controller.ExternalEntity) (string, string, string, error) {
+	if ee.HostVxlan == nil || ee.HostInterface == nil {
+		return "", "", "", fmt.Errorf("h2eUnderlay: ee '%s' has no host_vxlan or host_interface", ee.Name)
+	}
+	if he.VxlanTunnelIpv6 != "" && ee.HostVxlan.SourceIpv6 != "" {
+		if ee.HostInterface.Ipv6Addr == "" {
+			return "", "", "", fmt.Errorf("h2eUnderlay: ee '%s' has an ipv6 source but no host_interface ipv6_addr",
+				ee.Name)
+		}
+		return he.VxlanTunnelIpv6, ee.HostVxlan.SourceIpv6, ee.HostInterface.Ipv6Addr, nil
+	}
+	if he.VxlanTunnelIpv4 != "" && ee.HostVxlan.SourceIpv4 != "" {
+		return he.VxlanTunnelIpv4, ee.HostVxlan.SourceIpv4, ee.HostInterface.Ipv4Addr, nil
+	}
+	return "", "", "", fmt.Errorf("h2eUnderlay: host '%s' and ee '%s' have no vxlan tunnel address family in common",
+		he.Name, ee.Name)
 }
 
-// if the ip address has a /xx subnet attached, it is stripped off
-func stripSlashAndSubnetIpv4Address(ipAndSubnetStr string) string {
-	strs := strings.Split(ipAndSubnetStr, "/")
-	return strs[0]
+// e2hRoute returns the destination and next hop of the route from the ee to the host's tunnel endpoint, it
+// uses the address family of the tunnel, see h2eUnderlay
+func e2hRoute(ee *controller.ExternalEntity, he *controller.HostEntity) (string, string, error) {
+	srcAddr, _, _, err := h2eUnderlay(he, ee)
+	if err != nil {
+		return "", "", err
+	}
+	if srcAddr == he.VxlanTunnelIpv6 {
+		return he.VxlanTunnelIpv6, he.EthIpv6, nil
+	}
+	return he.VxlanTunnelIpv4, he.EthIpv4, nil
 }
 
 // ByIfName is used to sort i/f by name
@@ -2143,11 +3860,18 @@ func (cnpd *sfcCtlrL2CNPDriver) GetSfcInterfaceIPAndMac(container string, port s
 		container, port)
 }
 
-func (cnpd *sfcCtlrL2CNPDriver) setSfcInterfaceIPAndMac(container string, port string, ip string, mac string) {
+// sfcInterfaceIpv6Address returns the ipv6 address of the container's port without the prefix, "" if it has none
+func (cnpd *sfcCtlrL2CNPDriver) sfcInterfaceIpv6Address(container string, port string) string {
+	return strings.Split(cnpd.l2CNPStateCache.SFCIFAddr[container+"/"+port].ipv6Address, "/")[0]
+}
+
+func (cnpd *sfcCtlrL2CNPDriver) setSfcInterfaceIPAndMac(container string, port string, ip string, mac string,
+	ipv6 string) {
 
 	sfcIFAddr := sfcInterfaceAddressStateType{
-		ipAddress:  ip,
-		macAddress: mac,
+		ipAddress:   ip,
+		macAddress:  mac,
+		ipv6Address: ipv6,
 	}
 	cnpd.l2CNPStateCache.SFCIFAddr[container+"/"+port] = sfcIFAddr
 }
@@ -2166,6 +3890,16 @@ func stringFirstNLastM(n int, m int, str string) string {
 	return outStr
 }
 
+// containsString returns true if the string is in the slice
+func containsString(strs []string, str string) bool {
+	for _, s := range strs {
+		if s == str {
+			return true
+		}
+	}
+	return false
+}
+
 func constructBaseHostName(container string, port string, v string) string {
 
 	// Use at most 5 chrs from cntr name, and 5 from port, 3 for base 36 unique id plus some under scores
@@ -2225,5 +3959,12 @@ func constructBaseHostName(container string, port string, v string) string {
 		}
 	}
 
-	return stringFirstNLastM(cb, ce, container) + "_" + stringFirstNLastM(pb, pe, port)
+	baseHostName := stringFirstNLastM(cb, ce, container) + "_" + stringFirstNLastM(pb, pe, port)
+
+	// a vethid str of more than 3 chars leaves less than the budget of 11 chars for the container and port
+	if maxLen := maxLinuxIfNameLen - 1 - len(v); len(baseHostName) > maxLen {
+		baseHostName = stringFirstNLastM(maxLen/2, maxLen-maxLen/2, baseHostName)
+	}
+
+	return baseHostName
 }
@@ -0,0 +1,698 @@
+// Copyright (c) 2017 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package l2driver
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/ligato/cn-infra/datasync"
+	"github.com/ligato/cn-infra/db/keyval"
+	"github.com/ligato/sfc-controller/controller/model/controller"
+	"github.com/ligato/sfc-controller/controller/utils"
+	"github.com/ligato/vpp-agent/plugins/defaultplugins/common/model/interfaces"
+	"github.com/ligato/vpp-agent/plugins/defaultplugins/common/model/l2"
+)
+
+// memStore is an in memory etcd, the values are serialized as json like the etcd brokers do
+type memStore struct {
+	mu  sync.Mutex
+	kvs map[string][]byte
+}
+
+func newMemStore() *memStore {
+	return &memStore{kvs: make(map[string][]byte)}
+}
+
+// broker returns a broker of the keys with the prefix
+func (s *memStore) broker(prefix string) keyval.ProtoBroker {
+	return &memBroker{store: s, prefix: prefix}
+}
+
+func (s *memStore) putIfNotExists(key string, value []byte) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.kvs[key]; exists {
+		return false, nil
+	}
+	s.kvs[key] = value
+	return true, nil
+}
+
+// snapshot returns a copy of the keys and values of the store
+func (s *memStore) snapshot() map[string]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	kvs := make(map[string]string, len(s.kvs))
+	for key, value := range s.kvs {
+		kvs[key] = string(value)
+	}
+	return kvs
+}
+
+// keys returns the sorted keys with the prefix
+func (s *memStore) keys(prefix string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	keys := make([]string, 0)
+	for key := range s.kvs {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (s *memStore) get(key string, msg proto.Message) (bool, error) {
+	s.mu.Lock()
+	value, exists := s.kvs[key]
+	s.mu.Unlock()
+	if !exists {
+		return false, nil
+	}
+	return true, json.Unmarshal(value, msg)
+}
+
+type memBroker struct {
+	store  *memStore
+	prefix string
+}
+
+func (b *memBroker) Put(key string, msg proto.Message, opts ...datasync.PutOption) error {
+	value, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	b.store.mu.Lock()
+	defer b.store.mu.Unlock()
+	b.store.kvs[b.prefix+key] = value
+	return nil
+}
+
+func (b *memBroker) NewTxn() keyval.ProtoTxn {
+	return &memTxn{broker: b}
+}
+
+func (b *memBroker) GetValue(key string, msg proto.Message) (bool, int64, error) {
+	found, err := b.store.get(b.prefix+key, msg)
+	return found, 0, err
+}
+
+func (b *memBroker) ListValues(key string) (keyval.ProtoKeyValIterator, error) {
+	kvs := b.store.snapshot()
+	it := &memIterator{}
+	for _, fullKey := range b.store.keys(b.prefix + key) {
+		it.kvs = append(it.kvs, &memKeyVal{key: strings.TrimPrefix(fullKey, b.prefix), value: kvs[fullKey]})
+	}
+	return it, nil
+}
+
+func (b *memBroker) ListKeys(prefix string) (keyval.ProtoKeyIterator, error) {
+	it := &memKeyIterator{}
+	for _, fullKey := range b.store.keys(b.prefix + prefix) {
+		it.keys = append(it.keys, strings.TrimPrefix(fullKey, b.prefix))
+	}
+	return it, nil
+}
+
+func (b *memBroker) Delete(key string, opts ...datasync.DelOption) (bool, error) {
+	keys := []string{b.prefix + key}
+	for _, opt := range opts {
+		if _, withPrefix := opt.(*datasync.WithPrefixOpt); withPrefix {
+			keys = b.store.keys(b.prefix + key)
+		}
+	}
+	b.store.mu.Lock()
+	defer b.store.mu.Unlock()
+	existed := false
+	for _, fullKey := range keys {
+		if _, exists := b.store.kvs[fullKey]; exists {
+			existed = true
+			delete(b.store.kvs, fullKey)
+		}
+	}
+	return existed, nil
+}
+
+type memTxn struct {
+	broker *memBroker
+	ops    []func() error
+}
+
+func (txn *memTxn) Put(key string, msg proto.Message) keyval.ProtoTxn {
+	txn.ops = append(txn.ops, func() error { return txn.broker.Put(key, msg) })
+	return txn
+}
+
+func (txn *memTxn) Delete(key string) keyval.ProtoTxn {
+	txn.ops = append(txn.ops, func() error {
+		_, err := txn.broker.Delete(key)
+		return err
+	})
+	return txn
+}
+
+func (txn *memTxn) Commit() error {
+	for _, op := range txn.ops {
+		if err := op(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type memIterator struct {
+	kvs []*memKeyVal
+}
+
+func (it *memIterator) GetNext() (keyval.ProtoKeyVal, bool) {
+	if len(it.kvs) == 0 {
+		return nil, true
+	}
+	kv := it.kvs[0]
+	it.kvs = it.kvs[1:]
+	return kv, false
+}
+
+func (it *memIterator) Close() error {
+	return nil
+}
+
+type memKeyIterator struct {
+	keys []string
+}
+
+func (it *memKeyIterator) GetNext() (string, int64, bool) {
+	if len(it.keys) == 0 {
+		return "", 0, true
+	}
+	key := it.keys[0]
+	it.keys = it.keys[1:]
+	return key, 0, false
+}
+
+func (it *memKeyIterator) Close() error {
+	return nil
+}
+
+type memKeyVal struct {
+	key   string
+	value string
+}
+
+func (kv *memKeyVal) GetValue(msg proto.Message) error {
+	return json.Unmarshal([]byte(kv.value), msg)
+}
+
+func (kv *memKeyVal) GetPrevValue(msg proto.Message) (bool, error) {
+	return false, nil
+}
+
+func (kv *memKeyVal) GetKey() string {
+	return kv.key
+}
+
+func (kv *memKeyVal) GetRevision() int64 {
+	return 0
+}
+
+func TestChainEndIfNames(t *testing.T) {
+	tests := []struct {
+		name    string
+		ifs     []vswitchIf
+		ifNames map[string][]string
+	}{
+		{"no i/fs", nil, map[string][]string{}},
+		{"one i/f", []vswitchIf{{"h1", "if1"}}, map[string][]string{"h1": {"if1"}}},
+		{"one vswitch", []vswitchIf{{"h1", "if1"}, {"h1", "if2"}, {"h1", "if3"}},
+			map[string][]string{"h1": {"if1", "if3"}}},
+		{"two vswitches", []vswitchIf{{"h1", "if1"}, {"h1", "if2"}, {"h2", "if3"}},
+			map[string][]string{"h1": {"if1"}, "h2": {"if3"}}},
+	}
+	for _, test := range tests {
+		if ifNames := chainEndIfNames(test.ifs); !reflect.DeepEqual(ifNames, test.ifNames) {
+			t.Errorf("%s: i/fs %v, want %v", test.name, ifNames, test.ifNames)
+		}
+	}
+}
+
+func TestClassifierDstNetworks(t *testing.T) {
+	rules := []*controller.ClassifierRule{{DstNetwork: "10.1.0.0/16"}, {SrcNetwork: "10.2.0.0/16"},
+		{DstNetwork: "2001:db8::/32"}}
+	tests := []struct {
+		name        string
+		sfc         *controller.SfcEntity
+		hasIpv4     bool
+		hasIpv6     bool
+		dstNetworks []string
+	}{
+		{"rules", &controller.SfcEntity{ClassifierRules: rules}, true, false, []string{"10.1.0.0/16", "2001:db8::/32"}},
+		{"default", &controller.SfcEntity{ClassifierDefault: true}, true, false, []string{"0.0.0.0/0"}},
+		{"default with ipv6", &controller.SfcEntity{ClassifierDefault: true}, true, true,
+			[]string{"0.0.0.0/0", "::/0"}},
+		{"default with only ipv6", &controller.SfcEntity{ClassifierDefault: true}, false, true, []string{"::/0"}},
+		{"default without addresses", &controller.SfcEntity{ClassifierDefault: true}, false, false, nil},
+	}
+	for _, test := range tests {
+		dstNetworks := classifierDstNetworks(test.sfc, test.hasIpv4, test.hasIpv6)
+		if !reflect.DeepEqual(dstNetworks, test.dstNetworks) {
+			t.Errorf("%s: dst networks %v, want %v", test.name, dstNetworks, test.dstNetworks)
+		}
+	}
+}
+
+func TestClassifierAttachmentAcl(t *testing.T) {
+	sfc1 := &controller.SfcEntity{Name: "sfc1", ClassifierRules: []*controller.ClassifierRule{
+		{DstNetwork: "10.1.0.0/16"}}}
+	sfc2 := &controller.SfcEntity{Name: "sfc2", ClassifierRules: []*controller.ClassifierRule{
+		{DstNetwork: "10.2.0.0/16"}, {DstNetwork: "10.3.0.0/16"}}}
+	sfc3 := &controller.SfcEntity{Name: "sfc3", ClassifierDefault: true}
+
+	acl := classifierAttachmentAcl([]*controller.SfcEntity{sfc1, sfc2})
+	if acl.Direction != controller.AclDirection_ACL_DIRECTION_INGRESS || len(acl.Rules) != 3 {
+		t.Fatalf("acl: %v, want 3 ingress rules", acl)
+	}
+	for i, dstNetwork := range []string{"10.1.0.0/16", "10.2.0.0/16", "10.3.0.0/16"} {
+		if acl.Rules[i].Action != controller.AclAction_ACL_ACTION_PERMIT || acl.Rules[i].DstNetwork != dstNetwork {
+			t.Errorf("rule[%d]: %v, want permit %s", i, acl.Rules[i], dstNetwork)
+		}
+	}
+
+	acl = classifierAttachmentAcl([]*controller.SfcEntity{sfc1, sfc2, sfc3})
+	if len(acl.Rules) != 1 || acl.Rules[0].Action != controller.AclAction_ACL_ACTION_PERMIT ||
+		acl.Rules[0].DstNetwork != "" {
+		t.Errorf("acl with a default sfc: %v, want one rule permitting all", acl)
+	}
+}
+
+// newTestDriver returns a driver with its db in the store
+func newTestDriver(store *memStore) *sfcCtlrL2CNPDriver {
+	return NewSfcCtlrL2CNPDriver("sfcctlrl2", "c1", store.broker, store.putIfNotExists)
+}
+
+// testHostEntities returns the hosts h1 .. hn, see controller/validate.go for the defaults
+func testHostEntities(n int) []*controller.HostEntity {
+	hes := make([]*controller.HostEntity, 0, n)
+	for i := 1; i <= n; i++ {
+		id := strconv.Itoa(i)
+		hes = append(hes, &controller.HostEntity{
+			Name:                   "h" + id,
+			EthIfName:              "GigabitEthernet13/0/0",
+			EthIpv4:                "10.0.10." + id + "/24",
+			VxlanTunnelIpv4:        "10.0.20." + id + "/24",
+			CreateVxlanStaticRoute: true,
+		})
+	}
+	return hes
+}
+
+// renderTestConfig renders the config in a reconcile like the controller does on startup, and when an sfc is
+// deleted
+func renderTestConfig(t *testing.T, store *memStore, cnpd *sfcCtlrL2CNPDriver, hes []*controller.HostEntity,
+	sfcs []*controller.SfcEntity) {
+
+	// the labels of the vpp agents in the db, ie: the hosts and the containers
+	labels := make(map[string]struct{})
+	for _, key := range store.keys(utils.GetVppAgentPrefix()) {
+		labels[utils.GetVppEtcdlabel(key)] = struct{}{}
+	}
+	cnpd.ReconcileStart(labels)
+
+	sp := &controller.SystemParameters{
+		Mtu:                      1500,
+		StartingVlanId:           5000,
+		DefaultStaticRouteWeight: 5,
+		DynamicBridgeParms:       &controller.BDParms{Learn: true, UnknownUnicastFlood: true, Flood: true, Forward: true},
+		StaticBridgeParms:        &controller.BDParms{Forward: true},
+	}
+	if err := cnpd.SetSystemParameters(sp); err != nil {
+		t.Fatalf("SetSystemParameters: %s", err)
+	}
+	for _, he := range hes {
+		if err := cnpd.WireInternalsForHostEntity(he); err != nil {
+			t.Fatalf("WireInternalsForHostEntity: '%s': %s", he.Name, err)
+		}
+	}
+	for _, sh := range hes {
+		for _, dh := range hes {
+			if sh.Name == dh.Name {
+				continue
+			}
+			if err := cnpd.WireHostEntityToDestinationHostEntity(sh, dh); err != nil {
+				t.Fatalf("WireHostEntityToDestinationHostEntity: '%s' -> '%s': %s", sh.Name, dh.Name, err)
+			}
+		}
+	}
+	for _, sfc := range sfcs {
+		if err := cnpd.WireSfcEntity(sfc); err != nil {
+			t.Fatalf("WireSfcEntity: '%s': %s", sfc.Name, err)
+		}
+	}
+
+	if err := cnpd.ReconcileEnd(); err != nil {
+		t.Fatalf("ReconcileEnd: %s", err)
+	}
+}
+
+// testSfcElement returns a vpp memif container of the host with an l2fib mac
+func testSfcElement(container string, host string, mac string) *controller.SfcEntity_SfcElement {
+	return &controller.SfcEntity_SfcElement{
+		Container:        container,
+		PortLabel:        "port1",
+		EtcdVppSwitchKey: host,
+		Type:             controller.SfcElementType_VPP_CONTAINER_MEMIF,
+		L2FibMacs:        []string{mac},
+	}
+}
+
+func TestDeleteSfcEntity(t *testing.T) {
+	hes := testHostEntities(3)
+	sfcs := []*controller.SfcEntity{
+		{
+			Name:          "sfc1",
+			Type:          controller.SfcType_SFC_EW_BD_L2FIB,
+			SfcIpv4Prefix: "10.1.1.0/24",
+			Elements: []*controller.SfcEntity_SfcElement{
+				testSfcElement("c1", "h1", "02:00:00:00:01:01"),
+				testSfcElement("c2", "h2", "02:00:00:00:01:02"),
+				testSfcElement("c3", "h3", "02:00:00:00:01:03"),
+			},
+		},
+		{
+			Name: "sfc2",
+			Type: controller.SfcType_SFC_EW_L2XCONN,
+			Elements: []*controller.SfcEntity_SfcElement{
+				testSfcElement("c4", "h1", "02:00:00:00:02:01"),
+				testSfcElement("c5", "h2", "02:00:00:00:02:02"),
+			},
+		},
+	}
+
+	for _, sfc := range sfcs {
+		store := newMemStore()
+		cnpd := newTestDriver(store)
+
+		renderTestConfig(t, store, cnpd, hes, nil)
+		want := store.snapshot()
+
+		renderTestConfig(t, store, cnpd, hes, []*controller.SfcEntity{sfc})
+		if len(store.snapshot()) <= len(want) {
+			t.Fatalf("%s: no config rendered for the sfc", sfc.Name)
+		}
+
+		// the sfc is deleted like the controller does it
+		renderTestConfig(t, store, cnpd, hes, nil)
+		if err := cnpd.ReleaseSfcEntity(sfc); err != nil {
+			t.Fatalf("%s: ReleaseSfcEntity: %s", sfc.Name, err)
+		}
+
+		got := store.snapshot()
+		for key := range got {
+			if _, exists := want[key]; !exists {
+				t.Errorf("%s: key '%s' is left after the delete", sfc.Name, key)
+			}
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: the db differs from the one before the sfc was rendered", sfc.Name)
+		}
+		if _, exists := cnpd.l2CNPEntityCache.SFCs[sfc.Name]; exists {
+			t.Errorf("%s: the sfc is left in the entity cache", sfc.Name)
+		}
+		if len(cnpd.l2CNPStateCache.SFCIFAddr) != 0 || len(cnpd.ipam.OwnerAllocations(sfc.Name+"/")) != 0 {
+			t.Errorf("%s: the addresses of the sfc are left", sfc.Name)
+		}
+	}
+}
+
+// vxlanVnis returns the vnis of the vxlan tunnels of the host by the name of the tunnel
+func vxlanVnis(t *testing.T, store *memStore, host string) map[string]uint32 {
+	vnis := make(map[string]uint32)
+	for _, key := range store.keys(utils.InterfacePrefixKey(host)) {
+		iface := &interfaces.Interfaces_Interface{}
+		if _, err := store.get(key, iface); err != nil {
+			t.Fatalf("i/f '%s': %s", key, err)
+		}
+		if iface.Type == interfaces.InterfaceType_VXLAN_TUNNEL {
+			vnis[iface.Name] = iface.Vxlan.Vni
+		}
+	}
+	return vnis
+}
+
+// sfcVswitchIfName returns the name of the vswitch i/f of the element
+func sfcVswitchIfName(cnpd *sfcCtlrL2CNPDriver, sfcName string, element *controller.SfcEntity_SfcElement) string {
+	return cnpd.l2CNPStateCache.SFCIfNames[sfcInterfaceOwner(sfcName, element.Container, element.PortLabel)].VswitchIfName
+}
+
+func TestEastWestBDsViaVxLAN(t *testing.T) {
+	tests := []struct {
+		name    string
+		sfcType controller.SfcType
+		bdName  string // the host's bridge without the host name
+		segment string
+		hosts   int
+	}{
+		{"bd on 2 hosts", controller.SfcType_SFC_EW_BD, "BD_INTERNAL_EW_", "EW", 2},
+		{"bd on 3 hosts", controller.SfcType_SFC_EW_BD, "BD_INTERNAL_EW_", "EW", 3},
+		{"l2fib bd on 2 hosts", controller.SfcType_SFC_EW_BD_L2FIB, "BD_INTERNAL_EW_L2FIB_", "EW_L2FIB", 2},
+		{"l2fib bd on 3 hosts", controller.SfcType_SFC_EW_BD_L2FIB, "BD_INTERNAL_EW_L2FIB_", "EW_L2FIB", 3},
+	}
+	for _, test := range tests {
+		store := newMemStore()
+		cnpd := newTestDriver(store)
+		hes := testHostEntities(test.hosts)
+		sfc := &controller.SfcEntity{Name: "sfc1", Type: test.sfcType}
+		for i, he := range hes {
+			id := strconv.Itoa(i + 1)
+			sfc.Elements = append(sfc.Elements, testSfcElement("c"+id, he.Name, "02:00:00:00:01:0"+id))
+		}
+
+		renderTestConfig(t, store, cnpd, hes, []*controller.SfcEntity{sfc})
+
+		vnis := make(map[string]map[string]uint32)
+		for _, sh := range hes {
+			vnis[sh.Name] = vxlanVnis(t, store, sh.Name)
+			if len(vnis[sh.Name]) != test.hosts-1 {
+				t.Errorf("%s: host '%s': %d tunnels, want %d", test.name, sh.Name, len(vnis[sh.Name]), test.hosts-1)
+			}
+
+			bd := &l2.BridgeDomains_BridgeDomain{}
+			if found, _ := store.get(utils.L2BridgeDomainKey(sh.Name, test.bdName+sh.Name), bd); !found {
+				t.Fatalf("%s: host '%s': no bridge", test.name, sh.Name)
+			}
+			splitHorizonGroups := make(map[string]uint32)
+			for _, bdIf := range bd.Interfaces {
+				splitHorizonGroups[bdIf.Name] = bdIf.SplitHorizonGroup
+			}
+
+			for _, element := range sfc.Elements {
+				dh := element.EtcdVppSwitchKey
+				outIf := sfcVswitchIfName(cnpd, sfc.Name, element)
+				if dh != sh.Name {
+					outIf = "IF_VXLAN_H2H_" + sh.Name + "_" + test.segment + "_" + dh
+					if group, exists := splitHorizonGroups[outIf]; !exists || group != 1 {
+						t.Errorf("%s: host '%s': tunnel '%s' in split horizon group %d (in bridge %t), want 1",
+							test.name, sh.Name, outIf, group, exists)
+					}
+					reverseIf := "IF_VXLAN_H2H_" + dh + "_" + test.segment + "_" + sh.Name
+					if vni := vnis[sh.Name][outIf]; vni == 0 || vni != vxlanVnis(t, store, dh)[reverseIf] {
+						t.Errorf("%s: tunnel '%s': vni %d, want the one of '%s'", test.name, outIf, vni, reverseIf)
+					}
+				} else if group, exists := splitHorizonGroups[outIf]; !exists || group != 0 {
+					t.Errorf("%s: host '%s': i/f '%s' in split horizon group %d (in bridge %t), want 0",
+						test.name, sh.Name, outIf, group, exists)
+				}
+
+				l2fib := &l2.FibTableEntries_FibTableEntry{}
+				found, _ := store.get(utils.L2FibKey(sh.Name, bd.Name, element.L2FibMacs[0]), l2fib)
+				if !found || l2fib.OutgoingInterface != outIf {
+					t.Errorf("%s: host '%s': l2fib of '%s' to '%s', want '%s'", test.name, sh.Name,
+						element.L2FibMacs[0], l2fib.OutgoingInterface, outIf)
+				}
+			}
+		}
+
+		// a reconcile, ie: a restart, keeps the vnis and the rest of the config
+		want := store.snapshot()
+		renderTestConfig(t, store, newTestDriver(store), hes, []*controller.SfcEntity{sfc})
+		for _, sh := range hes {
+			if got := vxlanVnis(t, store, sh.Name); !reflect.DeepEqual(got, vnis[sh.Name]) {
+				t.Errorf("%s: host '%s': vnis %v after a reconcile, want %v", test.name, sh.Name, got,
+					vnis[sh.Name])
+			}
+		}
+		if !reflect.DeepEqual(store.snapshot(), want) {
+			t.Errorf("%s: the db changed in a reconcile", test.name)
+		}
+	}
+}
+
+func TestXConnectPairViaVxLAN(t *testing.T) {
+	hes := testHostEntities(3)
+	sfc := &controller.SfcEntity{
+		Name: "sfc1",
+		Type: controller.SfcType_SFC_EW_L2XCONN,
+		Elements: []*controller.SfcEntity_SfcElement{
+			testSfcElement("c1", "h1", "02:00:00:00:01:01"),
+			testSfcElement("c2", "h2", "02:00:00:00:01:02"),
+			testSfcElement("c3", "h2", "02:00:00:00:01:03"),
+			testSfcElement("c4", "h3", "02:00:00:00:01:04"),
+		},
+	}
+	store := newMemStore()
+	cnpd := newTestDriver(store)
+
+	renderTestConfig(t, store, cnpd, hes, []*controller.SfcEntity{sfc})
+
+	vnis := make(map[string]map[string]uint32)
+	for host, tunnels := range map[string]int{"h1": 1, "h2": 2, "h3": 1} {
+		vnis[host] = vxlanVnis(t, store, host)
+		if len(vnis[host]) != tunnels {
+			t.Errorf("host '%s': %d tunnels, want %d", host, len(vnis[host]), tunnels)
+		}
+	}
+
+	pairVnis := make(map[uint32]bool)
+	for i := 0; i < len(sfc.Elements); i += 2 {
+		element1, element2 := sfc.Elements[i], sfc.Elements[i+1]
+		segment := "EW_XCONN_" + sfc.Name + "_" + element1.Container + "_" + element1.PortLabel
+		for _, pair := range [][2]*controller.SfcEntity_SfcElement{{element1, element2}, {element2, element1}} {
+			sh, dh := pair[0].EtcdVppSwitchKey, pair[1].EtcdVppSwitchKey
+			vlanIf := "IF_VXLAN_H2H_" + sh + "_" + segment + "_" + dh
+			ifName := sfcVswitchIfName(cnpd, sfc.Name, pair[0])
+			for rxIf, txIf := range map[string]string{ifName: vlanIf, vlanIf: ifName} {
+				xconn := &l2.XConnectPairs_XConnectPair{}
+				if found, _ := store.get(utils.L2XConnectKey(sh, rxIf), xconn); !found ||
+					xconn.TransmitInterface != txIf {
+					t.Errorf("host '%s': '%s' xconnected to '%s', want '%s'", sh, rxIf, xconn.TransmitInterface,
+						txIf)
+				}
+			}
+			reverseIf := "IF_VXLAN_H2H_" + dh + "_" + segment + "_" + sh
+			if vni := vnis[sh][vlanIf]; vni == 0 || vni != vnis[dh][reverseIf] {
+				t.Errorf("tunnel '%s': vni %d, want the one of '%s'", vlanIf, vni, reverseIf)
+			}
+		}
+		vni := vnis[element1.EtcdVppSwitchKey]["IF_VXLAN_H2H_"+element1.EtcdVppSwitchKey+"_"+segment+"_"+
+			element2.EtcdVppSwitchKey]
+		if pairVnis[vni] {
+			t.Errorf("pair '%s': vni %d of another pair", segment, vni)
+		}
+		pairVnis[vni] = true
+	}
+
+	// a reconcile, ie: a restart, keeps the vnis and the rest of the config
+	want := store.snapshot()
+	renderTestConfig(t, store, newTestDriver(store), hes, []*controller.SfcEntity{sfc})
+	for host := range vnis {
+		if got := vxlanVnis(t, store, host); !reflect.DeepEqual(got, vnis[host]) {
+			t.Errorf("host '%s': vnis %v after a reconcile, want %v", host, got, vnis[host])
+		}
+	}
+	if !reflect.DeepEqual(store.snapshot(), want) {
+		t.Errorf("the db changed in a reconcile")
+	}
+}
+
+func TestNorthSouthVXLANPeers(t *testing.T) {
+	tests := []struct {
+		name        string
+		redundancy  controller.PeerRedundancyType
+		h2          controller.SfcEntity_SfcElement // the route parms of the dest hosts
+		h3          controller.SfcEntity_SfcElement
+		wantBridged map[string]uint32 // the bridged dest hosts by their split horizon group
+	}{
+		{"defaults", controller.PeerRedundancyType_PEER_REDUNDANCY_ACTIVE_STANDBY,
+			controller.SfcEntity_SfcElement{}, controller.SfcEntity_SfcElement{}, map[string]uint32{"h2": 0}},
+		{"lower preference", controller.PeerRedundancyType_PEER_REDUNDANCY_ACTIVE_STANDBY,
+			controller.SfcEntity_SfcElement{RoutePreference: 20}, controller.SfcEntity_SfcElement{RoutePreference: 10},
+			map[string]uint32{"h3": 0}},
+		{"higher than default preference", controller.PeerRedundancyType_PEER_REDUNDANCY_ACTIVE_STANDBY,
+			controller.SfcEntity_SfcElement{}, controller.SfcEntity_SfcElement{RoutePreference: 1},
+			map[string]uint32{"h2": 0}},
+		{"higher weight", controller.PeerRedundancyType_PEER_REDUNDANCY_ACTIVE_STANDBY,
+			controller.SfcEntity_SfcElement{}, controller.SfcEntity_SfcElement{RouteWeight: 10},
+			map[string]uint32{"h3": 0}},
+		{"active/active", controller.PeerRedundancyType_PEER_REDUNDANCY_ACTIVE_ACTIVE,
+			controller.SfcEntity_SfcElement{}, controller.SfcEntity_SfcElement{RouteWeight: 10},
+			map[string]uint32{"h2": 1, "h3": 1}},
+	}
+
+	hes := testHostEntities(3)
+	for _, test := range tests {
+		sfc := &controller.SfcEntity{
+			Name:           "sfc1",
+			Type:           controller.SfcType_SFC_NS_VXLAN,
+			DedicatedVni:   true,
+			PeerRedundancy: test.redundancy,
+			Elements: []*controller.SfcEntity_SfcElement{
+				testSfcElement("c1", "h1", "02:00:00:00:01:01"),
+				{Container: "h2", Type: controller.SfcElementType_HOST_ENTITY,
+					RouteWeight: test.h2.RouteWeight, RoutePreference: test.h2.RoutePreference},
+				{Container: "h3", Type: controller.SfcElementType_HOST_ENTITY,
+					RouteWeight: test.h3.RouteWeight, RoutePreference: test.h3.RoutePreference},
+			},
+		}
+		store := newMemStore()
+		cnpd := newTestDriver(store)
+
+		renderTestConfig(t, store, cnpd, hes, []*controller.SfcEntity{sfc})
+
+		// both tunnels are created, only the bridged ones are in the bridge of the container
+		tunnels := vxlanVnis(t, store, "h1")
+		memifName := sfcVswitchIfName(cnpd, sfc.Name, sfc.Elements[0])
+		var bd *l2.BridgeDomains_BridgeDomain
+		for _, key := range store.keys(utils.L2BridgeDomainKeyPrefix("h1")) {
+			keyBD := &l2.BridgeDomains_BridgeDomain{}
+			if found, _ := store.get(key, keyBD); !found || utils.IsL2FibKey("h1", key) {
+				continue
+			}
+			for _, bdIf := range keyBD.Interfaces {
+				if bdIf.Name == memifName {
+					bd = keyBD
+				}
+			}
+		}
+		if bd == nil {
+			t.Fatalf("%s: no bridge with '%s'", test.name, memifName)
+		}
+
+		got := make(map[string]uint32)
+		for _, bdIf := range bd.Interfaces {
+			if bdIf.Name != memifName {
+				got[bdIf.Name] = bdIf.SplitHorizonGroup
+			}
+		}
+		want := make(map[string]uint32)
+		for dh, group := range test.wantBridged {
+			want["IF_VXLAN_H2H_h1_"+tunnelScopedName(dh, sfc)] = group
+		}
+		for _, dh := range []string{"h2", "h3"} {
+			if _, exists := tunnels["IF_VXLAN_H2H_h1_"+tunnelScopedName(dh, sfc)]; !exists {
+				t.Errorf("%s: no tunnel to '%s'", test.name, dh)
+			}
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: bridged tunnels %v, want %v", test.name, got, want)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright (c) 2017 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Tenant isolation for the L2 driver.  The sfcs of a tenant get their vlan/vni
+// ids and generated macs from the tenant's own ranges, and are wired into
+// tunnels and bridges that are created per tenant, so the chains of two
+// tenants never share a bridge domain or a tunnel.  Sfcs without a tenant use
+// the global sequences and the shared host bridges/tunnels as before.
+
+package l2driver
+
+import (
+	"fmt"
+
+	"github.com/ligato/sfc-controller/controller/model/controller"
+	"github.com/ligato/sfc-controller/controller/utils"
+	"github.com/ligato/vpp-agent/plugins/defaultplugins/common/model/l2"
+)
+
+// SetTenant caches the tenant and defines its vni range, its mac range is a window of the global one
+func (cnpd *sfcCtlrL2CNPDriver) SetTenant(tenant *controller.Tenant) error {
+
+	cnpd.l2CNPEntityCache.Tenants[tenant.Name] = *tenant
+
+	vniRange := tenantIDRange(idRangeVni, tenant.Name)
+	if err := cnpd.ids.DefineRange(vniRange, tenant.VniRangeStart, tenant.VniRangeEnd); err != nil {
+		return err
+	}
+
+	// ids loaded from the db that fall in the tenant range must not be handed out again
+	cnpd.claimRecordIDs(vniRange, tenant.VniRangeStart, tenant.VniRangeEnd)
+
+	log.Infof("SetTenant: tenant: '%s', id ranges: %s", tenant.Name, cnpd.ids)
+
+	return nil
+}
+
+// allocateVLanID returns a free vlan/vni from the tenant range, or from the global range if no tenant, for the
+// ids record with the key
+func (cnpd *sfcCtlrL2CNPDriver) allocateVLanID(tenantName string, owner string) (uint32, error) {
+
+	if _, exists := cnpd.l2CNPEntityCache.Tenants[tenantName]; tenantName != "" && !exists {
+		return 0, fmt.Errorf("allocateVLanID: tenant not found: '%s'", tenantName)
+	}
+	return cnpd.ids.Allocate(tenantIDRange(idRangeVni, tenantName), owner)
+}
+
+// allocateMacInstanceID returns a free mac id from the tenant's window of the mac range, or if no tenant, from
+// above the windows of the tenants, for the ids record with the key, all the macs are claimed in the one range
+// so the macs of two tenants never collide
+func (cnpd *sfcCtlrL2CNPDriver) allocateMacInstanceID(tenantName string, owner string) (uint32, error) {
+
+	if tenantName == "" {
+		first := uint32(1)
+		for _, tenant := range cnpd.l2CNPEntityCache.Tenants {
+			if tenant.MacRangeEnd >= first {
+				first = tenant.MacRangeEnd + 1
+			}
+		}
+		return cnpd.ids.AllocateFrom(idRangeMac, first, cnpd.maxMacID(), owner)
+	}
+	tenant, exists := cnpd.l2CNPEntityCache.Tenants[tenantName]
+	if !exists {
+		return 0, fmt.Errorf("allocateMacInstanceID: tenant not found: '%s'", tenantName)
+	}
+	return cnpd.ids.AllocateFrom(idRangeMac, tenant.MacRangeStart, tenant.MacRangeEnd, owner)
+}
+
+// tenantScopedName qualifies the name of a per tenant object, ie tunnels, bridges, and id keys, entity
+// names cannot contain the separator so the names of two tenants never collide
+func tenantScopedName(name string, tenantName string) string {
+	if tenantName == "" {
+		return name
+	}
+	return name + utils.ScopeSeparator + tenantName
+}
+
+// tunnelScopedName qualifies the name of the h2e/h2h tunnel state used by the sfc, an sfc with
+// a dedicated vni has its own tunnel, the sfcs of a tenant share the tenant's tunnel, the doubled
+// separator keeps an sfc scoped name apart from a tenant scoped one
+func tunnelScopedName(name string, sfc *controller.SfcEntity) string {
+	if sfc.DedicatedVni {
+		return name + utils.ScopeSeparator + utils.ScopeSeparator + sfc.Name
+	}
+	return tenantScopedName(name, sfc.Tenant)
+}
+
+// tenantEastWestBD returns the tenant's east-west bridge on the host, it is created on first use
+func (cnpd *sfcCtlrL2CNPDriver) tenantEastWestBD(sfc *controller.SfcEntity,
+	hostName string) (*l2.BridgeDomains_BridgeDomain, error) {
+
+	tenantToHEMap, exists := cnpd.l2CNPStateCache.TenantToHEs[sfc.Tenant]
+	if !exists {
+		tenantToHEMap = make(map[string]*heStateType)
+		cnpd.l2CNPStateCache.TenantToHEs[sfc.Tenant] = tenantToHEMap
+	}
+	heState, exists := tenantToHEMap[hostName]
+	if !exists {
+		heState = &heStateType{}
+		tenantToHEMap[hostName] = heState
+	}
+
+	if sfc.Type == controller.SfcType_SFC_EW_BD {
+		if heState.ewBD == nil {
+			bdName := tenantScopedName("BD_INTERNAL_EW_"+hostName, sfc.Tenant)
+			bd, err := cnpd.bridgedDomainCreateWithIfs(hostName, bdName, nil,
+				cnpd.l2CNPEntityCache.SysParms.DynamicBridgeParms)
+			if err != nil {
+				log.Errorf("tenantEastWestBD: error creating BD: '%s'", bdName)
+				return nil, err
+			}
+			heState.ewBD = bd
+		}
+		return heState.ewBD, nil
+	}
+
+	if heState.ewBDL2Fib == nil {
+		bdName := tenantScopedName("BD_INTERNAL_EW_L2FIB_"+hostName, sfc.Tenant)
+		bd, err := cnpd.bridgedDomainCreateWithIfs(hostName, bdName, nil,
+			cnpd.l2CNPEntityCache.SysParms.StaticBridgeParms)
+		if err != nil {
+			log.Errorf("tenantEastWestBD: error creating BD: '%s'", bdName)
+			return nil, err
+		}
+		heState.ewBDL2Fib = bd
+	}
+	return heState.ewBDL2Fib, nil
+}
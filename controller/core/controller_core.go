@@ -21,6 +21,7 @@ package core
 import (
 	"os"
 	"sync"
+	"time"
 
 	"github.com/ligato/cn-infra/core"
 	"github.com/ligato/cn-infra/db/keyval"
@@ -44,6 +45,8 @@ var (
 	cnpDriverName     string // cli flag - see RegisterFlags
 	sfcConfigFile     string // cli flag - see RegisterFlags
 	cleanSfcDatastore bool   // cli flag - see RegisterFlags
+	adminToken        string // cli flag - see RegisterFlags
+	instanceID        string // cli flag - see RegisterFlags
 	log               = logrus.DefaultLogger()
 )
 
@@ -55,6 +58,12 @@ func RegisterFlags() {
 		"Name of a sfc config (yaml) file to load at startup")
 	flag.BoolVar(&cleanSfcDatastore, "clean", false,
 		"Clean the SFC datastore entries")
+	flag.StringVar(&adminToken, "admin-token", "",
+		"Token required in the X-Admin-Token header of REST requests not restricted to a tenant, "+
+			"if not set these requests are not authenticated")
+	flag.StringVar(&instanceID, "instance-id", "",
+		"Required id of this controller, unique among the controllers sharing the etcd; "+
+			"resources claimed by earlier releases are kept by setting it to the former agent label (default vpp1)")
 }
 
 // LogFlags dumps the command line flags
@@ -62,6 +71,7 @@ func LogFlags() {
 	log.Debugf("LogFlags:")
 	log.Debugf("\tcnpDriver:'%s'", cnpDriverName)
 	log.Debugf("\tsfcConfigFile:'%s'", sfcConfigFile)
+	log.Debugf("\tinstanceID:'%s'", instanceID)
 }
 
 // Init is the Go init() function for the sfcCtrlPlugin. It should
@@ -81,6 +91,7 @@ type SfcControllerCacheType struct {
 	EEs      map[string]controller.ExternalEntity
 	HEs      map[string]controller.HostEntity
 	SFCs     map[string]controller.SfcEntity
+	Tenants  map[string]controller.Tenant
 	SysParms controller.SystemParameters
 }
 
@@ -96,6 +107,7 @@ type SfcControllerPluginHandler struct {
 	controllerReady       bool
 	db                    keyval.ProtoBroker
 	ReconcileVppLabelsMap ReconcileVppLabelsMapType
+	instanceDone          chan struct{}
 }
 
 // Init the controller, read the db, reconcile/resync, render config to etcd
@@ -121,8 +133,24 @@ func (sfcCtrlPlugin *SfcControllerPluginHandler) Init() error {
 	// register northbound controller API's
 	sfcCtrlPlugin.InitHTTPHandlers()
 
+	// claim the instance id before the driver uses it to scope its resources
+	hostname, _ := os.Hostname()
+	instance := &controller.ControllerInstance{
+		InstanceId: instanceID,
+		Hostname:   hostname,
+		Pid:        int32(os.Getpid()),
+	}
+	if err := registerInstance(sfcCtrlPlugin.db, sfcCtrlPlugin.Etcd.PutIfNotExists, instance, time.Sleep); err != nil {
+		log.Error("error registering the controller instance: ", err)
+		os.Exit(1)
+	}
+	sfcCtrlPlugin.instanceDone = make(chan struct{})
+	go sfcCtrlPlugin.instanceHeartbeat(instance)
+
 	sfcCtrlPlugin.cnpDriverPlugin, err = cnpdriver.RegisterCNPDriverPlugin(cnpDriverName,
-		func(prefix string) keyval.ProtoBroker { return sfcCtrlPlugin.Etcd.NewBroker(prefix) })
+		instanceID,
+		func(prefix string) keyval.ProtoBroker { return sfcCtrlPlugin.Etcd.NewBroker(prefix) },
+		sfcCtrlPlugin.Etcd.PutIfNotExists)
 	if err != nil {
 		log.Error("error loading cnp driver sfcCtrlPlugin", err)
 		os.Exit(1)
@@ -200,9 +228,13 @@ func (sfcCtrlPlugin *SfcControllerPluginHandler) InitRAMCache() {
 	sfcCtrlPlugin.ramConfigCache.EEs = make(map[string]controller.ExternalEntity)
 	sfcCtrlPlugin.ramConfigCache.HEs = make(map[string]controller.HostEntity)
 	sfcCtrlPlugin.ramConfigCache.SFCs = make(map[string]controller.SfcEntity)
+	sfcCtrlPlugin.ramConfigCache.Tenants = make(map[string]controller.Tenant)
 }
 
 // Close performs close down procedures
 func (sfcCtrlPlugin *SfcControllerPluginHandler) Close() error {
+	if sfcCtrlPlugin.instanceDone != nil {
+		close(sfcCtrlPlugin.instanceDone)
+	}
 	return safeclose.Close(extentitydriver.EEOperationChannel)
 }
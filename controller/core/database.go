@@ -26,6 +26,7 @@ package core
 
 import (
 	"github.com/ligato/sfc-controller/controller/model/controller"
+	"github.com/ligato/sfc-controller/controller/utils"
 )
 
 // WriteRAMCacheToEtcd flushs the ram cache to the sfc cache to the tree in etcd
@@ -45,6 +46,11 @@ func (sfcCtrlPlugin *SfcControllerPluginHandler) WriteRAMCacheToEtcd() error {
 			return err
 		}
 	}
+	for _, tenant := range sfcCtrlPlugin.ramConfigCache.Tenants {
+		if err := sfcCtrlPlugin.DatastoreTenantCreate(&tenant); err != nil {
+			return err
+		}
+	}
 	for _, sfc := range sfcCtrlPlugin.ramConfigCache.SFCs {
 		if err := sfcCtrlPlugin.DatastoreSfcEntityCreate(&sfc); err != nil {
 			return err
@@ -68,6 +74,9 @@ func (sfcCtrlPlugin *SfcControllerPluginHandler) ReadEtcdDatastoreIntoRAMCache()
 	if err := sfcCtrlPlugin.DatastoreHostEntityRetrieveAllIntoRAMCache(); err != nil {
 		return err
 	}
+	if err := sfcCtrlPlugin.DatastoreTenantRetrieveAllIntoRAMCache(); err != nil {
+		return err
+	}
 	if err := sfcCtrlPlugin.DatastoreSfcEntityRetrieveAllIntoRAMCache(); err != nil {
 		return err
 	}
@@ -91,6 +100,9 @@ func (sfcCtrlPlugin *SfcControllerPluginHandler) DatastoreReInitialize() error {
 	if err := sfcCtrlPlugin.DatastoreHostEntityDeleteAll(); err != nil {
 		log.Error("DatastoreReInitialize: DatastoreHostEntityDeleteAll: ", err)
 	}
+	if err := sfcCtrlPlugin.DatastoreTenantDeleteAll(); err != nil {
+		log.Error("DatastoreReInitialize: DatastoreTenantDeleteAll: ", err)
+	}
 	if err := sfcCtrlPlugin.DatastoreSfcEntityDeleteAll(); err != nil {
 		log.Error("DatastoreReInitialize: DatastoreSfcEntityDeleteAll: ", err)
 	}
@@ -275,7 +287,7 @@ func (sfcCtrlPlugin *SfcControllerPluginHandler) DatastoreSfcEntityRetrieveAllIn
 
 	return sfcCtrlPlugin.DatastoreSfcEntityIterate(func(key string, sfc *controller.SfcEntity) {
 		sfcCtrlPlugin.ramConfigCache.SFCs[key] = *sfc
-		log.Infof("DatastoreSfcEntityRetrieveAllIntoRAMCache: adding sfc: '%s': ", key, *sfc)
+		log.Infof("DatastoreSfcEntityRetrieveAllIntoRAMCache: adding sfc: '%s': ", key, utils.RedactSfcEntity(sfc))
 	})
 }
 
@@ -287,7 +299,7 @@ func (sfcCtrlPlugin *SfcControllerPluginHandler) DatastoreSfcEntityDeleteAll() e
 
 	return sfcCtrlPlugin.DatastoreSfcEntityIterate(func(name string, sfc *controller.SfcEntity) {
 		key := controller.SfcEntityNameKey(name)
-		log.Infof("DatastoreSfcEntityDeleteAll: deleting sfc: '%s': ", key, *sfc)
+		log.Infof("DatastoreSfcEntityDeleteAll: deleting sfc: '%s': ", key, utils.RedactSfcEntity(sfc))
 		sfcCtrlPlugin.db.Delete(key)
 	})
 }
@@ -314,7 +326,7 @@ func (sfcCtrlPlugin *SfcControllerPluginHandler) DatastoreSfcEntityIterate(actio
 			return nil
 		}
 
-		log.Infof("DatastoreSfcEntityIterate: getting sfc: '%s': ", sfc.Name, sfc)
+		log.Infof("DatastoreSfcEntityIterate: getting sfc: '%s': ", sfc.Name, utils.RedactSfcEntity(sfc))
 		actionFunc(sfc.Name, sfc)
 
 	}
@@ -327,11 +339,86 @@ func (sfcCtrlPlugin *SfcControllerPluginHandler) DatastoreSfcEntityUpdate(ee *co
 }
 
 // DatastoreSfcEntityDelete deletes the specified entity from the sfc db in the etcd tree
-func (sfcCtrlPlugin *SfcControllerPluginHandler) DatastoreSfcEntityDelete(ee *controller.HostEntity) error {
+func (sfcCtrlPlugin *SfcControllerPluginHandler) DatastoreSfcEntityDelete(sfc *controller.SfcEntity) error {
+
+	key := controller.SfcEntityNameKey(sfc.Name)
 
+	log.Infof("DatastoreSfcEntityDelete: deleting key: '%s'", key)
+
+	if _, err := sfcCtrlPlugin.db.Delete(key); err != nil {
+		log.Errorf("DatastoreSfcEntityDelete: error deleting key: '%s'", key)
+		log.Error("DatastoreSfcEntityDelete: databroker delete: ", err)
+		return err
+	}
 	return nil
 }
 
+// DatastoreTenantCreate creates the specified entity in the sfc db in etcd
+func (sfcCtrlPlugin *SfcControllerPluginHandler) DatastoreTenantCreate(tenant *controller.Tenant) error {
+
+	name := controller.TenantNameKey(tenant.Name)
+
+	log.Infof("DatastoreTenantCreate: setting key: '%s'", name)
+
+	err := sfcCtrlPlugin.db.Put(name, tenant)
+	if err != nil {
+		log.Errorf("DatastoreTenantCreate: error storing key: '%s'", name)
+		log.Error("DatastoreTenantCreate: databroker put: ", err)
+		return err
+	}
+	return nil
+}
+
+// DatastoreTenantRetrieveAllIntoRAMCache pulls the specified entities from the sfc db in etcd into the sfc ram cache
+func (sfcCtrlPlugin *SfcControllerPluginHandler) DatastoreTenantRetrieveAllIntoRAMCache() error {
+
+	return sfcCtrlPlugin.DatastoreTenantIterate(func(key string, tenant *controller.Tenant) {
+		sfcCtrlPlugin.ramConfigCache.Tenants[key] = *tenant
+		log.Infof("DatastoreTenantRetrieveAllIntoRAMCache: adding tenant: '%s'", key)
+	})
+}
+
+// DatastoreTenantDeleteAll removes the specified entities from the sfc db in etcd
+func (sfcCtrlPlugin *SfcControllerPluginHandler) DatastoreTenantDeleteAll() error {
+
+	log.Info("DatastoreTenantDeleteAll: begin ...")
+	defer log.Info("DatastoreTenantDeleteAll: exit ...")
+
+	return sfcCtrlPlugin.DatastoreTenantIterate(func(name string, tenant *controller.Tenant) {
+		key := controller.TenantNameKey(name)
+		log.Infof("DatastoreTenantDeleteAll: deleting tenant: '%s'", key)
+		sfcCtrlPlugin.db.Delete(key)
+	})
+}
+
+// DatastoreTenantIterate iterates over the set of specified entities in the sfc tree in etcd
+func (sfcCtrlPlugin *SfcControllerPluginHandler) DatastoreTenantIterate(actionFunc func(key string,
+	tenant *controller.Tenant)) error {
+
+	kvi, err := sfcCtrlPlugin.db.ListValues(controller.TenantKeyPrefix())
+	if err != nil {
+		log.Fatal(err)
+		return nil
+	}
+
+	for {
+		kv, allReceived := kvi.GetNext()
+		if allReceived {
+			return nil
+		}
+		tenant := &controller.Tenant{}
+		err := kv.GetValue(tenant)
+		if err != nil {
+			log.Fatal(err)
+			return nil
+		}
+
+		log.Infof("DatastoreTenantIterate: getting tenant: '%s'", tenant.Name)
+		actionFunc(tenant.Name, tenant)
+
+	}
+}
+
 // DatastoreSystemParametersCreate creates the specified entity in the sfc db in etcd
 func (sfcCtrlPlugin *SfcControllerPluginHandler) DatastoreSystemParametersCreate(sp *controller.SystemParameters) error {
 
@@ -368,7 +455,7 @@ func (sfcCtrlPlugin *SfcControllerPluginHandler) DatastoreSystemParametersRetrie
 			log.Fatal(err)
 			return nil
 		}
-		log.Infof("DatastoreSystemParametersRetrieveIntoRAMCache: sp: '%s': ", sp)
+		log.Infof("DatastoreSystemParametersRetrieveIntoRAMCache: sp: '%s': ", utils.RedactSystemParameters(sp))
 		sfcCtrlPlugin.ramConfigCache.SysParms = *sp
 	}
 }
@@ -24,6 +24,7 @@ import (
 	"fmt"
 	"github.com/gorilla/mux"
 	"github.com/ligato/sfc-controller/controller/model/controller"
+	"github.com/ligato/sfc-controller/controller/utils"
 	"github.com/unrolled/render"
 	"io/ioutil"
 	"net/http"
@@ -57,12 +58,28 @@ func (sfcCtrlPlugin *SfcControllerPluginHandler) InitHTTPHandlers() {
 	sfcCtrlPlugin.HTTPmux.RegisterHTTPHandler(controller.HostEntitiesHTTPPrefix(), hostEntitiesHandler, "GET")
 
 	url = fmt.Sprintf(controller.SfcEntityKeyPrefix()+"{%s}", entityName)
-	sfcCtrlPlugin.HTTPmux.RegisterHTTPHandler(url, sfcChainHandler, "GET", "POST")
+	sfcCtrlPlugin.HTTPmux.RegisterHTTPHandler(url, sfcChainHandler, "GET", "POST", "DELETE")
 	sfcCtrlPlugin.HTTPmux.RegisterHTTPHandler(controller.SfcEntityHTTPPrefix(), sfcChainsHandler, "GET")
+
+	url = fmt.Sprintf(controller.TenantKeyPrefix()+"{%s}", entityName)
+	sfcCtrlPlugin.HTTPmux.RegisterHTTPHandler(url, tenantHandler, "GET", "POST")
+	sfcCtrlPlugin.HTTPmux.RegisterHTTPHandler(controller.TenantsHTTPPrefix(), tenantsHandler, "GET")
+
+	sfcCtrlPlugin.HTTPmux.RegisterHTTPHandler(controller.IPAMPoolsHTTPPrefix(), ipamPoolsHandler, "GET")
+	sfcCtrlPlugin.HTTPmux.RegisterHTTPHandler(controller.IPAMAllocationsHTTPPrefix(), ipamAllocationsHandler, "GET")
+	sfcCtrlPlugin.HTTPmux.RegisterHTTPHandler(controller.IPAMReserveHTTPPrefix(), ipamReserveHandler, "POST")
+	sfcCtrlPlugin.HTTPmux.RegisterHTTPHandler(controller.IPAMReleaseHTTPPrefix(), ipamReleaseHandler, "POST")
+
+	sfcCtrlPlugin.HTTPmux.RegisterHTTPHandler(controller.InterfaceNamesHTTPPrefix(), interfaceNamesHandler, "GET")
 }
 
 // Example curl invocations: for obtaining ALL external_entities
 //   - GET:  curl -v http://localhost:9191/sfc_controller/api/v1/config/EEs
+//   - GET:  curl -v http://localhost:9191/sfc_controller/api/v1/config/EEs?name_prefix=rtr&limit=10
+// See http_list.go for the pagination and field selection parms, filters:
+//   name_prefix=<prefix>  only external entities whose name starts with prefix
+//   labels=<k1=v1,k2>     only external entities with all of these labels, a key
+//                         without a value matches any value
 func externalEntitiesHandler(formatter *render.Render) http.HandlerFunc {
 
 	sfcplg.HttpMutex.Lock()
@@ -71,13 +88,26 @@ func externalEntitiesHandler(formatter *render.Render) http.HandlerFunc {
 	return func(w http.ResponseWriter, req *http.Request) {
 		log.Debugf("External Entities HTTP handler: Method %s, URL: %s, sfcPlugin", req.Method, req.URL, sfcplg)
 
-		var eeArray = make([]controller.ExternalEntity, 0)
-		for _, ee := range sfcplg.ramConfigCache.EEs {
-			eeArray = append(eeArray, ee)
+		if tenantRestricted(formatter, w, req) {
+			return
 		}
+
 		switch req.Method {
 		case "GET":
-			formatter.JSON(w, http.StatusOK, eeArray)
+			filter, err := parseEntityListFilter(req)
+			if err != nil {
+				formatter.JSON(w, http.StatusBadRequest, struct{ Error string }{err.Error()})
+				return
+			}
+			names := make([]string, 0, len(sfcplg.ramConfigCache.EEs))
+			for name, entity := range sfcplg.ramConfigCache.EEs {
+				if filter.matches(name, entity.Labels) {
+					names = append(names, name)
+				}
+			}
+			writeEntityList(formatter, w, req, names, func(name string) interface{} {
+				return sfcplg.ramConfigCache.EEs[name]
+			})
 			return
 		}
 	}
@@ -93,6 +123,10 @@ func externalEntityHandler(formatter *render.Render) http.HandlerFunc {
 
 	return func(w http.ResponseWriter, req *http.Request) {
 		log.Debugf("External Entity HTTP handler: Method %s, URL: %s", req.Method, req.URL)
+
+		if tenantRestricted(formatter, w, req) {
+			return
+		}
 		switch req.Method {
 		case "GET":
 			vars := mux.Vars(req)
@@ -160,6 +194,11 @@ func processExternalEntityPost(formatter *render.Render, w http.ResponseWriter,
 
 // Example curl invocations: for obtaining ALL host_entities
 //   - GET:  curl -v http://localhost:9191/sfc_controller/api/v1/config/HEs
+//   - GET:  curl -v http://localhost:9191/sfc_controller/api/v1/config/HEs?name_prefix=vswitch&fields=name,eth_ipv4
+// See http_list.go for the pagination and field selection parms, filters:
+//   name_prefix=<prefix>  only host entities whose name starts with prefix
+//   labels=<k1=v1,k2>     only host entities with all of these labels, a key
+//                         without a value matches any value
 func hostEntitiesHandler(formatter *render.Render) http.HandlerFunc {
 
 	sfcplg.HttpMutex.Lock()
@@ -168,13 +207,26 @@ func hostEntitiesHandler(formatter *render.Render) http.HandlerFunc {
 	return func(w http.ResponseWriter, req *http.Request) {
 		log.Debugf("Host Entities HTTP handler: Method %s, URL: %s", req.Method, req.URL)
 
-		var heArray = make([]controller.HostEntity, 0)
-		for _, he := range sfcplg.ramConfigCache.HEs {
-			heArray = append(heArray, he)
+		if tenantRestricted(formatter, w, req) {
+			return
 		}
+
 		switch req.Method {
 		case "GET":
-			formatter.JSON(w, http.StatusOK, heArray)
+			filter, err := parseEntityListFilter(req)
+			if err != nil {
+				formatter.JSON(w, http.StatusBadRequest, struct{ Error string }{err.Error()})
+				return
+			}
+			names := make([]string, 0, len(sfcplg.ramConfigCache.HEs))
+			for name, entity := range sfcplg.ramConfigCache.HEs {
+				if filter.matches(name, entity.Labels) {
+					names = append(names, name)
+				}
+			}
+			writeEntityList(formatter, w, req, names, func(name string) interface{} {
+				return sfcplg.ramConfigCache.HEs[name]
+			})
 			return
 		}
 	}
@@ -191,6 +243,10 @@ func hostEntityHandler(formatter *render.Render) http.HandlerFunc {
 	return func(w http.ResponseWriter, req *http.Request) {
 		log.Debugf("Host Entity HTTP handler: Method %s, URL: %s", req.Method, req.URL)
 
+		if tenantRestricted(formatter, w, req) {
+			return
+		}
+
 		switch req.Method {
 		case "GET":
 			vars := mux.Vars(req)
@@ -257,7 +313,15 @@ func processHostEntityPost(formatter *render.Render, w http.ResponseWriter, req
 
 // Example curl invocations: for obtaining ALL host_entities
 //   - GET:  curl -v http://localhost:9191/sfc_controller/api/v1/config/SFCs
+//   - GET:  curl -v http://localhost:9191/sfc_controller/api/v1/config/SFCs?type=SFC_NS_VXLAN&host=vswitch1
 //   - POST: not supported
+// See http_list.go for the pagination and field selection parms, filters:
+//   type=<sfc type>        only chains of this type, eg SFC_NS_VXLAN
+//   host=<vswitch key>     only chains with an element on this etcd_vpp_switch_key
+//   container=<name>       only chains with an element for this container
+//   name_prefix=<prefix>   only chains whose name starts with prefix
+//   labels=<k1=v1,k2>      only chains with all of these labels
+// A request with a tenant token (see http_tenant.go) only lists that tenant's chains.
 func sfcChainsHandler(formatter *render.Render) http.HandlerFunc {
 
 	sfcplg.HttpMutex.Lock()
@@ -266,13 +330,27 @@ func sfcChainsHandler(formatter *render.Render) http.HandlerFunc {
 	return func(w http.ResponseWriter, req *http.Request) {
 		log.Debugf("SFC Chains HTTP handler: Method %s, URL: %s", req.Method, req.URL)
 
-		var sfcArray = make([]controller.SfcEntity, 0)
-		for _, sfc := range sfcplg.ramConfigCache.SFCs {
-			sfcArray = append(sfcArray, sfc)
-		}
 		switch req.Method {
 		case "GET":
-			formatter.JSON(w, http.StatusOK, sfcArray)
+			reqTenant, ok := authorizeTenant(formatter, w, req)
+			if !ok {
+				return
+			}
+			filter, err := parseSfcListFilter(req)
+			if err != nil {
+				formatter.JSON(w, http.StatusBadRequest, struct{ Error string }{err.Error()})
+				return
+			}
+			names := make([]string, 0, len(sfcplg.ramConfigCache.SFCs))
+			for name, sfc := range sfcplg.ramConfigCache.SFCs {
+				if (reqTenant == "" || reqTenant == sfc.Tenant) && filter.matches(&sfc) {
+					names = append(names, name)
+				}
+			}
+			writeEntityList(formatter, w, req, names, func(name string) interface{} {
+				sfc := sfcplg.ramConfigCache.SFCs[name]
+				return utils.RedactSfcEntity(&sfc)
+			})
 			return
 		}
 	}
@@ -281,6 +359,7 @@ func sfcChainsHandler(formatter *render.Render) http.HandlerFunc {
 // Example curl invocations: for obtaining a provided host_entity
 //   - GET:  curl -v http://localhost:9191/sfc_controller/api/v1/config/SFCs/<chainName>
 //   - POST: curl -v -X POST -d '{"counter":30}' http://localhost:9191/example/test
+//   - DELETE: curl -v -X DELETE http://localhost:9191/sfc-controller/v1/SFC/<chainName>
 func sfcChainHandler(formatter *render.Render) http.HandlerFunc {
 
 	sfcplg.HttpMutex.Lock()
@@ -291,15 +370,22 @@ func sfcChainHandler(formatter *render.Render) http.HandlerFunc {
 
 		switch req.Method {
 		case "GET":
+			reqTenant, ok := authorizeTenant(formatter, w, req)
+			if !ok {
+				return
+			}
 			vars := mux.Vars(req)
-			if sfc, exists := sfcplg.ramConfigCache.SFCs[vars[entityName]]; exists {
-				formatter.JSON(w, http.StatusOK, sfc)
+			if sfc, exists := sfcplg.ramConfigCache.SFCs[vars[entityName]]; exists &&
+				(reqTenant == "" || reqTenant == sfc.Tenant) {
+				formatter.JSON(w, http.StatusOK, utils.RedactSfcEntity(&sfc))
 			} else {
 				formatter.JSON(w, http.StatusNotFound, "sfc chain does not fouind:"+vars[entityName])
 			}
 			return
 		case "POST":
 			processSfcChainPost(formatter, w, req)
+		case "DELETE":
+			processSfcChainDelete(formatter, w, req)
 		}
 	}
 }
@@ -320,6 +406,22 @@ func processSfcChainPost(formatter *render.Render, w http.ResponseWriter, req *h
 		return
 	}
 
+	reqTenant, ok := authorizeTenant(formatter, w, req)
+	if !ok {
+		return
+	}
+	if reqTenant != "" {
+		// a tenant can only post its own sfcs, and cannot take over another tenant's sfc
+		if sfc.Tenant == "" {
+			sfc.Tenant = reqTenant
+		}
+		existing, exists := sfcplg.ramConfigCache.SFCs[sfc.Name]
+		if sfc.Tenant != reqTenant || (exists && existing.Tenant != reqTenant) {
+			formatter.JSON(w, http.StatusForbidden, struct{ Error string }{"not permitted for tenant: " + reqTenant})
+			return
+		}
+	}
+
 	if err := sfcplg.validateSFC(&sfc); err != nil {
 		formatter.JSON(w, http.StatusBadRequest, struct{ Error string }{err.Error()})
 		return
@@ -355,6 +457,41 @@ func processSfcChainPost(formatter *render.Render, w http.ResponseWriter, req *h
 	formatter.JSON(w, http.StatusOK, "OK")
 }
 
+// remove the chain, the config rendered for it is removed by rendering the remaining config in a reconcile, then
+// the addresses, macs and interface names allocated to it are released
+func processSfcChainDelete(formatter *render.Render, w http.ResponseWriter, req *http.Request) {
+
+	reqTenant, ok := authorizeTenant(formatter, w, req)
+	if !ok {
+		return
+	}
+
+	vars := mux.Vars(req)
+	sfc, exists := sfcplg.ramConfigCache.SFCs[vars[entityName]]
+	if !exists || (reqTenant != "" && reqTenant != sfc.Tenant) {
+		formatter.JSON(w, http.StatusNotFound, "sfc chain does not fouind:"+vars[entityName])
+		return
+	}
+
+	if err := sfcplg.DatastoreSfcEntityDelete(&sfc); err != nil {
+		formatter.JSON(w, http.StatusInternalServerError, struct{ Error string }{err.Error()})
+		return
+	}
+	delete(sfcplg.ramConfigCache.SFCs, vars[entityName])
+
+	if err := sfcplg.ReconcileRender(); err != nil {
+		formatter.JSON(w, http.StatusInternalServerError, struct{ Error string }{err.Error()})
+		return
+	}
+
+	if err := sfcplg.cnpDriverPlugin.ReleaseSfcEntity(&sfc); err != nil {
+		formatter.JSON(w, http.StatusInternalServerError, struct{ Error string }{err.Error()})
+		return
+	}
+
+	formatter.JSON(w, http.StatusOK, "OK")
+}
+
 // Example curl invocations: for obtaining the system parameters
 //   - GET:  curl -X GET http://localhost:9191/sfc_controller/api/v1/SP
 //   - POST: curl -v -X POST -d '{"mtu":1500}' http://localhost:9191/sfc_controller/api/v1/SP
@@ -365,10 +502,14 @@ func systemParametersHandler(formatter *render.Render) http.HandlerFunc {
 
 	return func(w http.ResponseWriter, req *http.Request) {
 		log.Debugf("System Parameters HTTP handler: Method %s, URL: %s", req.Method, req.URL)
+
+		if tenantRestricted(formatter, w, req) {
+			return
+		}
 		switch req.Method {
 		case "GET":
 
-			formatter.JSON(w, http.StatusOK, sfcplg.ramConfigCache.SysParms)
+			formatter.JSON(w, http.StatusOK, utils.RedactSystemParameters(&sfcplg.ramConfigCache.SysParms))
 			return
 		case "POST":
 			processSystemParametersPost(formatter, w, req)
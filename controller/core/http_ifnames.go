@@ -0,0 +1,74 @@
+// Copyright (c) 2017 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// The vswitch interface names REST interface.  The names of the vswitch
+// interfaces of the sfc elements are shortened to fit the linux and vpp
+// limits, this maps each sfc, container and port to the names it got, a
+// container wired directly to another container has the name of its end of
+// the pair instead.
+
+package core
+
+import (
+	"net/http"
+
+	"github.com/ligato/sfc-controller/controller/cnpdriver/l2driver"
+	"github.com/unrolled/render"
+)
+
+// Example curl invocations: for obtaining the vswitch interface names of the sfc elements
+//   - GET:  curl -v http://localhost:9191/sfc-controller/v1/InterfaceNames?host=vswitch1
+// See http_list.go for the pagination and field selection parms, filters:
+//   sfc=<name>        only the interfaces of the sfc
+//   container=<name>  only the interfaces of the container
+//   host=<name>       only the interfaces on the host
+func interfaceNamesHandler(formatter *render.Render) http.HandlerFunc {
+
+	sfcplg.HttpMutex.Lock()
+	defer sfcplg.HttpMutex.Unlock()
+
+	return func(w http.ResponseWriter, req *http.Request) {
+		log.Debugf("Interface names HTTP handler: Method %s, URL: %s", req.Method, req.URL)
+
+		switch req.Method {
+		case "GET":
+			reqTenant, ok := authorizeTenant(formatter, w, req)
+			if !ok {
+				return
+			}
+			query := req.URL.Query()
+			sfcName := query.Get("sfc")
+			container := query.Get("container")
+			host := query.Get("host")
+
+			ifNames := make(map[string]l2driver.InterfaceName)
+			names := make([]string, 0)
+			for _, ifName := range sfcplg.cnpDriverPlugin.InterfaceNames() {
+				if (reqTenant != "" && sfcplg.ramConfigCache.SFCs[ifName.Sfc].Tenant != reqTenant) ||
+					(sfcName != "" && ifName.Sfc != sfcName) ||
+					(container != "" && ifName.Container != container) ||
+					(host != "" && ifName.Host != host) {
+					continue
+				}
+				name := ifName.Sfc + "/" + ifName.Container + "/" + ifName.Port
+				ifNames[name] = ifName
+				names = append(names, name)
+			}
+			writeEntityList(formatter, w, req, names, func(name string) interface{} {
+				return ifNames[name]
+			})
+			return
+		}
+	}
+}
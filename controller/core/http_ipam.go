@@ -0,0 +1,264 @@
+// Copyright (c) 2017 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// The ipam REST interface.  The subnets the sfc interface addresses are
+// allocated from, their utilization, and every allocated address with its
+// owner can be inspected, and an address can be reserved by hand for a
+// workload outside of the controller.  A tenant scoped request only sees and
+// reserves the addresses of its own tenant.
+
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/ligato/sfc-controller/controller/utils/ipam"
+	"github.com/unrolled/render"
+)
+
+// ipamPoolUsage is the utilization of a subnet, with the name of the ipam pool if it is a named pool
+type ipamPoolUsage struct {
+	Name string `json:"name,omitempty"`
+	ipam.PoolUsage
+}
+
+// ipamReservation is the body of a manual reservation or release, the subnet
+// is given directly or by the name of an ipam pool
+type ipamReservation struct {
+	Tenant    string `json:"tenant,omitempty"`
+	Subnet    string `json:"subnet,omitempty"`
+	Pool      string `json:"pool,omitempty"`
+	IpAddress string `json:"ip_address"`
+	Owner     string `json:"owner,omitempty"`
+}
+
+// ipamPoolName returns the name of the ipam pool with the prefix, "" if the prefix is not a named pool
+func ipamPoolName(prefix string) string {
+	for _, pool := range sfcplg.ramConfigCache.SysParms.GetIpamPools() {
+		if pool.Prefix == prefix {
+			return pool.Name
+		}
+	}
+	return ""
+}
+
+// Example curl invocations: for obtaining the utilization of the subnets
+//   - GET:  curl -v http://localhost:9191/sfc-controller/v1/IPAM/Pools
+// The used count includes the addresses configured on interfaces, filters:
+//   tenant=<tenant>  only the subnets of the tenant
+//   pool=<name>      only the subnet of the named ipam pool
+func ipamPoolsHandler(formatter *render.Render) http.HandlerFunc {
+
+	sfcplg.HttpMutex.Lock()
+	defer sfcplg.HttpMutex.Unlock()
+
+	return func(w http.ResponseWriter, req *http.Request) {
+		log.Debugf("IPAM pools HTTP handler: Method %s, URL: %s", req.Method, req.URL)
+
+		switch req.Method {
+		case "GET":
+			reqTenant, ok := authorizeTenant(formatter, w, req)
+			if !ok {
+				return
+			}
+			tenant := req.URL.Query().Get("tenant")
+			poolName := req.URL.Query().Get("pool")
+			pools := make([]ipamPoolUsage, 0)
+			for _, usage := range sfcplg.cnpDriverPlugin.IpamPools() {
+				name := ipamPoolName(usage.Subnet)
+				if (reqTenant != "" && usage.Tenant != reqTenant) ||
+					(tenant != "" && usage.Tenant != tenant) ||
+					(poolName != "" && name != poolName) {
+					continue
+				}
+				pools = append(pools, ipamPoolUsage{Name: name, PoolUsage: usage})
+			}
+			formatter.JSON(w, http.StatusOK, pools)
+			return
+		}
+	}
+}
+
+// Example curl invocations: for obtaining the allocated addresses
+//   - GET:  curl -v http://localhost:9191/sfc-controller/v1/IPAM/Allocations?sfc=chain1
+// See http_list.go for the pagination and field selection parms, filters:
+//   tenant=<tenant>  only the addresses of the tenant
+//   subnet=<prefix>  only the addresses of the subnet
+//   pool=<name>      only the addresses of the named ipam pool
+//   sfc=<name>       only the addresses of the sfc's interfaces
+func ipamAllocationsHandler(formatter *render.Render) http.HandlerFunc {
+
+	sfcplg.HttpMutex.Lock()
+	defer sfcplg.HttpMutex.Unlock()
+
+	return func(w http.ResponseWriter, req *http.Request) {
+		log.Debugf("IPAM allocations HTTP handler: Method %s, URL: %s", req.Method, req.URL)
+
+		switch req.Method {
+		case "GET":
+			reqTenant, ok := authorizeTenant(formatter, w, req)
+			if !ok {
+				return
+			}
+			query := req.URL.Query()
+			tenant := query.Get("tenant")
+			subnet := query.Get("subnet")
+			if poolName := query.Get("pool"); poolName != "" {
+				pool := sfcplg.findIpamPool(poolName)
+				if pool == nil {
+					formatter.JSON(w, http.StatusNotFound, "ipam pool not found:"+poolName)
+					return
+				}
+				subnet = pool.Prefix
+			}
+			sfcName := query.Get("sfc")
+
+			// name the allocations so the list pages in tenant, subnet, id order
+			allocs := make(map[string]ipam.Allocation)
+			names := make([]string, 0)
+			for _, alloc := range sfcplg.cnpDriverPlugin.IpamAllocations() {
+				if (reqTenant != "" && alloc.Tenant != reqTenant) ||
+					(tenant != "" && alloc.Tenant != tenant) ||
+					(subnet != "" && alloc.Subnet != subnet) ||
+					(sfcName != "" && !strings.HasPrefix(alloc.Owner, sfcName+"/")) {
+					continue
+				}
+				name := fmt.Sprintf("%s/%s/%010d", alloc.Tenant, alloc.Subnet, alloc.IpID)
+				allocs[name] = alloc
+				names = append(names, name)
+			}
+			writeEntityList(formatter, w, req, names, func(name string) interface{} {
+				return allocs[name]
+			})
+			return
+		}
+	}
+}
+
+// Example curl invocations: for reserving an address by hand
+//   - POST: curl -v -X POST -d '{"pool":"mgmt","ip_address":"10.1.1.50","owner":"dns"}'
+//           http://localhost:9191/sfc-controller/v1/IPAM/Reserve
+func ipamReserveHandler(formatter *render.Render) http.HandlerFunc {
+
+	sfcplg.HttpMutex.Lock()
+	defer sfcplg.HttpMutex.Unlock()
+
+	return func(w http.ResponseWriter, req *http.Request) {
+		log.Debugf("IPAM reserve HTTP handler: Method %s, URL: %s", req.Method, req.URL)
+
+		switch req.Method {
+		case "POST":
+			r, ok := parseIpamReservation(formatter, w, req)
+			if !ok {
+				return
+			}
+			if r.Owner == "" {
+				formatter.JSON(w, http.StatusBadRequest, struct{ Error string }{"owner is required"})
+				return
+			}
+			if err := sfcplg.cnpDriverPlugin.IpamReserve(r.Tenant, r.Subnet, r.IpAddress, r.Owner); err != nil {
+				formatter.JSON(w, http.StatusBadRequest, struct{ Error string }{err.Error()})
+				return
+			}
+			formatter.JSON(w, http.StatusOK, "OK")
+		}
+	}
+}
+
+// Example curl invocations: for releasing an address reserved by hand
+//   - POST: curl -v -X POST -d '{"pool":"mgmt","ip_address":"10.1.1.50"}'
+//           http://localhost:9191/sfc-controller/v1/IPAM/Release
+func ipamReleaseHandler(formatter *render.Render) http.HandlerFunc {
+
+	sfcplg.HttpMutex.Lock()
+	defer sfcplg.HttpMutex.Unlock()
+
+	return func(w http.ResponseWriter, req *http.Request) {
+		log.Debugf("IPAM release HTTP handler: Method %s, URL: %s", req.Method, req.URL)
+
+		switch req.Method {
+		case "POST":
+			r, ok := parseIpamReservation(formatter, w, req)
+			if !ok {
+				return
+			}
+			if err := sfcplg.cnpDriverPlugin.IpamRelease(r.Tenant, r.Subnet, r.IpAddress); err != nil {
+				formatter.JSON(w, http.StatusBadRequest, struct{ Error string }{err.Error()})
+				return
+			}
+			formatter.JSON(w, http.StatusOK, "OK")
+		}
+	}
+}
+
+// parseIpamReservation parses the body of a reservation or release and resolves the subnet of the pool, a
+// tenant scoped request can only reserve addresses of its own tenant, on error a response is written
+func parseIpamReservation(formatter *render.Render, w http.ResponseWriter,
+	req *http.Request) (*ipamReservation, bool) {
+
+	reqTenant, ok := authorizeTenant(formatter, w, req)
+	if !ok {
+		return nil, false
+	}
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		log.Debugf("Can't read body, error '%s'", err)
+		formatter.JSON(w, http.StatusBadRequest, struct{ Error string }{err.Error()})
+		return nil, false
+	}
+	var r ipamReservation
+	if err := json.Unmarshal(body, &r); err != nil {
+		log.Debugf("Can't parse body, error '%s'", err)
+		formatter.JSON(w, http.StatusBadRequest, struct{ Error string }{err.Error()})
+		return nil, false
+	}
+
+	if reqTenant != "" {
+		if r.Tenant != "" && r.Tenant != reqTenant {
+			formatter.JSON(w, http.StatusForbidden, struct{ Error string }{"not permitted for tenant: " + reqTenant})
+			return nil, false
+		}
+		r.Tenant = reqTenant
+	}
+	if r.Tenant != "" {
+		if _, exists := sfcplg.ramConfigCache.Tenants[r.Tenant]; !exists {
+			formatter.JSON(w, http.StatusNotFound, "tenant not found:"+r.Tenant)
+			return nil, false
+		}
+	}
+
+	if r.Pool != "" {
+		if r.Subnet != "" {
+			formatter.JSON(w, http.StatusBadRequest,
+				struct{ Error string }{"subnet and pool are mutually exclusive"})
+			return nil, false
+		}
+		pool := sfcplg.findIpamPool(r.Pool)
+		if pool == nil {
+			formatter.JSON(w, http.StatusNotFound, "ipam pool not found:"+r.Pool)
+			return nil, false
+		}
+		r.Subnet = pool.Prefix
+	}
+	if r.Subnet == "" || r.IpAddress == "" {
+		formatter.JSON(w, http.StatusBadRequest, struct{ Error string }{"subnet or pool, and ip_address are required"})
+		return nil, false
+	}
+
+	return &r, true
+}
@@ -0,0 +1,265 @@
+// Copyright (c) 2017 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Helpers for the REST list endpoints (EEs, HEs, SFCs).  Entities are always
+// returned sorted by name so the output is stable between calls.  The
+// following optional query parameters are supported by all list endpoints:
+//
+//   limit=<n>          return at most n entities
+//   continue=<token>   resume after the last entity of the previous page, the
+//                      token is returned in the X-Continue-Token header
+//   fields=<f1,f2,..>  only return the listed json fields of each entity
+//
+// Entity specific filters are documented on each list handler.
+
+package core
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ligato/sfc-controller/controller/model/controller"
+	"github.com/ligato/sfc-controller/controller/utils"
+	"github.com/unrolled/render"
+)
+
+const (
+	listLimitParam    = "limit"
+	listContinueParam = "continue"
+	listFieldsParam   = "fields"
+
+	namePrefixFilter   = "name_prefix"
+	labelsFilter       = "labels"
+	sfcTypeFilter      = "type"
+	sfcHostFilter      = "host"
+	sfcContainerFilter = "container"
+
+	// ContinueTokenHeader carries the token for the next page of a list
+	ContinueTokenHeader = "X-Continue-Token"
+)
+
+// listOptions are the pagination and field selection parms of a list request
+type listOptions struct {
+	limit         int
+	continueAfter string
+	fields        []string
+}
+
+// parseListOptions pulls the pagination and field selection parms from the url query
+func parseListOptions(req *http.Request) (*listOptions, error) {
+
+	query := req.URL.Query()
+	lo := &listOptions{}
+
+	if limitStr := query.Get(listLimitParam); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 {
+			return nil, fmt.Errorf("invalid %s: '%s'", listLimitParam, limitStr)
+		}
+		lo.limit = limit
+	}
+
+	if token := query.Get(listContinueParam); token != "" {
+		name, err := base64.RawURLEncoding.DecodeString(token)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s token: '%s'", listContinueParam, token)
+		}
+		lo.continueAfter = string(name)
+	}
+
+	if fieldsStr := query.Get(listFieldsParam); fieldsStr != "" {
+		for _, field := range strings.Split(fieldsStr, ",") {
+			if field = strings.TrimSpace(field); field != "" {
+				lo.fields = append(lo.fields, field)
+			}
+		}
+	}
+
+	return lo, nil
+}
+
+// paginate sorts the names, and returns the page of names selected by the
+// list options along with the continue token for the next page (if any)
+func (lo *listOptions) paginate(names []string) ([]string, string) {
+
+	sort.Strings(names)
+
+	start := 0
+	if lo.continueAfter != "" {
+		start = sort.SearchStrings(names, lo.continueAfter)
+		if start < len(names) && names[start] == lo.continueAfter {
+			start++
+		}
+	}
+	page := names[start:]
+
+	if lo.limit == 0 || len(page) <= lo.limit {
+		return page, ""
+	}
+	page = page[:lo.limit]
+
+	return page, base64.RawURLEncoding.EncodeToString([]byte(page[len(page)-1]))
+}
+
+// selectFields returns only the requested json fields of the entity, or the
+// entity itself if no fields were requested
+func (lo *listOptions) selectFields(entity interface{}) (interface{}, error) {
+
+	if len(lo.fields) == 0 {
+		return entity, nil
+	}
+
+	b, err := json.Marshal(entity)
+	if err != nil {
+		return nil, err
+	}
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(b, &all); err != nil {
+		return nil, err
+	}
+
+	selected := make(map[string]json.RawMessage, len(lo.fields))
+	for _, field := range lo.fields {
+		if val, exists := all[field]; exists {
+			selected[field] = val
+		}
+	}
+	return selected, nil
+}
+
+// writeEntityList renders the page of entities selected by the request's list
+// options, lookup returns the entity for a name from the matching set of names
+func writeEntityList(formatter *render.Render, w http.ResponseWriter, req *http.Request, names []string,
+	lookup func(name string) interface{}) {
+
+	lo, err := parseListOptions(req)
+	if err != nil {
+		formatter.JSON(w, http.StatusBadRequest, struct{ Error string }{err.Error()})
+		return
+	}
+
+	page, continueToken := lo.paginate(names)
+
+	entities := make([]interface{}, 0, len(page))
+	for _, name := range page {
+		entity, err := lo.selectFields(lookup(name))
+		if err != nil {
+			formatter.JSON(w, http.StatusInternalServerError, struct{ Error string }{err.Error()})
+			return
+		}
+		entities = append(entities, entity)
+	}
+
+	if continueToken != "" {
+		w.Header().Set(ContinueTokenHeader, continueToken)
+	}
+	formatter.JSON(w, http.StatusOK, entities)
+}
+
+// entityListFilter holds the name prefix and label filters common to all lists
+type entityListFilter struct {
+	namePrefix string
+	labels     map[string]string
+}
+
+// parseEntityListFilter pulls the name prefix and label selector from the url query
+func parseEntityListFilter(req *http.Request) (*entityListFilter, error) {
+
+	query := req.URL.Query()
+	filter := &entityListFilter{
+		namePrefix: query.Get(namePrefixFilter),
+	}
+
+	if selector := query.Get(labelsFilter); selector != "" {
+		labels, err := utils.ParseLabelSelector(selector)
+		if err != nil {
+			return nil, err
+		}
+		filter.labels = labels
+	}
+
+	return filter, nil
+}
+
+// matches returns true if the entity name and labels satisfy the filter
+func (filter *entityListFilter) matches(name string, labels map[string]string) bool {
+	return strings.HasPrefix(name, filter.namePrefix) && utils.MatchLabels(filter.labels, labels)
+}
+
+// sfcListFilter holds the SFC specific filters of a list request
+type sfcListFilter struct {
+	entityListFilter
+	sfcType   *controller.SfcType
+	host      string
+	container string
+}
+
+// parseSfcListFilter pulls the sfc type, host, and container filters from the url query
+func parseSfcListFilter(req *http.Request) (*sfcListFilter, error) {
+
+	entityFilter, err := parseEntityListFilter(req)
+	if err != nil {
+		return nil, err
+	}
+
+	query := req.URL.Query()
+	filter := &sfcListFilter{
+		entityListFilter: *entityFilter,
+		host:             query.Get(sfcHostFilter),
+		container:        query.Get(sfcContainerFilter),
+	}
+
+	if typeStr := query.Get(sfcTypeFilter); typeStr != "" {
+		sfcType, exists := controller.SfcType_value[typeStr]
+		if !exists {
+			return nil, fmt.Errorf("invalid %s: '%s'", sfcTypeFilter, typeStr)
+		}
+		t := controller.SfcType(sfcType)
+		filter.sfcType = &t
+	}
+
+	return filter, nil
+}
+
+// matches returns true if the sfc satisfies all of the filters
+func (filter *sfcListFilter) matches(sfc *controller.SfcEntity) bool {
+
+	if !filter.entityListFilter.matches(sfc.Name, sfc.Labels) {
+		return false
+	}
+	if filter.sfcType != nil && sfc.Type != *filter.sfcType {
+		return false
+	}
+	if filter.host == "" && filter.container == "" {
+		return true
+	}
+
+	hostFound := filter.host == ""
+	containerFound := filter.container == ""
+	for _, sfcElement := range sfc.GetElements() {
+		if sfcElement.EtcdVppSwitchKey == filter.host {
+			hostFound = true
+		}
+		if sfcElement.Container == filter.container {
+			containerFound = true
+		}
+	}
+
+	return hostFound && containerFound
+}
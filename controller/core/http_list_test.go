@@ -0,0 +1,137 @@
+// Copyright (c) 2017 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/ligato/sfc-controller/controller/model/controller"
+)
+
+func TestParseListOptions(t *testing.T) {
+	tests := []struct {
+		query   string
+		want    listOptions
+		wantErr bool
+	}{
+		{"", listOptions{}, false},
+		{"limit=2", listOptions{limit: 2}, false},
+		{"continue=" + base64.RawURLEncoding.EncodeToString([]byte("sfc1")), listOptions{continueAfter: "sfc1"}, false},
+		{"fields=name,%20type,,labels", listOptions{fields: []string{"name", "type", "labels"}}, false},
+		{"limit=0", listOptions{}, true},
+		{"limit=x", listOptions{}, true},
+		{"continue=%25%25", listOptions{}, true},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest("GET", "/sfc-controller/v1/SFCs?"+test.query, nil)
+		got, err := parseListOptions(req)
+		if (err != nil) != test.wantErr {
+			t.Errorf("parseListOptions('%s') error = %v, wantErr %v", test.query, err, test.wantErr)
+			continue
+		}
+		if !test.wantErr && !reflect.DeepEqual(*got, test.want) {
+			t.Errorf("parseListOptions('%s') = %+v, want %+v", test.query, *got, test.want)
+		}
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	token := func(name string) string { return base64.RawURLEncoding.EncodeToString([]byte(name)) }
+	names := []string{"d", "b", "a", "c"}
+	tests := []struct {
+		lo        listOptions
+		wantPage  []string
+		wantToken string
+	}{
+		{listOptions{}, []string{"a", "b", "c", "d"}, ""},
+		{listOptions{limit: 2}, []string{"a", "b"}, token("b")},
+		{listOptions{limit: 2, continueAfter: "b"}, []string{"c", "d"}, ""},
+		{listOptions{limit: 4}, []string{"a", "b", "c", "d"}, ""},
+		{listOptions{continueAfter: "bb"}, []string{"c", "d"}, ""},
+		{listOptions{continueAfter: "d"}, []string{}, ""},
+	}
+	for _, test := range tests {
+		page, continueToken := test.lo.paginate(append([]string{}, names...))
+		if !reflect.DeepEqual(page, test.wantPage) || continueToken != test.wantToken {
+			t.Errorf("paginate(%+v) = %v, '%s', want %v, '%s'", test.lo, page, continueToken, test.wantPage,
+				test.wantToken)
+		}
+	}
+}
+
+func TestSelectFields(t *testing.T) {
+	ee := &controller.ExternalEntity{Name: "ee1", MgmntIpAddress: "10.0.0.1"}
+
+	lo := &listOptions{}
+	if got, _ := lo.selectFields(ee); got != ee {
+		t.Errorf("selectFields without fields = %v, want the entity", got)
+	}
+
+	lo = &listOptions{fields: []string{"name", "unknown"}}
+	got, err := lo.selectFields(ee)
+	if err != nil {
+		t.Fatalf("selectFields error = %v", err)
+	}
+	selected, ok := got.(map[string]json.RawMessage)
+	if !ok || len(selected) != 1 || string(selected["name"]) != `"ee1"` {
+		t.Errorf("selectFields(%v) = %v, want only the name", lo.fields, got)
+	}
+}
+
+func TestSfcListFilter(t *testing.T) {
+	sfc := &controller.SfcEntity{
+		Name:   "sfc-fw",
+		Type:   controller.SfcType_SFC_NS_VXLAN,
+		Labels: map[string]string{"app": "fw"},
+		Elements: []*controller.SfcEntity_SfcElement{
+			{Container: "vnf1", PortLabel: "port1", EtcdVppSwitchKey: "host1"},
+			{Container: "vnf2", PortLabel: "port1", EtcdVppSwitchKey: "host2"},
+		},
+	}
+	tests := []struct {
+		query   string
+		want    bool
+		wantErr bool
+	}{
+		{"", true, false},
+		{"name_prefix=sfc-", true, false},
+		{"name_prefix=ee-", false, false},
+		{"labels=app=fw", true, false},
+		{"labels=app=lb", false, false},
+		{"type=SFC_NS_VXLAN", true, false},
+		{"type=SFC_EW_BD", false, false},
+		{"type=BOGUS", false, true},
+		{"host=host2", true, false},
+		{"host=host3", false, false},
+		{"container=vnf1&host=host2", true, false},
+		{"container=vnf3&host=host1", false, false},
+		{"labels==fw", false, true},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest("GET", "/sfc-controller/v1/SFCs?"+test.query, nil)
+		filter, err := parseSfcListFilter(req)
+		if (err != nil) != test.wantErr {
+			t.Errorf("parseSfcListFilter('%s') error = %v, wantErr %v", test.query, err, test.wantErr)
+			continue
+		}
+		if !test.wantErr && filter.matches(sfc) != test.want {
+			t.Errorf("filter '%s' matches = %v, want %v", test.query, !test.want, test.want)
+		}
+	}
+}
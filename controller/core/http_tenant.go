@@ -0,0 +1,230 @@
+// Copyright (c) 2017 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// The tenant REST interface, and the per tenant restriction of the REST api.
+// A request carrying a tenant's api_token in the X-Tenant-Token header is
+// restricted to that tenant: it only sees and posts the tenant's own SFCs,
+// and cannot access the system parameters, external and host entities, or
+// other tenants.  When the admin token is configured (see the admin-token
+// flag), all other requests must carry it in the X-Admin-Token header, without
+// it configured they are served unrestricted as before tenants existed.  The
+// api_token is write only, it is never returned in a response.
+
+package core
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/ligato/sfc-controller/controller/model/controller"
+	"github.com/ligato/sfc-controller/controller/utils"
+	"github.com/unrolled/render"
+)
+
+// TenantTokenHeader carries the api_token of the tenant the request is restricted to
+const TenantTokenHeader = "X-Tenant-Token"
+
+// AdminTokenHeader carries the admin token of a request not restricted to a tenant
+const AdminTokenHeader = "X-Admin-Token"
+
+// requestTenant returns the name of the tenant whose token is in the request,
+// or "" if the request is not restricted to a tenant, ie: it carries the admin
+// token, or no admin token is configured
+func requestTenant(req *http.Request) (string, error) {
+
+	token := req.Header.Get(TenantTokenHeader)
+	if token == "" {
+		if adminToken == "" {
+			return "", nil
+		}
+		admin := req.Header.Get(AdminTokenHeader)
+		if admin == "" || subtle.ConstantTimeCompare([]byte(adminToken), []byte(admin)) != 1 {
+			return "", fmt.Errorf("missing or invalid admin token")
+		}
+		return "", nil
+	}
+	for _, tenant := range sfcplg.ramConfigCache.Tenants {
+		if tenant.ApiToken != "" &&
+			subtle.ConstantTimeCompare([]byte(tenant.ApiToken), []byte(token)) == 1 {
+			return tenant.Name, nil
+		}
+	}
+	return "", fmt.Errorf("unknown tenant token")
+}
+
+// authorizeTenant returns the tenant of the request, if the token is invalid,
+// an unauthorized response is written and ok is false
+func authorizeTenant(formatter *render.Render, w http.ResponseWriter, req *http.Request) (string, bool) {
+
+	tenant, err := requestTenant(req)
+	if err != nil {
+		formatter.JSON(w, http.StatusUnauthorized, struct{ Error string }{err.Error()})
+		return "", false
+	}
+	return tenant, true
+}
+
+// tenantRestricted writes an error response and returns true if the request
+// is restricted to a tenant, it guards the non tenant aware entities
+func tenantRestricted(formatter *render.Render, w http.ResponseWriter, req *http.Request) bool {
+
+	tenant, ok := authorizeTenant(formatter, w, req)
+	if !ok {
+		return true
+	}
+	if tenant != "" {
+		formatter.JSON(w, http.StatusForbidden, struct{ Error string }{"not permitted for tenant: " + tenant})
+		return true
+	}
+	return false
+}
+
+// Example curl invocations: for obtaining ALL tenants
+//   - GET:  curl -v http://localhost:9191/sfc-controller/v1/Tenants
+// A tenant scoped request only lists its own tenant, filters:
+//   name_prefix=<prefix>  only tenants whose name starts with prefix
+//   labels=<k1=v1,k2>     only tenants with all of these labels
+func tenantsHandler(formatter *render.Render) http.HandlerFunc {
+
+	sfcplg.HttpMutex.Lock()
+	defer sfcplg.HttpMutex.Unlock()
+
+	return func(w http.ResponseWriter, req *http.Request) {
+		log.Debugf("Tenants HTTP handler: Method %s, URL: %s", req.Method, req.URL)
+
+		switch req.Method {
+		case "GET":
+			reqTenant, ok := authorizeTenant(formatter, w, req)
+			if !ok {
+				return
+			}
+			filter, err := parseEntityListFilter(req)
+			if err != nil {
+				formatter.JSON(w, http.StatusBadRequest, struct{ Error string }{err.Error()})
+				return
+			}
+			names := make([]string, 0, len(sfcplg.ramConfigCache.Tenants))
+			for name, tenant := range sfcplg.ramConfigCache.Tenants {
+				if (reqTenant == "" || reqTenant == name) && filter.matches(name, tenant.Labels) {
+					names = append(names, name)
+				}
+			}
+			writeEntityList(formatter, w, req, names, func(name string) interface{} {
+				tenant := sfcplg.ramConfigCache.Tenants[name]
+				return utils.RedactTenant(&tenant)
+			})
+			return
+		}
+	}
+}
+
+// Example curl invocations: for obtaining a provided tenant
+//   - GET:  curl -v http://localhost:9191/sfc-controller/v1/Tenant/<tenantName>
+//   - POST: curl -v -X POST -d '{"name":"acme","vni_range_start":100,"vni_range_end":199,
+//           "mac_range_start":1000,"mac_range_end":1999}' http://localhost:9191/sfc-controller/v1/Tenant/acme
+func tenantHandler(formatter *render.Render) http.HandlerFunc {
+
+	sfcplg.HttpMutex.Lock()
+	defer sfcplg.HttpMutex.Unlock()
+
+	return func(w http.ResponseWriter, req *http.Request) {
+		log.Debugf("Tenant HTTP handler: Method %s, URL: %s", req.Method, req.URL)
+
+		switch req.Method {
+		case "GET":
+			reqTenant, ok := authorizeTenant(formatter, w, req)
+			if !ok {
+				return
+			}
+			vars := mux.Vars(req)
+			if tenant, exists := sfcplg.ramConfigCache.Tenants[vars[entityName]]; exists &&
+				(reqTenant == "" || reqTenant == tenant.Name) {
+				formatter.JSON(w, http.StatusOK, utils.RedactTenant(&tenant))
+			} else {
+				formatter.JSON(w, http.StatusNotFound, "tenant not found:"+vars[entityName])
+			}
+			return
+		case "POST":
+			if tenantRestricted(formatter, w, req) {
+				return
+			}
+			processTenantPost(formatter, w, req)
+		}
+	}
+}
+
+// create the tenant and hand its ranges to the driver
+func processTenantPost(formatter *render.Render, w http.ResponseWriter, req *http.Request) {
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		log.Debugf("Can't read body, error '%s'", err)
+		formatter.JSON(w, http.StatusBadRequest, struct{ Error string }{err.Error()})
+		return
+	}
+	var tenant controller.Tenant
+	err = json.Unmarshal(body, &tenant)
+	if err != nil {
+		log.Debugf("Can't parse body, error '%s'", err)
+		formatter.JSON(w, http.StatusBadRequest, struct{ Error string }{err.Error()})
+		return
+	}
+
+	vars := mux.Vars(req)
+	if vars[entityName] != tenant.Name {
+		formatter.JSON(w, http.StatusBadRequest, "json name does not matach url name")
+		return
+	}
+
+	if existing, exists := sfcplg.ramConfigCache.Tenants[vars[entityName]]; exists {
+		// the token is never returned, so a post without one keeps the existing token
+		if tenant.ApiToken == "" {
+			tenant.ApiToken = existing.ApiToken
+		}
+		if tenant.String() == existing.String() {
+			formatter.JSON(w, http.StatusOK, "OK")
+			return
+		}
+		// the ranges are in use by the tenant's sfcs, only the token, labels, etc can change
+		if tenant.VniRangeStart != existing.VniRangeStart || tenant.VniRangeEnd != existing.VniRangeEnd ||
+			tenant.MacRangeStart != existing.MacRangeStart || tenant.MacRangeEnd != existing.MacRangeEnd ||
+			tenant.EeBdId != existing.EeBdId {
+			formatter.JSON(w, http.StatusBadRequest,
+				struct{ Error string }{"vni/mac ranges and ee_bd_id of an existing tenant cannot be changed"})
+			return
+		}
+	}
+
+	if err := sfcplg.validateTenant(&tenant); err != nil {
+		formatter.JSON(w, http.StatusBadRequest, struct{ Error string }{err.Error()})
+		return
+	}
+
+	sfcplg.ramConfigCache.Tenants[vars[entityName]] = tenant
+
+	if err := sfcplg.DatastoreTenantCreate(&tenant); err != nil {
+		formatter.JSON(w, http.StatusInternalServerError, struct{ Error string }{err.Error()})
+		return
+	}
+
+	if err := sfcplg.renderTenant(&tenant); err != nil {
+		formatter.JSON(w, http.StatusBadRequest, struct{ Error string }{err.Error()})
+		return
+	}
+
+	formatter.JSON(w, http.StatusOK, "OK")
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2017 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ligato/sfc-controller/controller/model/controller"
+)
+
+func TestRequestTenant(t *testing.T) {
+	savedPlugin, savedAdminToken := sfcplg, adminToken
+	defer func() { sfcplg, adminToken = savedPlugin, savedAdminToken }()
+
+	sfcplg = &SfcControllerPluginHandler{}
+	sfcplg.ramConfigCache.Tenants = map[string]controller.Tenant{
+		"t1": {Name: "t1", ApiToken: "t1-token"},
+		"t2": {Name: "t2"},
+	}
+
+	tests := []struct {
+		name       string
+		adminToken string
+		headers    map[string]string
+		wantTenant string
+		wantErr    bool
+	}{
+		{"no admin token configured", "", nil, "", false},
+		{"no admin token configured, tenant token", "", map[string]string{TenantTokenHeader: "t1-token"}, "t1", false},
+		{"no admin token configured, unknown tenant token", "", map[string]string{TenantTokenHeader: "x"}, "", true},
+		{"admin token", "secret", map[string]string{AdminTokenHeader: "secret"}, "", false},
+		{"missing admin token", "secret", nil, "", true},
+		{"wrong admin token", "secret", map[string]string{AdminTokenHeader: "secrets"}, "", true},
+		{"tenant token", "secret", map[string]string{TenantTokenHeader: "t1-token"}, "t1", false},
+		{"tenant token with the admin token", "secret",
+			map[string]string{TenantTokenHeader: "t1-token", AdminTokenHeader: "secret"}, "t1", false},
+		{"unknown tenant token", "secret", map[string]string{TenantTokenHeader: "secret"}, "", true},
+	}
+	for _, test := range tests {
+		adminToken = test.adminToken
+		req := httptest.NewRequest("GET", "/sfc-controller/v1/SFCs", nil)
+		for header, value := range test.headers {
+			req.Header.Set(header, value)
+		}
+		tenant, err := requestTenant(req)
+		if (err != nil) != test.wantErr || tenant != test.wantTenant {
+			t.Errorf("%s: tenant '%s', error %v, want '%s', error %t", test.name, tenant, err, test.wantTenant,
+				test.wantErr)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+// Copyright (c) 2017 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// The controller instance id scopes the resources (vlans, vnis, ...) that a
+// controller claims in etcd.  Controllers sharing an etcd must use distinct
+// ids, otherwise one would free the claims of the other on reconcile.  The
+// id is registered in etcd and kept alive with a heartbeat so a second
+// controller started with the same id refuses to run.
+
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ligato/cn-infra/db/keyval"
+	"github.com/ligato/sfc-controller/controller/model/controller"
+)
+
+const (
+	instanceHeartbeatInterval = 10 * time.Second
+	instanceHeartbeatExpiry   = 3 * instanceHeartbeatInterval
+)
+
+// registerInstance claims the instance id in etcd.  If a live controller
+// holds the id, i.e. its heartbeat is younger than the expiry, the heartbeat
+// is given one expiry period to lapse before the id is refused.
+func registerInstance(db keyval.ProtoBroker,
+	putIfNotExists func(key string, value []byte) (bool, error),
+	instance *controller.ControllerInstance,
+	wait func(time.Duration)) error {
+
+	if instance.InstanceId == "" {
+		return fmt.Errorf("the instance-id flag is required: it must be unique among the controllers sharing the etcd")
+	}
+
+	key := controller.ControllerInstanceKey(instance.InstanceId)
+
+	existing := &controller.ControllerInstance{}
+	found, _, err := db.GetValue(key, existing)
+	if err != nil {
+		return err
+	}
+	if found && instanceAlive(existing) {
+		log.Infof("registerInstance: instance '%s' held by host '%s' pid %d, waiting for its heartbeat to expire",
+			instance.InstanceId, existing.Hostname, existing.Pid)
+		heartbeat := existing.Heartbeat
+		wait(instanceHeartbeatExpiry)
+		existing = &controller.ControllerInstance{}
+		if found, _, err = db.GetValue(key, existing); err != nil {
+			return err
+		}
+		if found && existing.Heartbeat != heartbeat {
+			return fmt.Errorf("instance id '%s' is in use by the controller on host '%s' pid %d",
+				instance.InstanceId, existing.Hostname, existing.Pid)
+		}
+	}
+	if found {
+		log.Infof("registerInstance: taking over the stale registration of instance '%s' from host '%s' pid %d",
+			instance.InstanceId, existing.Hostname, existing.Pid)
+		if _, err := db.Delete(key); err != nil {
+			return err
+		}
+	}
+
+	instance.Heartbeat = time.Now().Unix()
+	data, err := json.Marshal(instance)
+	if err != nil {
+		return err
+	}
+	succeeded, err := putIfNotExists(key, data)
+	if err != nil {
+		return err
+	}
+	if !succeeded {
+		return fmt.Errorf("instance id '%s' is in use by another controller", instance.InstanceId)
+	}
+
+	log.Infof("registerInstance: registered instance '%s'", instance.InstanceId)
+
+	return nil
+}
+
+// refreshInstance updates the heartbeat of the registered instance, lost is
+// set if another controller has taken over the id meanwhile
+func refreshInstance(db keyval.ProtoBroker, instance *controller.ControllerInstance) (lost bool, err error) {
+
+	key := controller.ControllerInstanceKey(instance.InstanceId)
+
+	existing := &controller.ControllerInstance{}
+	found, _, err := db.GetValue(key, existing)
+	if err != nil {
+		return false, err
+	}
+	if found && (existing.Hostname != instance.Hostname || existing.Pid != instance.Pid) {
+		return true, fmt.Errorf("instance id '%s' taken over by the controller on host '%s' pid %d",
+			instance.InstanceId, existing.Hostname, existing.Pid)
+	}
+
+	instance.Heartbeat = time.Now().Unix()
+
+	return false, db.Put(key, instance)
+}
+
+// instanceHeartbeat keeps the registration alive until the plugin is closed,
+// the controller exits if it lost the instance id as its claims are no longer
+// its own
+func (sfcCtrlPlugin *SfcControllerPluginHandler) instanceHeartbeat(instance *controller.ControllerInstance) {
+
+	ticker := time.NewTicker(instanceHeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-sfcCtrlPlugin.instanceDone:
+			return
+		case <-ticker.C:
+			lost, err := refreshInstance(sfcCtrlPlugin.db, instance)
+			if err != nil {
+				log.Errorf("instanceHeartbeat: %s", err)
+			}
+			if lost {
+				os.Exit(1)
+			}
+		}
+	}
+}
+
+func instanceAlive(instance *controller.ControllerInstance) bool {
+	return time.Since(time.Unix(instance.Heartbeat, 0)) < instanceHeartbeatExpiry
+}
@@ -0,0 +1,133 @@
+// Copyright (c) 2017 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/ligato/cn-infra/datasync"
+	"github.com/ligato/cn-infra/db/keyval"
+	"github.com/ligato/sfc-controller/controller/model/controller"
+)
+
+// instanceBroker is an in-memory broker holding json encoded values, only
+// the calls used by the instance registration are implemented
+type instanceBroker struct {
+	keyval.ProtoBroker
+	kvs map[string][]byte
+}
+
+func (b *instanceBroker) Put(key string, data proto.Message, opts ...datasync.PutOption) error {
+	value, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	b.kvs[key] = value
+	return nil
+}
+
+func (b *instanceBroker) GetValue(key string, reqObj proto.Message) (bool, int64, error) {
+	value, found := b.kvs[key]
+	if !found {
+		return false, 0, nil
+	}
+	return true, 0, json.Unmarshal(value, reqObj)
+}
+
+func (b *instanceBroker) Delete(key string, opts ...datasync.DelOption) (bool, error) {
+	_, existed := b.kvs[key]
+	delete(b.kvs, key)
+	return existed, nil
+}
+
+func (b *instanceBroker) putIfNotExists(key string, value []byte) (bool, error) {
+	if _, exists := b.kvs[key]; exists {
+		return false, nil
+	}
+	b.kvs[key] = value
+	return true, nil
+}
+
+func TestRegisterInstance(t *testing.T) {
+	now := time.Now().Unix()
+	stale := now - int64(2*instanceHeartbeatExpiry/time.Second)
+
+	tests := []struct {
+		name       string
+		instanceID string
+		existing   *controller.ControllerInstance
+		// heartbeat of the existing instance once the registration waited
+		heartbeatAfterWait int64
+		wantErr            bool
+		wantWait           bool
+	}{
+		{"free id", "c1", nil, 0, false, false},
+		{"empty id", "", nil, 0, true, false},
+		{"live id", "c1", &controller.ControllerInstance{InstanceId: "c1", Hostname: "h2", Pid: 7, Heartbeat: now},
+			now + 10, true, true},
+		{"live id stopped heartbeating", "c1", &controller.ControllerInstance{InstanceId: "c1", Hostname: "h2", Pid: 7, Heartbeat: now},
+			now, false, true},
+		{"stale id", "c1", &controller.ControllerInstance{InstanceId: "c1", Hostname: "h2", Pid: 7, Heartbeat: stale},
+			stale, false, false},
+		{"other id", "c2", &controller.ControllerInstance{InstanceId: "c1", Hostname: "h2", Pid: 7, Heartbeat: now},
+			now, false, false},
+	}
+
+	for _, test := range tests {
+		db := &instanceBroker{kvs: make(map[string][]byte)}
+		if test.existing != nil {
+			db.Put(controller.ControllerInstanceKey(test.existing.InstanceId), test.existing)
+		}
+		waited := false
+		wait := func(time.Duration) {
+			waited = true
+			existing := *test.existing
+			existing.Heartbeat = test.heartbeatAfterWait
+			db.Put(controller.ControllerInstanceKey(existing.InstanceId), &existing)
+		}
+		instance := &controller.ControllerInstance{InstanceId: test.instanceID, Hostname: "h1", Pid: 1}
+
+		err := registerInstance(db, db.putIfNotExists, instance, wait)
+		if (err != nil) != test.wantErr {
+			t.Errorf("%s: registerInstance() error = %v, wantErr %v", test.name, err, test.wantErr)
+			continue
+		}
+		if waited != test.wantWait {
+			t.Errorf("%s: waited = %v, want %v", test.name, waited, test.wantWait)
+		}
+		if err != nil {
+			continue
+		}
+
+		stored := &controller.ControllerInstance{}
+		if found, _, _ := db.GetValue(controller.ControllerInstanceKey(test.instanceID), stored); !found ||
+			stored.Hostname != "h1" || stored.Pid != 1 || !instanceAlive(stored) {
+			t.Errorf("%s: stored instance = %v, want the registered instance", test.name, stored)
+		}
+		if lost, err := refreshInstance(db, instance); lost || err != nil {
+			t.Errorf("%s: refreshInstance() = %v, %v, want the instance kept", test.name, lost, err)
+		}
+
+		// another controller taking over the id makes the heartbeat fail
+		db.Put(controller.ControllerInstanceKey(test.instanceID),
+			&controller.ControllerInstance{InstanceId: test.instanceID, Hostname: "h3", Pid: 9, Heartbeat: now})
+		if lost, err := refreshInstance(db, instance); !lost || err == nil {
+			t.Errorf("%s: refreshInstance() = %v, %v, want the instance lost", test.name, lost, err)
+		}
+	}
+}
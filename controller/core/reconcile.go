@@ -65,6 +65,21 @@ func (sfcCtrlPlugin *SfcControllerPluginHandler) ReconcileEnd() error {
 	return nil
 }
 
+// ReconcileRender : render the ram cache in a reconcile so the config which is no longer rendered, ie: the one of
+// a deleted sfc, is removed from etcd, if the ram cache cannot be rendered etcd is not changed
+func (sfcCtrlPlugin *SfcControllerPluginHandler) ReconcileRender() error {
+
+	sfcCtrlPlugin.ReconcileStart()
+
+	if err := sfcCtrlPlugin.renderConfigFromRAMCache(); err != nil {
+		log.Error("ReconcileRender: error rendering the ram cache: ", err)
+		sfcCtrlPlugin.cnpDriverPlugin.ReconcileAbort()
+		return err
+	}
+
+	return sfcCtrlPlugin.ReconcileEnd()
+}
+
 // ReconcileLoadAllVppLabels : retrieve all vpp lavels from the etcd datastore
 func (sfcCtrlPlugin *SfcControllerPluginHandler) ReconcileLoadAllVppLabels() {
 
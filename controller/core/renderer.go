@@ -19,6 +19,7 @@ package core
 
 import (
 	"github.com/ligato/sfc-controller/controller/model/controller"
+	"github.com/ligato/sfc-controller/controller/utils"
 )
 
 // Render the config: note that because we are wiring everything, we wire only one end when we
@@ -30,7 +31,7 @@ func (sfcCtrlPlugin *SfcControllerPluginHandler) renderConfigFromRAMCache() erro
 
 	log.Infof("render system parameters from ram cache")
 	if err := sfcCtrlPlugin.renderSystemParameters(&sfcCtrlPlugin.ramConfigCache.SysParms); err != nil {
-		log.Error("Error rendering sys parms:", sfcCtrlPlugin.ramConfigCache.SysParms)
+		log.Error("Error rendering sys parms:", utils.RedactSystemParameters(&sfcCtrlPlugin.ramConfigCache.SysParms))
 		return err
 	}
 
@@ -65,10 +66,18 @@ func (sfcCtrlPlugin *SfcControllerPluginHandler) renderConfigFromRAMCache() erro
 		}
 	}
 
+	log.Infof("render tenants from ram cache")
+	for _, tenant := range sfcCtrlPlugin.ramConfigCache.Tenants {
+		if err := sfcCtrlPlugin.renderTenant(&tenant); err != nil {
+			log.Error("Error rendering tenant:", tenant.Name)
+			return err
+		}
+	}
+
 	log.Infof("render sfc's from ram cache")
 	for _, sfc := range sfcCtrlPlugin.ramConfigCache.SFCs {
 		if err := sfcCtrlPlugin.renderServiceFunctionEntity(&sfc); err != nil {
-			log.Error("Error rendering service function chain:", sfc)
+			log.Error("Error rendering service function chain:", utils.RedactSfcEntity(&sfc))
 			return err
 		}
 	}
@@ -80,12 +89,20 @@ func (sfcCtrlPlugin *SfcControllerPluginHandler) renderConfigFromRAMCache() erro
 
 func (sfcCtrlPlugin *SfcControllerPluginHandler) renderSystemParameters(sp *controller.SystemParameters) error {
 
-	log.Infof("renderSystemParameters: sp: ", *sp)
+	log.Infof("renderSystemParameters: sp: ", utils.RedactSystemParameters(sp))
 
 	return sfcCtrlPlugin.cnpDriverPlugin.SetSystemParameters(sp)
 
 }
 
+// The tenant's id ranges and pools are handed to the driver so the tenant's sfcs can be isolated
+func (sfcCtrlPlugin *SfcControllerPluginHandler) renderTenant(tenant *controller.Tenant) error {
+
+	log.Infof("renderTenant: tenant: '%s'", tenant.Name)
+
+	return sfcCtrlPlugin.cnpDriverPlugin.SetTenant(tenant)
+}
+
 // For this ee, find all host entities and effect external entity to host wiring.  Will need a "session"
 // per external entity, and this session will be used to communicate wiring configuration.  Also, if the
 // configOnlyEE is false, then from each host entity, wire from host to this ee.
@@ -131,7 +148,7 @@ func (sfcCtrlPlugin *SfcControllerPluginHandler) renderHostEntity(sh *controller
 		}
 
 		for _, dh := range sfcCtrlPlugin.ramConfigCache.HEs {
-			if *sh != dh {
+			if sh.Name != dh.Name {
 				log.Infof("WireHostEntityToDestinationHostEntity: sh:'%s' to dh:'%s'",
 					sh.Name, dh.Name)
 				sfcCtrlPlugin.cnpDriverPlugin.WireHostEntityToDestinationHostEntity(sh, &dh)
@@ -20,6 +20,7 @@ package core
 import (
 	"github.com/ghodss/yaml"
 	"github.com/ligato/sfc-controller/controller/model/controller"
+	"github.com/ligato/sfc-controller/controller/utils"
 	"io/ioutil"
 )
 
@@ -30,6 +31,7 @@ type YamlConfig struct {
 	EEs         []controller.ExternalEntity   `json:"external_entities"`
 	HEs         []controller.HostEntity       `json:"host_entities"`
 	SFCs        []controller.SfcEntity        `json:"sfc_entities"`
+	Tenants     []controller.Tenant           `json:"tenants"`
 	SysParms    controller.SystemParameters   `json:"system_parameters"`
 }
 
@@ -58,7 +60,7 @@ func (sfcCtrlPlugin *SfcControllerPluginHandler) readConfigFromFile(fpath string
 func (sfcCtrlPlugin *SfcControllerPluginHandler) copyYamlConfigToRAMCache() error {
 
 	sfcCtrlPlugin.ramConfigCache.SysParms = sfcCtrlPlugin.yamlConfig.SysParms
-	log.Debugf("copyYamlConfigToRAMCache: sp: ", sfcCtrlPlugin.yamlConfig.SysParms)
+	log.Debugf("copyYamlConfigToRAMCache: sp: ", utils.RedactSystemParameters(&sfcCtrlPlugin.yamlConfig.SysParms))
 
 	for _, ee := range sfcCtrlPlugin.yamlConfig.EEs {
 		sfcCtrlPlugin.ramConfigCache.EEs[ee.Name] = ee
@@ -68,12 +70,16 @@ func (sfcCtrlPlugin *SfcControllerPluginHandler) copyYamlConfigToRAMCache() erro
 		sfcCtrlPlugin.ramConfigCache.HEs[he.Name] = he
 		log.Debugf("copyYamlConfigToRAMCache: he: ", he)
 	}
+	for _, tenant := range sfcCtrlPlugin.yamlConfig.Tenants {
+		sfcCtrlPlugin.ramConfigCache.Tenants[tenant.Name] = tenant
+		log.Debugf("copyYamlConfigToRAMCache: tenant: %s", tenant.Name)
+	}
 	for _, sfc := range sfcCtrlPlugin.yamlConfig.SFCs {
 		sfcCtrlPlugin.ramConfigCache.SFCs[sfc.Name] = sfc
-		log.Debugf("copyYamlConfigToRAMCache: sfc: ", sfc)
+		log.Debugf("copyYamlConfigToRAMCache: sfc: ", utils.RedactSfcEntity(&sfc))
 		log.Debugf("copyYamlConfigToRAMCache: num_chain_elements=%d", len(sfc.GetElements()))
 		for i, sfcChainElement := range sfc.GetElements() {
-			log.Debugf("copyYamlConfigToRAMCache: sfc_chain_element[%d]=", i, sfcChainElement)
+			log.Debugf("copyYamlConfigToRAMCache: sfc_chain_element[%d]=", i, utils.RedactSfcElement(sfcChainElement))
 		}
 	}
 
@@ -18,8 +18,14 @@
 package core
 
 import (
+	"bytes"
 	"fmt"
 	"github.com/ligato/sfc-controller/controller/model/controller"
+	"github.com/ligato/sfc-controller/controller/utils"
+	"github.com/ligato/sfc-controller/controller/utils/ipam"
+	"net"
+	"path"
+	"strings"
 )
 
 func (sfcCtrlPlugin *SfcControllerPluginHandler) validateRAMCache() error {
@@ -38,6 +44,11 @@ func (sfcCtrlPlugin *SfcControllerPluginHandler) validateRAMCache() error {
 			return err
 		}
 	}
+	for _, tenant := range sfcCtrlPlugin.ramConfigCache.Tenants {
+		if err := sfcCtrlPlugin.validateTenant(&tenant); err != nil {
+			return err
+		}
+	}
 	for _, sfc := range sfcCtrlPlugin.ramConfigCache.SFCs {
 		if err := sfcCtrlPlugin.validateSFC(&sfc); err != nil {
 			return err
@@ -50,7 +61,7 @@ func (sfcCtrlPlugin *SfcControllerPluginHandler) validateRAMCache() error {
 // validate the system parameters
 func (sfcCtrlPlugin *SfcControllerPluginHandler) validateSystemParameters(sp *controller.SystemParameters) error {
 
-	log.Info("validateSystemParameters: initial SP's", sp)
+	log.Info("validateSystemParameters: initial SP's", utils.RedactSystemParameters(sp))
 
 	if sp.Mtu == 0 {
 		log.Info("validateSystemParameters: sys mtu = 0, defaulting to 1500")
@@ -84,7 +95,59 @@ func (sfcCtrlPlugin *SfcControllerPluginHandler) validateSystemParameters(sp *co
 			MacAge: 0,
 		}
 	}
-	log.Info("validateSystemParameters: final SP's", sp)
+	if err := validateIpamPools(sp.GetIpamPools()); err != nil {
+		return err
+	}
+	for _, pool := range sp.GetIpamPools() {
+		for _, sfc := range sfcCtrlPlugin.ramConfigCache.SFCs {
+			for _, prefix := range []string{sfc.SfcIpv4Prefix, sfc.SfcIpv6Prefix} {
+				if prefix != "" && prefixesOverlap(pool.Prefix, prefix) {
+					return fmt.Errorf("ipam pool: %s, prefix '%s' overlaps the prefix '%s' of sfc: %s", pool.Name,
+						pool.Prefix, prefix, sfc.Name)
+				}
+			}
+		}
+	}
+	if sp.MacPrefix == "" {
+		log.Info("validateSystemParameters: sys mac prefix not set, defaulting to 02")
+		sp.MacPrefix = "02" // if not provided, default it to 02
+	}
+	macPrefix, err := utils.ParseMacPrefix(sp.MacPrefix)
+	if err != nil {
+		return err
+	}
+	for _, tenant := range sfcCtrlPlugin.ramConfigCache.Tenants {
+		if tenant.MacRangeEnd > utils.MaxMacID(macPrefix) {
+			return fmt.Errorf("mac_prefix: '%s' leaves room for mac ids up to %d, tenant: %s mac range ends at %d",
+				sp.MacPrefix, utils.MaxMacID(macPrefix), tenant.Name, tenant.MacRangeEnd)
+		}
+	}
+	if _, exists := controller.MacAddressScheme_name[int32(sp.MacAddressScheme)]; !exists {
+		return fmt.Errorf("invalid mac_address_scheme: %d", sp.MacAddressScheme)
+	}
+	if err := validateMemifParms(sp.GetMemifParms()); err != nil {
+		return err
+	}
+	if sp.MemifSocketDir == "" {
+		log.Info("validateSystemParameters: sys memif socket dir not set, defaulting to /tmp")
+		sp.MemifSocketDir = "/tmp" // if not provided, default it to /tmp
+	}
+	if !path.IsAbs(sp.MemifSocketDir) {
+		return fmt.Errorf("invalid memif_socket_dir: '%s', must be an absolute path", sp.MemifSocketDir)
+	}
+	if _, exists := controller.MemifSocketScheme_name[int32(sp.MemifSocketScheme)]; !exists {
+		return fmt.Errorf("invalid memif_socket_scheme: %d", sp.MemifSocketScheme)
+	}
+	if sp.AutoVrfRangeStart == 0 && sp.AutoVrfRangeEnd == 0 {
+		log.Info("validateSystemParameters: sys auto vrf range not set, defaulting to 1000-1999")
+		sp.AutoVrfRangeStart = 1000 // if not provided, default it to 1000-1999
+		sp.AutoVrfRangeEnd = 1999
+	}
+	if sp.AutoVrfRangeStart == 0 || sp.AutoVrfRangeStart > sp.AutoVrfRangeEnd {
+		return fmt.Errorf("invalid auto vrf range: %d-%d, vrf 0 is the default vrf", sp.AutoVrfRangeStart,
+			sp.AutoVrfRangeEnd)
+	}
+	log.Info("validateSystemParameters: final SP's", utils.RedactSystemParameters(sp))
 
 	return nil
 }
@@ -92,24 +155,100 @@ func (sfcCtrlPlugin *SfcControllerPluginHandler) validateSystemParameters(sp *co
 // validate the External Router, TODO: perform better/complete validation
 func (sfcCtrlPlugin *SfcControllerPluginHandler) validateEE(ee *controller.ExternalEntity) error {
 
-	if ee.Name == "" {
-		err := fmt.Errorf("Missing entity name")
+	if err := utils.ValidateEntityName(ee.Name); err != nil {
 		return err
 	} else if ee.MgmntIpAddress == "" { //|| !validIpAddress(ee.MgmntIpAddress) {
 		err := fmt.Errorf("Invalid mgmt_ip_address: '%s'", ee.MgmntIpAddress)
 		return err
 	}
+	if err := utils.ValidateLabels(ee.Labels); err != nil {
+		return fmt.Errorf("ee: %s, %s", ee.Name, err)
+	}
+	if ee.HostInterface != nil && ee.HostInterface.Ipv6Addr != "" && !isIpv6Address(ee.HostInterface.Ipv6Addr) {
+		return fmt.Errorf("ee: %s, invalid host_interface ipv6_addr: '%s'", ee.Name, ee.HostInterface.Ipv6Addr)
+	}
+	if ee.HostVxlan != nil && ee.HostVxlan.SourceIpv6 != "" && !isIpv6Address(ee.HostVxlan.SourceIpv6) {
+		return fmt.Errorf("ee: %s, invalid host_vxlan source_ipv6: '%s'", ee.Name, ee.HostVxlan.SourceIpv6)
+	}
 
 	return nil
 }
 
+const maxLinuxIfNameLen = 15 // IFNAMSIZ less the terminating NULL
+
+// validateTapElement checks a tap element names the netns of its container, vpp moves the host end of the tap
+// into a named netns, ie: one of ip netns, it cannot find the namespace of a container by its label.
+func validateTapElement(sfcElement *controller.SfcEntity_SfcElement) error {
+	switch sfcElement.Type {
+	case controller.SfcElementType_VPP_CONTAINER_TAP:
+		if sfcElement.TapNamespace == "" {
+			return fmt.Errorf("tap_namespace is required for type VPP_CONTAINER_TAP")
+		}
+		if strings.Contains(sfcElement.TapNamespace, "/") {
+			return fmt.Errorf("tap_namespace: '%s' is not the name of a netns", sfcElement.TapNamespace)
+		}
+	default:
+		if sfcElement.TapNamespace != "" {
+			return fmt.Errorf("tap_namespace is only for type VPP_CONTAINER_TAP")
+		}
+	}
+	return nil
+}
+
 // validate the Host Entity, TODO: perform better/complete validation
 func (sfcCtrlPlugin *SfcControllerPluginHandler) validateHE(he *controller.HostEntity) error {
 
-	if he.Name == "" {
-		err := fmt.Errorf("Missing entity name")
+	if err := utils.ValidateEntityName(he.Name); err != nil {
 		return err
 	}
+	if err := utils.ValidateLabels(he.Labels); err != nil {
+		return fmt.Errorf("he: %s, %s", he.Name, err)
+	}
+	if he.VxlanTunnelIpv6 != "" {
+		if !isIpv6Address(he.VxlanTunnelIpv6) {
+			return fmt.Errorf("he: %s, invalid vxlan_tunnel_ipv6: '%s'", he.Name, he.VxlanTunnelIpv6)
+		}
+		// the ipv6 route to the host's tunnel endpoint is via the host's eth ipv6 addr
+		if he.EthIpv6 == "" {
+			return fmt.Errorf("he: %s, vxlan_tunnel_ipv6 requires eth_ipv6", he.Name)
+		}
+	}
+	if he.MemifSocketDir != "" && !path.IsAbs(he.MemifSocketDir) {
+		return fmt.Errorf("he: %s, invalid memif_socket_dir: '%s', must be an absolute path", he.Name,
+			he.MemifSocketDir)
+	}
+	if sp := &sfcCtrlPlugin.ramConfigCache.SysParms; he.EthVrfId != 0 && he.EthVrfId >= sp.AutoVrfRangeStart &&
+		he.EthVrfId <= sp.AutoVrfRangeEnd {
+		return fmt.Errorf("he: %s, eth_vrf_id: %d is in the auto vrf range %d-%d", he.Name, he.EthVrfId,
+			sp.AutoVrfRangeStart, sp.AutoVrfRangeEnd)
+	}
+	if he.EthVrfId != 0 && he.CreateVxlanStaticRoute {
+		// the static routes to the vxlan tunnel endpoints of the peers are in vrf 0 via the eth i/f
+		return fmt.Errorf("he: %s, eth_vrf_id: %d, the eth i/f carries the vxlan static routes in vrf 0",
+			he.Name, he.EthVrfId)
+	}
+	if he.EthUnnumbered {
+		// the eth i/f borrows the address of the loopback which is in the default vrf
+		if he.EthIfName == "" || he.EthIpv4 != "" || he.EthIpv6 != "" {
+			return fmt.Errorf("he: %s, eth_unnumbered requires eth_if_name and no eth_ipv4/eth_ipv6", he.Name)
+		}
+		if he.LoopbackIpv4 == "" && he.LoopbackIpv6 == "" {
+			return fmt.Errorf("he: %s, eth_unnumbered requires loopback_ipv4 or loopback_ipv6", he.Name)
+		}
+		if he.EthVrfId != 0 {
+			return fmt.Errorf("he: %s, eth_unnumbered requires the eth i/f in vrf 0, eth_vrf_id: %d", he.Name,
+				he.EthVrfId)
+		}
+	}
+	if he.LoopbackMacAddr != "" {
+		if !isUnicastMacAddress(he.LoopbackMacAddr) {
+			return fmt.Errorf("he: %s, invalid loopback_mac_addr: '%s'", he.Name, he.LoopbackMacAddr)
+		}
+		if user := sfcCtrlPlugin.findMacAddressUser(he.LoopbackMacAddr, he.Name, ""); user != "" {
+			return fmt.Errorf("he: %s, loopback_mac_addr: '%s' is already used by %s", he.Name,
+				he.LoopbackMacAddr, user)
+		}
+	}
 
 	return nil
 }
@@ -117,10 +256,132 @@ func (sfcCtrlPlugin *SfcControllerPluginHandler) validateHE(he *controller.HostE
 // validate the SFC, TODO: perform better/complete validation
 func (sfcCtrlPlugin *SfcControllerPluginHandler) validateSFC(sfc *controller.SfcEntity) error {
 
-	if sfc.Name == "" {
-		err := fmt.Errorf("Missing entity name")
+	if err := utils.ValidateEntityName(sfc.Name); err != nil {
 		return err
 	}
+	if err := utils.ValidateLabels(sfc.Labels); err != nil {
+		return fmt.Errorf("sfc: %s, %s", sfc.Name, err)
+	}
+	if sfc.SfcIpv6Prefix != "" {
+		if _, _, err := net.ParseCIDR(sfc.SfcIpv6Prefix); err != nil || !isIpv6Address(sfc.SfcIpv6Prefix) {
+			return fmt.Errorf("sfc: %s, invalid sfc_ipv6_prefix: '%s'", sfc.Name, sfc.SfcIpv6Prefix)
+		}
+	}
+	if sfc.SfcIpv4Prefix != "" {
+		if _, _, err := net.ParseCIDR(sfc.SfcIpv4Prefix); err != nil || isIpv6Address(sfc.SfcIpv4Prefix) {
+			return fmt.Errorf("sfc: %s, invalid sfc_ipv4_prefix: '%s'", sfc.Name, sfc.SfcIpv4Prefix)
+		}
+	}
+	if err := sfcCtrlPlugin.validateSFCRawPrefixes(sfc); err != nil {
+		return err
+	}
+	if sfc.Ipv4IpamPool != "" {
+		if sfc.SfcIpv4Prefix != "" {
+			return fmt.Errorf("sfc: %s, ipv4_ipam_pool and sfc_ipv4_prefix are mutually exclusive", sfc.Name)
+		}
+		pool := sfcCtrlPlugin.findIpamPool(sfc.Ipv4IpamPool)
+		if pool == nil || isIpv6Address(pool.Prefix) {
+			return fmt.Errorf("sfc: %s, ipv4 ipam pool not found: '%s'", sfc.Name, sfc.Ipv4IpamPool)
+		}
+	}
+	if sfc.Ipv6IpamPool != "" {
+		if sfc.SfcIpv6Prefix != "" {
+			return fmt.Errorf("sfc: %s, ipv6_ipam_pool and sfc_ipv6_prefix are mutually exclusive", sfc.Name)
+		}
+		pool := sfcCtrlPlugin.findIpamPool(sfc.Ipv6IpamPool)
+		if pool == nil || !isIpv6Address(pool.Prefix) {
+			return fmt.Errorf("sfc: %s, ipv6 ipam pool not found: '%s'", sfc.Name, sfc.Ipv6IpamPool)
+		}
+	}
+	if sfc.Tenant != "" {
+		if err := sfcCtrlPlugin.validateSFCTenant(sfc); err != nil {
+			return err
+		}
+	} else if prefix := sfcCtrlPlugin.sfcIpv4Prefix(sfc); prefix != "" {
+		// the tenants' pools are theirs only
+		for _, tenant := range sfcCtrlPlugin.ramConfigCache.Tenants {
+			if prefixOverlapsPools(prefix, tenant.Ipv4Pools) {
+				return fmt.Errorf("sfc: %s, ipv4 prefix: '%s' overlaps the ipv4_pools of tenant: %s", sfc.Name,
+					prefix, tenant.Name)
+			}
+		}
+	}
+	if err := sfcCtrlPlugin.validateSFCDedicatedVni(sfc); err != nil {
+		return err
+	}
+	if err := sfcCtrlPlugin.validateSFCUnderlay(sfc); err != nil {
+		return err
+	}
+	if err := sfcCtrlPlugin.validateSFCMacAddresses(sfc); err != nil {
+		return err
+	}
+	if err := sfcCtrlPlugin.validateSFCVrfs(sfc); err != nil {
+		return err
+	}
+	if err := sfcCtrlPlugin.validateSFCProxyArpRanges(sfc); err != nil {
+		return err
+	}
+	if err := validateSFCContainerL3Entries(sfc); err != nil {
+		return err
+	}
+	if err := validateSFCAcls(sfc); err != nil {
+		return err
+	}
+	if err := sfcCtrlPlugin.validateSFCClassifier(sfc); err != nil {
+		return err
+	}
+	for _, sfcElement := range sfc.GetElements() {
+		// the port of a container wired with a veth, or a tap, is the linux name of the interface in the container
+		vethInContainer := sfcElement.Type == controller.SfcElementType_NON_VPP_CONTAINER_AFP ||
+			sfcElement.Type == controller.SfcElementType_VPP_CONTAINER_AFP ||
+			sfcElement.Type == controller.SfcElementType_VPP_CONTAINER_TAP ||
+			(sfc.Type == controller.SfcType_SFC_EW_VETH && sfcElement.Type != controller.SfcElementType_HOST_ENTITY &&
+				sfcElement.Type != controller.SfcElementType_EXTERNAL_ENTITY)
+		if vethInContainer && len(sfcElement.PortLabel) > maxLinuxIfNameLen {
+			return fmt.Errorf("sfc: %s, container: %s, port_label: '%s' is longer than %d chars", sfc.Name,
+				sfcElement.Container, sfcElement.PortLabel, maxLinuxIfNameLen)
+		}
+		if err := validateMemifParms(sfcElement.GetMemifParms()); err != nil {
+			return fmt.Errorf("sfc: %s, container: %s, port: %s, %s", sfc.Name, sfcElement.Container,
+				sfcElement.PortLabel, err)
+		}
+		if err := validateTapElement(sfcElement); err != nil {
+			return fmt.Errorf("sfc: %s, container: %s, port: %s, %s", sfc.Name, sfcElement.Container,
+				sfcElement.PortLabel, err)
+		}
+	}
+	if sfc.Type == controller.SfcType_SFC_NS_VXLAN && !sfc.DedicatedVni {
+		// the tunnels of a chain attached to more than one ee/dest host share a bridge on the host so
+		// they cannot be the shared h2e/h2h tunnels
+		numPeers := 0
+		for _, sfcElement := range sfc.GetElements() {
+			if sfcElement.Type == controller.SfcElementType_EXTERNAL_ENTITY ||
+				sfcElement.Type == controller.SfcElementType_HOST_ENTITY {
+				numPeers++
+			}
+		}
+		if numPeers > 1 {
+			return fmt.Errorf("sfc: %s, more than one ee/dest host requires dedicated_vni", sfc.Name)
+		}
+	}
+	if sfc.PeerRedundancy != controller.PeerRedundancyType_PEER_REDUNDANCY_ACTIVE_STANDBY &&
+		sfc.Type != controller.SfcType_SFC_NS_VXLAN {
+		return fmt.Errorf("sfc: %s, peer_redundancy is only for n/s vxlan sfcs", sfc.Name)
+	}
+	if _, exists := controller.PeerRedundancyType_name[int32(sfc.PeerRedundancy)]; !exists {
+		return fmt.Errorf("sfc: %s, invalid peer_redundancy: %d", sfc.Name, sfc.PeerRedundancy)
+	}
+	for _, sfcElement := range sfc.GetElements() {
+		if sfcElement.RouteWeight == 0 && sfcElement.RoutePreference == 0 {
+			continue
+		}
+		if sfc.Type != controller.SfcType_SFC_NS_VXLAN ||
+			(sfcElement.Type != controller.SfcElementType_EXTERNAL_ENTITY &&
+				sfcElement.Type != controller.SfcElementType_HOST_ENTITY) {
+			return fmt.Errorf("sfc: %s, container: %s, route_weight/preference are only for the ees/dest hosts of n/s vxlan sfcs",
+				sfc.Name, sfcElement.Container)
+		}
+	}
 	numSfcElements := len(sfc.GetElements())
 	if numSfcElements <= 0 {
 		return nil
@@ -144,3 +405,879 @@ func (sfcCtrlPlugin *SfcControllerPluginHandler) validateSFC(sfc *controller.Sfc
 
 	return nil
 }
+
+// the ee bridge of a tenant or dedicated vni sfc is carried on the ee's host_bd interfaces tagged with
+// its id, so the id must be a valid dot1q vlan
+const maxEeBdID = 4094
+
+// validate the Tenant, its id ranges and pools must not overlap those of other tenants
+func (sfcCtrlPlugin *SfcControllerPluginHandler) validateTenant(tenant *controller.Tenant) error {
+
+	if err := utils.ValidateEntityName(tenant.Name); err != nil {
+		return err
+	}
+	if err := utils.ValidateLabels(tenant.Labels); err != nil {
+		return fmt.Errorf("tenant: %s, %s", tenant.Name, err)
+	}
+
+	// the global vlan sequence grows up from starting_vlan_id so tenant vnis must be below it
+	if tenant.VniRangeStart == 0 || tenant.VniRangeEnd < tenant.VniRangeStart {
+		return fmt.Errorf("tenant: %s, invalid vni range: %d-%d", tenant.Name,
+			tenant.VniRangeStart, tenant.VniRangeEnd)
+	}
+	if startingVlanID := sfcCtrlPlugin.ramConfigCache.SysParms.StartingVlanId; startingVlanID != 0 &&
+		tenant.VniRangeEnd >= startingVlanID {
+		return fmt.Errorf("tenant: %s, vni range: %d-%d must be below starting_vlan_id: %d", tenant.Name,
+			tenant.VniRangeStart, tenant.VniRangeEnd, startingVlanID)
+	}
+	if tenant.MacRangeStart == 0 || tenant.MacRangeEnd < tenant.MacRangeStart {
+		return fmt.Errorf("tenant: %s, invalid mac range: %d-%d", tenant.Name,
+			tenant.MacRangeStart, tenant.MacRangeEnd)
+	}
+	// the mac ids fill the octets after the mac prefix, the default prefix is one octet
+	if macPrefix, err := utils.ParseMacPrefix(sfcCtrlPlugin.ramConfigCache.SysParms.MacPrefix); err == nil &&
+		tenant.MacRangeEnd > utils.MaxMacID(macPrefix) {
+		return fmt.Errorf("tenant: %s, mac range: %d-%d must end at most at %d for mac_prefix: '%s'", tenant.Name,
+			tenant.MacRangeStart, tenant.MacRangeEnd, utils.MaxMacID(macPrefix),
+			sfcCtrlPlugin.ramConfigCache.SysParms.MacPrefix)
+	}
+
+	for i, pool := range tenant.Ipv4Pools {
+		if _, _, err := net.ParseCIDR(pool); err != nil || isIpv6Address(pool) {
+			return fmt.Errorf("tenant: %s, invalid ipv4 pool: '%s'", tenant.Name, pool)
+		}
+		for _, other := range tenant.Ipv4Pools[:i] {
+			if prefixesOverlap(pool, other) {
+				return fmt.Errorf("tenant: %s, ipv4 pool: '%s' overlaps ipv4 pool: '%s'", tenant.Name, pool, other)
+			}
+		}
+	}
+
+	if tenant.EeBdId > maxEeBdID {
+		return fmt.Errorf("tenant: %s, ee_bd_id: %d must be at most %d", tenant.Name, tenant.EeBdId, maxEeBdID)
+	}
+	for _, ee := range sfcCtrlPlugin.ramConfigCache.EEs {
+		if tenant.EeBdId != 0 && ee.HostBd != nil && ee.HostBd.Id == tenant.EeBdId {
+			return fmt.Errorf("tenant: %s, ee_bd_id: %d is the host_bd of ee: %s", tenant.Name,
+				tenant.EeBdId, ee.Name)
+		}
+	}
+
+	for _, sfc := range sfcCtrlPlugin.ramConfigCache.SFCs {
+		if tenant.EeBdId != 0 && sfc.EeBdId == tenant.EeBdId {
+			return fmt.Errorf("tenant: %s, ee_bd_id: %d is used by sfc: %s", tenant.Name,
+				tenant.EeBdId, sfc.Name)
+		}
+		// the sfcs of the tenant must stay inside its pools, the others outside of them
+		prefix := sfcCtrlPlugin.sfcIpv4Prefix(&sfc)
+		if prefix == "" {
+			continue
+		}
+		if sfc.Tenant == tenant.Name && !prefixInsidePools(prefix, tenant.Ipv4Pools) {
+			return fmt.Errorf("tenant: %s, ipv4 prefix: '%s' of sfc: %s is not inside the ipv4_pools", tenant.Name,
+				prefix, sfc.Name)
+		}
+		if sfc.Tenant != tenant.Name && prefixOverlapsPools(prefix, tenant.Ipv4Pools) {
+			return fmt.Errorf("tenant: %s, ipv4_pools overlap the ipv4 prefix: '%s' of sfc: %s", tenant.Name,
+				prefix, sfc.Name)
+		}
+	}
+
+	for _, other := range sfcCtrlPlugin.ramConfigCache.Tenants {
+		if other.Name == tenant.Name {
+			continue
+		}
+		if tenant.VniRangeStart <= other.VniRangeEnd && other.VniRangeStart <= tenant.VniRangeEnd {
+			return fmt.Errorf("tenant: %s, vni range overlaps tenant: %s", tenant.Name, other.Name)
+		}
+		if tenant.MacRangeStart <= other.MacRangeEnd && other.MacRangeStart <= tenant.MacRangeEnd {
+			return fmt.Errorf("tenant: %s, mac range overlaps tenant: %s", tenant.Name, other.Name)
+		}
+		if tenant.EeBdId != 0 && tenant.EeBdId == other.EeBdId {
+			return fmt.Errorf("tenant: %s, ee_bd_id: %d is used by tenant: %s", tenant.Name,
+				tenant.EeBdId, other.Name)
+		}
+		if tenant.ApiToken != "" && tenant.ApiToken == other.ApiToken {
+			return fmt.Errorf("tenant: %s, api_token is used by tenant: %s", tenant.Name, other.Name)
+		}
+		for _, pool := range tenant.Ipv4Pools {
+			if prefixOverlapsPools(pool, other.Ipv4Pools) {
+				return fmt.Errorf("tenant: %s, ipv4 pool: '%s' overlaps the ipv4_pools of tenant: %s", tenant.Name,
+					pool, other.Name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// validate the tenant specific parts of an SFC, ie its prefix and vlans are inside the tenant's pools/ranges
+func (sfcCtrlPlugin *SfcControllerPluginHandler) validateSFCTenant(sfc *controller.SfcEntity) error {
+
+	tenant, exists := sfcCtrlPlugin.ramConfigCache.Tenants[sfc.Tenant]
+	if !exists {
+		return fmt.Errorf("sfc: %s, tenant not found: '%s'", sfc.Name, sfc.Tenant)
+	}
+
+	// a tenant without ipv4 pools has no addresses for its sfcs
+	if sfc.SfcIpv4Prefix != "" && !prefixInsidePools(sfc.SfcIpv4Prefix, tenant.Ipv4Pools) {
+		return fmt.Errorf("sfc: %s, sfc_ipv4_prefix: '%s' is not inside the ipv4_pools of tenant: %s",
+			sfc.Name, sfc.SfcIpv4Prefix, tenant.Name)
+	}
+	if pool := sfcCtrlPlugin.findIpamPool(sfc.Ipv4IpamPool); pool != nil &&
+		!prefixInsidePools(pool.Prefix, tenant.Ipv4Pools) {
+		return fmt.Errorf("sfc: %s, ipv4_ipam_pool: '%s' is not inside the ipv4_pools of tenant: %s",
+			sfc.Name, pool.Name, tenant.Name)
+	}
+
+	for _, sfcElement := range sfc.GetElements() {
+		if sfcElement.VlanId != 0 &&
+			(sfcElement.VlanId < tenant.VniRangeStart || sfcElement.VlanId > tenant.VniRangeEnd) {
+			return fmt.Errorf("sfc: %s, vlan_id: %d is outside the vni range of tenant: %s",
+				sfc.Name, sfcElement.VlanId, tenant.Name)
+		}
+		if sfc.Type == controller.SfcType_SFC_NS_VXLAN &&
+			sfcElement.Type == controller.SfcElementType_EXTERNAL_ENTITY && tenant.EeBdId == 0 && sfc.EeBdId == 0 {
+			return fmt.Errorf("sfc: %s, tenant: %s has no ee_bd_id for ee: %s",
+				sfc.Name, tenant.Name, sfcElement.Container)
+		}
+	}
+
+	return nil
+}
+
+// validate the dedicated vni of an SFC, the ee bridge of the vni must not be shared with any
+// other ee, tenant, or sfc
+func (sfcCtrlPlugin *SfcControllerPluginHandler) validateSFCDedicatedVni(sfc *controller.SfcEntity) error {
+
+	if !sfc.DedicatedVni {
+		if sfc.EeBdId != 0 {
+			return fmt.Errorf("sfc: %s, ee_bd_id: %d requires dedicated_vni", sfc.Name, sfc.EeBdId)
+		}
+		return nil
+	}
+
+	if sfc.EeBdId == 0 {
+		if sfc.Type != controller.SfcType_SFC_NS_VXLAN {
+			return nil
+		}
+		for _, sfcElement := range sfc.GetElements() {
+			if sfcElement.Type == controller.SfcElementType_EXTERNAL_ENTITY {
+				return fmt.Errorf("sfc: %s, dedicated_vni requires an ee_bd_id for ee: %s",
+					sfc.Name, sfcElement.Container)
+			}
+		}
+		return nil
+	}
+
+	if sfc.EeBdId > maxEeBdID {
+		return fmt.Errorf("sfc: %s, ee_bd_id: %d must be at most %d", sfc.Name, sfc.EeBdId, maxEeBdID)
+	}
+	// the bridge of the dedicated vni is carried on the host_bd interfaces of the sfc's ees
+	for _, sfcElement := range sfc.GetElements() {
+		if sfcElement.Type != controller.SfcElementType_EXTERNAL_ENTITY {
+			continue
+		}
+		if ee, exists := sfcCtrlPlugin.ramConfigCache.EEs[sfcElement.Container]; exists &&
+			(ee.HostBd == nil || len(ee.HostBd.Interfaces) == 0) {
+			return fmt.Errorf("sfc: %s, ee_bd_id: %d requires host_bd interfaces on ee: %s", sfc.Name,
+				sfc.EeBdId, ee.Name)
+		}
+	}
+	for _, ee := range sfcCtrlPlugin.ramConfigCache.EEs {
+		if ee.HostBd != nil && ee.HostBd.Id == sfc.EeBdId {
+			return fmt.Errorf("sfc: %s, ee_bd_id: %d is the host_bd of ee: %s", sfc.Name,
+				sfc.EeBdId, ee.Name)
+		}
+	}
+	for _, tenant := range sfcCtrlPlugin.ramConfigCache.Tenants {
+		if tenant.EeBdId == sfc.EeBdId {
+			return fmt.Errorf("sfc: %s, ee_bd_id: %d is used by tenant: %s", sfc.Name,
+				sfc.EeBdId, tenant.Name)
+		}
+	}
+	for _, other := range sfcCtrlPlugin.ramConfigCache.SFCs {
+		if other.Name != sfc.Name && other.EeBdId == sfc.EeBdId {
+			return fmt.Errorf("sfc: %s, ee_bd_id: %d is used by sfc: %s", sfc.Name,
+				sfc.EeBdId, other.Name)
+		}
+	}
+
+	return nil
+}
+
+// validate the vxlan underlay of an SFC, the hosts of its containers and its ees/dest hosts are the ends of
+// tunnels so each pair must have a tunnel address family in common
+func (sfcCtrlPlugin *SfcControllerPluginHandler) validateSFCUnderlay(sfc *controller.SfcEntity) error {
+
+	var hosts []string
+	var peers []*controller.SfcEntity_SfcElement
+	for _, sfcElement := range sfc.GetElements() {
+		switch sfcElement.Type {
+		case controller.SfcElementType_EXTERNAL_ENTITY, controller.SfcElementType_HOST_ENTITY:
+			peers = append(peers, sfcElement)
+		default:
+			found := false
+			for _, host := range hosts {
+				if host == sfcElement.EtcdVppSwitchKey {
+					found = true
+					break
+				}
+			}
+			if !found && sfcElement.EtcdVppSwitchKey != "" {
+				hosts = append(hosts, sfcElement.EtcdVppSwitchKey)
+			}
+		}
+	}
+
+	for i, hostName := range hosts {
+		he, exists := sfcCtrlPlugin.ramConfigCache.HEs[hostName]
+		if !exists {
+			continue
+		}
+		for _, otherName := range hosts[i+1:] {
+			if other, exists := sfcCtrlPlugin.ramConfigCache.HEs[otherName]; exists &&
+				!commonAddressFamily(he.VxlanTunnelIpv4, he.VxlanTunnelIpv6, other.VxlanTunnelIpv4, other.VxlanTunnelIpv6) {
+				return fmt.Errorf("sfc: %s, hosts: %s and %s have no vxlan tunnel address family in common",
+					sfc.Name, he.Name, other.Name)
+			}
+		}
+		if sfc.Type != controller.SfcType_SFC_NS_VXLAN {
+			continue
+		}
+		for _, peer := range peers {
+			if peer.Type == controller.SfcElementType_HOST_ENTITY {
+				if dh, exists := sfcCtrlPlugin.ramConfigCache.HEs[peer.Container]; exists &&
+					!commonAddressFamily(he.VxlanTunnelIpv4, he.VxlanTunnelIpv6, dh.VxlanTunnelIpv4, dh.VxlanTunnelIpv6) {
+					return fmt.Errorf("sfc: %s, host: %s and dest host: %s have no vxlan tunnel address family in common",
+						sfc.Name, he.Name, dh.Name)
+				}
+				continue
+			}
+			ee, exists := sfcCtrlPlugin.ramConfigCache.EEs[peer.Container]
+			if !exists || ee.HostVxlan == nil {
+				continue
+			}
+			if !commonAddressFamily(he.VxlanTunnelIpv4, he.VxlanTunnelIpv6, ee.HostVxlan.SourceIpv4,
+				ee.HostVxlan.SourceIpv6) {
+				return fmt.Errorf("sfc: %s, host: %s and ee: %s have no vxlan tunnel address family in common",
+					sfc.Name, he.Name, ee.Name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// commonAddressFamily returns true if both ends have an ipv4, or both have an ipv6 address
+func commonAddressFamily(ipv4 string, ipv6 string, otherIpv4 string, otherIpv6 string) bool {
+	return (ipv4 != "" && otherIpv4 != "") || (ipv6 != "" && otherIpv6 != "")
+}
+
+// prefixInsidePools returns true if the prefix is fully contained in one of the pools
+func prefixInsidePools(prefix string, pools []string) bool {
+
+	_, prefixNet, err := net.ParseCIDR(prefix)
+	if err != nil {
+		return false
+	}
+	prefixBits, _ := prefixNet.Mask.Size()
+
+	for _, pool := range pools {
+		_, poolNet, err := net.ParseCIDR(pool)
+		if err != nil {
+			continue
+		}
+		poolBits, _ := poolNet.Mask.Size()
+		if poolBits <= prefixBits && poolNet.Contains(prefixNet.IP) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateSFCRawPrefixes checks the sfc_ipv4/6_prefix of the sfc is not inside or around a named ipam pool, the
+// addresses of a pool are allocated from it only, nor overlaps the prefix of another sfc.  The sfcs with the
+// same prefix, as written, share its addresses.
+func (sfcCtrlPlugin *SfcControllerPluginHandler) validateSFCRawPrefixes(sfc *controller.SfcEntity) error {
+
+	for _, prefix := range []string{sfc.SfcIpv4Prefix, sfc.SfcIpv6Prefix} {
+		if prefix == "" {
+			continue
+		}
+		for _, pool := range sfcCtrlPlugin.ramConfigCache.SysParms.GetIpamPools() {
+			if prefixesOverlap(prefix, pool.Prefix) {
+				return fmt.Errorf("sfc: %s, prefix: '%s' overlaps ipam pool: %s, prefix '%s', reference the pool instead",
+					sfc.Name, prefix, pool.Name, pool.Prefix)
+			}
+		}
+		for _, other := range sfcCtrlPlugin.ramConfigCache.SFCs {
+			if other.Name == sfc.Name {
+				continue
+			}
+			for _, otherPrefix := range []string{other.SfcIpv4Prefix, other.SfcIpv6Prefix} {
+				if otherPrefix != "" && otherPrefix != prefix && prefixesOverlap(prefix, otherPrefix) {
+					return fmt.Errorf("sfc: %s, prefix: '%s' overlaps the prefix '%s' of sfc: %s", sfc.Name,
+						prefix, otherPrefix, other.Name)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// prefixOverlapsPools returns true if the prefix shares an address with one of the pools
+func prefixOverlapsPools(prefix string, pools []string) bool {
+	for _, pool := range pools {
+		if prefixesOverlap(prefix, pool) {
+			return true
+		}
+	}
+	return false
+}
+
+// prefixesOverlap returns true if the prefixes share an address, ie: one contains the other
+func prefixesOverlap(prefix string, other string) bool {
+	_, prefixNet, err := net.ParseCIDR(prefix)
+	if err != nil {
+		return false
+	}
+	_, otherNet, err := net.ParseCIDR(other)
+	if err != nil {
+		return false
+	}
+	return prefixNet.Contains(otherNet.IP) || otherNet.Contains(prefixNet.IP)
+}
+
+// sfcIpv4Prefix returns the ipv4 prefix the sfc's addresses come from, its sfc_ipv4_prefix or the prefix of its
+// ipv4 ipam pool, or "" if it has neither
+func (sfcCtrlPlugin *SfcControllerPluginHandler) sfcIpv4Prefix(sfc *controller.SfcEntity) string {
+	if pool := sfcCtrlPlugin.findIpamPool(sfc.Ipv4IpamPool); pool != nil {
+		return pool.Prefix
+	}
+	return sfc.SfcIpv4Prefix
+}
+
+// validateIpamPools checks the named pools are valid and that no two pools overlap
+func validateIpamPools(pools []*controller.IpamPool) error {
+
+	for i, pool := range pools {
+		if pool.Name == "" {
+			return fmt.Errorf("ipam pool: missing name, prefix: '%s'", pool.Prefix)
+		}
+		_, poolNet, err := net.ParseCIDR(pool.Prefix)
+		if err != nil {
+			return fmt.Errorf("ipam pool: %s, invalid prefix: '%s'", pool.Name, pool.Prefix)
+		}
+		if _, exists := controller.IpamStrategy_name[int32(pool.Strategy)]; !exists {
+			return fmt.Errorf("ipam pool: %s, invalid strategy: %d", pool.Name, pool.Strategy)
+		}
+		cfg := ipam.SubnetConfig{
+			ReservedRanges: pool.ReservedRanges,
+			Gateway:        pool.Gateway,
+		}
+		if err := ipam.ValidateSubnetConfig(pool.Prefix, cfg); err != nil {
+			return fmt.Errorf("ipam pool: %s, %s", pool.Name, err)
+		}
+		for _, other := range pools[:i] {
+			if other.Name == pool.Name {
+				return fmt.Errorf("ipam pool: %s, duplicate name", pool.Name)
+			}
+			_, otherNet, _ := net.ParseCIDR(other.Prefix)
+			if otherNet.Contains(poolNet.IP) || poolNet.Contains(otherNet.IP) {
+				return fmt.Errorf("ipam pool: %s, prefix '%s' overlaps pool: %s, prefix '%s'", pool.Name,
+					pool.Prefix, other.Name, other.Prefix)
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateSFCVrfs checks the vrf and unnumbered settings of the vswitch i/fs of the sfc's elements, they are
+// only for the l3vrf sfc types, and an unnumbered i/f borrows the address of its host's loopback.  The vrfs of an
+// sfc with auto vrfs are allocated, the vrfs of the other sfcs are not allowed in the auto vrf range.
+func (sfcCtrlPlugin *SfcControllerPluginHandler) validateSFCVrfs(sfc *controller.SfcEntity) error {
+
+	isVrfType := sfc.Type == controller.SfcType_SFC_NS_NIC_VRF || sfc.Type == controller.SfcType_SFC_EW_VRF_FIB
+	if sfc.AutoVrf && !isVrfType {
+		return fmt.Errorf("sfc: %s, auto_vrf requires an l3vrf sfc type", sfc.Name)
+	}
+	sp := &sfcCtrlPlugin.ramConfigCache.SysParms
+	for _, sfcElement := range sfc.GetElements() {
+		vrfIDs := []uint32{sfcElement.VrfId}
+		for _, l3VRFRoute := range sfcElement.GetL3VrfRoutes() {
+			vrfIDs = append(vrfIDs, l3VRFRoute.VrfId)
+		}
+		for _, vrfID := range vrfIDs {
+			if vrfID == 0 {
+				continue
+			}
+			if sfc.AutoVrf {
+				return fmt.Errorf("sfc: %s, container: %s, port: %s, vrf %d, the vrfs of an auto_vrf sfc are allocated",
+					sfc.Name, sfcElement.Container, sfcElement.PortLabel, vrfID)
+			}
+			if vrfID >= sp.AutoVrfRangeStart && vrfID <= sp.AutoVrfRangeEnd {
+				return fmt.Errorf("sfc: %s, container: %s, port: %s, vrf %d is in the auto vrf range %d-%d",
+					sfc.Name, sfcElement.Container, sfcElement.PortLabel, vrfID, sp.AutoVrfRangeStart,
+					sp.AutoVrfRangeEnd)
+			}
+		}
+		if sfc.AutoVrf && sfcElement.Unnumbered {
+			return fmt.Errorf("sfc: %s, container: %s, port: %s, unnumbered requires the i/f in vrf 0, not an auto vrf",
+				sfc.Name, sfcElement.Container, sfcElement.PortLabel)
+		}
+		if sfcElement.VrfId == 0 && !sfcElement.Unnumbered {
+			continue
+		}
+		if !isVrfType {
+			return fmt.Errorf("sfc: %s, container: %s, port: %s, vrf_id and unnumbered require an l3vrf sfc type",
+				sfc.Name, sfcElement.Container, sfcElement.PortLabel)
+		}
+		if sfcElement.Type == controller.SfcElementType_EXTERNAL_ENTITY {
+			return fmt.Errorf("sfc: %s, external entity: %s, vrf_id and unnumbered are not supported", sfc.Name,
+				sfcElement.Container)
+		}
+		// the i/f is rendered in the vrf of its routes if it has no vrf_id
+		vrfID, err := utils.VrfIDOfElement(sfc, sfcElement)
+		if err != nil {
+			return err
+		}
+		if !sfcElement.Unnumbered {
+			continue
+		}
+		if vrfID != 0 {
+			return fmt.Errorf("sfc: %s, container: %s, port: %s, unnumbered requires the i/f in vrf 0, vrf: %d",
+				sfc.Name, sfcElement.Container, sfcElement.PortLabel, vrfID)
+		}
+		if sfcElement.Type == controller.SfcElementType_HOST_ENTITY && sfcElement.Ipv6Addr != "" {
+			return fmt.Errorf("sfc: %s, host: %s, port: %s, unnumbered and ipv6_addr are mutually exclusive",
+				sfc.Name, sfcElement.Container, sfcElement.PortLabel)
+		}
+		// the i/f of a host entity element is on the host itself
+		heName := sfcElement.EtcdVppSwitchKey
+		if sfcElement.Type == controller.SfcElementType_HOST_ENTITY {
+			heName = sfcElement.Container
+		}
+		he, exists := sfcCtrlPlugin.ramConfigCache.HEs[heName]
+		if !exists || (he.LoopbackIpv4 == "" && he.LoopbackIpv6 == "") {
+			return fmt.Errorf("sfc: %s, container: %s, port: %s, unnumbered requires a loopback on host: '%s'",
+				sfc.Name, sfcElement.Container, sfcElement.PortLabel, heName)
+		}
+	}
+
+	return nil
+}
+
+// validateSFCProxyArpRanges checks the proxy arp ranges of the sfc and its elements, they are only for the l3vrf
+// sfc types, and are rendered on the vswitch of the element.  The ranges of the sfc are for its container elements,
+// not the shared nic of a host entity element.  The vpp-agent proxy arp range has no vrf so the i/fs of the ranges
+// must be in vrf 0.
+func (sfcCtrlPlugin *SfcControllerPluginHandler) validateSFCProxyArpRanges(sfc *controller.SfcEntity) error {
+
+	isVrfType := sfc.Type == controller.SfcType_SFC_NS_NIC_VRF || sfc.Type == controller.SfcType_SFC_EW_VRF_FIB
+	if len(sfc.GetProxyArpRanges()) != 0 && !isVrfType {
+		return fmt.Errorf("sfc: %s, proxy_arp_ranges require an l3vrf sfc type", sfc.Name)
+	}
+	for _, proxyArpRange := range sfc.GetProxyArpRanges() {
+		if err := validateProxyArpRange(proxyArpRange); err != nil {
+			return fmt.Errorf("sfc: %s, %s", sfc.Name, err)
+		}
+	}
+	for _, sfcElement := range sfc.GetElements() {
+		sfcRanges := len(sfc.GetProxyArpRanges()) != 0 && sfcElement.Type != controller.SfcElementType_HOST_ENTITY &&
+			sfcElement.Type != controller.SfcElementType_EXTERNAL_ENTITY
+		if len(sfcElement.GetProxyArpRanges()) == 0 && !sfcRanges {
+			continue
+		}
+		if sfc.AutoVrf {
+			return fmt.Errorf("sfc: %s, container: %s, port: %s, proxy_arp_ranges require the i/f in vrf 0, not an auto vrf",
+				sfc.Name, sfcElement.Container, sfcElement.PortLabel)
+		}
+		vrfID, err := utils.VrfIDOfElement(sfc, sfcElement)
+		if err != nil {
+			return err
+		}
+		if vrfID != 0 {
+			return fmt.Errorf("sfc: %s, container: %s, port: %s, proxy_arp_ranges require the i/f in vrf 0, vrf: %d",
+				sfc.Name, sfcElement.Container, sfcElement.PortLabel, vrfID)
+		}
+		if len(sfcElement.GetProxyArpRanges()) == 0 {
+			continue
+		}
+		if !isVrfType {
+			return fmt.Errorf("sfc: %s, container: %s, port: %s, proxy_arp_ranges require an l3vrf sfc type",
+				sfc.Name, sfcElement.Container, sfcElement.PortLabel)
+		}
+		if sfcElement.Type == controller.SfcElementType_EXTERNAL_ENTITY {
+			return fmt.Errorf("sfc: %s, external entity: %s, proxy_arp_ranges are not supported", sfc.Name,
+				sfcElement.Container)
+		}
+		for _, proxyArpRange := range sfcElement.GetProxyArpRanges() {
+			if err := validateProxyArpRange(proxyArpRange); err != nil {
+				return fmt.Errorf("sfc: %s, container: %s, port: %s, %s", sfc.Name, sfcElement.Container,
+					sfcElement.PortLabel, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateProxyArpRange checks the range is a pair of ipv4 addresses where the start is not after the end
+func validateProxyArpRange(proxyArpRange *controller.ProxyArpRange) error {
+	start := net.ParseIP(proxyArpRange.RangeIpStart).To4()
+	end := net.ParseIP(proxyArpRange.RangeIpEnd).To4()
+	if start == nil || end == nil {
+		return fmt.Errorf("proxy arp range: '%s'-'%s', invalid ipv4 address", proxyArpRange.RangeIpStart,
+			proxyArpRange.RangeIpEnd)
+	}
+	if bytes.Compare(start, end) > 0 {
+		return fmt.Errorf("proxy arp range: '%s'-'%s', the start is after the end", proxyArpRange.RangeIpStart,
+			proxyArpRange.RangeIpEnd)
+	}
+	return nil
+}
+
+// validateSFCContainerL3Entries checks the routes, the default gateway, and the arp entries rendered in the
+// namespace of the elements' containers, they are only for the non vpp afp containers
+func validateSFCContainerL3Entries(sfc *controller.SfcEntity) error {
+
+	for _, sfcElement := range sfc.GetElements() {
+		if len(sfcElement.GetContainerRoutes()) == 0 && sfcElement.ContainerDefaultGw == "" &&
+			len(sfcElement.GetContainerArpEntries()) == 0 {
+			continue
+		}
+		if sfcElement.Type != controller.SfcElementType_NON_VPP_CONTAINER_AFP {
+			return fmt.Errorf("sfc: %s, container: %s, port: %s, container routes and arp entries require a "+
+				"non vpp afp container", sfc.Name, sfcElement.Container, sfcElement.PortLabel)
+		}
+		for _, containerRoute := range sfcElement.GetContainerRoutes() {
+			if _, _, err := net.ParseCIDR(containerRoute.DstIpAddr); err != nil {
+				return fmt.Errorf("sfc: %s, container: %s, port: %s, invalid container route dst_ip_addr: '%s'",
+					sfc.Name, sfcElement.Container, sfcElement.PortLabel, containerRoute.DstIpAddr)
+			}
+			if containerRoute.GwAddr != "" && net.ParseIP(containerRoute.GwAddr) == nil {
+				return fmt.Errorf("sfc: %s, container: %s, port: %s, invalid container route gw_addr: '%s'",
+					sfc.Name, sfcElement.Container, sfcElement.PortLabel, containerRoute.GwAddr)
+			}
+		}
+		if sfcElement.ContainerDefaultGw != "" && net.ParseIP(sfcElement.ContainerDefaultGw) == nil {
+			return fmt.Errorf("sfc: %s, container: %s, port: %s, invalid container_default_gw: '%s'",
+				sfc.Name, sfcElement.Container, sfcElement.PortLabel, sfcElement.ContainerDefaultGw)
+		}
+		for _, arpEntry := range sfcElement.GetContainerArpEntries() {
+			if net.ParseIP(arpEntry.IpAddress) == nil || !isUnicastMacAddress(arpEntry.PhysAddress) {
+				return fmt.Errorf("sfc: %s, container: %s, port: %s, invalid container arp entry: '%s'/'%s'",
+					sfc.Name, sfcElement.Container, sfcElement.PortLabel, arpEntry.IpAddress, arpEntry.PhysAddress)
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateSFCAcls checks the acls of the sfc and its elements, they are applied on the vswitch i/fs of the
+// elements so the containers of an e/w veth sfc, and the external entities, cannot have acls
+func validateSFCAcls(sfc *controller.SfcEntity) error {
+
+	isNICType := sfc.Type == controller.SfcType_SFC_NS_NIC_BD || sfc.Type == controller.SfcType_SFC_NS_NIC_VRF ||
+		sfc.Type == controller.SfcType_SFC_NS_NIC_L2XCONN
+	if len(sfc.GetAcls()) != 0 && sfc.Type == controller.SfcType_SFC_EW_VETH {
+		return fmt.Errorf("sfc: %s, acls are not supported for sfc type: '%s'", sfc.Name, sfc.Type)
+	}
+	if err := validateAcls(sfc.GetAcls()); err != nil {
+		return fmt.Errorf("sfc: %s, %s", sfc.Name, err)
+	}
+	for _, sfcElement := range sfc.GetElements() {
+		if len(sfcElement.GetAcls()) == 0 {
+			continue
+		}
+		if sfc.Type == controller.SfcType_SFC_EW_VETH ||
+			sfcElement.Type == controller.SfcElementType_EXTERNAL_ENTITY ||
+			(sfcElement.Type == controller.SfcElementType_HOST_ENTITY && !isNICType) {
+			return fmt.Errorf("sfc: %s, container: %s, port: %s, the element has no vswitch i/f for acls",
+				sfc.Name, sfcElement.Container, sfcElement.PortLabel)
+		}
+		if err := validateAcls(sfcElement.GetAcls()); err != nil {
+			return fmt.Errorf("sfc: %s, container: %s, port: %s, %s", sfc.Name, sfcElement.Container,
+				sfcElement.PortLabel, err)
+		}
+	}
+
+	return nil
+}
+
+// validateAcls checks the acls have unique names and their rules are valid
+func validateAcls(acls []*controller.Acl) error {
+
+	for i, acl := range acls {
+		if acl.Name == "" {
+			return fmt.Errorf("acl[%d]: missing name", i)
+		}
+		for _, other := range acls[:i] {
+			if other.Name == acl.Name {
+				return fmt.Errorf("acl: %s, duplicate name", acl.Name)
+			}
+		}
+		for j, rule := range acl.GetRules() {
+			if err := validateAclMatch(rule.Protocol, rule.SrcNetwork, rule.DstNetwork, rule.SrcPortLower,
+				rule.SrcPortUpper, rule.DstPortLower, rule.DstPortUpper); err != nil {
+				return fmt.Errorf("acl: %s, rule[%d]: %s", acl.Name, j, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateAclMatch checks the match of an acl, or classifier, rule
+func validateAclMatch(protocol controller.AclProtocol, srcNetwork string, dstNetwork string, srcPortLower uint32,
+	srcPortUpper uint32, dstPortLower uint32, dstPortUpper uint32) error {
+
+	if _, exists := controller.AclProtocol_name[int32(protocol)]; !exists {
+		return fmt.Errorf("invalid protocol: %d", protocol)
+	}
+	for _, network := range []string{srcNetwork, dstNetwork} {
+		if network == "" {
+			continue
+		}
+		if _, _, err := net.ParseCIDR(network); err != nil {
+			return fmt.Errorf("invalid network: '%s'", network)
+		}
+		if protocol == controller.AclProtocol_ACL_PROTOCOL_ICMP && isIpv6Address(network) {
+			return fmt.Errorf("protocol icmp with the ipv6 network: '%s', use icmpv6", network)
+		}
+		if protocol == controller.AclProtocol_ACL_PROTOCOL_ICMPV6 && !isIpv6Address(network) {
+			return fmt.Errorf("protocol icmpv6 with the ipv4 network: '%s', use icmp", network)
+		}
+	}
+	hasPorts := srcPortLower != 0 || srcPortUpper != 0 || dstPortLower != 0 || dstPortUpper != 0
+	if hasPorts && protocol != controller.AclProtocol_ACL_PROTOCOL_TCP &&
+		protocol != controller.AclProtocol_ACL_PROTOCOL_UDP {
+		return fmt.Errorf("ports require protocol tcp or udp")
+	}
+	if (srcPortUpper != 0 && srcPortLower > srcPortUpper) || (dstPortUpper != 0 && dstPortLower > dstPortUpper) ||
+		srcPortLower > 65535 || srcPortUpper > 65535 || dstPortLower > 65535 || dstPortUpper > 65535 {
+		return fmt.Errorf("invalid port range")
+	}
+	return nil
+}
+
+// validateSFCClassifier checks the classifier rules of the sfc, they are for the n/s sfcs sharing a nic, or a
+// tunnel bridge, with other sfcs, and only one of the sfcs sharing it is the default
+func (sfcCtrlPlugin *SfcControllerPluginHandler) validateSFCClassifier(sfc *controller.SfcEntity) error {
+
+	if len(sfc.GetClassifierRules()) == 0 && !sfc.ClassifierDefault {
+		return nil
+	}
+	attachment := utils.SfcClassifierAttachment(sfc)
+	if attachment == "" {
+		return fmt.Errorf("sfc: %s, classifier requires an n/s nic bd, n/s nic vrf, or n/s vxlan sfc without a "+
+			"dedicated vni", sfc.Name)
+	}
+	if sfc.ClassifierDefault && len(sfc.GetClassifierRules()) != 0 {
+		return fmt.Errorf("sfc: %s, the classifier default sfc has no classifier rules", sfc.Name)
+	}
+	for i, rule := range sfc.GetClassifierRules() {
+		if err := validateAclMatch(rule.Protocol, rule.SrcNetwork, rule.DstNetwork, rule.SrcPortLower,
+			rule.SrcPortUpper, rule.DstPortLower, rule.DstPortUpper); err != nil {
+			return fmt.Errorf("sfc: %s, classifier rule[%d]: %s", sfc.Name, i, err)
+		}
+		if sfc.Type != controller.SfcType_SFC_NS_NIC_VRF {
+			continue
+		}
+		// the traffic is steered into an l3vrf sfc by a route to the dst network, it matches nothing else
+		if rule.DstNetwork == "" {
+			return fmt.Errorf("sfc: %s, classifier rule[%d]: dst_network is required to route into an l3vrf sfc",
+				sfc.Name, i)
+		}
+		if rule.Protocol != controller.AclProtocol_ACL_PROTOCOL_ANY || rule.SrcNetwork != "" ||
+			rule.SrcPortLower != 0 || rule.SrcPortUpper != 0 || rule.DstPortLower != 0 || rule.DstPortUpper != 0 {
+			return fmt.Errorf("sfc: %s, classifier rule[%d]: an l3vrf sfc is classified by dst_network only",
+				sfc.Name, i)
+		}
+		for j, other := range sfc.GetClassifierRules()[:i] {
+			if sameNetwork(rule.DstNetwork, other.DstNetwork) {
+				return fmt.Errorf("sfc: %s, classifier rule[%d]: dst_network: '%s' is the one of rule[%d]",
+					sfc.Name, i, rule.DstNetwork, j)
+			}
+		}
+	}
+	if sfc.Type == controller.SfcType_SFC_NS_NIC_VRF && sfc.AutoVrf {
+		return fmt.Errorf("sfc: %s, the nic of a classified sfc is shared, it cannot be in an auto vrf", sfc.Name)
+	}
+	for name, other := range sfcCtrlPlugin.ramConfigCache.SFCs {
+		if name == sfc.Name || utils.SfcClassifierAttachment(&other) != attachment {
+			continue
+		}
+		if sfc.ClassifierDefault && other.ClassifierDefault {
+			return fmt.Errorf("sfc: %s, sfc: %s is already the classifier default", sfc.Name, name)
+		}
+		if sfc.Type != controller.SfcType_SFC_NS_NIC_VRF {
+			continue
+		}
+		// the nic is in one vrf, the classifier routes of all the sfcs sharing it are in that vrf
+		if sfcNICVrfID(sfc) != sfcNICVrfID(&other) {
+			return fmt.Errorf("sfc: %s, the nic is in vrf %d, sfc: %s sharing it has it in vrf %d", sfc.Name,
+				sfcNICVrfID(sfc), name, sfcNICVrfID(&other))
+		}
+		// so one dst network is routed into one sfc, the default sfc has the default routes
+		for _, network := range classifierDstNetworks(sfc) {
+			for _, otherNetwork := range classifierDstNetworks(&other) {
+				if sameNetwork(network, otherNetwork) {
+					return fmt.Errorf("sfc: %s, dst_network: '%s' is classified to sfc: %s", sfc.Name,
+						network, name)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// classifierDstNetworks returns the dst networks routed into the n/s nic vrf sfc, the default sfc's are the
+// default routes
+func classifierDstNetworks(sfc *controller.SfcEntity) []string {
+	if sfc.ClassifierDefault {
+		return []string{"0.0.0.0/0", "::/0"}
+	}
+	networks := make([]string, 0, len(sfc.GetClassifierRules()))
+	for _, rule := range sfc.GetClassifierRules() {
+		networks = append(networks, rule.DstNetwork)
+	}
+	return networks
+}
+
+// sameNetwork returns true if the prefixes are the same network, eg: 10.1.1.0/24 and 10.1.1.1/24
+func sameNetwork(prefix string, other string) bool {
+	_, prefixNet, err := net.ParseCIDR(prefix)
+	if err != nil {
+		return false
+	}
+	_, otherNet, err := net.ParseCIDR(other)
+	if err != nil {
+		return false
+	}
+	return prefixNet.String() == otherNet.String()
+}
+
+// sfcNICVrfID returns the vrf of the nic of the n/s nic sfc
+func sfcNICVrfID(sfc *controller.SfcEntity) uint32 {
+	for _, sfcElement := range sfc.GetElements() {
+		if sfcElement.Type == controller.SfcElementType_HOST_ENTITY {
+			return sfcElement.VrfId
+		}
+	}
+	return 0
+}
+
+// validate the configured macs of the SFC elements, a mac must not be on two interfaces
+func (sfcCtrlPlugin *SfcControllerPluginHandler) validateSFCMacAddresses(sfc *controller.SfcEntity) error {
+
+	for i, sfcElement := range sfc.GetElements() {
+		if sfcElement.MacAddr == "" {
+			continue
+		}
+		if !isUnicastMacAddress(sfcElement.MacAddr) {
+			return fmt.Errorf("sfc: %s, container: %s, port: %s, invalid mac_addr: '%s'", sfc.Name,
+				sfcElement.Container, sfcElement.PortLabel, sfcElement.MacAddr)
+		}
+		for _, other := range sfc.GetElements()[:i] {
+			if sameMacAddress(other.MacAddr, sfcElement.MacAddr) {
+				return fmt.Errorf("sfc: %s, mac_addr: '%s' is on container: %s, port: %s and container: %s, port: %s",
+					sfc.Name, sfcElement.MacAddr, other.Container, other.PortLabel, sfcElement.Container,
+					sfcElement.PortLabel)
+			}
+		}
+		if user := sfcCtrlPlugin.findMacAddressUser(sfcElement.MacAddr, "", sfc.Name); user != "" {
+			return fmt.Errorf("sfc: %s, container: %s, port: %s, mac_addr: '%s' is already used by %s", sfc.Name,
+				sfcElement.Container, sfcElement.PortLabel, sfcElement.MacAddr, user)
+		}
+	}
+
+	return nil
+}
+
+// findMacAddressUser returns the host or sfc, other than the ones named, which is configured with the mac,
+// "" if there is none
+func (sfcCtrlPlugin *SfcControllerPluginHandler) findMacAddressUser(macAddress string, heName string,
+	sfcName string) string {
+
+	for _, he := range sfcCtrlPlugin.ramConfigCache.HEs {
+		if he.Name != heName && sameMacAddress(he.LoopbackMacAddr, macAddress) {
+			return "he: " + he.Name
+		}
+	}
+	for _, sfc := range sfcCtrlPlugin.ramConfigCache.SFCs {
+		if sfc.Name == sfcName {
+			continue
+		}
+		for _, sfcElement := range sfc.GetElements() {
+			if sameMacAddress(sfcElement.MacAddr, macAddress) {
+				return fmt.Sprintf("sfc: %s, container: %s, port: %s", sfc.Name, sfcElement.Container,
+					sfcElement.PortLabel)
+			}
+		}
+	}
+	return ""
+}
+
+// isUnicastMacAddress returns true if the mac is a 6 octet unicast mac
+func isUnicastMacAddress(macAddress string) bool {
+	mac, err := net.ParseMAC(macAddress)
+	return err == nil && len(mac) == 6 && mac[0]&0x01 == 0
+}
+
+// sameMacAddress returns true if both macs are set and are the same mac, whatever the case of their digits
+func sameMacAddress(macAddress1 string, macAddress2 string) bool {
+	mac1, err1 := net.ParseMAC(macAddress1)
+	mac2, err2 := net.ParseMAC(macAddress2)
+	return err1 == nil && err2 == nil && mac1.String() == mac2.String()
+}
+
+// validateMemifParms checks the memif parms are accepted by vpp
+func validateMemifParms(parms *controller.MemifParms) error {
+
+	if parms == nil {
+		return nil
+	}
+	if _, exists := controller.MemifMode_name[int32(parms.Mode)]; !exists {
+		return fmt.Errorf("memif parms: invalid mode: %d", parms.Mode)
+	}
+	if len(parms.Secret) > 24 {
+		return fmt.Errorf("memif parms: secret is longer than 24 chars")
+	}
+	if parms.RingSize != 0 && (parms.RingSize&(parms.RingSize-1) != 0 || parms.RingSize > 1<<15) {
+		return fmt.Errorf("memif parms: ring_size: %d is not a power of 2 up to 32768", parms.RingSize)
+	}
+	if parms.BufferSize > 0xFFFF {
+		return fmt.Errorf("memif parms: buffer_size: %d is more than 65535", parms.BufferSize)
+	}
+	if parms.RxQueues > 0xFF || parms.TxQueues > 0xFF {
+		return fmt.Errorf("memif parms: rx_queues: %d, tx_queues: %d, at most 255 queues", parms.RxQueues,
+			parms.TxQueues)
+	}
+
+	return nil
+}
+
+// findIpamPool returns the named pool of the system parameters, nil if there is none
+func (sfcCtrlPlugin *SfcControllerPluginHandler) findIpamPool(name string) *controller.IpamPool {
+	if name == "" {
+		return nil
+	}
+	for _, pool := range sfcCtrlPlugin.ramConfigCache.SysParms.GetIpamPools() {
+		if pool.Name == name {
+			return pool
+		}
+	}
+	return nil
+}
+
+// isIpv6Address returns true if the address, with or without a prefix, is an ipv6 address
+func isIpv6Address(addr string) bool {
+	ip := net.ParseIP(strings.Split(addr, "/")[0])
+	return ip != nil && ip.To4() == nil
+}
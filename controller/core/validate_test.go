@@ -0,0 +1,330 @@
+// Copyright (c) 2017 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"testing"
+
+	"github.com/ligato/sfc-controller/controller/model/controller"
+)
+
+func TestValidateTapElement(t *testing.T) {
+	tests := []struct {
+		name      string
+		typ       controller.SfcElementType
+		namespace string
+		wantErr   bool
+	}{
+		{"vpp tap", controller.SfcElementType_VPP_CONTAINER_TAP, "vnf1-ns", false},
+		{"vpp tap without netns", controller.SfcElementType_VPP_CONTAINER_TAP, "", true},
+		{"vpp tap with a netns path", controller.SfcElementType_VPP_CONTAINER_TAP, "/var/run/netns/vnf1", true},
+		{"afp", controller.SfcElementType_NON_VPP_CONTAINER_AFP, "", false},
+		{"afp with a netns", controller.SfcElementType_NON_VPP_CONTAINER_AFP, "vnf1-ns", true},
+	}
+	for _, test := range tests {
+		element := &controller.SfcEntity_SfcElement{Container: "vnf1", PortLabel: "port1", Type: test.typ,
+			TapNamespace: test.namespace}
+		if err := validateTapElement(element); (err != nil) != test.wantErr {
+			t.Errorf("%s: error %v, want error %t", test.name, err, test.wantErr)
+		}
+	}
+}
+
+func TestValidateSFCVrfs(t *testing.T) {
+	sfcCtrlPlugin := &SfcControllerPluginHandler{}
+	sfcCtrlPlugin.ramConfigCache.HEs = map[string]controller.HostEntity{
+		"h1": {Name: "h1", LoopbackIpv4: "10.0.0.1"},
+	}
+	sfcCtrlPlugin.ramConfigCache.SysParms.AutoVrfRangeStart = 1000
+	sfcCtrlPlugin.ramConfigCache.SysParms.AutoVrfRangeEnd = 1999
+
+	route := func(vrfID uint32) []*controller.L3VRFRoute {
+		return []*controller.L3VRFRoute{{VrfId: vrfID, Description: "r1",
+			DstIpAddr: "10.1.0.0/16", NextHopAddr: "10.2.0.1"}}
+	}
+	tests := []struct {
+		name    string
+		element controller.SfcEntity_SfcElement
+		wantErr bool
+	}{
+		{"vrf", controller.SfcEntity_SfcElement{VrfId: 10, L3VrfRoutes: route(10)}, false},
+		{"route in another vrf", controller.SfcEntity_SfcElement{VrfId: 10, L3VrfRoutes: route(11)}, true},
+		{"vrf in the auto range", controller.SfcEntity_SfcElement{VrfId: 1000}, true},
+		{"unnumbered", controller.SfcEntity_SfcElement{Unnumbered: true}, false},
+		{"unnumbered in a vrf", controller.SfcEntity_SfcElement{Unnumbered: true, VrfId: 10}, true},
+		{"unnumbered with routes in a vrf", controller.SfcEntity_SfcElement{Unnumbered: true, L3VrfRoutes: route(10)},
+			true},
+		{"unnumbered with routes in vrf 0", controller.SfcEntity_SfcElement{Unnumbered: true, L3VrfRoutes: route(0)},
+			false},
+	}
+	for _, test := range tests {
+		element := test.element
+		element.Container = "vnf1"
+		element.PortLabel = "port1"
+		element.EtcdVppSwitchKey = "h1"
+		sfc := &controller.SfcEntity{Name: "sfc1", Type: controller.SfcType_SFC_EW_VRF_FIB,
+			Elements: []*controller.SfcEntity_SfcElement{&element}}
+		if err := sfcCtrlPlugin.validateSFCVrfs(sfc); (err != nil) != test.wantErr {
+			t.Errorf("%s: error %v, want error %t", test.name, err, test.wantErr)
+		}
+	}
+}
+
+func TestValidateHEEthVrf(t *testing.T) {
+	sfcCtrlPlugin := &SfcControllerPluginHandler{}
+	tests := []struct {
+		name    string
+		he      controller.HostEntity
+		wantErr bool
+	}{
+		{"eth vrf", controller.HostEntity{EthVrfId: 10}, false},
+		{"vxlan static routes", controller.HostEntity{CreateVxlanStaticRoute: true}, false},
+		{"eth vrf with vxlan static routes", controller.HostEntity{EthVrfId: 10, CreateVxlanStaticRoute: true}, true},
+	}
+	for _, test := range tests {
+		he := test.he
+		he.Name = "h1"
+		if err := sfcCtrlPlugin.validateHE(&he); (err != nil) != test.wantErr {
+			t.Errorf("%s: error %v, want error %t", test.name, err, test.wantErr)
+		}
+	}
+}
+
+func TestValidateSFCProxyArpRanges(t *testing.T) {
+	sfcCtrlPlugin := &SfcControllerPluginHandler{}
+	ranges := []*controller.ProxyArpRange{{RangeIpStart: "10.1.0.1", RangeIpEnd: "10.1.0.9"}}
+	tests := []struct {
+		name      string
+		autoVrf   bool
+		sfcRanges bool
+		element   controller.SfcEntity_SfcElement
+		wantErr   bool
+	}{
+		{"element ranges", false, false, controller.SfcEntity_SfcElement{ProxyArpRanges: ranges}, false},
+		{"element ranges in a vrf", false, false, controller.SfcEntity_SfcElement{VrfId: 10, ProxyArpRanges: ranges},
+			true},
+		{"element ranges with routes in a vrf", false, false, controller.SfcEntity_SfcElement{ProxyArpRanges: ranges,
+			L3VrfRoutes: []*controller.L3VRFRoute{{VrfId: 10}}}, true},
+		{"element ranges with auto vrfs", true, false, controller.SfcEntity_SfcElement{ProxyArpRanges: ranges}, true},
+		{"sfc ranges", false, true, controller.SfcEntity_SfcElement{}, false},
+		{"sfc ranges in a vrf", false, true, controller.SfcEntity_SfcElement{VrfId: 10}, true},
+		{"sfc ranges with a host nic in a vrf", false, true,
+			controller.SfcEntity_SfcElement{Type: controller.SfcElementType_HOST_ENTITY, VrfId: 10}, false},
+	}
+	for _, test := range tests {
+		element := test.element
+		element.Container = "vnf1"
+		element.PortLabel = "port1"
+		if element.Type == controller.SfcElementType_ELEMENT_UNKNOWN {
+			element.Type = controller.SfcElementType_VPP_CONTAINER_MEMIF
+		}
+		sfc := &controller.SfcEntity{Name: "sfc1", Type: controller.SfcType_SFC_EW_VRF_FIB, AutoVrf: test.autoVrf,
+			Elements: []*controller.SfcEntity_SfcElement{&element}}
+		if test.sfcRanges {
+			sfc.ProxyArpRanges = ranges
+		}
+		if err := sfcCtrlPlugin.validateSFCProxyArpRanges(sfc); (err != nil) != test.wantErr {
+			t.Errorf("%s: error %v, want error %t", test.name, err, test.wantErr)
+		}
+	}
+}
+
+func TestValidateTenantPools(t *testing.T) {
+	sfcCtrlPlugin := &SfcControllerPluginHandler{}
+	sfcCtrlPlugin.ramConfigCache.Tenants = map[string]controller.Tenant{
+		"t1": {Name: "t1", VniRangeStart: 100, VniRangeEnd: 199, MacRangeStart: 100, MacRangeEnd: 199,
+			Ipv4Pools: []string{"10.1.0.0/16"}},
+	}
+	sfcCtrlPlugin.ramConfigCache.SFCs = map[string]controller.SfcEntity{
+		"sfc1": {Name: "sfc1", SfcIpv4Prefix: "10.3.1.0/24"},
+		"sfc2": {Name: "sfc2", Tenant: "t2", SfcIpv4Prefix: "10.2.1.0/24"},
+	}
+	tests := []struct {
+		name    string
+		pools   []string
+		wantErr bool
+	}{
+		{"own pools", []string{"10.2.0.0/16"}, false},
+		{"invalid pool", []string{"10.2.0.0"}, true},
+		{"ipv6 pool", []string{"2001:db8::/64"}, true},
+		{"overlapping own pools", []string{"10.2.0.0/16", "10.2.1.0/24"}, true},
+		{"pool inside another tenant's pool", []string{"10.2.0.0/16", "10.1.1.0/24"}, true},
+		{"pool around another tenant's pool", []string{"10.2.0.0/16", "10.0.0.0/15"}, true},
+		{"pool overlapping an sfc without tenant", []string{"10.2.0.0/16", "10.3.0.0/16"}, true},
+		{"pools without its sfc's prefix", []string{"10.4.0.0/16"}, true},
+		{"no pools with an sfc prefix", nil, true},
+	}
+	for _, test := range tests {
+		tenant := &controller.Tenant{Name: "t2", VniRangeStart: 200, VniRangeEnd: 299, MacRangeStart: 200,
+			MacRangeEnd: 299, Ipv4Pools: test.pools}
+		if err := sfcCtrlPlugin.validateTenant(tenant); (err != nil) != test.wantErr {
+			t.Errorf("%s: error %v, want error %t", test.name, err, test.wantErr)
+		}
+	}
+}
+
+func TestValidateSFCTenantPrefix(t *testing.T) {
+	sfcCtrlPlugin := &SfcControllerPluginHandler{}
+	sfcCtrlPlugin.ramConfigCache.Tenants = map[string]controller.Tenant{
+		"t1": {Name: "t1", VniRangeStart: 100, VniRangeEnd: 199, Ipv4Pools: []string{"10.1.0.0/16"}},
+		"t2": {Name: "t2", VniRangeStart: 200, VniRangeEnd: 299},
+	}
+	sfcCtrlPlugin.ramConfigCache.SysParms.IpamPools = []*controller.IpamPool{
+		{Name: "p1", Prefix: "10.1.2.0/24"},
+		{Name: "p2", Prefix: "10.5.0.0/24"},
+	}
+	tests := []struct {
+		name    string
+		sfc     controller.SfcEntity
+		wantErr bool
+	}{
+		{"tenant prefix", controller.SfcEntity{Tenant: "t1", SfcIpv4Prefix: "10.1.1.0/24"}, false},
+		{"tenant prefix outside its pools", controller.SfcEntity{Tenant: "t1", SfcIpv4Prefix: "10.2.1.0/24"}, true},
+		{"tenant ipam pool", controller.SfcEntity{Tenant: "t1", Ipv4IpamPool: "p1"}, false},
+		{"tenant ipam pool outside its pools", controller.SfcEntity{Tenant: "t1", Ipv4IpamPool: "p2"}, true},
+		{"tenant without pools", controller.SfcEntity{Tenant: "t2"}, false},
+		{"prefix of a tenant without pools", controller.SfcEntity{Tenant: "t2", SfcIpv4Prefix: "10.2.1.0/24"}, true},
+		{"ipam pool of a tenant without pools", controller.SfcEntity{Tenant: "t2", Ipv4IpamPool: "p2"}, true},
+		{"prefix without tenant", controller.SfcEntity{SfcIpv4Prefix: "10.2.1.0/24"}, false},
+		{"prefix without tenant in a tenant's pool", controller.SfcEntity{SfcIpv4Prefix: "10.1.1.0/24"}, true},
+		{"prefix without tenant around a tenant's pool", controller.SfcEntity{SfcIpv4Prefix: "10.0.0.0/8"}, true},
+		{"ipam pool without tenant in a tenant's pool", controller.SfcEntity{Ipv4IpamPool: "p1"}, true},
+	}
+	for _, test := range tests {
+		sfc := test.sfc
+		sfc.Name = "sfc1"
+		sfc.Type = controller.SfcType_SFC_EW_BD
+		if err := sfcCtrlPlugin.validateSFC(&sfc); (err != nil) != test.wantErr {
+			t.Errorf("%s: error %v, want error %t", test.name, err, test.wantErr)
+		}
+	}
+}
+
+func TestValidateSFCRawPrefixes(t *testing.T) {
+	sfcCtrlPlugin := &SfcControllerPluginHandler{}
+	sfcCtrlPlugin.ramConfigCache.SysParms.IpamPools = []*controller.IpamPool{
+		{Name: "p1", Prefix: "10.1.0.0/16"},
+		{Name: "p6", Prefix: "2001:db8:1::/64"},
+	}
+	sfcCtrlPlugin.ramConfigCache.SFCs = map[string]controller.SfcEntity{
+		"sfc2": {Name: "sfc2", SfcIpv4Prefix: "10.2.0.0/16", SfcIpv6Prefix: "2001:db8:2::/64"},
+	}
+	tests := []struct {
+		name    string
+		sfc     controller.SfcEntity
+		wantErr bool
+	}{
+		{"own prefix", controller.SfcEntity{SfcIpv4Prefix: "10.3.0.0/16"}, false},
+		{"invalid prefix", controller.SfcEntity{SfcIpv4Prefix: "10.3.0.0"}, true},
+		{"prefix inside a pool", controller.SfcEntity{SfcIpv4Prefix: "10.1.1.0/24"}, true},
+		{"prefix around a pool", controller.SfcEntity{SfcIpv4Prefix: "10.0.0.0/8"}, true},
+		{"prefix of a pool", controller.SfcEntity{SfcIpv4Prefix: "10.1.0.0/16"}, true},
+		{"ipv6 prefix inside a pool", controller.SfcEntity{SfcIpv6Prefix: "2001:db8:1::/96"}, true},
+		{"shared prefix of another sfc", controller.SfcEntity{SfcIpv4Prefix: "10.2.0.0/16"}, false},
+		{"prefix inside another sfc's", controller.SfcEntity{SfcIpv4Prefix: "10.2.1.0/24"}, true},
+		{"another sfc's prefix written differently", controller.SfcEntity{SfcIpv4Prefix: "10.2.0.1/16"}, true},
+		{"ipv6 prefix around another sfc's", controller.SfcEntity{SfcIpv6Prefix: "2001:db8::/32"}, true},
+		{"pool reference", controller.SfcEntity{Ipv4IpamPool: "p1"}, false},
+	}
+	for _, test := range tests {
+		sfc := test.sfc
+		sfc.Name = "sfc1"
+		sfc.Type = controller.SfcType_SFC_EW_BD
+		if err := sfcCtrlPlugin.validateSFC(&sfc); (err != nil) != test.wantErr {
+			t.Errorf("%s: error %v, want error %t", test.name, err, test.wantErr)
+		}
+	}
+
+	// a pool cannot be added over the prefix of an sfc either
+	sp := &controller.SystemParameters{IpamPools: []*controller.IpamPool{{Name: "p2", Prefix: "10.2.128.0/17"}}}
+	if err := sfcCtrlPlugin.validateSystemParameters(sp); err == nil {
+		t.Errorf("pool inside an sfc's prefix: no error")
+	}
+	sp = &controller.SystemParameters{IpamPools: []*controller.IpamPool{{Name: "p2", Prefix: "10.4.0.0/16"}}}
+	if err := sfcCtrlPlugin.validateSystemParameters(sp); err != nil {
+		t.Errorf("pool outside the sfcs' prefixes: error %v", err)
+	}
+}
+
+func TestValidateSFCClassifierNICVrf(t *testing.T) {
+	nic := &controller.SfcEntity_SfcElement{Container: "h1", PortLabel: "GigabitEthernet13/0/0",
+		Type: controller.SfcElementType_HOST_ENTITY}
+	sfcCtrlPlugin := &SfcControllerPluginHandler{}
+	sfcCtrlPlugin.ramConfigCache.SFCs = map[string]controller.SfcEntity{
+		"sfc2": {Name: "sfc2", Type: controller.SfcType_SFC_NS_NIC_VRF, Elements: []*controller.SfcEntity_SfcElement{nic},
+			ClassifierRules: []*controller.ClassifierRule{{DstNetwork: "10.2.0.0/16"}}},
+	}
+	tests := []struct {
+		name    string
+		rules   []*controller.ClassifierRule
+		deflt   bool
+		wantErr bool
+	}{
+		{"dst networks", []*controller.ClassifierRule{{DstNetwork: "10.1.0.0/16"}, {DstNetwork: "10.1.1.0/24"}},
+			false, false},
+		{"no dst network", []*controller.ClassifierRule{{}}, false, true},
+		{"protocol", []*controller.ClassifierRule{{DstNetwork: "10.1.0.0/16",
+			Protocol: controller.AclProtocol_ACL_PROTOCOL_TCP}}, false, true},
+		{"ports", []*controller.ClassifierRule{{DstNetwork: "10.1.0.0/16",
+			Protocol: controller.AclProtocol_ACL_PROTOCOL_ANY, DstPortLower: 80}}, false, true},
+		{"src network", []*controller.ClassifierRule{{DstNetwork: "10.1.0.0/16", SrcNetwork: "10.9.0.0/16"}},
+			false, true},
+		{"duplicate dst network", []*controller.ClassifierRule{{DstNetwork: "10.1.0.0/16"},
+			{DstNetwork: "10.1.0.1/16"}}, false, true},
+		{"dst network of another sfc on the nic", []*controller.ClassifierRule{{DstNetwork: "10.2.0.0/16"}},
+			false, true},
+		{"default route with a default sfc", nil, true, false},
+	}
+	for _, test := range tests {
+		sfc := &controller.SfcEntity{Name: "sfc1", Type: controller.SfcType_SFC_NS_NIC_VRF,
+			Elements: []*controller.SfcEntity_SfcElement{nic}, ClassifierRules: test.rules,
+			ClassifierDefault: test.deflt}
+		if err := sfcCtrlPlugin.validateSFCClassifier(sfc); (err != nil) != test.wantErr {
+			t.Errorf("%s: error %v, want error %t", test.name, err, test.wantErr)
+		}
+	}
+
+	// the default sfc has the default routes
+	sfcCtrlPlugin.ramConfigCache.SFCs["sfc3"] = controller.SfcEntity{Name: "sfc3",
+		Type: controller.SfcType_SFC_NS_NIC_VRF, Elements: []*controller.SfcEntity_SfcElement{nic},
+		ClassifierDefault: true}
+	sfc := &controller.SfcEntity{Name: "sfc1", Type: controller.SfcType_SFC_NS_NIC_VRF,
+		Elements:        []*controller.SfcEntity_SfcElement{nic},
+		ClassifierRules: []*controller.ClassifierRule{{DstNetwork: "0.0.0.0/0"}}}
+	if err := sfcCtrlPlugin.validateSFCClassifier(sfc); err == nil {
+		t.Errorf("default route with a default sfc on the nic: no error")
+	}
+}
+
+func TestValidateAclMatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		protocol controller.AclProtocol
+		src      string
+		dst      string
+		wantErr  bool
+	}{
+		{"icmp ipv4", controller.AclProtocol_ACL_PROTOCOL_ICMP, "10.1.0.0/16", "", false},
+		{"icmp ipv6", controller.AclProtocol_ACL_PROTOCOL_ICMP, "", "2001:db8::/64", true},
+		{"icmpv6 ipv6", controller.AclProtocol_ACL_PROTOCOL_ICMPV6, "2001:db8::/64", "", false},
+		{"icmpv6 ipv4", controller.AclProtocol_ACL_PROTOCOL_ICMPV6, "", "10.1.0.0/16", true},
+		{"unknown protocol", controller.AclProtocol(9), "", "", true},
+	}
+	for _, test := range tests {
+		if err := validateAclMatch(test.protocol, test.src, test.dst, 0, 0, 0, 0); (err != nil) != test.wantErr {
+			t.Errorf("%s: error %v, want error %t", test.name, err, test.wantErr)
+		}
+	}
+}
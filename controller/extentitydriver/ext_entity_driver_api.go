@@ -44,11 +44,12 @@ const (
 
 // EEOperation is external entity operation
 type EEOperation struct {
-	ee  controller.ExternalEntity
-	he  controller.HostEntity
-	op  int
-	vni uint32
-	sr  *l3.StaticRoutes_Route
+	ee   controller.ExternalEntity
+	he   controller.HostEntity
+	op   int
+	vni  uint32
+	bdID uint32
+	sr   *l3.StaticRoutes_Route
 }
 
 // external entity configuration
@@ -69,19 +70,21 @@ func SfcExternalEntityDriverInit() {
 	go processEEOperationChannel()
 }
 
-// SfcCtlrL2WireExternalEntityToHostEntity (called from the sfcctlr l2 driver) configures the bridge, vxlan tunnel, and static route
+// SfcCtlrL2WireExternalEntityToHostEntity (called from the sfcctlr l2 driver) configures the bridge, vxlan tunnel, and static route,
+// the vni is added to the bridge bdID, or to the ee's host_bd if bdID is 0
 func SfcCtlrL2WireExternalEntityToHostEntity(ee controller.ExternalEntity, he controller.HostEntity,
-	vni uint32, sr *l3.StaticRoutes_Route) error {
+	vni uint32, bdID uint32, sr *l3.StaticRoutes_Route) error {
 
 	switch ee.EeDriverType {
 	case controller.ExtEntDriverType_EE_DRIVER_TYPE_IOSXE_SSH:
 
 		eeOp := &EEOperation{
-			ee:  ee,
-			he:  he,
-			op:  eeOpSFCCtlrL2EEToHESSH,
-			vni: vni,
-			sr:  sr,
+			ee:   ee,
+			he:   he,
+			op:   eeOpSFCCtlrL2EEToHESSH,
+			vni:  vni,
+			bdID: bdID,
+			sr:   sr,
 		}
 
 		EEOperationChannel <- eeOp
@@ -126,7 +129,7 @@ func processEEOperationChannel() {
 
 		switch eeOp.op {
 		case eeOpSFCCtlrL2EEToHESSH:
-			sfcCtlrL2WireExternalEntityToHostEntityUsingCli(&eeOp.ee, &eeOp.he, eeOp.vni, eeOp.sr)
+			sfcCtlrL2WireExternalEntityToHostEntityUsingCli(&eeOp.ee, &eeOp.he, eeOp.vni, eeOp.bdID, eeOp.sr)
 		case eeOpSFCCtlrL2EEInternalsSSH:
 			sfcCtlrL2WireExternalEntityInternalsUsingCli(&eeOp.ee)
 
@@ -135,10 +138,14 @@ func processEEOperationChannel() {
 }
 
 func sfcCtlrL2WireExternalEntityToHostEntityUsingCli(ee *controller.ExternalEntity, he *controller.HostEntity,
-	vni uint32, sr *l3.StaticRoutes_Route) error {
+	vni uint32, bdID uint32, sr *l3.StaticRoutes_Route) error {
 
-	log.Infof("sfcCtlrL2WireExternalEntityToHostEntityUsingCli: creating an ssh session (dstIP:%s) ee: %s, he: %s, vni: %d, bd: %s, static route: %s",
-		ee.MgmntIpAddress, ee.Name, he.Name, vni, sr.String())
+	if bdID == 0 {
+		bdID = ee.HostBd.Id
+	}
+
+	log.Infof("sfcCtlrL2WireExternalEntityToHostEntityUsingCli: creating an ssh session (dstIP:%s) ee: %s, he: %s, vni: %d, bd: %d, static route: %s",
+		ee.MgmntIpAddress, ee.Name, he.Name, vni, bdID, sr.String())
 
 	s, err := connectToRouter(ee.MgmntIpAddress, ee.MgmntPort, ee.BasicAuthUser, ee.BasicAuthPasswd)
 	if err != nil {
@@ -147,11 +154,18 @@ func sfcCtlrL2WireExternalEntityToHostEntityUsingCli(ee *controller.ExternalEnti
 	}
 	defer s.Close()
 
-	// configure static route
-	ip := utils.TruncateString(sr.DstIpAddr, strings.Index(sr.DstIpAddr, "/"))
+	// configure static route, the host is an ipv6 peer if its tunnel endpoint is ipv6
+	ip := sr.DstIpAddr
+	if i := strings.Index(ip, "/"); i >= 0 {
+		ip = utils.TruncateString(ip, i)
+	}
+	hostPrefix := ip + "/32"
+	if strings.Contains(ip, ":") {
+		hostPrefix = ip + "/128"
+	}
 	err = s.AddStaticRoute(
 		&iosxe.StaticRoute{
-			ip + "/32",
+			hostPrefix,
 			sr.NextHopAddr,
 			"",
 		})
@@ -174,9 +188,17 @@ func sfcCtlrL2WireExternalEntityToHostEntityUsingCli(ee *controller.ExternalEnti
 		return err
 	}
 
-	// add the VNI into the host_bd
-	eeCfg.bds[ee.HostBd.Id].Vni = append(eeCfg.bds[ee.HostBd.Id].Vni, vni)
-	err = s.AddBridgeDomain(eeCfg.bds[ee.HostBd.Id])
+	// add the VNI into the host_bd, or the tenant's/sfc's bd which is created on first use
+	bd, exists := eeCfg.bds[bdID]
+	if !exists {
+		if bd, err = configureEEScopedBD(s, ee.HostBd, bdID); err != nil {
+			log.Error(err)
+			return err
+		}
+		eeCfg.bds[bdID] = bd
+	}
+	bd.Vni = append(bd.Vni, vni)
+	err = s.AddBridgeDomain(bd)
 	if err != nil {
 		log.Error(err)
 		return err
@@ -262,6 +284,7 @@ func configureEEHostInterface(s *iosxecfg.Session, eeCfg *eeConfig,
 		Type:        iosxe.InterfaceType_ETHERNET_CSMACD,
 		Decription:  "host interface",
 		IpAddress:   hostIf.Ipv4Addr,
+		Ipv6Address: hostIf.Ipv6Addr,
 		IpRedirects: true,
 	})
 
@@ -337,6 +360,44 @@ func configureEEHostBD(s *iosxecfg.Session, eeCfg *eeConfig, hostBd *controller.
 	return nil
 }
 
+// configureEEScopedBD creates the member interfaces of a tenant's or sfc's bridge domain, a service
+// instance on each host_bd interface carries the bridge domain's traffic tagged with its id, so the
+// scoped bridges share the ee's host interfaces but stay isolated from each other and from the host_bd
+func configureEEScopedBD(s *iosxecfg.Session, hostBd *controller.ExternalEntity_HostBD,
+	bdID uint32) (*iosxe.BridgeDomain, error) {
+
+	if hostBd == nil || len(hostBd.Interfaces) == 0 {
+		return nil, fmt.Errorf("configureEEScopedBD: bd: %d, the ee has no host_bd interfaces", bdID)
+	}
+
+	bd := &iosxe.BridgeDomain{
+		Id: bdID,
+	}
+	// offset so the service instance never reuses the one of the host_bd
+	serviceInstance := BridgeDomainServiceInstance + bdID
+	for _, ifName := range hostBd.Interfaces {
+		// the interface already exists, only its service instance for this bridge domain is added
+		err := s.AddInterface(&iosxe.Interface{
+			Name:        ifName,
+			Type:        iosxe.InterfaceType_ETHERNET_CSMACD,
+			IpRedirects: true,
+			ServiceInstance: &iosxe.Interface_ServiceInstance{
+				Id:            serviceInstance,
+				Encapsulation: fmt.Sprintf("dot1q %d", bdID),
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		bd.Interfaces = append(bd.Interfaces, &iosxe.BridgeDomain_Interface{
+			Name:            ifName,
+			ServiceInstance: serviceInstance,
+		})
+	}
+
+	return bd, nil
+}
+
 // configureEEHostBD configures external entity's host VXLAN.
 func configureEEHostVxlan(s *iosxecfg.Session, eeCfg *eeConfig, hostVxlan *controller.ExternalEntity_HostVxlan) error {
 
@@ -350,6 +411,7 @@ func configureEEHostVxlan(s *iosxecfg.Session, eeCfg *eeConfig, hostVxlan *contr
 		Type:        iosxe.InterfaceType_SOFTWARE_LOOPBACK,
 		Decription:  "source interface for the VXLAN tunnel",
 		IpAddress:   hostVxlan.SourceIpv4,
+		Ipv6Address: hostVxlan.SourceIpv6,
 		IpRedirects: true,
 	})
 	if err != nil {
@@ -50,6 +50,11 @@ func (s *Session) AddInterface(iface *iosxe.Interface) error {
 		cmds = append(cmds, fmt.Sprintf("ip address %s %s", ip, netmask))
 	}
 
+	// IPv6 address
+	if iface.Ipv6Address != "" {
+		cmds = append(cmds, fmt.Sprintf("ipv6 address %s", iface.Ipv6Address))
+	}
+
 	// IP redirects (on by default)
 	if !iface.IpRedirects {
 		cmds = append(cmds, "no ip redirects")
@@ -187,6 +192,12 @@ func (s *Session) DumpInterfaces() (map[string]*iosxe.Interface, error) {
 				ifs[ifName].IpAddress = ip + "/" + strconv.Itoa(prefixSize)
 			}
 
+			// ipv6 address
+			var ipv6 string
+			if _, err := fmt.Sscanf(line, "ipv6 address %s", &ipv6); err == nil {
+				ifs[ifName].Ipv6Address = ipv6
+			}
+
 			// ip redirects
 			if strings.HasPrefix(line, "no ip redirects") {
 				ifs[ifName].IpRedirects = false
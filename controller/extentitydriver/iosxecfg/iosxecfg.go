@@ -147,3 +147,9 @@ func ipv4CidrToIPMask(cidr string) (ip, netmask string, err error) {
 	netmask = net.IP(network.Mask).To4().String()
 	return
 }
+
+// isIPv6Prefix returns true if the cidr prefix (e.g. 2001:db8::1/128) is an ipv6 prefix.
+func isIPv6Prefix(cidr string) bool {
+	address, _, err := net.ParseCIDR(cidr)
+	return err == nil && address.To4() == nil
+}
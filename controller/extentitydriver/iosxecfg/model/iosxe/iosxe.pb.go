@@ -52,6 +52,7 @@ type Interface struct {
 	Decription      string                     `protobuf:"bytes,3,opt,name=decription,proto3" json:"decription,omitempty"`
 	IpAddress       string                     `protobuf:"bytes,4,opt,name=ip_address,proto3" json:"ip_address,omitempty"`
 	IpRedirects     bool                       `protobuf:"varint,5,opt,name=ip_redirects,proto3" json:"ip_redirects,omitempty"`
+	Ipv6Address     string                     `protobuf:"bytes,6,opt,name=ipv6_address,proto3" json:"ipv6_address,omitempty"`
 	ServiceInstance *Interface_ServiceInstance `protobuf:"bytes,10,opt,name=service_instance" json:"service_instance,omitempty"`
 	Vxlan           []*Interface_Vxlan         `protobuf:"bytes,11,rep,name=vxlan" json:"vxlan,omitempty"`
 }
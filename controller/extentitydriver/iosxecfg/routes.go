@@ -27,6 +27,12 @@ import (
 // AddStaticRoute adds a new static route into the router's configuration.
 func (s *Session) AddStaticRoute(route *iosxe.StaticRoute) error {
 
+	if err := validateStaticRoute(route); err != nil {
+		return err
+	}
+	if isIPv6Prefix(route.DstAddress) {
+		return s.addDelIPv6Route(route.DstAddress, route.NextHopAddress, true)
+	}
 	ip, netmask, err := ipv4CidrToIPMask(route.DstAddress)
 	if err != nil {
 		return err
@@ -37,6 +43,12 @@ func (s *Session) AddStaticRoute(route *iosxe.StaticRoute) error {
 // DeleteStaticRoute deletes the static route from the router's configuration.
 func (s *Session) DeleteStaticRoute(route *iosxe.StaticRoute) error {
 
+	if err := validateStaticRoute(route); err != nil {
+		return err
+	}
+	if isIPv6Prefix(route.DstAddress) {
+		return s.addDelIPv6Route(route.DstAddress, route.NextHopAddress, false)
+	}
 	ip, netmask, err := ipv4CidrToIPMask(route.DstAddress)
 	if err != nil {
 		return err
@@ -129,3 +141,54 @@ func (s *Session) addDelIPRoute(dstPrefix, dstMask, nextHopIP string, isAdd bool
 
 	return nil
 }
+
+// addDelIPv6Route adds or deletes ipv6 static route configuration on the router.
+func (s *Session) addDelIPv6Route(dstPrefix, nextHopIP string, isAdd bool) error {
+	logFields := log.WithFields(logging.Fields{
+		"dstPrefix": dstPrefix,
+		"nextHopIP": nextHopIP,
+	})
+	if isAdd {
+		logFields.Info("Adding a new ipv6 static route")
+	} else {
+		logFields.Info("Deleting ipv6 static route")
+	}
+
+	mainCmd := ""
+	if isAdd {
+		mainCmd = fmt.Sprintf("ipv6 route %s %s", dstPrefix, nextHopIP)
+	} else {
+		mainCmd = fmt.Sprintf("no ipv6 route %s %s", dstPrefix, nextHopIP)
+	}
+
+	// execute the commands on VTY
+	s.enterConfigMode()
+	resp, err := s.vty.ExecCMD(mainCmd)
+
+	if err != nil {
+		log.Error("Error by configuring ipv6 route: ", err)
+		return err
+	}
+	if err = checkResponse(resp); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// validateStaticRoute checks the destination is a cidr prefix and the next hop is an address of the same
+// family, an ipv4 peer of an ipv6 only peer would otherwise get a route with no usable next hop.
+func validateStaticRoute(route *iosxe.StaticRoute) error {
+	if _, _, err := net.ParseCIDR(route.DstAddress); err != nil {
+		return fmt.Errorf("invalid static route destination '%s'", route.DstAddress)
+	}
+	nextHop := net.ParseIP(route.NextHopAddress)
+	if nextHop == nil {
+		return fmt.Errorf("invalid static route next hop '%s' for %s", route.NextHopAddress, route.DstAddress)
+	}
+	if isIPv6Prefix(route.DstAddress) != (nextHop.To4() == nil) {
+		return fmt.Errorf("static route next hop '%s' is not in the address family of %s",
+			route.NextHopAddress, route.DstAddress)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+// Copyright (c) 2017 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package iosxecfg
+
+import (
+	"testing"
+
+	"github.com/ligato/sfc-controller/controller/extentitydriver/iosxecfg/model/iosxe"
+)
+
+func TestValidateStaticRoute(t *testing.T) {
+	tests := []struct {
+		dst     string
+		nextHop string
+		wantErr bool
+	}{
+		{"10.0.0.1/32", "192.168.1.1", false},
+		{"2001:db8::1/128", "2001:db8:1::1", false},
+		{"2001:db8::1/128", "192.168.1.1", true},
+		{"10.0.0.1/32", "2001:db8:1::1", true},
+		{"10.0.0.1/32", "", true},
+		{"2001:db8::1/128", "", true},
+		{"10.0.0.1", "192.168.1.1", true},
+		{"", "192.168.1.1", true},
+	}
+	for _, test := range tests {
+		err := validateStaticRoute(&iosxe.StaticRoute{DstAddress: test.dst, NextHopAddress: test.nextHop})
+		if (err != nil) != test.wantErr {
+			t.Errorf("validateStaticRoute(%s via %s) error = %v, wantErr %v", test.dst, test.nextHop, err,
+				test.wantErr)
+		}
+	}
+}
+
+func TestIsIPv6Prefix(t *testing.T) {
+	tests := []struct {
+		cidr string
+		want bool
+	}{
+		{"2001:db8::1/128", true},
+		{"::/0", true},
+		{"10.0.0.1/32", false},
+		{"::ffff:10.0.0.1/128", false},
+		{"2001:db8::1", false},
+		{"", false},
+	}
+	for _, test := range tests {
+		if got := isIPv6Prefix(test.cidr); got != test.want {
+			t.Errorf("isIPv6Prefix('%s') = %v, want %v", test.cidr, got, test.want)
+		}
+	}
+}
+
+func TestIPv4CidrToIPMask(t *testing.T) {
+	tests := []struct {
+		cidr        string
+		wantIP      string
+		wantNetmask string
+		wantErr     bool
+	}{
+		{"10.1.2.3/24", "10.1.2.3", "255.255.255.0", false},
+		{"10.1.2.3/32", "10.1.2.3", "255.255.255.255", false},
+		{"0.0.0.0/0", "0.0.0.0", "0.0.0.0", false},
+		{"10.1.2.3", "", "", true},
+	}
+	for _, test := range tests {
+		ip, netmask, err := ipv4CidrToIPMask(test.cidr)
+		if (err != nil) != test.wantErr {
+			t.Errorf("ipv4CidrToIPMask('%s') error = %v, wantErr %v", test.cidr, err, test.wantErr)
+			continue
+		}
+		if !test.wantErr && (ip != test.wantIP || netmask != test.wantNetmask) {
+			t.Errorf("ipv4CidrToIPMask('%s') = %s %s, want %s %s", test.cidr, ip, netmask, test.wantIP,
+				test.wantNetmask)
+		}
+	}
+}
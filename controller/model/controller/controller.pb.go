@@ -10,13 +10,22 @@ It is generated from these files:
 
 It has these top-level messages:
 	BDParms
+	MemifParms
+	IpamPool
 	SystemParameters
 	ExternalEntity
 	HostEntity
+	Tenant
 	CustomInfoType
+	ProxyArpRange
 	L3VRFRoute
 	L3ArpEntry
+	ContainerRoute
+	AclRule
+	Acl
+	ClassifierRule
 	SfcEntity
+	ControllerInstance
 */
 package controller
 
@@ -48,6 +57,92 @@ func (x RxModeType) String() string {
 	return proto.EnumName(RxModeType_name, int32(x))
 }
 
+type MemifMode int32
+
+const (
+	MemifMode_MEMIF_MODE_ETHERNET    MemifMode = 0
+	MemifMode_MEMIF_MODE_IP          MemifMode = 1
+	MemifMode_MEMIF_MODE_PUNT_INJECT MemifMode = 2
+)
+
+var MemifMode_name = map[int32]string{
+	0: "MEMIF_MODE_ETHERNET",
+	1: "MEMIF_MODE_IP",
+	2: "MEMIF_MODE_PUNT_INJECT",
+}
+var MemifMode_value = map[string]int32{
+	"MEMIF_MODE_ETHERNET":    0,
+	"MEMIF_MODE_IP":          1,
+	"MEMIF_MODE_PUNT_INJECT": 2,
+}
+
+func (x MemifMode) String() string {
+	return proto.EnumName(MemifMode_name, int32(x))
+}
+
+type IpamStrategy int32
+
+const (
+	IpamStrategy_IPAM_STRATEGY_FIRST_FREE IpamStrategy = 0
+	IpamStrategy_IPAM_STRATEGY_SEQUENTIAL IpamStrategy = 1
+)
+
+var IpamStrategy_name = map[int32]string{
+	0: "IPAM_STRATEGY_FIRST_FREE",
+	1: "IPAM_STRATEGY_SEQUENTIAL",
+}
+var IpamStrategy_value = map[string]int32{
+	"IPAM_STRATEGY_FIRST_FREE": 0,
+	"IPAM_STRATEGY_SEQUENTIAL": 1,
+}
+
+func (x IpamStrategy) String() string {
+	return proto.EnumName(IpamStrategy_name, int32(x))
+}
+
+type MacAddressScheme int32
+
+const (
+	MacAddressScheme_MAC_ADDRESS_SCHEME_SEQUENTIAL MacAddressScheme = 0
+	MacAddressScheme_MAC_ADDRESS_SCHEME_HASH       MacAddressScheme = 1
+)
+
+var MacAddressScheme_name = map[int32]string{
+	0: "MAC_ADDRESS_SCHEME_SEQUENTIAL",
+	1: "MAC_ADDRESS_SCHEME_HASH",
+}
+var MacAddressScheme_value = map[string]int32{
+	"MAC_ADDRESS_SCHEME_SEQUENTIAL": 0,
+	"MAC_ADDRESS_SCHEME_HASH":       1,
+}
+
+func (x MacAddressScheme) String() string {
+	return proto.EnumName(MacAddressScheme_name, int32(x))
+}
+
+type MemifSocketScheme int32
+
+const (
+	MemifSocketScheme_MEMIF_SOCKET_PER_MASTER MemifSocketScheme = 0
+	MemifSocketScheme_MEMIF_SOCKET_PER_SFC    MemifSocketScheme = 1
+	MemifSocketScheme_MEMIF_SOCKET_PER_PAIR   MemifSocketScheme = 2
+)
+
+var MemifSocketScheme_name = map[int32]string{
+	0: "MEMIF_SOCKET_PER_MASTER",
+	1: "MEMIF_SOCKET_PER_SFC",
+	2: "MEMIF_SOCKET_PER_PAIR",
+}
+var MemifSocketScheme_value = map[string]int32{
+	"MEMIF_SOCKET_PER_MASTER": 0,
+	"MEMIF_SOCKET_PER_SFC":    1,
+	"MEMIF_SOCKET_PER_PAIR":   2,
+}
+
+func (x MemifSocketScheme) String() string {
+	return proto.EnumName(MemifSocketScheme_name, int32(x))
+}
+
 type ExtEntDriverType int32
 
 const (
@@ -125,6 +220,7 @@ const (
 	SfcElementType_NON_VPP_CONTAINER_MEMIF SfcElementType = 4
 	SfcElementType_HOST_ENTITY             SfcElementType = 5
 	SfcElementType_VPP_CONTAINER_AFP       SfcElementType = 6
+	SfcElementType_VPP_CONTAINER_TAP       SfcElementType = 7
 )
 
 var SfcElementType_name = map[int32]string{
@@ -135,6 +231,7 @@ var SfcElementType_name = map[int32]string{
 	4: "NON_VPP_CONTAINER_MEMIF",
 	5: "HOST_ENTITY",
 	6: "VPP_CONTAINER_AFP",
+	7: "VPP_CONTAINER_TAP",
 }
 var SfcElementType_value = map[string]int32{
 	"ELEMENT_UNKNOWN":         0,
@@ -144,12 +241,105 @@ var SfcElementType_value = map[string]int32{
 	"NON_VPP_CONTAINER_MEMIF": 4,
 	"HOST_ENTITY":             5,
 	"VPP_CONTAINER_AFP":       6,
+	"VPP_CONTAINER_TAP":       7,
 }
 
 func (x SfcElementType) String() string {
 	return proto.EnumName(SfcElementType_name, int32(x))
 }
 
+type AclAction int32
+
+const (
+	AclAction_ACL_ACTION_DENY    AclAction = 0
+	AclAction_ACL_ACTION_PERMIT  AclAction = 1
+	AclAction_ACL_ACTION_REFLECT AclAction = 2
+)
+
+var AclAction_name = map[int32]string{
+	0: "ACL_ACTION_DENY",
+	1: "ACL_ACTION_PERMIT",
+	2: "ACL_ACTION_REFLECT",
+}
+var AclAction_value = map[string]int32{
+	"ACL_ACTION_DENY":    0,
+	"ACL_ACTION_PERMIT":  1,
+	"ACL_ACTION_REFLECT": 2,
+}
+
+func (x AclAction) String() string {
+	return proto.EnumName(AclAction_name, int32(x))
+}
+
+type AclProtocol int32
+
+const (
+	AclProtocol_ACL_PROTOCOL_ANY    AclProtocol = 0
+	AclProtocol_ACL_PROTOCOL_TCP    AclProtocol = 1
+	AclProtocol_ACL_PROTOCOL_UDP    AclProtocol = 2
+	AclProtocol_ACL_PROTOCOL_ICMP   AclProtocol = 3
+	AclProtocol_ACL_PROTOCOL_ICMPV6 AclProtocol = 4
+)
+
+var AclProtocol_name = map[int32]string{
+	0: "ACL_PROTOCOL_ANY",
+	1: "ACL_PROTOCOL_TCP",
+	2: "ACL_PROTOCOL_UDP",
+	3: "ACL_PROTOCOL_ICMP",
+	4: "ACL_PROTOCOL_ICMPV6",
+}
+var AclProtocol_value = map[string]int32{
+	"ACL_PROTOCOL_ANY":    0,
+	"ACL_PROTOCOL_TCP":    1,
+	"ACL_PROTOCOL_UDP":    2,
+	"ACL_PROTOCOL_ICMP":   3,
+	"ACL_PROTOCOL_ICMPV6": 4,
+}
+
+func (x AclProtocol) String() string {
+	return proto.EnumName(AclProtocol_name, int32(x))
+}
+
+type AclDirection int32
+
+const (
+	AclDirection_ACL_DIRECTION_INGRESS AclDirection = 0
+	AclDirection_ACL_DIRECTION_EGRESS  AclDirection = 1
+)
+
+var AclDirection_name = map[int32]string{
+	0: "ACL_DIRECTION_INGRESS",
+	1: "ACL_DIRECTION_EGRESS",
+}
+var AclDirection_value = map[string]int32{
+	"ACL_DIRECTION_INGRESS": 0,
+	"ACL_DIRECTION_EGRESS":  1,
+}
+
+func (x AclDirection) String() string {
+	return proto.EnumName(AclDirection_name, int32(x))
+}
+
+type PeerRedundancyType int32
+
+const (
+	PeerRedundancyType_PEER_REDUNDANCY_ACTIVE_STANDBY PeerRedundancyType = 0
+	PeerRedundancyType_PEER_REDUNDANCY_ACTIVE_ACTIVE  PeerRedundancyType = 1
+)
+
+var PeerRedundancyType_name = map[int32]string{
+	0: "PEER_REDUNDANCY_ACTIVE_STANDBY",
+	1: "PEER_REDUNDANCY_ACTIVE_ACTIVE",
+}
+var PeerRedundancyType_value = map[string]int32{
+	"PEER_REDUNDANCY_ACTIVE_STANDBY": 0,
+	"PEER_REDUNDANCY_ACTIVE_ACTIVE":  1,
+}
+
+func (x PeerRedundancyType) String() string {
+	return proto.EnumName(PeerRedundancyType_name, int32(x))
+}
+
 type BDParms struct {
 	Flood               bool   `protobuf:"varint,1,opt,name=flood,proto3" json:"flood,omitempty"`
 	UnknownUnicastFlood bool   `protobuf:"varint,2,opt,name=unknown_unicast_flood,proto3" json:"unknown_unicast_flood,omitempty"`
@@ -163,13 +353,46 @@ func (m *BDParms) Reset()         { *m = BDParms{} }
 func (m *BDParms) String() string { return proto.CompactTextString(m) }
 func (*BDParms) ProtoMessage()    {}
 
+type MemifParms struct {
+	Mode       MemifMode `protobuf:"varint,1,opt,name=mode,proto3,enum=controller.MemifMode" json:"mode,omitempty"`
+	Secret     string    `protobuf:"bytes,2,opt,name=secret,proto3" json:"secret,omitempty"`
+	RingSize   uint32    `protobuf:"varint,3,opt,name=ring_size,proto3" json:"ring_size,omitempty"`
+	BufferSize uint32    `protobuf:"varint,4,opt,name=buffer_size,proto3" json:"buffer_size,omitempty"`
+	RxQueues   uint32    `protobuf:"varint,5,opt,name=rx_queues,proto3" json:"rx_queues,omitempty"`
+	TxQueues   uint32    `protobuf:"varint,6,opt,name=tx_queues,proto3" json:"tx_queues,omitempty"`
+}
+
+func (m *MemifParms) Reset()         { *m = MemifParms{} }
+func (m *MemifParms) String() string { return proto.CompactTextString(m) }
+func (*MemifParms) ProtoMessage()    {}
+
+type IpamPool struct {
+	Name           string       `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Prefix         string       `protobuf:"bytes,2,opt,name=prefix,proto3" json:"prefix,omitempty"`
+	ReservedRanges []string     `protobuf:"bytes,3,rep,name=reserved_ranges" json:"reserved_ranges,omitempty"`
+	Gateway        string       `protobuf:"bytes,4,opt,name=gateway,proto3" json:"gateway,omitempty"`
+	Strategy       IpamStrategy `protobuf:"varint,5,opt,name=strategy,proto3,enum=controller.IpamStrategy" json:"strategy,omitempty"`
+}
+
+func (m *IpamPool) Reset()         { *m = IpamPool{} }
+func (m *IpamPool) String() string { return proto.CompactTextString(m) }
+func (*IpamPool) ProtoMessage()    {}
+
 type SystemParameters struct {
-	Mtu                          uint32   `protobuf:"varint,1,opt,name=mtu,proto3" json:"mtu,omitempty"`
-	StartingVlanId               uint32   `protobuf:"varint,2,opt,name=starting_vlan_id,proto3" json:"starting_vlan_id,omitempty"`
-	DefaultStaticRouteWeight     uint32   `protobuf:"varint,3,opt,name=default_static_route_weight,proto3" json:"default_static_route_weight,omitempty"`
-	DefaultStaticRoutePreference uint32   `protobuf:"varint,4,opt,name=default_static_route_preference,proto3" json:"default_static_route_preference,omitempty"`
-	DynamicBridgeParms           *BDParms `protobuf:"bytes,5,opt,name=dynamic_bridge_parms" json:"dynamic_bridge_parms,omitempty"`
-	StaticBridgeParms            *BDParms `protobuf:"bytes,6,opt,name=static_bridge_parms" json:"static_bridge_parms,omitempty"`
+	Mtu                          uint32            `protobuf:"varint,1,opt,name=mtu,proto3" json:"mtu,omitempty"`
+	StartingVlanId               uint32            `protobuf:"varint,2,opt,name=starting_vlan_id,proto3" json:"starting_vlan_id,omitempty"`
+	DefaultStaticRouteWeight     uint32            `protobuf:"varint,3,opt,name=default_static_route_weight,proto3" json:"default_static_route_weight,omitempty"`
+	DefaultStaticRoutePreference uint32            `protobuf:"varint,4,opt,name=default_static_route_preference,proto3" json:"default_static_route_preference,omitempty"`
+	DynamicBridgeParms           *BDParms          `protobuf:"bytes,5,opt,name=dynamic_bridge_parms" json:"dynamic_bridge_parms,omitempty"`
+	StaticBridgeParms            *BDParms          `protobuf:"bytes,6,opt,name=static_bridge_parms" json:"static_bridge_parms,omitempty"`
+	IpamPools                    []*IpamPool       `protobuf:"bytes,8,rep,name=ipam_pools" json:"ipam_pools,omitempty"`
+	MacPrefix                    string            `protobuf:"bytes,9,opt,name=mac_prefix,proto3" json:"mac_prefix,omitempty"`
+	MacAddressScheme             MacAddressScheme  `protobuf:"varint,10,opt,name=mac_address_scheme,proto3,enum=controller.MacAddressScheme" json:"mac_address_scheme,omitempty"`
+	MemifParms                   *MemifParms       `protobuf:"bytes,11,opt,name=memif_parms" json:"memif_parms,omitempty"`
+	MemifSocketDir               string            `protobuf:"bytes,12,opt,name=memif_socket_dir,proto3" json:"memif_socket_dir,omitempty"`
+	MemifSocketScheme            MemifSocketScheme `protobuf:"varint,13,opt,name=memif_socket_scheme,proto3,enum=controller.MemifSocketScheme" json:"memif_socket_scheme,omitempty"`
+	AutoVrfRangeStart            uint32            `protobuf:"varint,14,opt,name=auto_vrf_range_start,proto3" json:"auto_vrf_range_start,omitempty"`
+	AutoVrfRangeEnd              uint32            `protobuf:"varint,15,opt,name=auto_vrf_range_end,proto3" json:"auto_vrf_range_end,omitempty"`
 }
 
 func (m *SystemParameters) Reset()         { *m = SystemParameters{} }
@@ -190,6 +413,20 @@ func (m *SystemParameters) GetStaticBridgeParms() *BDParms {
 	return nil
 }
 
+func (m *SystemParameters) GetIpamPools() []*IpamPool {
+	if m != nil {
+		return m.IpamPools
+	}
+	return nil
+}
+
+func (m *SystemParameters) GetMemifParms() *MemifParms {
+	if m != nil {
+		return m.MemifParms
+	}
+	return nil
+}
+
 type ExternalEntity struct {
 	Name            string                        `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
 	MgmntIpAddress  string                        `protobuf:"bytes,2,opt,name=mgmnt_ip_address,proto3" json:"mgmnt_ip_address,omitempty"`
@@ -200,6 +437,8 @@ type ExternalEntity struct {
 	HostInterface   *ExternalEntity_HostInterface `protobuf:"bytes,7,opt,name=host_interface" json:"host_interface,omitempty"`
 	HostVxlan       *ExternalEntity_HostVxlan     `protobuf:"bytes,8,opt,name=host_vxlan" json:"host_vxlan,omitempty"`
 	HostBd          *ExternalEntity_HostBD        `protobuf:"bytes,9,opt,name=host_bd" json:"host_bd,omitempty"`
+	Labels          map[string]string             `protobuf:"bytes,10,rep,name=labels" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	Annotations     map[string]string             `protobuf:"bytes,11,rep,name=annotations" json:"annotations,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
 }
 
 func (m *ExternalEntity) Reset()         { *m = ExternalEntity{} }
@@ -227,9 +466,24 @@ func (m *ExternalEntity) GetHostBd() *ExternalEntity_HostBD {
 	return nil
 }
 
+func (m *ExternalEntity) GetLabels() map[string]string {
+	if m != nil {
+		return m.Labels
+	}
+	return nil
+}
+
+func (m *ExternalEntity) GetAnnotations() map[string]string {
+	if m != nil {
+		return m.Annotations
+	}
+	return nil
+}
+
 type ExternalEntity_HostInterface struct {
 	IfName   string `protobuf:"bytes,1,opt,name=if_name,proto3" json:"if_name,omitempty"`
 	Ipv4Addr string `protobuf:"bytes,2,opt,name=ipv4_addr,proto3" json:"ipv4_addr,omitempty"`
+	Ipv6Addr string `protobuf:"bytes,3,opt,name=ipv6_addr,proto3" json:"ipv6_addr,omitempty"`
 }
 
 func (m *ExternalEntity_HostInterface) Reset()         { *m = ExternalEntity_HostInterface{} }
@@ -239,6 +493,7 @@ func (*ExternalEntity_HostInterface) ProtoMessage()    {}
 type ExternalEntity_HostVxlan struct {
 	IfName     string `protobuf:"bytes,1,opt,name=if_name,proto3" json:"if_name,omitempty"`
 	SourceIpv4 string `protobuf:"bytes,2,opt,name=source_ipv4,proto3" json:"source_ipv4,omitempty"`
+	SourceIpv6 string `protobuf:"bytes,3,opt,name=source_ipv6,proto3" json:"source_ipv6,omitempty"`
 }
 
 func (m *ExternalEntity_HostVxlan) Reset()         { *m = ExternalEntity_HostVxlan{} }
@@ -256,23 +511,74 @@ func (m *ExternalEntity_HostBD) String() string { return proto.CompactTextString
 func (*ExternalEntity_HostBD) ProtoMessage()    {}
 
 type HostEntity struct {
-	Name                   string     `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
-	EthIfName              string     `protobuf:"bytes,2,opt,name=eth_if_name,proto3" json:"eth_if_name,omitempty"`
-	EthIpv4                string     `protobuf:"bytes,3,opt,name=eth_ipv4,proto3" json:"eth_ipv4,omitempty"`
-	EthIpv6                string     `protobuf:"bytes,4,opt,name=eth_ipv6,proto3" json:"eth_ipv6,omitempty"`
-	LoopbackMacAddr        string     `protobuf:"bytes,5,opt,name=loopback_mac_addr,proto3" json:"loopback_mac_addr,omitempty"`
-	LoopbackIpv4           string     `protobuf:"bytes,6,opt,name=loopback_ipv4,proto3" json:"loopback_ipv4,omitempty"`
-	LoopbackIpv6           string     `protobuf:"bytes,7,opt,name=loopback_ipv6,proto3" json:"loopback_ipv6,omitempty"`
-	VxlanTunnelIpv4        string     `protobuf:"bytes,8,opt,name=vxlan_tunnel_ipv4,proto3" json:"vxlan_tunnel_ipv4,omitempty"`
-	CreateVxlanStaticRoute bool       `protobuf:"varint,9,opt,name=create_vxlan_static_route,proto3" json:"create_vxlan_static_route,omitempty"`
-	Mtu                    uint32     `protobuf:"varint,10,opt,name=mtu,proto3" json:"mtu,omitempty"`
-	RxMode                 RxModeType `protobuf:"varint,11,opt,name=rx_mode,proto3,enum=controller.RxModeType" json:"rx_mode,omitempty"`
+	Name                   string            `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	EthIfName              string            `protobuf:"bytes,2,opt,name=eth_if_name,proto3" json:"eth_if_name,omitempty"`
+	EthIpv4                string            `protobuf:"bytes,3,opt,name=eth_ipv4,proto3" json:"eth_ipv4,omitempty"`
+	EthIpv6                string            `protobuf:"bytes,4,opt,name=eth_ipv6,proto3" json:"eth_ipv6,omitempty"`
+	LoopbackMacAddr        string            `protobuf:"bytes,5,opt,name=loopback_mac_addr,proto3" json:"loopback_mac_addr,omitempty"`
+	LoopbackIpv4           string            `protobuf:"bytes,6,opt,name=loopback_ipv4,proto3" json:"loopback_ipv4,omitempty"`
+	LoopbackIpv6           string            `protobuf:"bytes,7,opt,name=loopback_ipv6,proto3" json:"loopback_ipv6,omitempty"`
+	VxlanTunnelIpv4        string            `protobuf:"bytes,8,opt,name=vxlan_tunnel_ipv4,proto3" json:"vxlan_tunnel_ipv4,omitempty"`
+	CreateVxlanStaticRoute bool              `protobuf:"varint,9,opt,name=create_vxlan_static_route,proto3" json:"create_vxlan_static_route,omitempty"`
+	Mtu                    uint32            `protobuf:"varint,10,opt,name=mtu,proto3" json:"mtu,omitempty"`
+	RxMode                 RxModeType        `protobuf:"varint,11,opt,name=rx_mode,proto3,enum=controller.RxModeType" json:"rx_mode,omitempty"`
+	Labels                 map[string]string `protobuf:"bytes,12,rep,name=labels" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	Annotations            map[string]string `protobuf:"bytes,13,rep,name=annotations" json:"annotations,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	VxlanTunnelIpv6        string            `protobuf:"bytes,14,opt,name=vxlan_tunnel_ipv6,proto3" json:"vxlan_tunnel_ipv6,omitempty"`
+	MemifSocketDir         string            `protobuf:"bytes,15,opt,name=memif_socket_dir,proto3" json:"memif_socket_dir,omitempty"`
+	EthVrfId               uint32            `protobuf:"varint,16,opt,name=eth_vrf_id,proto3" json:"eth_vrf_id,omitempty"`
+	EthUnnumbered          bool              `protobuf:"varint,17,opt,name=eth_unnumbered,proto3" json:"eth_unnumbered,omitempty"`
 }
 
 func (m *HostEntity) Reset()         { *m = HostEntity{} }
 func (m *HostEntity) String() string { return proto.CompactTextString(m) }
 func (*HostEntity) ProtoMessage()    {}
 
+func (m *HostEntity) GetLabels() map[string]string {
+	if m != nil {
+		return m.Labels
+	}
+	return nil
+}
+
+func (m *HostEntity) GetAnnotations() map[string]string {
+	if m != nil {
+		return m.Annotations
+	}
+	return nil
+}
+
+type Tenant struct {
+	Name          string            `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	VniRangeStart uint32            `protobuf:"varint,2,opt,name=vni_range_start,proto3" json:"vni_range_start,omitempty"`
+	VniRangeEnd   uint32            `protobuf:"varint,3,opt,name=vni_range_end,proto3" json:"vni_range_end,omitempty"`
+	Ipv4Pools     []string          `protobuf:"bytes,4,rep,name=ipv4_pools" json:"ipv4_pools,omitempty"`
+	MacRangeStart uint32            `protobuf:"varint,5,opt,name=mac_range_start,proto3" json:"mac_range_start,omitempty"`
+	MacRangeEnd   uint32            `protobuf:"varint,6,opt,name=mac_range_end,proto3" json:"mac_range_end,omitempty"`
+	EeBdId        uint32            `protobuf:"varint,7,opt,name=ee_bd_id,proto3" json:"ee_bd_id,omitempty"`
+	ApiToken      string            `protobuf:"bytes,8,opt,name=api_token,proto3" json:"api_token,omitempty"`
+	Labels        map[string]string `protobuf:"bytes,9,rep,name=labels" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	Annotations   map[string]string `protobuf:"bytes,10,rep,name=annotations" json:"annotations,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+}
+
+func (m *Tenant) Reset()         { *m = Tenant{} }
+func (m *Tenant) String() string { return proto.CompactTextString(m) }
+func (*Tenant) ProtoMessage()    {}
+
+func (m *Tenant) GetLabels() map[string]string {
+	if m != nil {
+		return m.Labels
+	}
+	return nil
+}
+
+func (m *Tenant) GetAnnotations() map[string]string {
+	if m != nil {
+		return m.Annotations
+	}
+	return nil
+}
+
 type CustomInfoType struct {
 	Label string `protobuf:"bytes,1,opt,name=label,proto3" json:"label,omitempty"`
 }
@@ -281,6 +587,15 @@ func (m *CustomInfoType) Reset()         { *m = CustomInfoType{} }
 func (m *CustomInfoType) String() string { return proto.CompactTextString(m) }
 func (*CustomInfoType) ProtoMessage()    {}
 
+type ProxyArpRange struct {
+	RangeIpStart string `protobuf:"bytes,1,opt,name=range_ip_start,proto3" json:"range_ip_start,omitempty"`
+	RangeIpEnd   string `protobuf:"bytes,2,opt,name=range_ip_end,proto3" json:"range_ip_end,omitempty"`
+}
+
+func (m *ProxyArpRange) Reset()         { *m = ProxyArpRange{} }
+func (m *ProxyArpRange) String() string { return proto.CompactTextString(m) }
+func (*ProxyArpRange) ProtoMessage()    {}
+
 type L3VRFRoute struct {
 	VrfId             uint32 `protobuf:"varint,1,opt,name=vrf_id,proto3" json:"vrf_id,omitempty"`
 	Description       string `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
@@ -304,14 +619,84 @@ func (m *L3ArpEntry) Reset()         { *m = L3ArpEntry{} }
 func (m *L3ArpEntry) String() string { return proto.CompactTextString(m) }
 func (*L3ArpEntry) ProtoMessage()    {}
 
+type ContainerRoute struct {
+	DstIpAddr string `protobuf:"bytes,1,opt,name=dst_ip_addr,proto3" json:"dst_ip_addr,omitempty"`
+	GwAddr    string `protobuf:"bytes,2,opt,name=gw_addr,proto3" json:"gw_addr,omitempty"`
+	Metric    uint32 `protobuf:"varint,3,opt,name=metric,proto3" json:"metric,omitempty"`
+}
+
+func (m *ContainerRoute) Reset()         { *m = ContainerRoute{} }
+func (m *ContainerRoute) String() string { return proto.CompactTextString(m) }
+func (*ContainerRoute) ProtoMessage()    {}
+
+type AclRule struct {
+	Action       AclAction   `protobuf:"varint,1,opt,name=action,proto3,enum=controller.AclAction" json:"action,omitempty"`
+	Protocol     AclProtocol `protobuf:"varint,2,opt,name=protocol,proto3,enum=controller.AclProtocol" json:"protocol,omitempty"`
+	SrcNetwork   string      `protobuf:"bytes,3,opt,name=src_network,proto3" json:"src_network,omitempty"`
+	DstNetwork   string      `protobuf:"bytes,4,opt,name=dst_network,proto3" json:"dst_network,omitempty"`
+	SrcPortLower uint32      `protobuf:"varint,5,opt,name=src_port_lower,proto3" json:"src_port_lower,omitempty"`
+	SrcPortUpper uint32      `protobuf:"varint,6,opt,name=src_port_upper,proto3" json:"src_port_upper,omitempty"`
+	DstPortLower uint32      `protobuf:"varint,7,opt,name=dst_port_lower,proto3" json:"dst_port_lower,omitempty"`
+	DstPortUpper uint32      `protobuf:"varint,8,opt,name=dst_port_upper,proto3" json:"dst_port_upper,omitempty"`
+}
+
+func (m *AclRule) Reset()         { *m = AclRule{} }
+func (m *AclRule) String() string { return proto.CompactTextString(m) }
+func (*AclRule) ProtoMessage()    {}
+
+type Acl struct {
+	Name      string       `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Direction AclDirection `protobuf:"varint,2,opt,name=direction,proto3,enum=controller.AclDirection" json:"direction,omitempty"`
+	Rules     []*AclRule   `protobuf:"bytes,3,rep,name=rules" json:"rules,omitempty"`
+}
+
+func (m *Acl) Reset()         { *m = Acl{} }
+func (m *Acl) String() string { return proto.CompactTextString(m) }
+func (*Acl) ProtoMessage()    {}
+
+func (m *Acl) GetRules() []*AclRule {
+	if m != nil {
+		return m.Rules
+	}
+	return nil
+}
+
+type ClassifierRule struct {
+	Protocol     AclProtocol `protobuf:"varint,1,opt,name=protocol,proto3,enum=controller.AclProtocol" json:"protocol,omitempty"`
+	SrcNetwork   string      `protobuf:"bytes,2,opt,name=src_network,proto3" json:"src_network,omitempty"`
+	DstNetwork   string      `protobuf:"bytes,3,opt,name=dst_network,proto3" json:"dst_network,omitempty"`
+	SrcPortLower uint32      `protobuf:"varint,4,opt,name=src_port_lower,proto3" json:"src_port_lower,omitempty"`
+	SrcPortUpper uint32      `protobuf:"varint,5,opt,name=src_port_upper,proto3" json:"src_port_upper,omitempty"`
+	DstPortLower uint32      `protobuf:"varint,6,opt,name=dst_port_lower,proto3" json:"dst_port_lower,omitempty"`
+	DstPortUpper uint32      `protobuf:"varint,7,opt,name=dst_port_upper,proto3" json:"dst_port_upper,omitempty"`
+}
+
+func (m *ClassifierRule) Reset()         { *m = ClassifierRule{} }
+func (m *ClassifierRule) String() string { return proto.CompactTextString(m) }
+func (*ClassifierRule) ProtoMessage()    {}
+
 type SfcEntity struct {
-	Name           string                  `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
-	Description    string                  `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
-	Type           SfcType                 `protobuf:"varint,3,opt,name=type,proto3,enum=controller.SfcType" json:"type,omitempty"`
-	SfcIpv4Prefix  string                  `protobuf:"bytes,4,opt,name=sfc_ipv4_prefix,proto3" json:"sfc_ipv4_prefix,omitempty"`
-	VnfRepeatCount uint32                  `protobuf:"varint,5,opt,name=vnf_repeat_count,proto3" json:"vnf_repeat_count,omitempty"`
-	BdParms        *BDParms                `protobuf:"bytes,6,opt,name=bd_parms" json:"bd_parms,omitempty"`
-	Elements       []*SfcEntity_SfcElement `protobuf:"bytes,7,rep,name=elements" json:"elements,omitempty"`
+	Name              string                  `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Description       string                  `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	Type              SfcType                 `protobuf:"varint,3,opt,name=type,proto3,enum=controller.SfcType" json:"type,omitempty"`
+	SfcIpv4Prefix     string                  `protobuf:"bytes,4,opt,name=sfc_ipv4_prefix,proto3" json:"sfc_ipv4_prefix,omitempty"`
+	VnfRepeatCount    uint32                  `protobuf:"varint,5,opt,name=vnf_repeat_count,proto3" json:"vnf_repeat_count,omitempty"`
+	BdParms           *BDParms                `protobuf:"bytes,6,opt,name=bd_parms" json:"bd_parms,omitempty"`
+	Elements          []*SfcEntity_SfcElement `protobuf:"bytes,7,rep,name=elements" json:"elements,omitempty"`
+	Labels            map[string]string       `protobuf:"bytes,8,rep,name=labels" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	Annotations       map[string]string       `protobuf:"bytes,9,rep,name=annotations" json:"annotations,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	Tenant            string                  `protobuf:"bytes,10,opt,name=tenant,proto3" json:"tenant,omitempty"`
+	DedicatedVni      bool                    `protobuf:"varint,11,opt,name=dedicated_vni,proto3" json:"dedicated_vni,omitempty"`
+	EeBdId            uint32                  `protobuf:"varint,12,opt,name=ee_bd_id,proto3" json:"ee_bd_id,omitempty"`
+	SfcIpv6Prefix     string                  `protobuf:"bytes,13,opt,name=sfc_ipv6_prefix,proto3" json:"sfc_ipv6_prefix,omitempty"`
+	Ipv4IpamPool      string                  `protobuf:"bytes,14,opt,name=ipv4_ipam_pool,proto3" json:"ipv4_ipam_pool,omitempty"`
+	Ipv6IpamPool      string                  `protobuf:"bytes,15,opt,name=ipv6_ipam_pool,proto3" json:"ipv6_ipam_pool,omitempty"`
+	AutoVrf           bool                    `protobuf:"varint,16,opt,name=auto_vrf,proto3" json:"auto_vrf,omitempty"`
+	ProxyArpRanges    []*ProxyArpRange        `protobuf:"bytes,17,rep,name=proxy_arp_ranges" json:"proxy_arp_ranges,omitempty"`
+	Acls              []*Acl                  `protobuf:"bytes,18,rep,name=acls" json:"acls,omitempty"`
+	ClassifierRules   []*ClassifierRule       `protobuf:"bytes,19,rep,name=classifier_rules" json:"classifier_rules,omitempty"`
+	ClassifierDefault bool                    `protobuf:"varint,20,opt,name=classifier_default,proto3" json:"classifier_default,omitempty"`
+	PeerRedundancy    PeerRedundancyType      `protobuf:"varint,21,opt,name=peer_redundancy,proto3,enum=controller.PeerRedundancyType" json:"peer_redundancy,omitempty"`
 }
 
 func (m *SfcEntity) Reset()         { *m = SfcEntity{} }
@@ -332,20 +717,66 @@ func (m *SfcEntity) GetElements() []*SfcEntity_SfcElement {
 	return nil
 }
 
+func (m *SfcEntity) GetLabels() map[string]string {
+	if m != nil {
+		return m.Labels
+	}
+	return nil
+}
+
+func (m *SfcEntity) GetAnnotations() map[string]string {
+	if m != nil {
+		return m.Annotations
+	}
+	return nil
+}
+
+func (m *SfcEntity) GetProxyArpRanges() []*ProxyArpRange {
+	if m != nil {
+		return m.ProxyArpRanges
+	}
+	return nil
+}
+
+func (m *SfcEntity) GetAcls() []*Acl {
+	if m != nil {
+		return m.Acls
+	}
+	return nil
+}
+
+func (m *SfcEntity) GetClassifierRules() []*ClassifierRule {
+	if m != nil {
+		return m.ClassifierRules
+	}
+	return nil
+}
+
 type SfcEntity_SfcElement struct {
-	Container        string         `protobuf:"bytes,1,opt,name=container,proto3" json:"container,omitempty"`
-	PortLabel        string         `protobuf:"bytes,2,opt,name=port_label,proto3" json:"port_label,omitempty"`
-	EtcdVppSwitchKey string         `protobuf:"bytes,3,opt,name=etcd_vpp_switch_key,proto3" json:"etcd_vpp_switch_key,omitempty"`
-	Ipv4Addr         string         `protobuf:"bytes,4,opt,name=ipv4_addr,proto3" json:"ipv4_addr,omitempty"`
-	MacAddr          string         `protobuf:"bytes,5,opt,name=mac_addr,proto3" json:"mac_addr,omitempty"`
-	Type             SfcElementType `protobuf:"varint,6,opt,name=type,proto3,enum=controller.SfcElementType" json:"type,omitempty"`
-	VlanId           uint32         `protobuf:"varint,7,opt,name=vlan_id,proto3" json:"vlan_id,omitempty"`
-	Mtu              uint32         `protobuf:"varint,8,opt,name=mtu,proto3" json:"mtu,omitempty"`
-	RxMode           RxModeType     `protobuf:"varint,9,opt,name=rx_mode,proto3,enum=controller.RxModeType" json:"rx_mode,omitempty"`
-	L2FibMacs        []string       `protobuf:"bytes,10,rep,name=l2fib_macs" json:"l2fib_macs,omitempty"`
-	Ipv6Addr         string         `protobuf:"bytes,11,opt,name=ipv6_addr,proto3" json:"ipv6_addr,omitempty"`
-	L3VrfRoutes      []*L3VRFRoute  `protobuf:"bytes,12,rep,name=l3vrf_routes" json:"l3vrf_routes,omitempty"`
-	L3ArpEntries     []*L3ArpEntry  `protobuf:"bytes,13,rep,name=l3arp_entries" json:"l3arp_entries,omitempty"`
+	Container           string            `protobuf:"bytes,1,opt,name=container,proto3" json:"container,omitempty"`
+	PortLabel           string            `protobuf:"bytes,2,opt,name=port_label,proto3" json:"port_label,omitempty"`
+	EtcdVppSwitchKey    string            `protobuf:"bytes,3,opt,name=etcd_vpp_switch_key,proto3" json:"etcd_vpp_switch_key,omitempty"`
+	Ipv4Addr            string            `protobuf:"bytes,4,opt,name=ipv4_addr,proto3" json:"ipv4_addr,omitempty"`
+	MacAddr             string            `protobuf:"bytes,5,opt,name=mac_addr,proto3" json:"mac_addr,omitempty"`
+	Type                SfcElementType    `protobuf:"varint,6,opt,name=type,proto3,enum=controller.SfcElementType" json:"type,omitempty"`
+	VlanId              uint32            `protobuf:"varint,7,opt,name=vlan_id,proto3" json:"vlan_id,omitempty"`
+	Mtu                 uint32            `protobuf:"varint,8,opt,name=mtu,proto3" json:"mtu,omitempty"`
+	RxMode              RxModeType        `protobuf:"varint,9,opt,name=rx_mode,proto3,enum=controller.RxModeType" json:"rx_mode,omitempty"`
+	L2FibMacs           []string          `protobuf:"bytes,10,rep,name=l2fib_macs" json:"l2fib_macs,omitempty"`
+	Ipv6Addr            string            `protobuf:"bytes,11,opt,name=ipv6_addr,proto3" json:"ipv6_addr,omitempty"`
+	L3VrfRoutes         []*L3VRFRoute     `protobuf:"bytes,12,rep,name=l3vrf_routes" json:"l3vrf_routes,omitempty"`
+	L3ArpEntries        []*L3ArpEntry     `protobuf:"bytes,13,rep,name=l3arp_entries" json:"l3arp_entries,omitempty"`
+	MemifParms          *MemifParms       `protobuf:"bytes,14,opt,name=memif_parms" json:"memif_parms,omitempty"`
+	VrfId               uint32            `protobuf:"varint,15,opt,name=vrf_id,proto3" json:"vrf_id,omitempty"`
+	Unnumbered          bool              `protobuf:"varint,16,opt,name=unnumbered,proto3" json:"unnumbered,omitempty"`
+	ProxyArpRanges      []*ProxyArpRange  `protobuf:"bytes,17,rep,name=proxy_arp_ranges" json:"proxy_arp_ranges,omitempty"`
+	ContainerRoutes     []*ContainerRoute `protobuf:"bytes,18,rep,name=container_routes" json:"container_routes,omitempty"`
+	ContainerDefaultGw  string            `protobuf:"bytes,19,opt,name=container_default_gw,proto3" json:"container_default_gw,omitempty"`
+	ContainerArpEntries []*L3ArpEntry     `protobuf:"bytes,20,rep,name=container_arp_entries" json:"container_arp_entries,omitempty"`
+	Acls                []*Acl            `protobuf:"bytes,21,rep,name=acls" json:"acls,omitempty"`
+	TapNamespace        string            `protobuf:"bytes,22,opt,name=tap_namespace,proto3" json:"tap_namespace,omitempty"`
+	RouteWeight         uint32            `protobuf:"varint,23,opt,name=route_weight,proto3" json:"route_weight,omitempty"`
+	RoutePreference     uint32            `protobuf:"varint,24,opt,name=route_preference,proto3" json:"route_preference,omitempty"`
 }
 
 func (m *SfcEntity_SfcElement) Reset()         { *m = SfcEntity_SfcElement{} }
@@ -366,9 +797,63 @@ func (m *SfcEntity_SfcElement) GetL3ArpEntries() []*L3ArpEntry {
 	return nil
 }
 
+func (m *SfcEntity_SfcElement) GetMemifParms() *MemifParms {
+	if m != nil {
+		return m.MemifParms
+	}
+	return nil
+}
+
+func (m *SfcEntity_SfcElement) GetProxyArpRanges() []*ProxyArpRange {
+	if m != nil {
+		return m.ProxyArpRanges
+	}
+	return nil
+}
+
+func (m *SfcEntity_SfcElement) GetContainerRoutes() []*ContainerRoute {
+	if m != nil {
+		return m.ContainerRoutes
+	}
+	return nil
+}
+
+func (m *SfcEntity_SfcElement) GetContainerArpEntries() []*L3ArpEntry {
+	if m != nil {
+		return m.ContainerArpEntries
+	}
+	return nil
+}
+
+func (m *SfcEntity_SfcElement) GetAcls() []*Acl {
+	if m != nil {
+		return m.Acls
+	}
+	return nil
+}
+
+type ControllerInstance struct {
+	InstanceId string `protobuf:"bytes,1,opt,name=instance_id,proto3" json:"instance_id,omitempty"`
+	Hostname   string `protobuf:"bytes,2,opt,name=hostname,proto3" json:"hostname,omitempty"`
+	Pid        int32  `protobuf:"varint,3,opt,name=pid,proto3" json:"pid,omitempty"`
+	Heartbeat  int64  `protobuf:"varint,4,opt,name=heartbeat,proto3" json:"heartbeat,omitempty"`
+}
+
+func (m *ControllerInstance) Reset()         { *m = ControllerInstance{} }
+func (m *ControllerInstance) String() string { return proto.CompactTextString(m) }
+func (*ControllerInstance) ProtoMessage()    {}
+
 func init() {
 	proto.RegisterEnum("controller.RxModeType", RxModeType_name, RxModeType_value)
+	proto.RegisterEnum("controller.MemifMode", MemifMode_name, MemifMode_value)
+	proto.RegisterEnum("controller.IpamStrategy", IpamStrategy_name, IpamStrategy_value)
+	proto.RegisterEnum("controller.MacAddressScheme", MacAddressScheme_name, MacAddressScheme_value)
+	proto.RegisterEnum("controller.MemifSocketScheme", MemifSocketScheme_name, MemifSocketScheme_value)
 	proto.RegisterEnum("controller.ExtEntDriverType", ExtEntDriverType_name, ExtEntDriverType_value)
 	proto.RegisterEnum("controller.SfcType", SfcType_name, SfcType_value)
 	proto.RegisterEnum("controller.SfcElementType", SfcElementType_name, SfcElementType_value)
+	proto.RegisterEnum("controller.AclAction", AclAction_name, AclAction_value)
+	proto.RegisterEnum("controller.AclProtocol", AclProtocol_name, AclProtocol_value)
+	proto.RegisterEnum("controller.AclDirection", AclDirection_name, AclDirection_value)
+	proto.RegisterEnum("controller.PeerRedundancyType", PeerRedundancyType_name, PeerRedundancyType_value)
 }
@@ -68,3 +68,53 @@ func SfcEntityHTTPPrefix() string {
 func SfcEntityNameKey(name string) string {
 	return SfcEntityKeyPrefix() + name
 }
+
+// TenantKeyPrefix provides sfc controller's tenant key prefix
+func TenantKeyPrefix() string {
+	return SfcControllerPrefix() + "Tenant/"
+}
+
+// TenantsHTTPPrefix provides sfc controller's tenants HTTP prefix
+func TenantsHTTPPrefix() string {
+	return SfcControllerPrefix() + "Tenants"
+}
+
+// TenantNameKey provides sfc controller's tenant name key
+func TenantNameKey(name string) string {
+	return TenantKeyPrefix() + name
+}
+
+// IPAMPoolsHTTPPrefix provides sfc controller's ipam pool utilization HTTP prefix
+func IPAMPoolsHTTPPrefix() string {
+	return SfcControllerPrefix() + "IPAM/Pools"
+}
+
+// IPAMAllocationsHTTPPrefix provides sfc controller's ipam allocations HTTP prefix
+func IPAMAllocationsHTTPPrefix() string {
+	return SfcControllerPrefix() + "IPAM/Allocations"
+}
+
+// IPAMReserveHTTPPrefix provides sfc controller's ipam manual reservation HTTP prefix
+func IPAMReserveHTTPPrefix() string {
+	return SfcControllerPrefix() + "IPAM/Reserve"
+}
+
+// IPAMReleaseHTTPPrefix provides sfc controller's ipam manual release HTTP prefix
+func IPAMReleaseHTTPPrefix() string {
+	return SfcControllerPrefix() + "IPAM/Release"
+}
+
+// InterfaceNamesHTTPPrefix provides sfc controller's vswitch interface names HTTP prefix
+func InterfaceNamesHTTPPrefix() string {
+	return SfcControllerPrefix() + "InterfaceNames"
+}
+
+// ControllerInstanceKeyPrefix provides sfc controller's controller instance key prefix
+func ControllerInstanceKeyPrefix() string {
+	return SfcControllerPrefix() + "Instance/"
+}
+
+// ControllerInstanceKey provides sfc controller's controller instance key
+func ControllerInstanceKey(instanceID string) string {
+	return ControllerInstanceKeyPrefix() + instanceID
+}
@@ -18,10 +18,12 @@ import (
 	"net"
 	"strings"
 
+	"github.com/ligato/vpp-agent/plugins/defaultplugins/common/model/acl"
 	"github.com/ligato/vpp-agent/plugins/defaultplugins/common/model/interfaces"
 	"github.com/ligato/vpp-agent/plugins/defaultplugins/common/model/l2"
 	"github.com/ligato/vpp-agent/plugins/defaultplugins/common/model/l3"
 	linuxIntf "github.com/ligato/vpp-agent/plugins/linuxplugin/ifplugin/model/interfaces"
+	linuxL3 "github.com/ligato/vpp-agent/plugins/linuxplugin/l3plugin/model/l3"
 )
 
 // this must match what utils the vpp-agent uses
@@ -81,6 +83,22 @@ func L2BridgeDomainKeyPrefix(vppLabel string) string {
 	return agentPrefix + vppLabel + "/" + l2.BridgeDomainKeyPrefix()
 }
 
+// L2FibKey constructs L2 FIB entry db key
+func L2FibKey(vppLabel string, bdName string, fibMac string) string {
+	return agentPrefix + vppLabel + "/" + l2.FibKey(bdName, fibMac)
+}
+
+// IsL2FibKey returns true if the key of the bridge domain db key prefix is the key of an L2 FIB entry
+func IsL2FibKey(vppLabel string, key string) bool {
+	isFibKey, _, _ := l2.ParseFibKey(strings.TrimPrefix(key, agentPrefix+vppLabel+"/"))
+	return isFibKey
+}
+
+// L2XConnectKeyPrefix constructs L2 XConnect db key prefix
+func L2XConnectKeyPrefix(vppLabel string) string {
+	return agentPrefix + vppLabel + "/" + l2.XConnectKeyPrefix()
+}
+
 // L2XConnectKey constructs L2 XConnect db key
 func L2XConnectKey(vppLabel string, rxIf string) string {
 	return agentPrefix + vppLabel + "/" + l2.XConnectKey(rxIf)
@@ -97,7 +115,64 @@ func L3RouteKey(vppLabel string, vrf uint32, destNet *net.IPNet, nextHop string)
 	return agentPrefix + vppLabel + "/" + l3.RouteKey(vrf, destNet.String(), nextHop)
 }
 
+// ArpEntryKeyPrefix constructs l3 arp entry db key prefix
+func ArpEntryKeyPrefix(vppLabel string) string {
+	return agentPrefix + vppLabel + "/" + l3.ArpKeyPrefix()
+}
+
 // ArpEntryKeyl3 arp key
 func ArpEntryKey(vppLabel string, iface string, ipAddress string) string {
 	return agentPrefix + vppLabel + "/" + l3.ArpEntryKey(iface, ipAddress)
 }
+
+// ProxyArpRangeKeyPrefix constructs proxy arp range db key prefix
+func ProxyArpRangeKeyPrefix(vppLabel string) string {
+	return agentPrefix + vppLabel + "/" + strings.Split(l3.ProxyARPRangePrefix, "{")[0]
+}
+
+// ProxyArpRangeKey constructs proxy arp range db key
+func ProxyArpRangeKey(vppLabel string, rangeIPStart string, rangeIPEnd string) string {
+	key := strings.Replace(l3.ProxyARPRangePrefix, "{lo_ip}", rangeIPStart, 1)
+	key = strings.Replace(key, "{hi_ip}", rangeIPEnd, 1)
+	return agentPrefix + vppLabel + "/" + key
+}
+
+// ProxyArpInterfaceKeyPrefix constructs proxy arp interface db key prefix
+func ProxyArpInterfaceKeyPrefix(vppLabel string) string {
+	return agentPrefix + vppLabel + "/" + strings.Split(l3.ProxyARPInterfacePrefix, "{")[0]
+}
+
+// ProxyArpInterfaceKey constructs proxy arp interface db key
+func ProxyArpInterfaceKey(vppLabel string, iface string) string {
+	return agentPrefix + vppLabel + "/" + strings.Replace(l3.ProxyARPInterfacePrefix, "{if}", iface, 1)
+}
+
+// LinuxRouteKeyPrefix constructs Linux static route db key prefix
+func LinuxRouteKeyPrefix(vppLabel string) string {
+	return agentPrefix + vppLabel + "/" + linuxL3.StaticRouteKeyPrefix()
+}
+
+// LinuxRouteKey constructs Linux static route db key
+func LinuxRouteKey(vppLabel string, routeLabel string) string {
+	return agentPrefix + vppLabel + "/" + linuxL3.StaticRouteKey(routeLabel)
+}
+
+// LinuxArpEntryKeyPrefix constructs Linux static arp entry db key prefix
+func LinuxArpEntryKeyPrefix(vppLabel string) string {
+	return agentPrefix + vppLabel + "/" + linuxL3.StaticArpKeyPrefix()
+}
+
+// LinuxArpEntryKey constructs Linux static arp entry db key
+func LinuxArpEntryKey(vppLabel string, arpLabel string) string {
+	return agentPrefix + vppLabel + "/" + linuxL3.StaticArpKey(arpLabel)
+}
+
+// AclKeyPrefix constructs acl db key prefix
+func AclKeyPrefix(vppLabel string) string {
+	return agentPrefix + vppLabel + "/" + acl.KeyPrefix()
+}
+
+// AclKey constructs acl db key
+func AclKey(vppLabel string, aclName string) string {
+	return agentPrefix + vppLabel + "/" + acl.Key(aclName)
+}
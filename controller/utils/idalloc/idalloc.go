@@ -0,0 +1,505 @@
+// Copyright (c) 2017 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// package idalloc allocates ids from named ranges, ie: "vni" 5000-9999.  An
+// id is claimed in the store before it is handed out, the claim is a put if
+// not exists so two controllers sharing the store never hand out the same id,
+// and as the claims are kept in the store they survive a restart.  A claim
+// records the controller instance which made it, an instance only ever
+// releases its own claims, the claims of the other instances are skipped.  An
+// id is owned until it is released, released ids are handed out again.  The
+// allocator is safe for concurrent use, the store is not accessed while the
+// allocator is locked, the ids being claimed or released in the store are
+// marked pending so they are not handed out meanwhile.
+package idalloc
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Claim is an id of a range, who owns it and the controller instance which claimed it
+type Claim struct {
+	Range    string
+	ID       uint32
+	Owner    string
+	Instance string
+}
+
+// Store keeps the claims, Claim must be atomic, ie: an etcd put if not exists,
+// as it is what keeps concurrent controllers from handing out the same id
+type Store interface {
+	// Claim stores the claim if the id is not claimed, false if it is already claimed
+	Claim(claim *Claim) (bool, error)
+	// Get returns the claim of the id, nil if the id is not claimed
+	Get(rangeName string, id uint32) (*Claim, error)
+	// Release removes the claim of the id
+	Release(rangeName string, id uint32) error
+	// Claims returns all the stored claims
+	Claims() ([]Claim, error)
+}
+
+// ExhaustedError is returned when every id of a range is owned
+type ExhaustedError struct {
+	Range string
+	First uint32
+	Last  uint32
+}
+
+func (e *ExhaustedError) Error() string {
+	return fmt.Sprintf("id range '%s' %d-%d is exhausted", e.Range, e.First, e.Last)
+}
+
+// idRange is a named range, the owners of the ids this instance claimed, the ids claimed by the other
+// instances and the ids being claimed or released, the owners are kept even if the range is redefined so
+// ids outside of a shrunk range are not lost
+type idRange struct {
+	first   uint32
+	last    uint32
+	next    map[uint64]uint32 // per window, the search resumes here so released ids are not reused right away
+	defined bool
+	owners  map[uint32]string
+	foreign map[uint32]string
+	pending map[uint32]struct{}
+}
+
+func (r *idRange) used(id uint32) bool {
+	_, owned := r.owners[id]
+	_, foreign := r.foreign[id]
+	_, pending := r.pending[id]
+	return owned || foreign || pending
+}
+
+// Allocator hands out the ids of the named ranges of one controller instance
+type Allocator struct {
+	mu       sync.Mutex
+	store    Store
+	instance string
+	ranges   map[string]*idRange
+}
+
+// New returns an allocator of the controller instance backed by the store, with a nil store the claims
+// are in memory only
+func New(store Store, instance string) *Allocator {
+	return &Allocator{
+		store:    store,
+		instance: instance,
+		ranges:   make(map[string]*idRange),
+	}
+}
+
+func (a *Allocator) getRange(rangeName string) *idRange {
+	r, exists := a.ranges[rangeName]
+	if !exists {
+		r = &idRange{
+			next:    make(map[uint64]uint32),
+			owners:  make(map[uint32]string),
+			foreign: make(map[uint32]string),
+			pending: make(map[uint32]struct{}),
+		}
+		a.ranges[rangeName] = r
+	}
+	return r
+}
+
+// DefineRange defines or redefines the ids first..last of the range, id 0 is never handed out
+func (a *Allocator) DefineRange(rangeName string, first uint32, last uint32) error {
+
+	if first == 0 || first > last {
+		return fmt.Errorf("DefineRange: range '%s' %d-%d is invalid", rangeName, first, last)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	r := a.getRange(rangeName)
+	r.first = first
+	r.last = last
+	r.defined = true
+
+	return nil
+}
+
+// Load reads the claims from the store, it is called on startup so ids
+// claimed before a restart, or by another controller, are not handed out,
+// only the claims of this instance are owned by it
+func (a *Allocator) Load() error {
+
+	if a.store == nil {
+		return nil
+	}
+	claims, err := a.store.Claims()
+	if err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for _, claim := range claims {
+		a.addClaim(claim)
+	}
+
+	return nil
+}
+
+// addClaim records a claim read from the store as owned by this instance or as a foreign claim
+func (a *Allocator) addClaim(claim Claim) {
+	r := a.getRange(claim.Range)
+	if claim.Instance == a.instance {
+		r.owners[claim.ID] = claim.Owner
+		delete(r.foreign, claim.ID)
+	} else {
+		r.foreign[claim.ID] = claim.Instance
+		delete(r.owners, claim.ID)
+	}
+}
+
+// claimInStore claims the id in the store, it is called unlocked with the id pending, an id already claimed
+// by the owner of this instance is fine as an owner reclaims its ids, ie: when the config is rendered again,
+// it returns the claim which holds the id
+func (a *Allocator) claimInStore(rangeName string, id uint32, owner string) (*Claim, error) {
+
+	claim := &Claim{Range: rangeName, ID: id, Owner: owner, Instance: a.instance}
+	if a.store == nil {
+		return claim, nil
+	}
+	for retry := 0; retry < 2; retry++ {
+		claimed, err := a.store.Claim(claim)
+		if err != nil || claimed {
+			return claim, err
+		}
+		stored, err := a.store.Get(rangeName, id)
+		if err != nil || stored != nil {
+			return stored, err
+		}
+		// released between the claim and the lookup, try once more
+	}
+	return nil, fmt.Errorf("id %d of range '%s' cannot be claimed", id, rangeName)
+}
+
+// isMine is true if the claim is the owner's claim of this instance
+func (a *Allocator) isMine(claim *Claim, owner string) bool {
+	return claim.Owner == owner && claim.Instance == a.instance
+}
+
+// Allocate hands out a free id of the range to the owner
+func (a *Allocator) Allocate(rangeName string, owner string) (uint32, error) {
+	return a.AllocateFrom(rangeName, 0, 0, owner)
+}
+
+// AllocateFrom hands out a free id of the first..last window of the range to the owner, the ids of the
+// windows are claimed in the range so windows sharing a range never hand out the same id, 0..0 is the whole
+// range
+func (a *Allocator) AllocateFrom(rangeName string, first uint32, last uint32, owner string) (uint32, error) {
+
+	id, found, err := a.allocate(rangeName, first, last, owner)
+	if err != nil || found || a.store == nil {
+		return a.allocated(rangeName, first, last, id, found, err)
+	}
+
+	// the ids claimed by other controllers may have been released since, so refresh the foreign claims of
+	// the range from the store before giving up
+	claims, err := a.store.Claims()
+	if err != nil {
+		return 0, err
+	}
+	a.mu.Lock()
+	r := a.getRange(rangeName)
+	r.foreign = make(map[uint32]string)
+	for _, claim := range claims {
+		if claim.Range == rangeName && claim.Instance != a.instance {
+			r.foreign[claim.ID] = claim.Instance
+		}
+	}
+	a.mu.Unlock()
+
+	id, found, err = a.allocate(rangeName, first, last, owner)
+	return a.allocated(rangeName, first, last, id, found, err)
+}
+
+// allocated returns the allocated id, or the exhaustion error of the window if none was found
+func (a *Allocator) allocated(rangeName string, first uint32, last uint32, id uint32, found bool,
+	err error) (uint32, error) {
+
+	if err != nil || found {
+		return id, err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	r := a.ranges[rangeName]
+	first, last = r.window(first, last)
+	return 0, &ExhaustedError{Range: rangeName, First: first, Last: last}
+}
+
+// window returns the first..last window of the range, the whole range if the window is 0..0
+func (r *idRange) window(first uint32, last uint32) (uint32, uint32) {
+	if first == 0 && last == 0 {
+		return r.first, r.last
+	}
+	return first, last
+}
+
+// allocate walks the window from where the last search of the window ended and claims the first id which
+// is not used, an id claimed in the store by someone else meanwhile is remembered so it is skipped from now on
+func (a *Allocator) allocate(rangeName string, first uint32, last uint32, owner string) (uint32, bool, error) {
+
+	for {
+		id, found, err := a.nextFreeID(rangeName, first, last)
+		if err != nil || !found {
+			return 0, false, err
+		}
+		claim, err := a.claimInStore(rangeName, id, owner)
+
+		a.mu.Lock()
+		r := a.ranges[rangeName]
+		delete(r.pending, id)
+		if err != nil {
+			a.mu.Unlock()
+			return 0, false, err
+		}
+		a.addClaim(*claim)
+		mine := a.isMine(claim, owner)
+		a.mu.Unlock()
+
+		if mine {
+			return id, true, nil
+		}
+	}
+}
+
+// nextFreeID finds the next id of the window which is not used and marks it pending, false if there is none
+func (a *Allocator) nextFreeID(rangeName string, first uint32, last uint32) (uint32, bool, error) {
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	r, exists := a.ranges[rangeName]
+	if !exists || !r.defined {
+		return 0, false, fmt.Errorf("Allocate: range '%s' is not defined", rangeName)
+	}
+	first, last = r.window(first, last)
+	if first == 0 || first > last || first < r.first || last > r.last {
+		return 0, false, fmt.Errorf("Allocate: window %d-%d is not inside range '%s' %d-%d", first, last,
+			rangeName, r.first, r.last)
+	}
+
+	windowKey := uint64(first)<<32 | uint64(last)
+	size := uint64(last) - uint64(first) + 1
+	id := r.next[windowKey]
+	for n := uint64(0); n < size; n++ {
+		if id < first || id > last {
+			id = first
+		}
+		if !r.used(id) {
+			r.pending[id] = struct{}{}
+			r.next[windowKey] = id + 1
+			return id, true, nil
+		}
+		id++
+	}
+	return 0, false, nil
+}
+
+// Claim hands out a specific id to the owner, it fails if the id is owned by someone else
+func (a *Allocator) Claim(rangeName string, id uint32, owner string) error {
+
+	a.mu.Lock()
+	r := a.getRange(rangeName)
+	if current, owned := r.owners[id]; owned {
+		a.mu.Unlock()
+		if current != owner {
+			return fmt.Errorf("Claim: id %d of range '%s' is owned by '%s'", id, rangeName, current)
+		}
+		return nil
+	}
+	if instance, foreign := r.foreign[id]; foreign {
+		a.mu.Unlock()
+		return fmt.Errorf("Claim: id %d of range '%s' is owned by instance '%s'", id, rangeName, instance)
+	}
+	if _, pending := r.pending[id]; pending {
+		a.mu.Unlock()
+		return fmt.Errorf("Claim: id %d of range '%s' is being claimed or released", id, rangeName)
+	}
+	r.pending[id] = struct{}{}
+	a.mu.Unlock()
+
+	claim, err := a.claimInStore(rangeName, id, owner)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	delete(r.pending, id)
+	if err != nil {
+		return err
+	}
+	a.addClaim(*claim)
+	if !a.isMine(claim, owner) {
+		return fmt.Errorf("Claim: id %d of range '%s' is owned by '%s' of instance '%s'", id, rangeName,
+			claim.Owner, claim.Instance)
+	}
+
+	return nil
+}
+
+// Release releases the id so it can be handed out again, an id which this instance does not own is left
+// as is
+func (a *Allocator) Release(rangeName string, id uint32) error {
+
+	a.mu.Lock()
+	r, exists := a.ranges[rangeName]
+	if !exists {
+		a.mu.Unlock()
+		return nil
+	}
+	owner, owned := r.owners[id]
+	if !owned {
+		a.mu.Unlock()
+		return nil
+	}
+	delete(r.owners, id)
+	r.pending[id] = struct{}{}
+	a.mu.Unlock()
+
+	return a.releaseInStore(rangeName, r, id, owner)
+}
+
+// releaseInStore removes the claim of the pending id from the store, then the id is free, if the store
+// fails the id stays with the owner
+func (a *Allocator) releaseInStore(rangeName string, r *idRange, id uint32, owner string) error {
+
+	var err error
+	if a.store != nil {
+		err = a.store.Release(rangeName, id)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	delete(r.pending, id)
+	if err != nil {
+		r.owners[id] = owner
+	}
+	return err
+}
+
+// ReleaseOwner releases all the ids of the owner and returns them
+func (a *Allocator) ReleaseOwner(owner string) ([]Claim, error) {
+	return a.releaseOwners(func(idOwner string) bool {
+		return idOwner == owner
+	})
+}
+
+// ReleaseOwnerPrefix releases all the ids of the owners with the prefix and returns them
+func (a *Allocator) ReleaseOwnerPrefix(ownerPrefix string) ([]Claim, error) {
+	return a.releaseOwners(func(idOwner string) bool {
+		return strings.HasPrefix(idOwner, ownerPrefix)
+	})
+}
+
+func (a *Allocator) releaseOwners(match func(owner string) bool) ([]Claim, error) {
+
+	type releasing struct {
+		claim Claim
+		r     *idRange
+	}
+
+	a.mu.Lock()
+	toRelease := make([]releasing, 0)
+	for rangeName, r := range a.ranges {
+		for id, idOwner := range r.owners {
+			if !match(idOwner) {
+				continue
+			}
+			delete(r.owners, id)
+			r.pending[id] = struct{}{}
+			toRelease = append(toRelease, releasing{
+				claim: Claim{Range: rangeName, ID: id, Owner: idOwner, Instance: a.instance},
+				r:     r,
+			})
+		}
+	}
+	a.mu.Unlock()
+
+	released := make([]Claim, 0, len(toRelease))
+	var firstErr error
+	for _, rel := range toRelease {
+		if err := a.releaseInStore(rel.claim.Range, rel.r, rel.claim.ID, rel.claim.Owner); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		released = append(released, rel.claim)
+	}
+
+	return released, firstErr
+}
+
+// Owner returns the owner of an id of this instance, "" if the id is free or claimed by another instance
+func (a *Allocator) Owner(rangeName string, id uint32) string {
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if r, exists := a.ranges[rangeName]; exists {
+		return r.owners[id]
+	}
+	return ""
+}
+
+// Claims returns the ids owned by this instance ordered by range and id
+func (a *Allocator) Claims() []Claim {
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	claims := make([]Claim, 0)
+	for rangeName, r := range a.ranges {
+		for id, owner := range r.owners {
+			claims = append(claims, Claim{Range: rangeName, ID: id, Owner: owner, Instance: a.instance})
+		}
+	}
+	sort.Slice(claims, func(i, j int) bool {
+		if claims[i].Range != claims[j].Range {
+			return claims[i].Range < claims[j].Range
+		}
+		return claims[i].ID < claims[j].ID
+	})
+	return claims
+}
+
+func (a *Allocator) String() string {
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	names := make([]string, 0, len(a.ranges))
+	for rangeName := range a.ranges {
+		names = append(names, rangeName)
+	}
+	sort.Strings(names)
+
+	s := ""
+	for _, rangeName := range names {
+		r := a.ranges[rangeName]
+		s += fmt.Sprintf("%s: %d-%d owned: %d, foreign: %d, ", rangeName, r.first, r.last, len(r.owners),
+			len(r.foreign))
+	}
+	return s
+}
@@ -0,0 +1,381 @@
+// Copyright (c) 2017 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package idalloc
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+// memStore is a store shared by the allocators of a test, like etcd shared by controller instances
+type memStore struct {
+	mu      sync.Mutex
+	claims  map[string]Claim
+	fail    error
+	onClaim func() // called unlocked on every claim, ie: to check the allocator is not locked
+}
+
+func newMemStore() *memStore {
+	return &memStore{claims: make(map[string]Claim)}
+}
+
+func claimKey(rangeName string, id uint32) string {
+	return fmt.Sprintf("%s/%d", rangeName, id)
+}
+
+func (s *memStore) Claim(claim *Claim) (bool, error) {
+	if s.onClaim != nil {
+		s.onClaim()
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.fail != nil {
+		return false, s.fail
+	}
+	key := claimKey(claim.Range, claim.ID)
+	if _, exists := s.claims[key]; exists {
+		return false, nil
+	}
+	s.claims[key] = *claim
+	return true, nil
+}
+
+func (s *memStore) Get(rangeName string, id uint32) (*Claim, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if claim, exists := s.claims[claimKey(rangeName, id)]; exists {
+		return &claim, nil
+	}
+	return nil, nil
+}
+
+func (s *memStore) Release(rangeName string, id uint32) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.fail != nil {
+		return s.fail
+	}
+	delete(s.claims, claimKey(rangeName, id))
+	return nil
+}
+
+func (s *memStore) Claims() ([]Claim, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	claims := make([]Claim, 0, len(s.claims))
+	for _, claim := range s.claims {
+		claims = append(claims, claim)
+	}
+	return claims, nil
+}
+
+func TestDefineRange(t *testing.T) {
+	tests := []struct {
+		first   uint32
+		last    uint32
+		wantErr bool
+	}{
+		{1, 10, false},
+		{5, 5, false},
+		{0, 10, true},
+		{10, 5, true},
+	}
+	for _, test := range tests {
+		a := New(nil, "c1")
+		if err := a.DefineRange("r", test.first, test.last); (err != nil) != test.wantErr {
+			t.Errorf("DefineRange(%d, %d) error = %v, wantErr %v", test.first, test.last, err, test.wantErr)
+		}
+	}
+}
+
+func TestAllocate(t *testing.T) {
+	tests := []struct {
+		name    string
+		first   uint32
+		last    uint32
+		claimed []uint32 // claimed by other owners before the allocations
+		want    []uint32 // the allocations in order, 0 when the range is exhausted
+		release []uint32 // released after the allocations
+		after   []uint32 // the allocations after the release
+	}{
+		{
+			name:  "ids in order",
+			first: 5,
+			last:  9,
+			want:  []uint32{5, 6, 7},
+		},
+		{
+			name:  "exhausted",
+			first: 1,
+			last:  2,
+			want:  []uint32{1, 2, 0},
+		},
+		{
+			name:    "claimed ids are skipped",
+			first:   1,
+			last:    4,
+			claimed: []uint32{1, 3},
+			want:    []uint32{2, 4, 0},
+		},
+		{
+			name:    "released ids are handed out after the rest of the range",
+			first:   1,
+			last:    4,
+			want:    []uint32{1, 2},
+			release: []uint32{1},
+			after:   []uint32{3, 4, 1, 0},
+		},
+	}
+	for _, test := range tests {
+		a := New(newMemStore(), "c1")
+		a.DefineRange("r", test.first, test.last)
+		for _, id := range test.claimed {
+			if err := a.Claim("r", id, "other"); err != nil {
+				t.Errorf("%s: Claim(%d) error = %v", test.name, id, err)
+			}
+		}
+		check := func(want []uint32) {
+			for i, wantID := range want {
+				id, err := a.Allocate("r", "owner")
+				if wantID == 0 {
+					if _, exhausted := err.(*ExhaustedError); !exhausted {
+						t.Errorf("%s: allocation %d = %d, %v, want exhausted", test.name, i, id, err)
+					}
+					continue
+				}
+				if err != nil || id != wantID {
+					t.Errorf("%s: allocation %d = %d, %v, want %d", test.name, i, id, err, wantID)
+				}
+			}
+		}
+		check(test.want)
+		for _, id := range test.release {
+			if err := a.Release("r", id); err != nil {
+				t.Errorf("%s: Release(%d) error = %v", test.name, id, err)
+			}
+		}
+		check(test.after)
+	}
+}
+
+func TestAllocateFrom(t *testing.T) {
+	a := New(newMemStore(), "c1")
+	a.DefineRange("r", 1, 100)
+
+	tests := []struct {
+		name  string
+		first uint32
+		last  uint32
+		want  uint32 // 0 when the window is exhausted or invalid
+	}{
+		{"first window", 10, 11, 10},
+		{"second window", 20, 21, 20},
+		{"first window again", 10, 11, 11},
+		{"first window exhausted", 10, 11, 0},
+		{"whole range skips the ids of the windows", 0, 0, 1},
+		{"overlapping window skips the ids of the others", 9, 12, 9},
+		{"window outside the range", 90, 110, 0},
+		{"inverted window", 21, 20, 0},
+	}
+	for _, test := range tests {
+		id, err := a.AllocateFrom("r", test.first, test.last, test.name)
+		if (err != nil) != (test.want == 0) || id != test.want {
+			t.Errorf("%s: AllocateFrom(%d, %d) = %d, %v, want %d", test.name, test.first, test.last, id, err,
+				test.want)
+		}
+	}
+}
+
+func TestAllocateUndefinedRange(t *testing.T) {
+	a := New(nil, "c1")
+	if _, err := a.Allocate("r", "owner"); err == nil {
+		t.Errorf("Allocate of an undefined range succeeded")
+	}
+}
+
+func TestClaim(t *testing.T) {
+	a := New(newMemStore(), "c1")
+	if err := a.Claim("r", 7, "owner1"); err != nil {
+		t.Fatalf("Claim error = %v", err)
+	}
+	if err := a.Claim("r", 7, "owner1"); err != nil {
+		t.Errorf("Claim of an id by its owner error = %v", err)
+	}
+	if err := a.Claim("r", 7, "owner2"); err == nil {
+		t.Errorf("Claim of an id owned by another owner succeeded")
+	}
+	if owner := a.Owner("r", 7); owner != "owner1" {
+		t.Errorf("Owner = '%s', want 'owner1'", owner)
+	}
+}
+
+func TestInstancesShareTheStore(t *testing.T) {
+	store := newMemStore()
+	a1 := New(store, "c1")
+	a2 := New(store, "c2")
+	a1.DefineRange("r", 1, 3)
+	a2.DefineRange("r", 1, 3)
+
+	if id, err := a1.Allocate("r", "owner1"); err != nil || id != 1 {
+		t.Fatalf("c1 Allocate = %d, %v, want 1", id, err)
+	}
+	// c2 does not know c1's claim yet, the store refuses it and c2 moves on
+	if id, err := a2.Allocate("r", "owner2"); err != nil || id != 2 {
+		t.Fatalf("c2 Allocate = %d, %v, want 2", id, err)
+	}
+	if err := a2.Claim("r", 1, "owner2"); err == nil {
+		t.Errorf("c2 Claim of c1's id succeeded")
+	}
+	if claims := a2.Claims(); len(claims) != 1 || claims[0].ID != 2 {
+		t.Errorf("c2 Claims = %v, want only id 2", claims)
+	}
+	if owner := a2.Owner("r", 1); owner != "" {
+		t.Errorf("c2 Owner of c1's id = '%s', want ''", owner)
+	}
+
+	// c2 never releases c1's claim
+	if err := a2.Release("r", 1); err != nil {
+		t.Errorf("c2 Release error = %v", err)
+	}
+	if _, err := a2.ReleaseOwner("owner1"); err != nil {
+		t.Errorf("c2 ReleaseOwner error = %v", err)
+	}
+	if claim, _ := store.Get("r", 1); claim == nil || claim.Instance != "c1" {
+		t.Errorf("c1's claim after c2's release = %v, want it kept", claim)
+	}
+
+	// c1 restarts, it owns its claims again, c2's claims are skipped
+	a1 = New(store, "c1")
+	a1.DefineRange("r", 1, 3)
+	if err := a1.Load(); err != nil {
+		t.Fatalf("Load error = %v", err)
+	}
+	if claims := a1.Claims(); len(claims) != 1 || claims[0].ID != 1 || claims[0].Owner != "owner1" {
+		t.Errorf("c1 Claims after Load = %v, want only id 1 of owner1", claims)
+	}
+	if id, err := a1.Allocate("r", "owner1"); err != nil || id != 3 {
+		t.Errorf("c1 Allocate after Load = %d, %v, want 3", id, err)
+	}
+}
+
+func TestAllocateRefreshesReleasedForeignClaims(t *testing.T) {
+	store := newMemStore()
+	a1 := New(store, "c1")
+	a2 := New(store, "c2")
+	a1.DefineRange("r", 1, 2)
+	a2.DefineRange("r", 1, 2)
+
+	a1.Allocate("r", "owner1")
+	a1.Allocate("r", "owner1")
+	if _, err := a2.Allocate("r", "owner2"); err == nil {
+		t.Fatalf("c2 Allocate of an exhausted range succeeded")
+	}
+	a1.Release("r", 2)
+	if id, err := a2.Allocate("r", "owner2"); err != nil || id != 2 {
+		t.Errorf("c2 Allocate after c1's release = %d, %v, want 2", id, err)
+	}
+}
+
+func TestReleaseOwnerPrefix(t *testing.T) {
+	store := newMemStore()
+	a := New(store, "c1")
+	a.DefineRange("r", 1, 10)
+	for _, owner := range []string{"sfc1/a", "sfc1/b", "sfc10/a"} {
+		if _, err := a.Allocate("r", owner); err != nil {
+			t.Fatalf("Allocate(%s) error = %v", owner, err)
+		}
+	}
+	released, err := a.ReleaseOwnerPrefix("sfc1/")
+	if err != nil || len(released) != 2 {
+		t.Errorf("ReleaseOwnerPrefix = %v, %v, want the 2 ids of sfc1", released, err)
+	}
+	if claims := a.Claims(); len(claims) != 1 || claims[0].Owner != "sfc10/a" {
+		t.Errorf("Claims after ReleaseOwnerPrefix = %v, want only sfc10/a", claims)
+	}
+	if claims, _ := store.Claims(); len(claims) != 1 {
+		t.Errorf("store claims after ReleaseOwnerPrefix = %v, want 1", claims)
+	}
+}
+
+func TestStoreErrors(t *testing.T) {
+	store := newMemStore()
+	a := New(store, "c1")
+	a.DefineRange("r", 1, 1)
+
+	store.fail = errors.New("store down")
+	if _, err := a.Allocate("r", "owner"); err == nil {
+		t.Errorf("Allocate with a failing store succeeded")
+	}
+	store.fail = nil
+	if id, err := a.Allocate("r", "owner"); err != nil || id != 1 {
+		t.Fatalf("Allocate after the store recovered = %d, %v, want 1", id, err)
+	}
+
+	store.fail = errors.New("store down")
+	if err := a.Release("r", 1); err == nil {
+		t.Errorf("Release with a failing store succeeded")
+	}
+	if owner := a.Owner("r", 1); owner != "owner" {
+		t.Errorf("Owner after a failed release = '%s', want 'owner'", owner)
+	}
+}
+
+func TestStoreIsAccessedUnlocked(t *testing.T) {
+	store := newMemStore()
+	a := New(store, "c1")
+	a.DefineRange("r", 1, 10)
+	// the allocator's mutex is not reentrant, this deadlocks if the store is called with it held
+	store.onClaim = func() { a.Owner("r", 1) }
+
+	if _, err := a.Allocate("r", "owner"); err != nil {
+		t.Errorf("Allocate error = %v", err)
+	}
+	if err := a.Claim("r", 5, "owner"); err != nil {
+		t.Errorf("Claim error = %v", err)
+	}
+}
+
+func TestConcurrentAllocate(t *testing.T) {
+	store := newMemStore()
+	instances := []*Allocator{New(store, "c1"), New(store, "c2")}
+	for _, a := range instances {
+		a.DefineRange("r", 1, 200)
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	ids := make(map[uint32]string)
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			owner := fmt.Sprintf("owner%d", i)
+			id, err := instances[i%2].Allocate("r", owner)
+			if err != nil {
+				t.Errorf("Allocate(%s) error = %v", owner, err)
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if other, exists := ids[id]; exists {
+				t.Errorf("id %d handed out to '%s' and '%s'", id, other, owner)
+			}
+			ids[id] = owner
+		}(i)
+	}
+	wg.Wait()
+}
@@ -17,6 +17,7 @@ package bitmap
 
 import (
 	"fmt"
+	"math/bits"
 )
 
 const ALL_BITS_SET = 0xFFFFFFFFFFFFFFFF
@@ -68,6 +69,15 @@ func (bm *Bitmap) FindFirstClear() uint32 {
 	return 0
 }
 
+// Count returns the number of bits set
+func (bm *Bitmap) Count() uint32 {
+	count := 0
+	for _, v := range bm.u64Array {
+		count += bits.OnesCount64(v)
+	}
+	return uint32(count)
+}
+
 func (bm *Bitmap) String() string {
 	str := fmt.Sprintf("numBits: %d, bits:", bm.numBits)
 
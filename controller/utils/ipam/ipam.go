@@ -17,16 +17,25 @@
 // subnets at the same "level" ie 10.1.1/24" and "10.1.2.0/24" and these
 // would be allocatated addresses out of the separate pools.  But, having
 // pools at different levels will cause issues: eg: "10.1.1.0/24", and
-// "10.1.0.0/16" overlap.  The ipam can be extended to see if subnets are
+// "10.1.0.0/16" overlap, the named pools of the system parameters are
+// validated not to overlap.  The ipam can be extended to see if subnets are
 // contained in other pools and the hierarchy can be "walked" to ensure
 // addresses are set and cleared across levels.  Also, might have to have
 // configurable address blocks per subnet so not allocating undesirable
-// addresses.
+// addresses.  Subnets are scoped per tenant, so the same subnet of two
+// tenants is allocated from two separate pools.  Ipv6 subnets use the same
+// api, see ipam6.go.  Each allocated address has an owner so it can be
+// released, the ipam is in memory only, persisting the allocations is up to
+// the caller.  The pools are held by an IPAM instance which is safe for
+// concurrent use, each driver instance has its own.
 package ipam
 
 import (
 	"fmt"
 	"net"
+	"sort"
+	"strings"
+	"sync"
 	"github.com/ligato/sfc-controller/controller/utils/ipam/bitmap"
 )
 
@@ -35,79 +44,472 @@ type ipamSubnet struct {
 	ipNetworku32  uint32
 	ipMasku32     uint32
 	numBitsInMask int
+	maxID         uint32 // the highest host id, ie the broadcast id is never handed out
 	bm            *bitmap.Bitmap
 	ipNetwork     *net.IPNet
 }
 
-var ipamSubnetCache map[string]*ipamSubnet = make(map[string]*ipamSubnet)
+// ipamSubnetAllocator hands out the ip ids of a subnet, ipID 0 is never handed out
+type ipamSubnetAllocator interface {
+	allocateFromSubnet() (string, uint32, error)
+	setIpIDInSubnet(ipID uint32) (string, error)
+	setIpAddrIfInsideSubnet(ipAddressStr string)
+	clearIpIDInSubnet(ipID uint32)
+	isSetInSubnet(ipID uint32) bool
+	numSetInSubnet() uint32
+	formatIpID(ipID uint32) string
+	ipIDOfAddr(ipAddressStr string) (uint32, bool)
+	maxIpID() uint32
+	String() string
+}
+
+// ipamPool is a subnet of a tenant and the owners of the ids allocated from it, an id which is set
+// via SetIpAddrIfInsideSubnet, ie a configured address, has no owner and is never released
+type ipamPool struct {
+	tenant     string
+	subnetStr  string
+	subnet     ipamSubnetAllocator
+	owners     map[uint32]string
+	reserved   []ipIDRange
+	sequential bool
+	nextID     uint32
+}
 
-func AllocateFromSubnet(ipamSubnetStr string) (string, uint32, error) {
+// ipIDRange is a range of ids in a subnet which are never allocated
+type ipIDRange struct {
+	first uint32
+	last  uint32
+}
+
+// SubnetConfig is the configuration of a named pool, it applies to the subnet of every tenant
+type SubnetConfig struct {
+	ReservedRanges []string // "a.b.c.d" or "a.b.c.d-a.b.c.e", never allocated
+	Gateway        string   // the router of the subnet, never allocated
+	Sequential     bool     // allocate after the last allocated id instead of the first free id
+}
 
-	var ipamSubnet *ipamSubnet
-	var exists bool
-	var err error
+// Allocation is an ip id allocated from a tenant's subnet and who it was allocated to
+type Allocation struct {
+	Tenant    string `json:"tenant,omitempty"`
+	Subnet    string `json:"subnet"`
+	IpID      uint32 `json:"ip_id"`
+	IpAddress string `json:"ip_address"`
+	Owner     string `json:"owner"`
+}
+
+// PoolUsage is the utilization of a tenant's subnet, used counts the allocated and the configured addresses
+type PoolUsage struct {
+	Tenant    string `json:"tenant,omitempty"`
+	Subnet    string `json:"subnet"`
+	Size      uint32 `json:"size"`
+	Reserved  uint32 `json:"reserved"`
+	Used      uint32 `json:"used"`
+	Allocated uint32 `json:"allocated"`
+}
+
+// IPAM holds the pools of the tenant subnets and the configs of the named subnets
+type IPAM struct {
+	mu            sync.Mutex
+	pools         map[string]*ipamPool
+	subnetConfigs map[string]SubnetConfig
+}
 
-	ipamSubnet, exists = ipamSubnetCache[ipamSubnetStr]
+// NewIPAM returns an ipam without any pools
+func NewIPAM() *IPAM {
+	return &IPAM{
+		pools:         make(map[string]*ipamPool),
+		subnetConfigs: make(map[string]SubnetConfig),
+	}
+}
+
+func (ipam *IPAM) getIPAMPool(tenant string, ipamSubnetStr string) (*ipamPool, error) {
+
+	pool, exists := ipam.pools[subnetKey(tenant, ipamSubnetStr)]
 	if !exists {
-		ipamSubnet, err = newIPAMSubnet(ipamSubnetStr)
+		subnet, err := newSubnetAllocator(ipamSubnetStr)
 		if err != nil {
-			return "", 0, err
+			return nil, err
+		}
+		pool = &ipamPool{
+			tenant:    tenant,
+			subnetStr: ipamSubnetStr,
+			subnet:    subnet,
+			owners:    make(map[uint32]string),
 		}
-		ipamSubnetCache[ipamSubnetStr] = ipamSubnet
+		if cfg, exists := ipam.subnetConfigs[ipamSubnetStr]; exists {
+			if err := pool.configure(cfg); err != nil {
+				return nil, err
+			}
+		}
+		ipam.pools[subnetKey(tenant, ipamSubnetStr)] = pool
 	}
-	return ipamSubnet.allocateFromSubnet()
+	return pool, nil
 }
 
-func SetIpIDInSubnet(ipamSubnetStr string, ipID uint32) (string, error) {
+func newSubnetAllocator(ipamSubnetStr string) (ipamSubnetAllocator, error) {
+	if isIPv6Subnet(ipamSubnetStr) {
+		return newIPAMSubnet6(ipamSubnetStr)
+	}
+	return newIPAMSubnet(ipamSubnetStr)
+}
 
-	var ipamSubnet *ipamSubnet
-	var exists bool
-	var err error
+// ConfigureSubnet sets the reserved ranges, gateway and strategy of the subnet, the subnets of the tenants
+// which are already in use are re-configured
+func (ipam *IPAM) ConfigureSubnet(ipamSubnetStr string, cfg SubnetConfig) error {
 
-	ipamSubnet, exists = ipamSubnetCache[ipamSubnetStr]
-	if !exists {
-		ipamSubnet, err = newIPAMSubnet(ipamSubnetStr)
-		if err != nil {
-			return "", err
+	if err := ValidateSubnetConfig(ipamSubnetStr, cfg); err != nil {
+		return err
+	}
+
+	ipam.mu.Lock()
+	defer ipam.mu.Unlock()
+
+	ipam.subnetConfigs[ipamSubnetStr] = cfg
+	for _, pool := range ipam.pools {
+		if pool.subnetStr == ipamSubnetStr {
+			pool.configure(cfg)
 		}
-		ipamSubnetCache[ipamSubnetStr] = ipamSubnet
 	}
-	return ipamSubnet.setIpIDInSubnet(ipID)
+	return nil
 }
 
-func SetIpAddrIfInsideSubnet(ipamSubnetStr string, ipAddress string) {
+// ValidateSubnetConfig checks the reserved ranges and gateway are addresses inside the subnet
+func ValidateSubnetConfig(ipamSubnetStr string, cfg SubnetConfig) error {
+
+	subnet, err := newSubnetAllocator(ipamSubnetStr)
+	if err != nil {
+		return err
+	}
+	_, err = reservedIpIDRanges(ipamSubnetStr, subnet, cfg)
+	return err
+}
 
-	var ipamSubnet *ipamSubnet
-	var exists bool
-	var err error
+// reservedIpIDRanges converts the reserved ranges and gateway of the config into ranges of ids of the subnet
+func reservedIpIDRanges(subnetStr string, subnet ipamSubnetAllocator, cfg SubnetConfig) ([]ipIDRange, error) {
 
-	ipamSubnet, exists = ipamSubnetCache[ipamSubnetStr]
-	if !exists {
-		ipamSubnet, err = newIPAMSubnet(ipamSubnetStr)
-		if err != nil {
-			return
+	ranges := make([]ipIDRange, 0)
+	addrRanges := cfg.ReservedRanges
+	if cfg.Gateway != "" {
+		addrRanges = append(addrRanges[:len(addrRanges):len(addrRanges)], cfg.Gateway)
+	}
+	for _, addrRange := range addrRanges {
+		addrs := strings.Split(addrRange, "-")
+		if len(addrs) > 2 {
+			return nil, fmt.Errorf("invalid address range: '%s'", addrRange)
 		}
-		ipamSubnetCache[ipamSubnetStr] = ipamSubnet
+		first, inside := subnet.ipIDOfAddr(strings.TrimSpace(addrs[0]))
+		if !inside {
+			return nil, fmt.Errorf("address range: '%s' is not inside subnet: '%s'", addrRange, subnetStr)
+		}
+		last := first
+		if len(addrs) == 2 {
+			if last, inside = subnet.ipIDOfAddr(strings.TrimSpace(addrs[1])); !inside || last < first {
+				return nil, fmt.Errorf("invalid address range: '%s' for subnet: '%s'", addrRange, subnetStr)
+			}
+		}
+		ranges = append(ranges, ipIDRange{first: first, last: last})
 	}
+	return ranges, nil
+}
 
-	ipamSubnet.setIpAddrIfInsideSubnet(ipAddress)
+func (pool *ipamPool) configure(cfg SubnetConfig) error {
+
+	reserved, err := reservedIpIDRanges(pool.subnetStr, pool.subnet, cfg)
+	if err != nil {
+		return err
+	}
+	pool.reserved = reserved
+	pool.sequential = cfg.Sequential
+	return nil
 }
 
-func DumpSubnet(ipamSubnetStr string) (string) {
+// reservedRange returns the reserved range containing the ipID
+func (pool *ipamPool) reservedRange(ipID uint32) *ipIDRange {
+	for i := range pool.reserved {
+		if ipID >= pool.reserved[i].first && ipID <= pool.reserved[i].last {
+			return &pool.reserved[i]
+		}
+	}
+	return nil
+}
 
-	var ipamSubnet *ipamSubnet
-	var exists bool
-	var err error
+// allocate finds a free id which is not reserved, from the start of the subnet or after the last allocated
+// id if the pool is sequential, a pool without reserved ranges leaves this to the subnet
+func (pool *ipamPool) allocate() (string, uint32, error) {
 
-	ipamSubnet, exists = ipamSubnetCache[ipamSubnetStr]
+	if len(pool.reserved) == 0 && !pool.sequential {
+		return pool.subnet.allocateFromSubnet()
+	}
+
+	maxID := pool.subnet.maxIpID()
+	ipID := uint32(1)
+	if pool.sequential {
+		ipID = pool.nextID
+	}
+	for n := uint64(0); n < uint64(maxID); n++ {
+		if ipID == 0 || ipID > maxID {
+			ipID = 1
+		}
+		if r := pool.reservedRange(ipID); r != nil {
+			n += uint64(r.last - ipID)
+			ipID = r.last + 1
+			continue
+		}
+		if !pool.subnet.isSetInSubnet(ipID) {
+			ipAddrStr, err := pool.subnet.setIpIDInSubnet(ipID)
+			if err != nil {
+				return "", 0, err
+			}
+			pool.nextID = ipID + 1
+			return ipAddrStr, ipID, nil
+		}
+		ipID++
+	}
+	return "", 0, fmt.Errorf("AllocateFromSubnet: all addresses allocated in '%s", pool.subnetStr)
+}
+
+// AllocateFromSubnet allocates the first free address of the subnet to the owner
+func (ipam *IPAM) AllocateFromSubnet(tenant string, ipamSubnetStr string, owner string) (string, uint32, error) {
+
+	ipam.mu.Lock()
+	defer ipam.mu.Unlock()
+
+	pool, err := ipam.getIPAMPool(tenant, ipamSubnetStr)
+	if err != nil {
+		return "", 0, err
+	}
+	ipAddrStr, ipID, err := pool.allocate()
+	if err != nil {
+		return "", 0, err
+	}
+	pool.owners[ipID] = owner
+
+	return ipAddrStr, ipID, nil
+}
+
+// SetIpIDInSubnet marks the ipID as allocated to the owner, ie when an allocation is read back from the
+// db, it fails if the ipID is allocated to another owner
+func (ipam *IPAM) SetIpIDInSubnet(tenant string, ipamSubnetStr string, ipID uint32, owner string) (string, error) {
+
+	ipam.mu.Lock()
+	defer ipam.mu.Unlock()
+
+	pool, err := ipam.getIPAMPool(tenant, ipamSubnetStr)
+	if err != nil {
+		return "", err
+	}
+	if currOwner, exists := pool.owners[ipID]; exists && currOwner != owner {
+		return "", fmt.Errorf("SetIpIDInSubnet: ipID(%d) in subnet '%s' is allocated to '%s'", ipID,
+			ipamSubnetStr, currOwner)
+	}
+	if pool.reservedRange(ipID) != nil {
+		return "", fmt.Errorf("SetIpIDInSubnet: ipID(%d) in subnet '%s' is reserved", ipID, ipamSubnetStr)
+	}
+	ipAddrStr, err := pool.subnet.setIpIDInSubnet(ipID)
+	if err != nil {
+		return "", err
+	}
+	pool.owners[ipID] = owner
+
+	return ipAddrStr, nil
+}
+
+// SetIpAddrIfInsideSubnet marks a configured address as used so it is not allocated
+func (ipam *IPAM) SetIpAddrIfInsideSubnet(tenant string, ipamSubnetStr string, ipAddress string) {
+
+	ipam.mu.Lock()
+	defer ipam.mu.Unlock()
+
+	pool, err := ipam.getIPAMPool(tenant, ipamSubnetStr)
+	if err != nil {
+		return
+	}
+	pool.subnet.setIpAddrIfInsideSubnet(ipAddress)
+}
+
+// ReleaseIpIDInSubnet returns an allocated ipID to the subnet
+func (ipam *IPAM) ReleaseIpIDInSubnet(tenant string, ipamSubnetStr string, ipID uint32) {
+
+	ipam.mu.Lock()
+	defer ipam.mu.Unlock()
+
+	pool, exists := ipam.pools[subnetKey(tenant, ipamSubnetStr)]
 	if !exists {
-		ipamSubnet, err = newIPAMSubnet(ipamSubnetStr)
-		if err != nil {
-			return err.Error()
+		return
+	}
+	if _, exists := pool.owners[ipID]; exists {
+		delete(pool.owners, ipID)
+		pool.subnet.clearIpIDInSubnet(ipID)
+	}
+}
+
+// ReleaseOwner returns all the ipIDs allocated to the owner to their subnets
+func (ipam *IPAM) ReleaseOwner(owner string) []Allocation {
+
+	ipam.mu.Lock()
+	defer ipam.mu.Unlock()
+
+	released := make([]Allocation, 0)
+	for _, pool := range ipam.pools {
+		for ipID, currOwner := range pool.owners {
+			if currOwner == owner {
+				delete(pool.owners, ipID)
+				pool.subnet.clearIpIDInSubnet(ipID)
+				released = append(released, Allocation{
+					Tenant:    pool.tenant,
+					Subnet:    pool.subnetStr,
+					IpID:      ipID,
+					IpAddress: pool.subnet.formatIpID(ipID),
+					Owner:     owner,
+				})
+			}
+		}
+	}
+	return released
+}
+
+// OwnerAllocations returns the ids allocated to the owners with the prefix, ordered by tenant, subnet and id
+func (ipam *IPAM) OwnerAllocations(ownerPrefix string) []Allocation {
+
+	allocs := make([]Allocation, 0)
+	for _, alloc := range ipam.Allocations() {
+		if strings.HasPrefix(alloc.Owner, ownerPrefix) {
+			allocs = append(allocs, alloc)
+		}
+	}
+	return allocs
+}
+
+// OwnerOfIpID returns the owner of the ipID, "" if it is not allocated
+func (ipam *IPAM) OwnerOfIpID(tenant string, ipamSubnetStr string, ipID uint32) string {
+
+	ipam.mu.Lock()
+	defer ipam.mu.Unlock()
+
+	if pool, exists := ipam.pools[subnetKey(tenant, ipamSubnetStr)]; exists {
+		return pool.owners[ipID]
+	}
+	return ""
+}
+
+// ReserveIpAddrInSubnet allocates a specific address of the subnet to the owner, it fails if the address is in
+// use, ie allocated or configured, or is in a reserved range
+func (ipam *IPAM) ReserveIpAddrInSubnet(tenant string, ipamSubnetStr string, ipAddress string,
+	owner string) (string, uint32, error) {
+
+	ipam.mu.Lock()
+	defer ipam.mu.Unlock()
+
+	pool, err := ipam.getIPAMPool(tenant, ipamSubnetStr)
+	if err != nil {
+		return "", 0, err
+	}
+	ipID, inside := pool.subnet.ipIDOfAddr(ipAddress)
+	if !inside || ipID == 0 || ipID > pool.subnet.maxIpID() {
+		return "", 0, fmt.Errorf("ReserveIpAddrInSubnet: '%s' is not an address of subnet '%s'", ipAddress,
+			ipamSubnetStr)
+	}
+	if pool.reservedRange(ipID) != nil {
+		return "", 0, fmt.Errorf("ReserveIpAddrInSubnet: '%s' is reserved in subnet '%s'", ipAddress, ipamSubnetStr)
+	}
+	if pool.subnet.isSetInSubnet(ipID) {
+		return "", 0, fmt.Errorf("ReserveIpAddrInSubnet: '%s' is in use in subnet '%s', owner: '%s'", ipAddress,
+			ipamSubnetStr, pool.owners[ipID])
+	}
+	ipAddrStr, err := pool.subnet.setIpIDInSubnet(ipID)
+	if err != nil {
+		return "", 0, err
+	}
+	pool.owners[ipID] = owner
+
+	return ipAddrStr, ipID, nil
+}
+
+// IpIDOfAddr returns the id of the address in the subnet, false if it is not an address of the subnet
+func IpIDOfAddr(ipamSubnetStr string, ipAddress string) (uint32, bool) {
+
+	subnet, err := newSubnetAllocator(ipamSubnetStr)
+	if err != nil {
+		return 0, false
+	}
+	return subnet.ipIDOfAddr(ipAddress)
+}
+
+// Allocations returns the ids allocated to an owner, ordered by tenant, subnet and id
+func (ipam *IPAM) Allocations() []Allocation {
+
+	ipam.mu.Lock()
+	defer ipam.mu.Unlock()
+
+	allocs := make([]Allocation, 0)
+	for _, pool := range ipam.pools {
+		for ipID, owner := range pool.owners {
+			allocs = append(allocs, Allocation{
+				Tenant:    pool.tenant,
+				Subnet:    pool.subnetStr,
+				IpID:      ipID,
+				IpAddress: pool.subnet.formatIpID(ipID),
+				Owner:     owner,
+			})
+		}
+	}
+	sort.Slice(allocs, func(i, j int) bool {
+		if allocs[i].Tenant != allocs[j].Tenant {
+			return allocs[i].Tenant < allocs[j].Tenant
+		}
+		if allocs[i].Subnet != allocs[j].Subnet {
+			return allocs[i].Subnet < allocs[j].Subnet
+		}
+		return allocs[i].IpID < allocs[j].IpID
+	})
+	return allocs
+}
+
+// Pools returns the utilization of the tenant subnets in use, ordered by tenant and subnet
+func (ipam *IPAM) Pools() []PoolUsage {
+
+	ipam.mu.Lock()
+	defer ipam.mu.Unlock()
+
+	pools := make([]PoolUsage, 0, len(ipam.pools))
+	for _, pool := range ipam.pools {
+		usage := PoolUsage{
+			Tenant:    pool.tenant,
+			Subnet:    pool.subnetStr,
+			Size:      pool.subnet.maxIpID(),
+			Used:      pool.subnet.numSetInSubnet(),
+			Allocated: uint32(len(pool.owners)),
+		}
+		for _, r := range pool.reserved {
+			usage.Reserved += r.last - r.first + 1
 		}
-		ipamSubnetCache[ipamSubnetStr] = ipamSubnet
+		pools = append(pools, usage)
+	}
+	sort.Slice(pools, func(i, j int) bool {
+		if pools[i].Tenant != pools[j].Tenant {
+			return pools[i].Tenant < pools[j].Tenant
+		}
+		return pools[i].Subnet < pools[j].Subnet
+	})
+	return pools
+}
+
+func (ipam *IPAM) DumpSubnet(tenant string, ipamSubnetStr string) (string) {
+
+	ipam.mu.Lock()
+	defer ipam.mu.Unlock()
+
+	pool, err := ipam.getIPAMPool(tenant, ipamSubnetStr)
+	if err != nil {
+		return err.Error()
 	}
-	return fmt.Sprintf("ipam: %s", ipamSubnet)
+	return fmt.Sprintf("ipam: %s, owned: %d, reserved ranges: %d", pool.subnet, len(pool.owners),
+		len(pool.reserved))
+}
+
+// subnetKey is the cache key of the tenant's subnet
+func subnetKey(tenant string, ipamSubnetStr string) string {
+	return tenant + "/" + ipamSubnetStr
 }
 
 func (ipamSubnet *ipamSubnet) String() string {
@@ -121,28 +523,56 @@ func (ipamSubnet *ipamSubnet) setIpIDInSubnet(ipID uint32) (string, error) {
 		return "", fmt.Errorf("setIpIDInSubnet: ipID(%d) not in subnet '%s", ipID, ipamSubnet.subnetStr)
 	}
 
+	ipAddrStr := ipamSubnet.formatIpID(ipID)
+
+	//fmt.Println("setIpIDInSubnet: ", ipAddrStr)
+
+	return ipAddrStr, nil
+}
+
+// formatIpID returns the address of the ipID in the subnet in the form a.b.c.d/x
+func (ipamSubnet *ipamSubnet) formatIpID(ipID uint32) string {
+
 	ipAddru32 := ipamSubnet.ipNetworku32 | ipID
-	ipAddrStr := fmt.Sprintf("%d.", (ipAddru32>>24) & 0xFF) +
+	return fmt.Sprintf("%d.", (ipAddru32>>24) & 0xFF) +
 		fmt.Sprintf("%d.", (ipAddru32>>16) & 0xFF) +
 		fmt.Sprintf("%d.", (ipAddru32>>8) & 0xFF) +
 		fmt.Sprintf("%d", (ipAddru32) & 0xFF) +
 		fmt.Sprintf("/%d", ipamSubnet.numBitsInMask)
+}
 
-	//fmt.Println("setIpIDInSubnet: ", ipAddrStr)
+func (ipamSubnet *ipamSubnet) clearIpIDInSubnet(ipID uint32) {
+	ipamSubnet.bm.Clear(ipID)
+}
 
-	return ipAddrStr, nil
+func (ipamSubnet *ipamSubnet) isSetInSubnet(ipID uint32) bool {
+	return ipamSubnet.bm.IsSet(ipID)
 }
 
-func (ipamSubnet *ipamSubnet) setIpAddrIfInsideSubnet(ipAddressStr string) {
+func (ipamSubnet *ipamSubnet) numSetInSubnet() uint32 {
+	return ipamSubnet.bm.Count()
+}
+
+func (ipamSubnet *ipamSubnet) maxIpID() uint32 {
+	return ipamSubnet.maxID
+}
+
+// ipIDOfAddr returns the id of the address in the subnet, false if the address is not inside the subnet
+func (ipamSubnet *ipamSubnet) ipIDOfAddr(ipAddressStr string) (uint32, bool) {
 
-	// see if this address falls within the subnet, and if it does, set the addr in the bitmap
 	ipAddress := net.ParseIP(ipAddressStr)
-	if ipAddress != nil && ipamSubnet.ipNetwork.Contains(ipAddress) {
+	if ipAddress == nil || !ipamSubnet.ipNetwork.Contains(ipAddress) {
+		return 0, false
+	}
+	ip := ipAddress.To4()
+	ipAddressu32 := uint32(ip[0])<<24 | uint32(ip[1])<<16 | uint32(ip[2])<<8 | uint32(ip[3])
+	return ipAddressu32 &^ ipamSubnet.ipMasku32, true
+}
 
-		//fmt.Println("setIpAddrIfInsideSubnet: ", ipAddress)
-		ip := ipAddress.To4()
-		ipAddressu32 := uint32(ip[0])<<24 | uint32(ip[1])<<16 | uint32(ip[2])<<8 | uint32(ip[3])
-		ipID := ipAddressu32 &^ ipamSubnet.ipMasku32
+func (ipamSubnet *ipamSubnet) setIpAddrIfInsideSubnet(ipAddressStr string) {
+
+	// see if this address falls within the subnet, and if it does, set the addr in the bitmap
+	if ipID, inside := ipamSubnet.ipIDOfAddr(ipAddressStr); inside {
 		//fmt.Println("setIpAddrIfInsideSubnet: ipID", ipID)
 		ipamSubnet.setIpIDInSubnet(ipID)
 	}
@@ -150,19 +580,13 @@ func (ipamSubnet *ipamSubnet) setIpAddrIfInsideSubnet(ipAddressStr string) {
 
 func (ipamSubnet *ipamSubnet) allocateFromSubnet() (string, uint32, error) {
 	freeBit := ipamSubnet.bm.FindFirstClear()
-	if freeBit == 0 {
+	if freeBit == 0 || freeBit > ipamSubnet.maxID {
 		return "", 0, fmt.Errorf("AllocateFromSubnet: all addresses allocated in '%s", ipamSubnet.subnetStr)
 	}
 
 	ipamSubnet.bm.Set(freeBit)
 
-	ipAddru32 := ipamSubnet.ipNetworku32 | freeBit
-
-	ipAddrStr := fmt.Sprintf("%d.", (ipAddru32>>24) & 0xFF) +
-		fmt.Sprintf("%d.", (ipAddru32>>16) & 0xFF) +
-		fmt.Sprintf("%d.", (ipAddru32>>8) & 0xFF) +
-		fmt.Sprintf("%d", (ipAddru32) & 0xFF) +
-		fmt.Sprintf("/%d", ipamSubnet.numBitsInMask)
+	ipAddrStr := ipamSubnet.formatIpID(freeBit)
 
 	//fmt.Println("AllocateFromSubnet: ", ipAddrStr, freeBit )
 
@@ -178,14 +602,24 @@ func newIPAMSubnet(ipSubnetStr string) (*ipamSubnet, error) {
 
 	numBits, _ := n.Mask.Size()
 
-	// example: 32 - /24 is 8 bits so need 2**8 for the bitmap
-	bm := bitmap.NewBitmap((1<<uint32(32 - numBits))-1)
+	// example: 32 - /24 is 8 bits so ids 1 to 2**8-2, id 0 is the network and 2**8-1 the broadcast address,
+	// a /31 is a point to point link without a broadcast address (rfc 3021), and a /32 has no host ids
+	maxID := uint32(1<<uint32(32-numBits)) - 1
+	if numBits <= 30 {
+		maxID--
+	}
+	bmSize := maxID
+	if bmSize == 0 {
+		bmSize = 1
+	}
+	bm := bitmap.NewBitmap(bmSize)
 
 	ipamSubnet := &ipamSubnet{
 		subnetStr:     ipSubnetStr,
 		ipNetworku32:  uint32(n.IP[0])<<24 | uint32(n.IP[1])<<16 | uint32(n.IP[2])<<8 | uint32(n.IP[3]),
 		ipMasku32:     uint32(n.Mask[0])<<24 | uint32(n.Mask[1])<<16 | uint32(n.Mask[2])<<8 | uint32(n.Mask[3]),
 		numBitsInMask: numBits,
+		maxID:         maxID,
 		bm:            bm,
 		ipNetwork:     n,
 	}
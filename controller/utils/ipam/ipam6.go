@@ -0,0 +1,160 @@
+// Copyright (c) 2017 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ipam
+
+import (
+	"fmt"
+	"net"
+)
+
+// An ipv6 subnet can be /64 or bigger so a bitmap of the host part is not an option.  The
+// ipID of an ipv6 address is the low order 32 bits of its host part, so at most 2**32-1
+// addresses are handed out of a subnet, and only the allocated ids are remembered.
+type ipamSubnet6 struct {
+	subnetStr     string // example form 2001:db8:1::/64
+	numBitsInMask int
+	maxID         uint32 // highest ipID in the subnet, ipID 0 is the subnet itself
+	nextID        uint32 // the search for a free ipID resumes here
+	allocated     map[uint32]struct{}
+	ipNetwork     *net.IPNet
+}
+
+// isIPv6Subnet returns true if the subnet string is of the form x:y::/z
+func isIPv6Subnet(ipamSubnetStr string) bool {
+	_, n, err := net.ParseCIDR(ipamSubnetStr)
+	return err == nil && n.IP.To4() == nil
+}
+
+func (ipamSubnet *ipamSubnet6) String() string {
+	return fmt.Sprintf("network: %s, allocated: %d of %d", ipamSubnet.ipNetwork,
+		len(ipamSubnet.allocated), ipamSubnet.maxID)
+}
+
+// formatIpID returns the address of the ipID in the subnet in the form x:y::id/z
+func (ipamSubnet *ipamSubnet6) formatIpID(ipID uint32) string {
+	ip := make(net.IP, net.IPv6len)
+	copy(ip, ipamSubnet.ipNetwork.IP)
+	ip[12] |= byte(ipID >> 24)
+	ip[13] |= byte(ipID >> 16)
+	ip[14] |= byte(ipID >> 8)
+	ip[15] |= byte(ipID)
+	return fmt.Sprintf("%s/%d", ip, ipamSubnet.numBitsInMask)
+}
+
+func (ipamSubnet *ipamSubnet6) setIpIDInSubnet(ipID uint32) (string, error) {
+	if ipID == 0 || ipID > ipamSubnet.maxID {
+		return "", fmt.Errorf("setIpIDInSubnet: ipID(%d) not in subnet '%s", ipID, ipamSubnet.subnetStr)
+	}
+	ipamSubnet.allocated[ipID] = struct{}{}
+
+	return ipamSubnet.formatIpID(ipID), nil
+}
+
+func (ipamSubnet *ipamSubnet6) clearIpIDInSubnet(ipID uint32) {
+	delete(ipamSubnet.allocated, ipID)
+}
+
+func (ipamSubnet *ipamSubnet6) isSetInSubnet(ipID uint32) bool {
+	_, exists := ipamSubnet.allocated[ipID]
+	return exists
+}
+
+func (ipamSubnet *ipamSubnet6) numSetInSubnet() uint32 {
+	return uint32(len(ipamSubnet.allocated))
+}
+
+func (ipamSubnet *ipamSubnet6) maxIpID() uint32 {
+	return ipamSubnet.maxID
+}
+
+// ipIDOfAddr returns the id of the address in the subnet, false if the address is not inside the subnet, or
+// if it has host bits above the low order 32 as those can never be allocated
+func (ipamSubnet *ipamSubnet6) ipIDOfAddr(ipAddressStr string) (uint32, bool) {
+
+	ipAddress := net.ParseIP(ipAddressStr)
+	if ipAddress == nil || ipAddress.To4() != nil || !ipamSubnet.ipNetwork.Contains(ipAddress) {
+		return 0, false
+	}
+	hostBits := make(net.IP, net.IPv6len)
+	for i := range hostBits {
+		hostBits[i] = ipAddress[i] &^ ipamSubnet.ipNetwork.Mask[i]
+	}
+	for i := 0; i < 12; i++ {
+		if hostBits[i] != 0 {
+			return 0, false
+		}
+	}
+	return uint32(hostBits[12])<<24 | uint32(hostBits[13])<<16 | uint32(hostBits[14])<<8 | uint32(hostBits[15]), true
+}
+
+func (ipamSubnet *ipamSubnet6) setIpAddrIfInsideSubnet(ipAddressStr string) {
+
+	// see if this address falls within the subnet, and if it does, remember its ipID
+	if ipID, inside := ipamSubnet.ipIDOfAddr(ipAddressStr); inside {
+		ipamSubnet.setIpIDInSubnet(ipID)
+	}
+}
+
+func (ipamSubnet *ipamSubnet6) allocateFromSubnet() (string, uint32, error) {
+	if uint64(len(ipamSubnet.allocated)) >= uint64(ipamSubnet.maxID) {
+		return "", 0, fmt.Errorf("AllocateFromSubnet: all addresses allocated in '%s", ipamSubnet.subnetStr)
+	}
+
+	// walk forward from where the last search ended, there is a free id so this terminates
+	ipID := ipamSubnet.nextID
+	for {
+		if ipID == 0 || ipID > ipamSubnet.maxID {
+			ipID = 1
+		}
+		if _, used := ipamSubnet.allocated[ipID]; !used {
+			break
+		}
+		ipID++
+	}
+	ipamSubnet.allocated[ipID] = struct{}{}
+	ipamSubnet.nextID = ipID + 1
+
+	return ipamSubnet.formatIpID(ipID), ipID, nil
+}
+
+func newIPAMSubnet6(ipSubnetStr string) (*ipamSubnet6, error) {
+
+	_, n, err := net.ParseCIDR(ipSubnetStr)
+	if err != nil {
+		return nil, err
+	}
+	if n.IP.To4() != nil {
+		return nil, fmt.Errorf("newIPAMSubnet6: not an ipv6 subnet: '%s'", ipSubnetStr)
+	}
+
+	numBits, _ := n.Mask.Size()
+
+	// example: 128 - /120 is 8 bits so ids 1..255, anything /96 or bigger is capped at 32 bits
+	maxID := uint32(0xFFFFFFFF)
+	if hostBits := uint32(128 - numBits); hostBits < 32 {
+		maxID = (1 << hostBits) - 1
+	}
+
+	ipamSubnet := &ipamSubnet6{
+		subnetStr:     ipSubnetStr,
+		numBitsInMask: numBits,
+		maxID:         maxID,
+		nextID:        1,
+		allocated:     make(map[uint32]struct{}),
+		ipNetwork:     n,
+	}
+
+	return ipamSubnet, nil
+}
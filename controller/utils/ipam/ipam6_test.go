@@ -0,0 +1,166 @@
+// Copyright (c) 2017 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ipam
+
+import (
+	"testing"
+)
+
+func TestNewIPAMSubnet6(t *testing.T) {
+	tests := []struct {
+		subnet    string
+		wantMaxID uint32
+		wantErr   bool
+	}{
+		{"2001:db8:1::/126", 3, false},
+		{"2001:db8:1::/120", 255, false},
+		{"2001:db8:1::/97", 0x7FFFFFFF, false},
+		{"2001:db8:1::/96", 0xFFFFFFFF, false},
+		{"2001:db8:1::/64", 0xFFFFFFFF, false},
+		{"10.1.1.0/24", 0, true},
+		{"2001:db8:1::", 0, true},
+	}
+	for _, test := range tests {
+		subnet, err := newIPAMSubnet6(test.subnet)
+		if (err != nil) != test.wantErr {
+			t.Errorf("newIPAMSubnet6('%s') error = %v, wantErr %v", test.subnet, err, test.wantErr)
+			continue
+		}
+		if !test.wantErr && subnet.maxIpID() != test.wantMaxID {
+			t.Errorf("newIPAMSubnet6('%s') maxID = %d, want %d", test.subnet, subnet.maxIpID(), test.wantMaxID)
+		}
+	}
+}
+
+func TestIPAMSubnet6IpIDOfAddr(t *testing.T) {
+	tests := []struct {
+		addr       string
+		wantID     uint32
+		wantInside bool
+	}{
+		{"2001:db8:1::1", 1, true},
+		{"2001:db8:1::1:0", 0x10000, true},
+		{"2001:db8:1::ffff:ffff", 0xFFFFFFFF, true},
+		{"2001:db8:1::", 0, true},
+		{"2001:db8:1::1:0:0", 0, false}, // above the low order 32 bits
+		{"2001:db8:2::1", 0, false},
+		{"10.1.1.1", 0, false},
+		{"bogus", 0, false},
+	}
+	subnet, _ := newIPAMSubnet6("2001:db8:1::/64")
+	for _, test := range tests {
+		ipID, inside := subnet.ipIDOfAddr(test.addr)
+		if inside != test.wantInside || ipID != test.wantID {
+			t.Errorf("ipIDOfAddr('%s') = %d, %v, want %d, %v", test.addr, ipID, inside, test.wantID, test.wantInside)
+		}
+	}
+}
+
+func TestIPAMSubnet6Allocate(t *testing.T) {
+	tests := []struct {
+		name    string
+		subnet  string
+		cfg     *SubnetConfig
+		preset  []string // configured addresses, never allocated
+		want    []string // the allocations in order, "" when the subnet is exhausted
+		release []uint32 // released after the allocations
+		after   []string // the allocations after the release
+	}{
+		{
+			name:   "first free ids",
+			subnet: "2001:db8:1::/64",
+			want:   []string{"2001:db8:1::1/64", "2001:db8:1::2/64", "2001:db8:1::3/64"},
+		},
+		{
+			name:   "exhausted",
+			subnet: "2001:db8:1::/126",
+			want:   []string{"2001:db8:1::1/126", "2001:db8:1::2/126", "2001:db8:1::3/126", ""},
+		},
+		{
+			name:    "released id is handed out again",
+			subnet:  "2001:db8:1::/126",
+			want:    []string{"2001:db8:1::1/126", "2001:db8:1::2/126", "2001:db8:1::3/126", ""},
+			release: []uint32{2},
+			after:   []string{"2001:db8:1::2/126", ""},
+		},
+		{
+			name:   "configured addresses are skipped",
+			subnet: "2001:db8:1::/126",
+			preset: []string{"2001:db8:1::1", "2001:db8:1::3", "2001:db8:2::2"},
+			want:   []string{"2001:db8:1::2/126", ""},
+		},
+		{
+			name:   "reserved range and gateway are skipped",
+			subnet: "2001:db8:1::/125",
+			cfg: &SubnetConfig{
+				ReservedRanges: []string{"2001:db8:1::2-2001:db8:1::4"},
+				Gateway:        "2001:db8:1::1",
+			},
+			want: []string{"2001:db8:1::5/125", "2001:db8:1::6/125", "2001:db8:1::7/125", ""},
+		},
+		{
+			name:    "sequential resumes after the last allocated id",
+			subnet:  "2001:db8:1::/125",
+			cfg:     &SubnetConfig{Sequential: true},
+			want:    []string{"2001:db8:1::1/125", "2001:db8:1::2/125"},
+			release: []uint32{1},
+			after:   []string{"2001:db8:1::3/125"},
+		},
+	}
+	for _, test := range tests {
+		ipam := NewIPAM()
+		if test.cfg != nil {
+			if err := ipam.ConfigureSubnet(test.subnet, *test.cfg); err != nil {
+				t.Errorf("%s: ConfigureSubnet error = %v", test.name, err)
+				continue
+			}
+		}
+		for _, addr := range test.preset {
+			ipam.SetIpAddrIfInsideSubnet("", test.subnet, addr)
+		}
+		check := func(want []string) {
+			for i, wantAddr := range want {
+				addr, _, err := ipam.AllocateFromSubnet("", test.subnet, "owner")
+				if (err != nil) != (wantAddr == "") || addr != wantAddr {
+					t.Errorf("%s: allocation %d = '%s', %v, want '%s'", test.name, i, addr, err, wantAddr)
+				}
+			}
+		}
+		check(test.want)
+		for _, ipID := range test.release {
+			ipam.ReleaseIpIDInSubnet("", test.subnet, ipID)
+		}
+		check(test.after)
+	}
+}
+
+func TestValidateSubnetConfig6(t *testing.T) {
+	tests := []struct {
+		cfg     SubnetConfig
+		wantErr bool
+	}{
+		{SubnetConfig{ReservedRanges: []string{"2001:db8:1::10-2001:db8:1::20"}}, false},
+		{SubnetConfig{Gateway: "2001:db8:1::1"}, false},
+		{SubnetConfig{ReservedRanges: []string{"2001:db8:1::20-2001:db8:1::10"}}, true},
+		{SubnetConfig{ReservedRanges: []string{"2001:db8:2::10"}}, true},
+		{SubnetConfig{ReservedRanges: []string{"2001:db8:1::1-2001:db8:1::2-2001:db8:1::3"}}, true},
+		{SubnetConfig{Gateway: "10.1.1.1"}, true},
+	}
+	for _, test := range tests {
+		if err := ValidateSubnetConfig("2001:db8:1::/64", test.cfg); (err != nil) != test.wantErr {
+			t.Errorf("ValidateSubnetConfig(%+v) error = %v, wantErr %v", test.cfg, err, test.wantErr)
+		}
+	}
+}
@@ -0,0 +1,201 @@
+// Copyright (c) 2017 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ipam
+
+import (
+	"testing"
+)
+
+func TestNewIPAMSubnet(t *testing.T) {
+	tests := []struct {
+		subnet    string
+		wantMaxID uint32
+		wantErr   bool
+	}{
+		{"10.1.0.0/16", 65534, false},
+		{"10.1.1.0/24", 254, false},
+		{"10.1.1.0/30", 2, false},
+		{"10.1.1.0/31", 1, false},
+		{"10.1.1.1/32", 0, false},
+		{"10.1.1.0", 0, true},
+	}
+	for _, test := range tests {
+		subnet, err := newIPAMSubnet(test.subnet)
+		if (err != nil) != test.wantErr {
+			t.Errorf("newIPAMSubnet('%s') error = %v, wantErr %v", test.subnet, err, test.wantErr)
+			continue
+		}
+		if !test.wantErr && subnet.maxIpID() != test.wantMaxID {
+			t.Errorf("newIPAMSubnet('%s') maxID = %d, want %d", test.subnet, subnet.maxIpID(), test.wantMaxID)
+		}
+	}
+}
+
+func TestIPAMSubnetAllocate(t *testing.T) {
+	tests := []struct {
+		name    string
+		subnet  string
+		cfg     *SubnetConfig
+		preset  []string // configured addresses, never allocated
+		want    []string // the allocations in order, "" when the subnet is exhausted
+		release []uint32 // released after the allocations
+		after   []string // the allocations after the release
+	}{
+		{
+			name:   "broadcast address is not handed out",
+			subnet: "10.1.1.0/30",
+			want:   []string{"10.1.1.1/30", "10.1.1.2/30", ""},
+		},
+		{
+			name:   "point to point subnet",
+			subnet: "10.1.1.0/31",
+			want:   []string{"10.1.1.1/31", ""},
+		},
+		{
+			name:   "host subnet has no ids",
+			subnet: "10.1.1.1/32",
+			want:   []string{""},
+		},
+		{
+			name:   "released id is handed out again",
+			subnet: "10.1.1.0/29",
+			want: []string{"10.1.1.1/29", "10.1.1.2/29", "10.1.1.3/29", "10.1.1.4/29", "10.1.1.5/29",
+				"10.1.1.6/29", ""},
+			release: []uint32{3},
+			after:   []string{"10.1.1.3/29", ""},
+		},
+		{
+			name:   "configured addresses are skipped",
+			subnet: "10.1.1.0/30",
+			preset: []string{"10.1.1.1", "10.1.1.3", "10.1.2.2"},
+			want:   []string{"10.1.1.2/30", ""},
+		},
+		{
+			name:   "reserved range and gateway are skipped, broadcast is not handed out",
+			subnet: "10.1.1.0/29",
+			cfg: &SubnetConfig{
+				ReservedRanges: []string{"10.1.1.2-10.1.1.4"},
+				Gateway:        "10.1.1.1",
+			},
+			want: []string{"10.1.1.5/29", "10.1.1.6/29", ""},
+		},
+		{
+			name:    "sequential wraps before the broadcast address",
+			subnet:  "10.1.1.0/30",
+			cfg:     &SubnetConfig{Sequential: true},
+			want:    []string{"10.1.1.1/30", "10.1.1.2/30", ""},
+			release: []uint32{1},
+			after:   []string{"10.1.1.1/30", ""},
+		},
+	}
+	for _, test := range tests {
+		ipam := NewIPAM()
+		if test.cfg != nil {
+			if err := ipam.ConfigureSubnet(test.subnet, *test.cfg); err != nil {
+				t.Errorf("%s: ConfigureSubnet error = %v", test.name, err)
+				continue
+			}
+		}
+		for _, addr := range test.preset {
+			ipam.SetIpAddrIfInsideSubnet("", test.subnet, addr)
+		}
+		check := func(want []string) {
+			for i, wantAddr := range want {
+				addr, _, err := ipam.AllocateFromSubnet("", test.subnet, "owner")
+				if (err != nil) != (wantAddr == "") || addr != wantAddr {
+					t.Errorf("%s: allocation %d = '%s', %v, want '%s'", test.name, i, addr, err, wantAddr)
+				}
+			}
+		}
+		check(test.want)
+		for _, ipID := range test.release {
+			ipam.ReleaseIpIDInSubnet("", test.subnet, ipID)
+		}
+		check(test.after)
+	}
+}
+
+func TestReserveIpAddrInSubnet(t *testing.T) {
+	tests := []struct {
+		addr    string
+		wantErr bool
+	}{
+		{"10.1.1.1", false},
+		{"10.1.1.254", false},
+		{"10.1.1.0", true},   // network
+		{"10.1.1.255", true}, // broadcast
+		{"10.1.2.1", true},
+	}
+	for _, test := range tests {
+		ipam := NewIPAM()
+		_, _, err := ipam.ReserveIpAddrInSubnet("", "10.1.1.0/24", test.addr, "owner")
+		if (err != nil) != test.wantErr {
+			t.Errorf("ReserveIpAddrInSubnet('%s') error = %v, wantErr %v", test.addr, err, test.wantErr)
+		}
+	}
+}
+
+func TestOwnerAllocations(t *testing.T) {
+	ipam := NewIPAM()
+	owners := []struct {
+		tenant string
+		subnet string
+		owner  string
+	}{
+		{"", "10.1.1.0/24", "sfc1/c1/p1"},
+		{"", "10.1.1.0/24", "sfc1/c2/p1"},
+		{"t1", "10.1.1.0/24", "sfc1/c1/p2"},
+		{"", "2001:db8:1::/64", "sfc1/c1/p1"},
+		{"", "10.1.1.0/24", "sfc10/c1/p1"},
+		{"", "10.1.1.0/24", "manual:sfc1/c1/p1"},
+	}
+	for _, o := range owners {
+		if _, _, err := ipam.AllocateFromSubnet(o.tenant, o.subnet, o.owner); err != nil {
+			t.Fatalf("AllocateFromSubnet('%s', '%s', '%s') error: %v", o.tenant, o.subnet, o.owner, err)
+		}
+	}
+
+	tests := []struct {
+		prefix string
+		want   []string
+	}{
+		{"sfc1/", []string{"sfc1/c1/p1", "sfc1/c2/p1", "sfc1/c1/p1", "sfc1/c1/p2"}},
+		{"sfc10/", []string{"sfc10/c1/p1"}},
+		{"sfc2/", []string{}},
+	}
+	for _, test := range tests {
+		allocs := ipam.OwnerAllocations(test.prefix)
+		if len(allocs) != len(test.want) {
+			t.Errorf("OwnerAllocations('%s') = %v, want owners %v", test.prefix, allocs, test.want)
+			continue
+		}
+		for i, alloc := range allocs {
+			if alloc.Owner != test.want[i] {
+				t.Errorf("OwnerAllocations('%s')[%d] owner = '%s', want '%s'", test.prefix, i, alloc.Owner,
+					test.want[i])
+			}
+		}
+	}
+
+	for _, alloc := range ipam.OwnerAllocations("sfc1/") {
+		ipam.ReleaseIpIDInSubnet(alloc.Tenant, alloc.Subnet, alloc.IpID)
+	}
+	if allocs := ipam.OwnerAllocations("sfc1/"); len(allocs) != 0 {
+		t.Errorf("OwnerAllocations('sfc1/') after release = %v, want none", allocs)
+	}
+	if allocs := ipam.Allocations(); len(allocs) != 2 {
+		t.Errorf("Allocations() after release = %v, want the sfc10 and manual ones", allocs)
+	}
+}
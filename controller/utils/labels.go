@@ -0,0 +1,87 @@
+// Copyright (c) 2017 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// FormatLabels returns the labels as a sorted "k1=v1,k2=v2" string so it can
+// be used as the description of rendered vpp-agent objects
+func FormatLabels(labels map[string]string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, k+"="+labels[k])
+	}
+	return strings.Join(pairs, ",")
+}
+
+// ParseLabelSelector parses a "k1=v1,k2=v2" selector into a map, a key with
+// no "=value" matches any value of that key
+func ParseLabelSelector(selector string) (map[string]string, error) {
+	labels := make(map[string]string)
+	for _, pair := range strings.Split(selector, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		kv := strings.SplitN(pair, "=", 2)
+		key := strings.TrimSpace(kv[0])
+		if key == "" {
+			return nil, fmt.Errorf("invalid label selector: '%s'", selector)
+		}
+		if len(kv) == 1 {
+			labels[key] = ""
+		} else {
+			labels[key] = strings.TrimSpace(kv[1])
+		}
+	}
+	return labels, nil
+}
+
+// MatchLabels returns true if every key in the selector is in labels, and the
+// values match for selector keys that have a value
+func MatchLabels(selector map[string]string, labels map[string]string) bool {
+	for k, v := range selector {
+		lv, exists := labels[k]
+		if !exists || (v != "" && v != lv) {
+			return false
+		}
+	}
+	return true
+}
+
+// ValidateLabels ensures label keys are non empty and contain no ',' or '='
+// so the labels can always be expressed as a selector
+func ValidateLabels(labels map[string]string) error {
+	for k := range labels {
+		if k == "" || strings.ContainsAny(k, ",=") {
+			return fmt.Errorf("invalid label key: '%s'", k)
+		}
+		if strings.Contains(labels[k], ",") {
+			return fmt.Errorf("invalid value for label '%s': '%s'", k, labels[k])
+		}
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+// Copyright (c) 2017 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFormatLabels(t *testing.T) {
+	tests := []struct {
+		labels map[string]string
+		want   string
+	}{
+		{nil, ""},
+		{map[string]string{"app": "fw"}, "app=fw"},
+		{map[string]string{"tier": "edge", "app": "fw", "env": ""}, "app=fw,env=,tier=edge"},
+	}
+	for _, test := range tests {
+		if got := FormatLabels(test.labels); got != test.want {
+			t.Errorf("FormatLabels(%v) = '%s', want '%s'", test.labels, got, test.want)
+		}
+	}
+}
+
+func TestParseLabelSelector(t *testing.T) {
+	tests := []struct {
+		selector string
+		want     map[string]string
+		wantErr  bool
+	}{
+		{"", map[string]string{}, false},
+		{"app=fw", map[string]string{"app": "fw"}, false},
+		{" app = fw , tier ", map[string]string{"app": "fw", "tier": ""}, false},
+		{"app=fw,,tier=edge", map[string]string{"app": "fw", "tier": "edge"}, false},
+		{"a=b=c", map[string]string{"a": "b=c"}, false},
+		{"=fw", nil, true},
+		{"app=fw, =edge", nil, true},
+	}
+	for _, test := range tests {
+		got, err := ParseLabelSelector(test.selector)
+		if (err != nil) != test.wantErr {
+			t.Errorf("ParseLabelSelector('%s') error = %v, wantErr %v", test.selector, err, test.wantErr)
+			continue
+		}
+		if !test.wantErr && !reflect.DeepEqual(got, test.want) {
+			t.Errorf("ParseLabelSelector('%s') = %v, want %v", test.selector, got, test.want)
+		}
+	}
+}
+
+func TestMatchLabels(t *testing.T) {
+	labels := map[string]string{"app": "fw", "tier": "edge"}
+	tests := []struct {
+		selector map[string]string
+		labels   map[string]string
+		want     bool
+	}{
+		{nil, labels, true},
+		{nil, nil, true},
+		{map[string]string{"app": "fw"}, labels, true},
+		{map[string]string{"app": "fw", "tier": "edge"}, labels, true},
+		{map[string]string{"tier": ""}, labels, true},
+		{map[string]string{"app": "lb"}, labels, false},
+		{map[string]string{"env": ""}, labels, false},
+		{map[string]string{"app": "fw"}, nil, false},
+	}
+	for _, test := range tests {
+		if got := MatchLabels(test.selector, test.labels); got != test.want {
+			t.Errorf("MatchLabels(%v, %v) = %v, want %v", test.selector, test.labels, got, test.want)
+		}
+	}
+}
+
+func TestValidateLabels(t *testing.T) {
+	tests := []struct {
+		labels  map[string]string
+		wantErr bool
+	}{
+		{nil, false},
+		{map[string]string{"app": "fw", "env": ""}, false},
+		{map[string]string{"": "fw"}, true},
+		{map[string]string{"a,b": "fw"}, true},
+		{map[string]string{"a=b": "fw"}, true},
+		{map[string]string{"app": "fw,lb"}, true},
+	}
+	for _, test := range tests {
+		if err := ValidateLabels(test.labels); (err != nil) != test.wantErr {
+			t.Errorf("ValidateLabels(%v) error = %v, wantErr %v", test.labels, err, test.wantErr)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright (c) 2017 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// MaxMacPrefixOctets is the longest mac_prefix, the generated part of a mac is at least 3 octets
+const MaxMacPrefixOctets = 3
+
+// ParseMacPrefix parses a mac prefix of one to three octets, ie: 02 or 02:5A:01, the first octet must be
+// locally administered and unicast
+func ParseMacPrefix(prefix string) ([]byte, error) {
+
+	octets := strings.Split(prefix, ":")
+	if prefix == "" || len(octets) > MaxMacPrefixOctets {
+		return nil, fmt.Errorf("invalid mac_prefix: '%s', must be 1 to %d octets, ie: 02 or 02:5A",
+			prefix, MaxMacPrefixOctets)
+	}
+	bytes := make([]byte, len(octets))
+	for i, octet := range octets {
+		b, err := strconv.ParseUint(octet, 16, 8)
+		if err != nil || len(octet) != 2 {
+			return nil, fmt.Errorf("invalid mac_prefix: '%s', octet: '%s' is not 2 hex digits", prefix, octet)
+		}
+		bytes[i] = byte(b)
+	}
+	if bytes[0]&0x02 == 0 || bytes[0]&0x01 != 0 {
+		return nil, fmt.Errorf("invalid mac_prefix: '%s', the first octet must be locally administered "+
+			"unicast, ie: 02", prefix)
+	}
+	return bytes, nil
+}
+
+// MaxMacID returns the highest id which fits in the octets of a mac after the prefix
+func MaxMacID(prefix []byte) uint32 {
+	idBits := uint(8 * (6 - len(prefix)))
+	if idBits >= 32 {
+		return 0xFFFFFFFF
+	}
+	return uint32(1)<<idBits - 1
+}
+
+// FormatMacAddress returns the mac of the prefix followed by the id, ie: 02:00:00:00:00:01
+func FormatMacAddress(prefix []byte, id uint32) string {
+
+	mac := make([]byte, 6)
+	copy(mac, prefix)
+	for i := 5; i >= len(prefix); i-- {
+		mac[i] = byte(id)
+		id >>= 8
+	}
+
+	octets := make([]string, len(mac))
+	for i, b := range mac {
+		octets[i] = fmt.Sprintf("%02X", b)
+	}
+	return strings.Join(octets, ":")
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2017 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestParseMacPrefix(t *testing.T) {
+	tests := []struct {
+		prefix  string
+		want    []byte
+		wantErr bool
+	}{
+		{"02", []byte{0x02}, false},
+		{"0a:5B", []byte{0x0A, 0x5B}, false},
+		{"02:5A:01", []byte{0x02, 0x5A, 0x01}, false},
+		{"", nil, true},
+		{"02:5A:01:01", nil, true}, // leaves too few octets for the ids
+		{"00", nil, true},          // not locally administered
+		{"03", nil, true},          // multicast
+		{"2", nil, true},
+		{"02:5", nil, true},
+		{"02:zz", nil, true},
+	}
+	for _, test := range tests {
+		prefix, err := ParseMacPrefix(test.prefix)
+		if (err != nil) != test.wantErr {
+			t.Errorf("ParseMacPrefix('%s') error = %v, wantErr %v", test.prefix, err, test.wantErr)
+			continue
+		}
+		if !bytes.Equal(prefix, test.want) {
+			t.Errorf("ParseMacPrefix('%s') = %v, want %v", test.prefix, prefix, test.want)
+		}
+	}
+}
+
+func TestMaxMacID(t *testing.T) {
+	tests := []struct {
+		prefix []byte
+		want   uint32
+	}{
+		{[]byte{0x02}, 0xFFFFFFFF},
+		{[]byte{0x02, 0x00}, 0xFFFFFFFF},
+		{[]byte{0x02, 0x00, 0x00}, 0xFFFFFF},
+	}
+	for _, test := range tests {
+		if max := MaxMacID(test.prefix); max != test.want {
+			t.Errorf("MaxMacID(%v) = %#x, want %#x", test.prefix, max, test.want)
+		}
+	}
+}
+
+func TestFormatMacAddress(t *testing.T) {
+	tests := []struct {
+		prefix []byte
+		id     uint32
+		want   string
+	}{
+		{[]byte{0x02}, 1, "02:00:00:00:00:01"},
+		{[]byte{0x02}, 0xFFFFFFFF, "02:00:FF:FF:FF:FF"},
+		{[]byte{0x02, 0x5A}, 0x01020304, "02:5A:01:02:03:04"},
+		{[]byte{0x0A, 0x5A, 0x01}, 0xABCDEF, "0A:5A:01:AB:CD:EF"},
+	}
+	for _, test := range tests {
+		if mac := FormatMacAddress(test.prefix, test.id); mac != test.want {
+			t.Errorf("FormatMacAddress(%v, %#x) = '%s', want '%s'", test.prefix, test.id, mac, test.want)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright (c) 2017 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"github.com/ligato/sfc-controller/controller/model/controller"
+)
+
+// The secrets of the config, ie: the memif secrets and the api_tokens of the
+// tenants, are write only.  They are never logged, nor returned by the REST
+// api, the entities are logged and returned as redacted copies.
+
+// RedactMemifParms returns a copy of the memif parms without the secret
+func RedactMemifParms(parms *controller.MemifParms) *controller.MemifParms {
+	if parms == nil || parms.Secret == "" {
+		return parms
+	}
+	redacted := *parms
+	redacted.Secret = ""
+	return &redacted
+}
+
+// RedactSystemParameters returns a copy of the system parameters without the memif secret
+func RedactSystemParameters(sp *controller.SystemParameters) *controller.SystemParameters {
+	if sp == nil {
+		return nil
+	}
+	redacted := *sp
+	redacted.MemifParms = RedactMemifParms(sp.MemifParms)
+	return &redacted
+}
+
+// RedactSfcElement returns a copy of the sfc element without its memif secret
+func RedactSfcElement(element *controller.SfcEntity_SfcElement) *controller.SfcEntity_SfcElement {
+	if element == nil {
+		return nil
+	}
+	redacted := *element
+	redacted.MemifParms = RedactMemifParms(element.MemifParms)
+	return &redacted
+}
+
+// RedactSfcEntity returns a copy of the sfc without the memif secrets of its elements
+func RedactSfcEntity(sfc *controller.SfcEntity) *controller.SfcEntity {
+	if sfc == nil {
+		return nil
+	}
+	redacted := *sfc
+	redacted.Elements = make([]*controller.SfcEntity_SfcElement, len(sfc.Elements))
+	for i, element := range sfc.Elements {
+		redacted.Elements[i] = RedactSfcElement(element)
+	}
+	return &redacted
+}
+
+// RedactTenant returns a copy of the tenant without its api_token
+func RedactTenant(tenant *controller.Tenant) *controller.Tenant {
+	if tenant == nil {
+		return nil
+	}
+	redacted := *tenant
+	redacted.ApiToken = ""
+	return &redacted
+}
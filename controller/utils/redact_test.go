@@ -0,0 +1,63 @@
+// Copyright (c) 2017 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ligato/sfc-controller/controller/model/controller"
+)
+
+func TestRedact(t *testing.T) {
+	const secret = "s3cr3t"
+
+	sp := &controller.SystemParameters{Mtu: 1500, MemifParms: &controller.MemifParms{Secret: secret, RingSize: 1024}}
+	sfc := &controller.SfcEntity{
+		Name: "s1",
+		Elements: []*controller.SfcEntity_SfcElement{
+			{Container: "vnf1", PortLabel: "port1", MemifParms: &controller.MemifParms{Secret: secret}},
+			{Container: "vnf2", PortLabel: "port1"},
+		},
+	}
+	tenant := &controller.Tenant{Name: "t1", ApiToken: secret}
+
+	tests := []struct {
+		name     string
+		redacted interface{}
+	}{
+		{"system parameters", RedactSystemParameters(sp)},
+		{"sfc", RedactSfcEntity(sfc)},
+		{"sfc element", RedactSfcElement(sfc.Elements[0])},
+		{"tenant", RedactTenant(tenant)},
+	}
+	for _, test := range tests {
+		if logged := fmt.Sprint(test.redacted); strings.Contains(logged, secret) {
+			t.Errorf("%s: %s has the secret", test.name, logged)
+		}
+	}
+
+	// the entities themselves keep their secrets
+	if sp.MemifParms.Secret != secret || sfc.Elements[0].MemifParms.Secret != secret || tenant.ApiToken != secret {
+		t.Errorf("the redaction changed the entities")
+	}
+	if RedactSystemParameters(sp).MemifParms.RingSize != 1024 {
+		t.Errorf("the redaction dropped more than the secret")
+	}
+	if RedactSystemParameters(nil) != nil || RedactSfcEntity(nil) != nil || RedactTenant(nil) != nil {
+		t.Errorf("the redaction of nil is not nil")
+	}
+}
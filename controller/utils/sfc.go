@@ -0,0 +1,68 @@
+// Copyright (c) 2017 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"fmt"
+
+	"github.com/ligato/sfc-controller/controller/model/controller"
+)
+
+// VrfIDOfElement returns the vrf of the element's vswitch i/f, if not configured it is the vrf of the element's
+// l3vrf routes, the routes of an element must all be in the vrf of its i/f
+func VrfIDOfElement(sfc *controller.SfcEntity, sfcEntityElement *controller.SfcEntity_SfcElement) (uint32, error) {
+
+	vrfID := sfcEntityElement.VrfId
+	for i, l3VRFRoute := range sfcEntityElement.GetL3VrfRoutes() {
+		if sfcEntityElement.VrfId != 0 && l3VRFRoute.VrfId == 0 {
+			continue // in the vrf of the i/f
+		}
+		if i == 0 && sfcEntityElement.VrfId == 0 {
+			vrfID = l3VRFRoute.VrfId
+		} else if l3VRFRoute.VrfId != vrfID {
+			return 0, fmt.Errorf("sfc: '%s', container: '%s', port: '%s', routes in vrfs %d and %d",
+				sfc.Name, sfcEntityElement.Container, sfcEntityElement.PortLabel, vrfID, l3VRFRoute.VrfId)
+		}
+	}
+
+	return vrfID, nil
+}
+
+// SfcClassifierAttachment returns what the n/s sfc shares with the other sfcs whose traffic is classified, the
+// nic of the host, or the tunnel bridge of the sfc's tenant to the peer when the sfc has no dedicated vni, "" if it
+// shares nothing
+func SfcClassifierAttachment(sfc *controller.SfcEntity) string {
+
+	for _, sfcEntityElement := range sfc.GetElements() {
+		switch sfc.Type {
+		case controller.SfcType_SFC_NS_NIC_BD, controller.SfcType_SFC_NS_NIC_VRF:
+			if sfcEntityElement.Type == controller.SfcElementType_HOST_ENTITY {
+				return sfc.Type.String() + "/" + sfcEntityElement.Container + "/" + sfcEntityElement.PortLabel
+			}
+		case controller.SfcType_SFC_NS_VXLAN:
+			if sfc.DedicatedVni {
+				return ""
+			}
+			if sfcEntityElement.Type == controller.SfcElementType_HOST_ENTITY ||
+				sfcEntityElement.Type == controller.SfcElementType_EXTERNAL_ENTITY {
+				return sfc.Type.String() + "/" + sfcEntityElement.Container + ScopeSeparator + sfc.Tenant
+			}
+		default:
+			return ""
+		}
+	}
+
+	return ""
+}
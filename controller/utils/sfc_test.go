@@ -0,0 +1,76 @@
+// Copyright (c) 2017 Cisco and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"testing"
+
+	"github.com/ligato/sfc-controller/controller/model/controller"
+)
+
+func TestVrfIDOfElement(t *testing.T) {
+	tests := []struct {
+		name    string
+		vrfID   uint32
+		routes  []uint32
+		want    uint32
+		wantErr bool
+	}{
+		{"no vrf", 0, nil, 0, false},
+		{"i/f vrf", 10, []uint32{0, 10}, 10, false},
+		{"route vrf", 0, []uint32{10, 10}, 10, false},
+		{"routes in two vrfs", 0, []uint32{10, 11}, 0, true},
+		{"route in another vrf than the i/f", 10, []uint32{11}, 0, true},
+	}
+	for _, test := range tests {
+		element := &controller.SfcEntity_SfcElement{Container: "vnf1", PortLabel: "port1", VrfId: test.vrfID}
+		for _, vrfID := range test.routes {
+			element.L3VrfRoutes = append(element.L3VrfRoutes, &controller.L3VRFRoute{VrfId: vrfID})
+		}
+		vrfID, err := VrfIDOfElement(&controller.SfcEntity{Name: "sfc1"}, element)
+		if (err != nil) != test.wantErr || vrfID != test.want {
+			t.Errorf("%s: vrf %d, error %v, want vrf %d, error %t", test.name, vrfID, err, test.want, test.wantErr)
+		}
+	}
+}
+
+func TestSfcClassifierAttachment(t *testing.T) {
+	he := &controller.SfcEntity_SfcElement{Container: "h1", PortLabel: "eth0",
+		Type: controller.SfcElementType_HOST_ENTITY}
+	ee := &controller.SfcEntity_SfcElement{Container: "ee1", Type: controller.SfcElementType_EXTERNAL_ENTITY}
+	vnf := &controller.SfcEntity_SfcElement{Container: "vnf1", PortLabel: "port1",
+		Type: controller.SfcElementType_VPP_CONTAINER_MEMIF}
+	tests := []struct {
+		name string
+		sfc  *controller.SfcEntity
+		want string
+	}{
+		{"nic", &controller.SfcEntity{Type: controller.SfcType_SFC_NS_NIC_VRF,
+			Elements: []*controller.SfcEntity_SfcElement{vnf, he}}, "SFC_NS_NIC_VRF/h1/eth0"},
+		{"tunnel", &controller.SfcEntity{Type: controller.SfcType_SFC_NS_VXLAN,
+			Elements: []*controller.SfcEntity_SfcElement{ee, vnf}}, "SFC_NS_VXLAN/ee1@"},
+		{"tenant tunnel", &controller.SfcEntity{Type: controller.SfcType_SFC_NS_VXLAN, Tenant: "t1",
+			Elements: []*controller.SfcEntity_SfcElement{ee, vnf}}, "SFC_NS_VXLAN/ee1@t1"},
+		{"dedicated vni", &controller.SfcEntity{Type: controller.SfcType_SFC_NS_VXLAN, DedicatedVni: true,
+			Elements: []*controller.SfcEntity_SfcElement{ee, vnf}}, ""},
+		{"e/w", &controller.SfcEntity{Type: controller.SfcType_SFC_EW_BD,
+			Elements: []*controller.SfcEntity_SfcElement{vnf}}, ""},
+	}
+	for _, test := range tests {
+		if attachment := SfcClassifierAttachment(test.sfc); attachment != test.want {
+			t.Errorf("%s: attachment '%s', want '%s'", test.name, attachment, test.want)
+		}
+	}
+}
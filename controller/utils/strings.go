@@ -14,6 +14,30 @@
 
 package utils
 
+import (
+	"fmt"
+	"strings"
+)
+
+// ScopeSeparator joins an entity name and the tenant or sfc it is scoped to,
+// entity names cannot contain it so a scoped name is never ambiguous
+const ScopeSeparator = "@"
+
+// ValidateEntityName checks the name is set and does not contain the scope separator, or a '/' which
+// separates the name from the rest of its etcd key and of the owners of its allocations
+func ValidateEntityName(name string) error {
+	if name == "" {
+		return fmt.Errorf("Missing entity name")
+	}
+	if strings.Contains(name, "/") {
+		return fmt.Errorf("entity name: '%s' cannot contain '/'", name)
+	}
+	if strings.Contains(name, ScopeSeparator) {
+		return fmt.Errorf("entity name: '%s' cannot contain '%s'", name, ScopeSeparator)
+	}
+	return nil
+}
+
 // TruncateString returns input string possibly truncated to at most n characters.
 func TruncateString(s string, n int) string {
 	if len(s) <= n {